@@ -3,6 +3,8 @@ package e2b
 import (
 	"encoding/json"
 	"fmt"
+	"sync"
+	"time"
 )
 
 // ChartType represents the type of chart.
@@ -83,12 +85,52 @@ type PointData struct {
 	Points []Point `json:"points"`
 }
 
-// Point represents a single data point.
+// Point represents a single data point. Y is a float64. X is a time.Time on
+// a datetime scale, and a float64 or, on a categorical scale, a string
+// otherwise.
 type Point struct {
 	X any `json:"x"`
 	Y any `json:"y"`
 }
 
+// TimePoint is a data point on a datetime scale.
+type TimePoint struct {
+	T time.Time
+	Y float64
+}
+
+// FloatPoints returns the points of the series as (x, y) pairs. It returns
+// an error wrapping ErrInvalidArgument if an X value is not a number, as on
+// datetime and categorical scales.
+func (d *PointData) FloatPoints() ([][2]float64, error) {
+	points := make([][2]float64, 0, len(d.Points))
+	for i, p := range d.Points {
+		x, xOK := p.X.(float64)
+		y, yOK := p.Y.(float64)
+		if !xOK || !yOK {
+			return nil, fmt.Errorf("%w: point %d of series %q is not numeric: (%v, %v)", ErrInvalidArgument, i, d.Label, p.X, p.Y)
+		}
+		points = append(points, [2]float64{x, y})
+	}
+	return points, nil
+}
+
+// TimePoints returns the points of a series on a datetime scale. It returns
+// an error wrapping ErrInvalidArgument if an X value is not a time, as on
+// other scales.
+func (d *PointData) TimePoints() ([]TimePoint, error) {
+	points := make([]TimePoint, 0, len(d.Points))
+	for i, p := range d.Points {
+		t, tOK := p.X.(time.Time)
+		y, yOK := p.Y.(float64)
+		if !tOK || !yOK {
+			return nil, fmt.Errorf("%w: point %d of series %q is not a time point: (%v, %v)", ErrInvalidArgument, i, d.Label, p.X, p.Y)
+		}
+		points = append(points, TimePoint{T: t, Y: y})
+	}
+	return points, nil
+}
+
 // PointChart contains fields for point-based charts (line, scatter).
 type PointChart struct {
 	Chart2D
@@ -99,6 +141,11 @@ type PointChart struct {
 	YTickLabels []string    `json:"y_tick_labels"`
 	YScale      ScaleType   `json:"y_scale"`
 	Data        []PointData `json:"-"`
+
+	// DroppedPoints is the number of points left out of Data because they
+	// were malformed, had a Y value that is not a number or, on a datetime
+	// scale, an X value that is not a valid date.
+	DroppedPoints int `json:"-"`
 }
 
 // LineChart represents a line chart.
@@ -190,7 +237,82 @@ func (c *SuperChart) ChartType() ChartType {
 	return ChartTypeSuperChart
 }
 
-// DeserializeChart deserializes a chart from a map.
+// FlattenCharts returns the leaf charts of chart: the chart itself unless it
+// is a SuperChart, in which case its SubCharts are flattened recursively,
+// in order. It returns nil for a nil chart.
+//
+// Example:
+//
+//	for _, leaf := range e2b.FlattenCharts(chart) {
+//	    fmt.Println(leaf.ChartType(), leaf.ChartTitle())
+//	}
+func FlattenCharts(chart Chart) []Chart {
+	var leaves []Chart
+	var walk func(Chart)
+	walk = func(c Chart) {
+		switch c := c.(type) {
+		case nil:
+		case *SuperChart:
+			if c == nil {
+				return
+			}
+			for _, sub := range c.SubCharts {
+				walk(sub)
+			}
+		default:
+			leaves = append(leaves, c)
+		}
+	}
+	walk(chart)
+	return leaves
+}
+
+// chartDecoders holds the decoders registered with RegisterChartType.
+var (
+	chartDecodersMu sync.RWMutex
+	chartDecoders   = map[ChartType]func(map[string]any) (Chart, error){}
+)
+
+// builtinChartTypes are the chart types DeserializeChart decodes itself.
+var builtinChartTypes = map[ChartType]bool{
+	ChartTypeLine:          true,
+	ChartTypeScatter:       true,
+	ChartTypeBar:           true,
+	ChartTypePie:           true,
+	ChartTypeBoxAndWhisker: true,
+	ChartTypeSuperChart:    true,
+}
+
+// RegisterChartType registers a decoder that DeserializeChart uses for
+// charts of type t, such as chart kinds added after this release or
+// produced by custom kernels, instead of returning them as a BaseChart.
+// Registering a type again replaces its decoder. It panics if decoder is
+// nil or t is one of the built-in chart types.
+//
+// Charts returned by the decoder are serialized by SerializeChart from
+// their ToMap method.
+//
+// Example:
+//
+//	e2b.RegisterChartType("heatmap", func(data map[string]any) (e2b.Chart, error) {
+//	    return newHeatmapChart(data)
+//	})
+func RegisterChartType(t ChartType, decoder func(map[string]any) (Chart, error)) {
+	if decoder == nil {
+		panic("e2b: RegisterChartType decoder is nil")
+	}
+	if builtinChartTypes[t] {
+		panic(fmt.Sprintf("e2b: RegisterChartType of built-in chart type %q", t))
+	}
+
+	chartDecodersMu.Lock()
+	defer chartDecodersMu.Unlock()
+	chartDecoders[t] = decoder
+}
+
+// DeserializeChart deserializes a chart from a map. Chart types that are
+// not built in are decoded by the decoder registered with
+// RegisterChartType, or returned as a BaseChart of type ChartTypeUnknown.
 func DeserializeChart(data map[string]any) (Chart, error) {
 	if data == nil {
 		return nil, nil
@@ -214,7 +336,7 @@ func DeserializeChart(data map[string]any) (Chart, error) {
 			return nil, fmt.Errorf("failed to unmarshal line chart: %w", err)
 		}
 		chart.RawData = data
-		chart.Data = parsePointData(data)
+		chart.Data, chart.DroppedPoints = parsePointData(data, chart.XScale)
 		return &chart, nil
 
 	case ChartTypeScatter:
@@ -223,7 +345,7 @@ func DeserializeChart(data map[string]any) (Chart, error) {
 			return nil, fmt.Errorf("failed to unmarshal scatter chart: %w", err)
 		}
 		chart.RawData = data
-		chart.Data = parsePointData(data)
+		chart.Data, chart.DroppedPoints = parsePointData(data, chart.XScale)
 		return &chart, nil
 
 	case ChartTypeBar:
@@ -273,6 +395,13 @@ func DeserializeChart(data map[string]any) (Chart, error) {
 		return &chart, nil
 
 	default:
+		chartDecodersMu.RLock()
+		decoder, ok := chartDecoders[ChartType(chartType)]
+		chartDecodersMu.RUnlock()
+		if ok {
+			return decoder(data)
+		}
+
 		// Unknown chart type - return base chart
 		var chart BaseChart
 		if err := json.Unmarshal(rawJSON, &chart); err != nil {
@@ -284,13 +413,210 @@ func DeserializeChart(data map[string]any) (Chart, error) {
 	}
 }
 
-// parsePointData extracts point data from chart data.
-func parsePointData(data map[string]any) []PointData {
+// SerializeChart converts a chart to a map with the same shape the code
+// interpreter produces, built from the chart's typed fields, so that
+// DeserializeChart(SerializeChart(c)) yields an equal chart. Charts of an
+// unknown type are returned as their raw data.
+//
+// Example:
+//
+//	data, err := e2b.SerializeChart(chart)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	restored, err := e2b.DeserializeChart(data)
+func SerializeChart(c Chart) (map[string]any, error) {
+	switch c := c.(type) {
+	case nil:
+		return nil, nil
+	case *LineChart:
+		return serializePointChart(ChartTypeLine, &c.PointChart), nil
+	case *ScatterChart:
+		return serializePointChart(ChartTypeScatter, &c.PointChart), nil
+	case *BarChart:
+		m := serializeChart2D(ChartTypeBar, &c.Chart2D)
+		elements := make([]any, 0, len(c.Data))
+		for _, d := range c.Data {
+			elements = append(elements, map[string]any{"label": d.Label, "group": d.Group, "value": d.Value})
+		}
+		m["elements"] = elements
+		return m, nil
+	case *PieChart:
+		m := map[string]any{"type": string(ChartTypePie), "title": c.Title}
+		elements := make([]any, 0, len(c.Data))
+		for _, d := range c.Data {
+			elements = append(elements, map[string]any{"label": d.Label, "angle": d.Angle, "radius": d.Radius})
+		}
+		m["elements"] = elements
+		return m, nil
+	case *BoxAndWhiskerChart:
+		m := serializeChart2D(ChartTypeBoxAndWhisker, &c.Chart2D)
+		elements := make([]any, 0, len(c.Data))
+		for _, d := range c.Data {
+			outliers := make([]any, 0, len(d.Outliers))
+			for _, o := range d.Outliers {
+				outliers = append(outliers, o)
+			}
+			elements = append(elements, map[string]any{
+				"label":          d.Label,
+				"min":            d.Min,
+				"first_quartile": d.FirstQuartile,
+				"median":         d.Median,
+				"third_quartile": d.ThirdQuartile,
+				"max":            d.Max,
+				"outliers":       outliers,
+			})
+		}
+		m["elements"] = elements
+		return m, nil
+	case *SuperChart:
+		elements := make([]any, 0, len(c.SubCharts))
+		for _, sub := range c.SubCharts {
+			subMap, err := SerializeChart(sub)
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, subMap)
+		}
+		return map[string]any{"type": string(ChartTypeSuperChart), "title": c.Title, "elements": elements}, nil
+	case *BaseChart:
+		if c.RawData != nil {
+			return c.RawData, nil
+		}
+		return map[string]any{"type": string(c.Type), "title": c.Title}, nil
+	default:
+		// Charts of registered types provide their own map
+		if m := c.ToMap(); m != nil {
+			return m, nil
+		}
+		return nil, fmt.Errorf("unsupported chart type %T", c)
+	}
+}
+
+// serializeChart2D returns the fields of a 2D chart as a map.
+func serializeChart2D(chartType ChartType, c *Chart2D) map[string]any {
+	return map[string]any{
+		"type":    string(chartType),
+		"title":   c.Title,
+		"x_label": c.XLabel,
+		"y_label": c.YLabel,
+		"x_unit":  c.XUnit,
+		"y_unit":  c.YUnit,
+	}
+}
+
+// serializePointChart returns the fields and elements of a line or scatter
+// chart as a map.
+func serializePointChart(chartType ChartType, c *PointChart) map[string]any {
+	m := serializeChart2D(chartType, &c.Chart2D)
+	m["x_ticks"] = c.XTicks
+	m["x_tick_labels"] = stringsToAny(c.XTickLabels)
+	m["x_scale"] = string(c.XScale)
+	m["y_ticks"] = c.YTicks
+	m["y_tick_labels"] = stringsToAny(c.YTickLabels)
+	m["y_scale"] = string(c.YScale)
+
+	elements := make([]any, 0, len(c.Data))
+	for _, d := range c.Data {
+		points := make([]any, 0, len(d.Points))
+		for _, p := range d.Points {
+			x := p.X
+			if t, ok := x.(time.Time); ok {
+				x = t.Format(time.RFC3339Nano)
+			}
+			points = append(points, []any{x, p.Y})
+		}
+		elements = append(elements, map[string]any{"label": d.Label, "points": points})
+	}
+	m["elements"] = elements
+	return m
+}
+
+// stringsToAny converts a string slice to the []any form of decoded JSON.
+func stringsToAny(values []string) []any {
+	if values == nil {
+		return nil
+	}
+	result := make([]any, len(values))
+	for i, v := range values {
+		result[i] = v
+	}
+	return result
+}
+
+// marshalChart encodes a chart as the JSON produced by SerializeChart.
+func marshalChart(c Chart) ([]byte, error) {
+	m, err := SerializeChart(c)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(m)
+}
+
+// UnmarshalChart decodes a chart from JSON produced by json.Marshal or
+// SerializeChart, the inbound counterpart of a chart's MarshalJSON.
+//
+// Example:
+//
+//	data, err := json.Marshal(execution.FirstChart())
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	chart, err := e2b.UnmarshalChart(data)
+func UnmarshalChart(data []byte) (Chart, error) {
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to decode chart: %w", err)
+	}
+	return DeserializeChart(m)
+}
+
+// MarshalJSON implements json.Marshaler. It encodes the chart's raw data, or
+// its type and title if it has none.
+func (c *BaseChart) MarshalJSON() ([]byte, error) {
+	return marshalChart(c)
+}
+
+// MarshalJSON implements json.Marshaler, encoding the chart like SerializeChart.
+func (c *LineChart) MarshalJSON() ([]byte, error) {
+	return marshalChart(c)
+}
+
+// MarshalJSON implements json.Marshaler, encoding the chart like SerializeChart.
+func (c *ScatterChart) MarshalJSON() ([]byte, error) {
+	return marshalChart(c)
+}
+
+// MarshalJSON implements json.Marshaler, encoding the chart like SerializeChart.
+func (c *BarChart) MarshalJSON() ([]byte, error) {
+	return marshalChart(c)
+}
+
+// MarshalJSON implements json.Marshaler, encoding the chart like SerializeChart.
+func (c *PieChart) MarshalJSON() ([]byte, error) {
+	return marshalChart(c)
+}
+
+// MarshalJSON implements json.Marshaler, encoding the chart like SerializeChart.
+func (c *BoxAndWhiskerChart) MarshalJSON() ([]byte, error) {
+	return marshalChart(c)
+}
+
+// MarshalJSON implements json.Marshaler, encoding the chart like SerializeChart.
+func (c *SuperChart) MarshalJSON() ([]byte, error) {
+	return marshalChart(c)
+}
+
+// parsePointData extracts point data from chart data and returns it with
+// the number of invalid points that were skipped. On a datetime scale, X
+// values are parsed into time.Time.
+func parsePointData(data map[string]any, xScale ScaleType) ([]PointData, int) {
 	var result []PointData
+	dropped := 0
 
 	elements, ok := data["elements"].([]any)
 	if !ok {
-		return result
+		return result, dropped
 	}
 
 	for _, elem := range elements {
@@ -305,16 +631,54 @@ func parsePointData(data map[string]any) []PointData {
 
 		if points, ok := elemMap["points"].([]any); ok {
 			for _, p := range points {
-				if pArr, ok := p.([]any); ok && len(pArr) >= 2 {
-					pd.Points = append(pd.Points, Point{X: pArr[0], Y: pArr[1]})
+				point, ok := parsePoint(p, xScale)
+				if !ok {
+					dropped++
+					continue
 				}
+				pd.Points = append(pd.Points, point)
 			}
 		}
 
 		result = append(result, pd)
 	}
 
-	return result
+	return result, dropped
+}
+
+// chartTimeLayouts are the layouts of datetime values in chart data, which
+// are ISO 8601 strings with or without a time zone.
+var chartTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// parsePoint parses an [x, y] pair, reporting false if it is invalid.
+func parsePoint(p any, xScale ScaleType) (Point, bool) {
+	pair, ok := p.([]any)
+	if !ok || len(pair) < 2 {
+		return Point{}, false
+	}
+	y, ok := pair[1].(float64)
+	if !ok {
+		return Point{}, false
+	}
+	if xScale != ScaleTypeDatetime {
+		return Point{X: pair[0], Y: y}, true
+	}
+
+	value, ok := pair[0].(string)
+	if !ok {
+		return Point{}, false
+	}
+	for _, layout := range chartTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return Point{X: t, Y: y}, true
+		}
+	}
+	return Point{}, false
 }
 
 // parseBarData extracts bar data from chart data.
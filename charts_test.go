@@ -1,11 +1,16 @@
 package e2b
 
 import (
+	"encoding/json"
+	"errors"
+	"reflect"
 	"testing"
+	"time"
 )
 
-func TestDeserializeLineChart(t *testing.T) {
-	data := map[string]any{
+// lineChartFixture returns line chart data as produced by the code interpreter.
+func lineChartFixture() map[string]any {
+	return map[string]any{
 		"type":          "line",
 		"title":         "Test Line Chart",
 		"x_label":       "X Axis",
@@ -27,6 +32,180 @@ func TestDeserializeLineChart(t *testing.T) {
 			},
 		},
 	}
+}
+
+// datetimeLineChartFixture returns line chart data with a datetime X scale
+// as produced by the code interpreter.
+func datetimeLineChartFixture() map[string]any {
+	return map[string]any{
+		"type":    "line",
+		"title":   "Daily Visitors",
+		"x_label": "Date",
+		"y_label": "Visitors",
+		"x_scale": "datetime",
+		"y_scale": "linear",
+		"elements": []any{
+			map[string]any{
+				"label": "visitors",
+				"points": []any{
+					[]any{"2023-09-01T00:00:00", 120.0},
+					[]any{"2023-09-02T12:30:00.5+02:00", 98.0},
+					[]any{"2023-09-03", 143.0},
+				},
+			},
+		},
+	}
+}
+
+// categoricalLineChartFixture returns line chart data with a categorical X
+// scale as produced by the code interpreter.
+func categoricalLineChartFixture() map[string]any {
+	return map[string]any{
+		"type":    "line",
+		"title":   "Weekly Sales",
+		"x_label": "Day",
+		"y_label": "Sales",
+		"x_scale": "categorical",
+		"y_scale": "linear",
+		"elements": []any{
+			map[string]any{
+				"label": "sales",
+				"points": []any{
+					[]any{"Mon", 3.0},
+					[]any{"Tue", 5.0},
+				},
+			},
+		},
+	}
+}
+
+// barChartFixture returns bar chart data as produced by the code interpreter.
+func barChartFixture() map[string]any {
+	return map[string]any{
+		"type":    "bar",
+		"title":   "Test Bar Chart",
+		"x_label": "Category",
+		"y_label": "Value",
+		"elements": []any{
+			map[string]any{
+				"label": "A",
+				"group": "Group 1",
+				"value": 10.0,
+			},
+			map[string]any{
+				"label": "B",
+				"group": "Group 1",
+				"value": 20.0,
+			},
+		},
+	}
+}
+
+// pieChartFixture returns pie chart data as produced by the code interpreter.
+func pieChartFixture() map[string]any {
+	return map[string]any{
+		"type":  "pie",
+		"title": "Test Pie Chart",
+		"elements": []any{
+			map[string]any{
+				"label":  "Slice 1",
+				"angle":  90.0,
+				"radius": 1.0,
+			},
+			map[string]any{
+				"label":  "Slice 2",
+				"angle":  270.0,
+				"radius": 1.0,
+			},
+		},
+	}
+}
+
+// scatterChartFixture returns scatter chart data as produced by the code interpreter.
+func scatterChartFixture() map[string]any {
+	return map[string]any{
+		"type":          "scatter",
+		"title":         "Test Scatter Chart",
+		"x_label":       "X",
+		"y_label":       "Y",
+		"x_scale":       "linear",
+		"y_scale":       "log",
+		"x_ticks":       []any{},
+		"x_tick_labels": []any{},
+		"y_ticks":       []any{},
+		"y_tick_labels": []any{},
+		"elements": []any{
+			map[string]any{
+				"label": "Data Points",
+				"points": []any{
+					[]any{1.0, 2.0},
+					[]any{3.0, 4.0},
+				},
+			},
+		},
+	}
+}
+
+// boxAndWhiskerChartFixture returns box and whisker chart data as produced by the code interpreter.
+func boxAndWhiskerChartFixture() map[string]any {
+	return map[string]any{
+		"type":    "box_and_whisker",
+		"title":   "Test Box Chart",
+		"x_label": "Category",
+		"y_label": "Value",
+		"elements": []any{
+			map[string]any{
+				"label":          "Box 1",
+				"min":            1.0,
+				"first_quartile": 2.0,
+				"median":         3.0,
+				"third_quartile": 4.0,
+				"max":            5.0,
+				"outliers":       []any{0.5, 5.5},
+			},
+		},
+	}
+}
+
+// superChartFixture returns superchart chart data as produced by the code interpreter.
+func superChartFixture() map[string]any {
+	return map[string]any{
+		"type":  "superchart",
+		"title": "Test Super Chart",
+		"elements": []any{
+			map[string]any{
+				"type":          "line",
+				"title":         "Sub Chart 1",
+				"x_label":       "X",
+				"y_label":       "Y",
+				"x_ticks":       []any{},
+				"x_tick_labels": []any{},
+				"y_ticks":       []any{},
+				"y_tick_labels": []any{},
+				"elements":      []any{},
+			},
+			map[string]any{
+				"type":     "bar",
+				"title":    "Sub Chart 2",
+				"x_label":  "X",
+				"y_label":  "Y",
+				"elements": []any{},
+			},
+		},
+	}
+}
+
+// unknownChartFixture returns unknown chart data as produced by the code interpreter.
+func unknownChartFixture() map[string]any {
+	return map[string]any{
+		"type":     "unknown_type",
+		"title":    "Unknown Chart",
+		"elements": []any{},
+	}
+}
+
+func TestDeserializeLineChart(t *testing.T) {
+	data := lineChartFixture()
 
 	chart, err := DeserializeChart(data)
 	if err != nil {
@@ -63,26 +242,70 @@ func TestDeserializeLineChart(t *testing.T) {
 	}
 }
 
-func TestDeserializeBarChart(t *testing.T) {
-	data := map[string]any{
-		"type":    "bar",
-		"title":   "Test Bar Chart",
-		"x_label": "Category",
-		"y_label": "Value",
-		"elements": []any{
-			map[string]any{
-				"label": "A",
-				"group": "Group 1",
-				"value": 10.0,
-			},
-			map[string]any{
-				"label": "B",
-				"group": "Group 1",
-				"value": 20.0,
-			},
-		},
+func TestDeserializePointChartScales(t *testing.T) {
+	data := datetimeLineChartFixture()
+	elements := data["elements"].([]any)
+	series := elements[0].(map[string]any)
+	series["points"] = append(series["points"].([]any), []any{"yesterday", 1.0}, []any{"2023-09-04", nil}, []any{1.0})
+
+	chart, err := DeserializeChart(data)
+	if err != nil {
+		t.Fatalf("DeserializeChart() error = %v", err)
+	}
+	lineChart := chart.(*LineChart)
+	if lineChart.DroppedPoints != 3 {
+		t.Errorf("DroppedPoints = %d, want 3", lineChart.DroppedPoints)
+	}
+
+	points, err := lineChart.Data[0].TimePoints()
+	if err != nil {
+		t.Fatalf("TimePoints() error = %v", err)
+	}
+	want := []TimePoint{
+		{T: time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC), Y: 120},
+		{T: time.Date(2023, 9, 2, 10, 30, 0, 500000000, time.UTC), Y: 98},
+		{T: time.Date(2023, 9, 3, 0, 0, 0, 0, time.UTC), Y: 143},
+	}
+	if len(points) != len(want) {
+		t.Fatalf("TimePoints() returned %d points, want %d", len(points), len(want))
+	}
+	for i := range want {
+		if !points[i].T.Equal(want[i].T) || points[i].Y != want[i].Y {
+			t.Errorf("TimePoints()[%d] = %v, want %v", i, points[i], want[i])
+		}
+	}
+	if _, err := lineChart.Data[0].FloatPoints(); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("FloatPoints() on a datetime scale error = %v, want %v", err, ErrInvalidArgument)
+	}
+
+	chart, err = DeserializeChart(categoricalLineChartFixture())
+	if err != nil {
+		t.Fatalf("DeserializeChart() error = %v", err)
+	}
+	categorical := chart.(*LineChart).Data[0]
+	if categorical.Points[1].X != "Tue" || categorical.Points[1].Y != 5.0 {
+		t.Errorf("Points[1] = %v, want (Tue, 5)", categorical.Points[1])
+	}
+	if _, err := categorical.TimePoints(); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("TimePoints() on a categorical scale error = %v, want %v", err, ErrInvalidArgument)
 	}
 
+	chart, err = DeserializeChart(lineChartFixture())
+	if err != nil {
+		t.Fatalf("DeserializeChart() error = %v", err)
+	}
+	floats, err := chart.(*LineChart).Data[0].FloatPoints()
+	if err != nil {
+		t.Fatalf("FloatPoints() error = %v", err)
+	}
+	if !reflect.DeepEqual(floats, [][2]float64{{0, 1}, {1, 4}, {2, 9}}) {
+		t.Errorf("FloatPoints() = %v, want the fixture points", floats)
+	}
+}
+
+func TestDeserializeBarChart(t *testing.T) {
+	data := barChartFixture()
+
 	chart, err := DeserializeChart(data)
 	if err != nil {
 		t.Fatalf("DeserializeChart() error = %v", err)
@@ -111,22 +334,7 @@ func TestDeserializeBarChart(t *testing.T) {
 }
 
 func TestDeserializePieChart(t *testing.T) {
-	data := map[string]any{
-		"type":  "pie",
-		"title": "Test Pie Chart",
-		"elements": []any{
-			map[string]any{
-				"label":  "Slice 1",
-				"angle":  90.0,
-				"radius": 1.0,
-			},
-			map[string]any{
-				"label":  "Slice 2",
-				"angle":  270.0,
-				"radius": 1.0,
-			},
-		},
-	}
+	data := pieChartFixture()
 
 	chart, err := DeserializeChart(data)
 	if err != nil {
@@ -156,27 +364,7 @@ func TestDeserializePieChart(t *testing.T) {
 }
 
 func TestDeserializeScatterChart(t *testing.T) {
-	data := map[string]any{
-		"type":          "scatter",
-		"title":         "Test Scatter Chart",
-		"x_label":       "X",
-		"y_label":       "Y",
-		"x_scale":       "linear",
-		"y_scale":       "log",
-		"x_ticks":       []any{},
-		"x_tick_labels": []any{},
-		"y_ticks":       []any{},
-		"y_tick_labels": []any{},
-		"elements": []any{
-			map[string]any{
-				"label": "Data Points",
-				"points": []any{
-					[]any{1.0, 2.0},
-					[]any{3.0, 4.0},
-				},
-			},
-		},
-	}
+	data := scatterChartFixture()
 
 	chart, err := DeserializeChart(data)
 	if err != nil {
@@ -198,23 +386,7 @@ func TestDeserializeScatterChart(t *testing.T) {
 }
 
 func TestDeserializeBoxAndWhiskerChart(t *testing.T) {
-	data := map[string]any{
-		"type":    "box_and_whisker",
-		"title":   "Test Box Chart",
-		"x_label": "Category",
-		"y_label": "Value",
-		"elements": []any{
-			map[string]any{
-				"label":          "Box 1",
-				"min":            1.0,
-				"first_quartile": 2.0,
-				"median":         3.0,
-				"third_quartile": 4.0,
-				"max":            5.0,
-				"outliers":       []any{0.5, 5.5},
-			},
-		},
-	}
+	data := boxAndWhiskerChartFixture()
 
 	chart, err := DeserializeChart(data)
 	if err != nil {
@@ -244,30 +416,7 @@ func TestDeserializeBoxAndWhiskerChart(t *testing.T) {
 }
 
 func TestDeserializeSuperChart(t *testing.T) {
-	data := map[string]any{
-		"type":  "superchart",
-		"title": "Test Super Chart",
-		"elements": []any{
-			map[string]any{
-				"type":          "line",
-				"title":         "Sub Chart 1",
-				"x_label":       "X",
-				"y_label":       "Y",
-				"x_ticks":       []any{},
-				"x_tick_labels": []any{},
-				"y_ticks":       []any{},
-				"y_tick_labels": []any{},
-				"elements":      []any{},
-			},
-			map[string]any{
-				"type":     "bar",
-				"title":    "Sub Chart 2",
-				"x_label":  "X",
-				"y_label":  "Y",
-				"elements": []any{},
-			},
-		},
-	}
+	data := superChartFixture()
 
 	chart, err := DeserializeChart(data)
 	if err != nil {
@@ -297,11 +446,7 @@ func TestDeserializeSuperChart(t *testing.T) {
 }
 
 func TestDeserializeUnknownChart(t *testing.T) {
-	data := map[string]any{
-		"type":     "unknown_type",
-		"title":    "Unknown Chart",
-		"elements": []any{},
-	}
+	data := unknownChartFixture()
 
 	chart, err := DeserializeChart(data)
 	if err != nil {
@@ -324,6 +469,236 @@ func TestDeserializeNilChart(t *testing.T) {
 	}
 }
 
+// clearRawData removes the raw data from c and its sub-charts so that charts
+// can be compared by their typed fields.
+func clearRawData(c Chart) {
+	switch c := c.(type) {
+	case *LineChart:
+		c.RawData = nil
+	case *ScatterChart:
+		c.RawData = nil
+	case *BarChart:
+		c.RawData = nil
+	case *PieChart:
+		c.RawData = nil
+	case *BoxAndWhiskerChart:
+		c.RawData = nil
+	case *SuperChart:
+		c.RawData = nil
+		for _, sub := range c.SubCharts {
+			clearRawData(sub)
+		}
+	case *BaseChart:
+		c.RawData = nil
+	}
+}
+
+func TestSerializeChartRoundTrip(t *testing.T) {
+	fixtures := map[string]map[string]any{
+		"line":             lineChartFixture(),
+		"datetime line":    datetimeLineChartFixture(),
+		"categorical line": categoricalLineChartFixture(),
+		"bar":              barChartFixture(),
+		"pie":              pieChartFixture(),
+		"scatter":          scatterChartFixture(),
+		"box and whisker":  boxAndWhiskerChartFixture(),
+		"superchart":       superChartFixture(),
+		"unknown":          unknownChartFixture(),
+	}
+	for name, data := range fixtures {
+		t.Run(name, func(t *testing.T) {
+			chart, err := DeserializeChart(data)
+			if err != nil {
+				t.Fatalf("DeserializeChart() error = %v", err)
+			}
+
+			serialized, err := SerializeChart(chart)
+			if err != nil {
+				t.Fatalf("SerializeChart() error = %v", err)
+			}
+			restored, err := DeserializeChart(serialized)
+			if err != nil {
+				t.Fatalf("DeserializeChart() of serialized chart error = %v", err)
+			}
+
+			// Results cached as JSON are encoded through MarshalJSON
+			encoded, err := json.Marshal(chart)
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			var decoded map[string]any
+			if err := json.Unmarshal(encoded, &decoded); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			fromJSON, err := DeserializeChart(decoded)
+			if err != nil {
+				t.Fatalf("DeserializeChart() of decoded JSON error = %v", err)
+			}
+
+			clearRawData(chart)
+			clearRawData(restored)
+			clearRawData(fromJSON)
+			if !reflect.DeepEqual(restored, chart) {
+				t.Errorf("round trip through SerializeChart = %+v, want %+v", restored, chart)
+			}
+			if !reflect.DeepEqual(fromJSON, chart) {
+				t.Errorf("round trip through JSON = %+v, want %+v", fromJSON, chart)
+			}
+		})
+	}
+
+	if data, err := SerializeChart(nil); data != nil || err != nil {
+		t.Errorf("SerializeChart(nil) = %v, %v, want nil, nil", data, err)
+	}
+}
+
+func TestExecutionJSONRoundTrip(t *testing.T) {
+	line, err := DeserializeChart(datetimeLineChartFixture())
+	if err != nil {
+		t.Fatalf("DeserializeChart() error = %v", err)
+	}
+	super, err := DeserializeChart(superChartFixture())
+	if err != nil {
+		t.Fatalf("DeserializeChart() error = %v", err)
+	}
+
+	execution := &Execution{
+		Results: []*Result{
+			{Text: "<Figure>", PNG: "iVBORw0KGgo=", Chart: line, IsMainResult: true},
+			{HTML: "<b>hi</b>", Chart: super, Extra: map[string]any{"custom": "value"}},
+			{JSON: map[string]any{"a": 1.0}},
+		},
+		Logs:           &Logs{Stdout: []string{"out\n"}, Stderr: []string{}},
+		Error:          &ExecutionError{Name: "ValueError", Value: "bad", Traceback: "..."},
+		ExecutionCount: 3,
+		CellID:         "cell-1",
+		Metadata:       map[string]string{"job": "42"},
+	}
+
+	data, err := json.Marshal(execution)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var restored Execution
+	if err := json.Unmarshal(data, &restored); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	for _, r := range append(execution.Results, restored.Results...) {
+		clearRawData(r.Chart)
+	}
+	if !reflect.DeepEqual(&restored, execution) {
+		t.Errorf("round trip = %+v, want %+v", &restored, execution)
+	}
+
+	chartJSON, err := json.Marshal(line)
+	if err != nil {
+		t.Fatalf("json.Marshal() of chart error = %v", err)
+	}
+	chart, err := UnmarshalChart(chartJSON)
+	if err != nil {
+		t.Fatalf("UnmarshalChart() error = %v", err)
+	}
+	clearRawData(chart)
+	if !reflect.DeepEqual(chart, line) {
+		t.Errorf("UnmarshalChart() = %+v, want %+v", chart, line)
+	}
+
+	if err := json.Unmarshal([]byte(`{"results":[{"chart":{"title":"no type"}}]}`), &restored); err == nil {
+		t.Error("json.Unmarshal() of chart without type expected error, got nil")
+	}
+}
+
+func TestFlattenCharts(t *testing.T) {
+	super, err := DeserializeChart(superChartFixture())
+	if err != nil {
+		t.Fatalf("DeserializeChart() error = %v", err)
+	}
+	bar, err := DeserializeChart(barChartFixture())
+	if err != nil {
+		t.Fatalf("DeserializeChart() error = %v", err)
+	}
+	subs := super.(*SuperChart).SubCharts
+	if len(subs) == 0 {
+		t.Fatal("superchart fixture has no sub-charts")
+	}
+
+	nested := &SuperChart{BaseChart: BaseChart{Type: ChartTypeSuperChart}, SubCharts: []Chart{super, bar}}
+	want := append(append([]Chart{}, subs...), bar)
+	if got := FlattenCharts(nested); !reflect.DeepEqual(got, want) {
+		t.Errorf("FlattenCharts() = %v, want %v", got, want)
+	}
+	if got := FlattenCharts(bar); len(got) != 1 || got[0] != bar {
+		t.Errorf("FlattenCharts() of a leaf = %v, want [%v]", got, bar)
+	}
+	if got := FlattenCharts(nil); got != nil {
+		t.Errorf("FlattenCharts(nil) = %v, want nil", got)
+	}
+
+	execution := &Execution{Results: []*Result{{Chart: bar}, {Text: "no chart"}, {Chart: nested}}}
+	if got := execution.AllCharts(); !reflect.DeepEqual(got, append([]Chart{bar}, want...)) {
+		t.Errorf("AllCharts() = %v, want %v", got, append([]Chart{bar}, want...))
+	}
+}
+
+// heatmapChart is a custom chart type for TestRegisterChartType.
+type heatmapChart struct {
+	BaseChart
+	Cells []any
+}
+
+func TestRegisterChartType(t *testing.T) {
+	const heatmap ChartType = "test_heatmap"
+	data := map[string]any{"type": string(heatmap), "title": "Heat", "elements": []any{1.0, 2.0}}
+
+	chart, err := DeserializeChart(data)
+	if err != nil {
+		t.Fatalf("DeserializeChart() error = %v", err)
+	}
+	if chart.ChartType() != ChartTypeUnknown {
+		t.Errorf("ChartType() before registration = %v, want %v", chart.ChartType(), ChartTypeUnknown)
+	}
+
+	RegisterChartType(heatmap, func(data map[string]any) (Chart, error) {
+		cells, _ := data["elements"].([]any)
+		return &heatmapChart{BaseChart: BaseChart{Type: heatmap, Title: data["title"].(string), RawData: data}, Cells: cells}, nil
+	})
+
+	chart, err = DeserializeChart(data)
+	if err != nil {
+		t.Fatalf("DeserializeChart() error = %v", err)
+	}
+	hm, ok := chart.(*heatmapChart)
+	if !ok || hm.Title != "Heat" || len(hm.Cells) != 2 {
+		t.Fatalf("DeserializeChart() = %#v, want the registered heatmap chart", chart)
+	}
+	if serialized, err := SerializeChart(chart); err != nil || !reflect.DeepEqual(serialized, data) {
+		t.Errorf("SerializeChart() = %v, %v, want %v", serialized, err, data)
+	}
+
+	super, err := DeserializeChart(map[string]any{"type": "superchart", "title": "", "elements": []any{data}})
+	if err != nil {
+		t.Fatalf("DeserializeChart() of superchart error = %v", err)
+	}
+	if subs := super.(*SuperChart).SubCharts; len(subs) != 1 || subs[0].ChartType() != heatmap {
+		t.Errorf("SubCharts = %v, want the registered heatmap chart", subs)
+	}
+
+	for name, register := range map[string]func(){
+		"built-in type": func() { RegisterChartType(ChartTypeLine, func(map[string]any) (Chart, error) { return nil, nil }) },
+		"nil decoder":   func() { RegisterChartType("test_other", nil) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RegisterChartType() with %s did not panic", name)
+				}
+			}()
+			register()
+		}()
+	}
+}
+
 func TestChartToMap(t *testing.T) {
 	data := map[string]any{
 		"type":    "bar",
@@ -411,3 +786,52 @@ func TestScaleTypes(t *testing.T) {
 		}
 	}
 }
+
+func TestChartToVegaLite(t *testing.T) {
+	chart, err := DeserializeChart(map[string]any{
+		"type":    "line",
+		"title":   "Growth",
+		"x_label": "Time",
+		"x_unit":  "s",
+		"x_scale": "linear",
+		"y_scale": "log",
+		"elements": []any{
+			map[string]any{"label": "a", "points": []any{[]any{0.0, 1.0}, []any{1.0, 10.0}}},
+			map[string]any{"label": "b", "points": []any{[]any{0.0, 2.0}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("DeserializeChart() error = %v", err)
+	}
+
+	spec, err := ChartToVegaLite(chart)
+	if err != nil {
+		t.Fatalf("ChartToVegaLite() error = %v", err)
+	}
+	if spec["title"] != "Growth" {
+		t.Errorf("title = %v, want Growth", spec["title"])
+	}
+	if mark := spec["mark"].(map[string]any)["type"]; mark != "line" {
+		t.Errorf("mark = %v, want line", mark)
+	}
+	if values := spec["data"].(map[string]any)["values"].([]map[string]any); len(values) != 3 {
+		t.Errorf("values length = %d, want 3", len(values))
+	}
+	encoding := spec["encoding"].(map[string]any)
+	if title := encoding["x"].(map[string]any)["title"]; title != "Time (s)" {
+		t.Errorf("x title = %v, want Time (s)", title)
+	}
+	if scale := encoding["y"].(map[string]any)["scale"].(map[string]any)["type"]; scale != "log" {
+		t.Errorf("y scale = %v, want log", scale)
+	}
+	if _, ok := encoding["color"]; !ok {
+		t.Error("color encoding missing for multiple series")
+	}
+
+	if _, err := ChartToVegaLite(&SuperChart{}); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("ChartToVegaLite(SuperChart) error = %v, want %v", err, ErrInvalidArgument)
+	}
+	if _, err := ChartToVegaLite(&BaseChart{Type: ChartTypeUnknown}); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("ChartToVegaLite(unknown) error = %v, want %v", err, ErrInvalidArgument)
+	}
+}
@@ -0,0 +1,162 @@
+package e2b
+
+import (
+	"fmt"
+)
+
+// vegaLiteSchema is the Vega-Lite JSON schema referenced by generated specs.
+const vegaLiteSchema = "https://vega.github.io/schema/vega-lite/v5.json"
+
+// ChartToVegaLite converts a chart to a minimal Vega-Lite specification that
+// can be rendered in a browser, for example with vega-embed.
+//
+// Line, scatter, bar and pie charts are supported. The parsed chart elements
+// become inline data values, and axis labels, units and scales are carried
+// over to the encoding. A SuperChart returns an error; convert each of its
+// SubCharts instead. Other chart types return an error wrapping
+// ErrInvalidArgument.
+//
+// Example:
+//
+//	for _, result := range execution.Results {
+//	    if result.Chart == nil {
+//	        continue
+//	    }
+//	    spec, err := e2b.ChartToVegaLite(result.Chart)
+//	    if err != nil {
+//	        log.Println(err)
+//	        continue
+//	    }
+//	    json.NewEncoder(w).Encode(spec)
+//	}
+func ChartToVegaLite(c Chart) (map[string]any, error) {
+	if c == nil {
+		return nil, fmt.Errorf("%w: chart is required", ErrInvalidArgument)
+	}
+
+	var spec map[string]any
+	switch chart := c.(type) {
+	case *LineChart:
+		spec = pointChartToVegaLite(&chart.PointChart, "line")
+	case *ScatterChart:
+		spec = pointChartToVegaLite(&chart.PointChart, "point")
+	case *BarChart:
+		spec = barChartToVegaLite(chart)
+	case *PieChart:
+		spec = pieChartToVegaLite(chart)
+	case *SuperChart:
+		return nil, fmt.Errorf("%w: cannot convert a superchart to Vega-Lite, convert each of its SubCharts instead", ErrInvalidArgument)
+	default:
+		return nil, fmt.Errorf("%w: cannot convert chart of type %q to Vega-Lite", ErrInvalidArgument, c.ChartType())
+	}
+
+	spec["$schema"] = vegaLiteSchema
+	if title := c.ChartTitle(); title != "" {
+		spec["title"] = title
+	}
+
+	return spec, nil
+}
+
+// pointChartToVegaLite converts a line or scatter chart with the given mark.
+func pointChartToVegaLite(c *PointChart, mark string) map[string]any {
+	values := make([]map[string]any, 0)
+	for _, series := range c.Data {
+		for _, p := range series.Points {
+			values = append(values, map[string]any{"series": series.Label, "x": p.X, "y": p.Y})
+		}
+	}
+
+	encoding := map[string]any{
+		"x": vegaLiteAxisEncoding("x", c.XScale, c.XLabel, c.XUnit),
+		"y": vegaLiteAxisEncoding("y", c.YScale, c.YLabel, c.YUnit),
+	}
+	if len(c.Data) > 1 {
+		encoding["color"] = map[string]any{"field": "series", "type": "nominal", "title": nil}
+	}
+
+	return map[string]any{
+		"data":     map[string]any{"values": values},
+		"mark":     map[string]any{"type": mark, "tooltip": true},
+		"encoding": encoding,
+	}
+}
+
+// barChartToVegaLite converts a bar chart, grouping bars by their group.
+func barChartToVegaLite(c *BarChart) map[string]any {
+	values := make([]map[string]any, 0, len(c.Data))
+	grouped := false
+	for _, bar := range c.Data {
+		values = append(values, map[string]any{"label": bar.Label, "group": bar.Group, "value": bar.Value})
+		if bar.Group != "" {
+			grouped = true
+		}
+	}
+
+	encoding := map[string]any{
+		"x": vegaLiteAxisEncoding("label", ScaleTypeCategorical, c.XLabel, c.XUnit),
+		"y": vegaLiteAxisEncoding("value", ScaleTypeLinear, c.YLabel, c.YUnit),
+	}
+	if grouped {
+		encoding["color"] = map[string]any{"field": "group", "type": "nominal"}
+		encoding["xOffset"] = map[string]any{"field": "group"}
+	}
+
+	return map[string]any{
+		"data":     map[string]any{"values": values},
+		"mark":     map[string]any{"type": "bar", "tooltip": true},
+		"encoding": encoding,
+	}
+}
+
+// pieChartToVegaLite converts a pie chart using the slice angles as weights.
+func pieChartToVegaLite(c *PieChart) map[string]any {
+	values := make([]map[string]any, 0, len(c.Data))
+	for _, slice := range c.Data {
+		values = append(values, map[string]any{"label": slice.Label, "angle": slice.Angle})
+	}
+
+	return map[string]any{
+		"data": map[string]any{"values": values},
+		"mark": map[string]any{"type": "arc", "tooltip": true},
+		"encoding": map[string]any{
+			"theta": map[string]any{"field": "angle", "type": "quantitative", "stack": true},
+			"color": map[string]any{"field": "label", "type": "nominal", "sort": nil},
+		},
+	}
+}
+
+// vegaLiteAxisEncoding builds a positional encoding for a field from the
+// chart's scale, label and unit.
+func vegaLiteAxisEncoding(field string, scale ScaleType, label, unit string) map[string]any {
+	encoding := map[string]any{"field": field}
+
+	switch scale {
+	case ScaleTypeDatetime:
+		encoding["type"] = "temporal"
+	case ScaleTypeCategorical:
+		encoding["type"] = "nominal"
+		encoding["sort"] = nil
+	case ScaleTypeLog, ScaleTypeFunctionLog:
+		encoding["type"] = "quantitative"
+		encoding["scale"] = map[string]any{"type": "log"}
+	case ScaleTypeSymlog, ScaleTypeAsinh:
+		encoding["type"] = "quantitative"
+		encoding["scale"] = map[string]any{"type": "symlog"}
+	default:
+		encoding["type"] = "quantitative"
+	}
+
+	title := label
+	if unit != "" {
+		if title != "" {
+			title += " "
+		}
+		title += "(" + unit + ")"
+	}
+	if title != "" {
+		encoding["title"] = title
+	}
+
+	return encoding
+}
@@ -2,7 +2,11 @@ package e2b
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"strings"
+	"sync"
 	"time"
 
 	"connectrpc.com/connect"
@@ -22,9 +26,10 @@ type Commands struct {
 func newCommands(sandbox *Sandbox) *Commands {
 	base := newRPCClient(sandbox)
 
+	client, baseURL := base.connectClient()
 	processClient := processpbconnect.NewProcessClient(
-		base.httpClient,
-		base.envdBaseURL,
+		client,
+		baseURL,
 		connect.WithGRPCWeb(),
 	)
 
@@ -137,6 +142,11 @@ func (c *Commands) SendStdin(ctx context.Context, pid uint32, data string, opts
 		opt(cfg)
 	}
 
+	return c.sendStdin(ctx, pid, []byte(data), cfg)
+}
+
+// sendStdin sends data to the stdin of a running command.
+func (c *Commands) sendStdin(ctx context.Context, pid uint32, data []byte, cfg *commandRequestConfig) error {
 	ctx, cancel := c.applyTimeout(ctx, cfg.requestTimeout)
 	defer cancel()
 
@@ -148,7 +158,7 @@ func (c *Commands) SendStdin(ctx context.Context, pid uint32, data string, opts
 		},
 		Input: &processpb.ProcessInput{
 			Input: &processpb.ProcessInput_Stdin{
-				Stdin: []byte(data),
+				Stdin: data,
 			},
 		},
 	})
@@ -162,6 +172,49 @@ func (c *Commands) SendStdin(ctx context.Context, pid uint32, data string, opts
 	return nil
 }
 
+// CloseStdin closes the stdin of a running command, so that programs
+// reading it until end of input can finish. The command must have been
+// started with WithStdin(true).
+//
+// Closing stdin requires an envd version with the CloseStdin RPC; on older
+// versions an error wrapping ErrInvalidArgument is returned.
+//
+// Example:
+//
+//	if err := sandbox.Commands.SendStdin(ctx, pid, "a,b\n1,2\n"); err != nil {
+//	    log.Fatal(err)
+//	}
+//	if err := sandbox.Commands.CloseStdin(ctx, pid); err != nil {
+//	    log.Fatal(err)
+//	}
+func (c *Commands) CloseStdin(ctx context.Context, pid uint32, opts ...CommandRequestOption) error {
+	cfg := defaultCommandRequestConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	ctx, cancel := c.applyTimeout(ctx, cfg.requestTimeout)
+	defer cancel()
+
+	req := connect.NewRequest(&processpb.CloseStdinRequest{
+		Process: &processpb.ProcessSelector{
+			Selector: &processpb.ProcessSelector_Pid{
+				Pid: pid,
+			},
+		},
+	})
+	c.setRPCHeaders(req)
+
+	if _, err := c.processClient.CloseStdin(ctx, req); err != nil {
+		if connect.CodeOf(err) == connect.CodeUnimplemented {
+			return fmt.Errorf("%w: closing stdin is not supported by envd version %s", ErrInvalidArgument, c.envdVersion())
+		}
+		return c.wrapRPCError(ctx, err)
+	}
+
+	return nil
+}
+
 // Run executes a command and waits for it to complete.
 // Returns the command result with stdout, stderr, and exit code.
 //
@@ -203,26 +256,168 @@ func (c *Commands) RunBackground(ctx context.Context, cmd string, opts ...Comman
 	return c.start(ctx, cmd, opts...)
 }
 
-// start is the internal method that starts a command and returns a handle.
+// RunWithStdinPipe starts a command in the background with stdin enabled and
+// returns a pipe connected to its stdin together with the command's handle.
+//
+// Data written to the pipe is sent to the command in chunks of up to 64 KiB,
+// each write returning once envd has accepted it, so a large input is
+// streamed without being held in memory. Closing the pipe closes the
+// command's stdin (see Commands.CloseStdin). Writes fail once ctx is done.
+//
+// Example:
+//
+//	stdin, handle, err := sandbox.Commands.RunWithStdinPipe(ctx, "gzip > /tmp/data.gz")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	f, _ := os.Open("data.csv")
+//	defer f.Close()
+//	if _, err := io.Copy(stdin, f); err != nil {
+//	    log.Fatal(err)
+//	}
+//	if err := stdin.Close(); err != nil {
+//	    log.Fatal(err)
+//	}
+//	result, err := handle.Wait(ctx)
+func (c *Commands) RunWithStdinPipe(ctx context.Context, cmd string, opts ...CommandOption) (io.WriteCloser, *CommandHandle, error) {
+	handle, err := c.start(ctx, cmd, append(opts[:len(opts):len(opts)], WithStdin(true))...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &commandStdinPipe{ctx: ctx, commands: c, pid: handle.PID()}, handle, nil
+}
+
+// commandStdinPipe is an io.WriteCloser writing to the stdin of a command.
+type commandStdinPipe struct {
+	ctx      context.Context
+	commands *Commands
+	pid      uint32
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// Write implements io.Writer.
+func (p *commandStdinPipe) Write(data []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return 0, io.ErrClosedPipe
+	}
+
+	written := 0
+	for written < len(data) {
+		if err := p.ctx.Err(); err != nil {
+			return written, err
+		}
+		end := min(len(data), written+maxStdinChunkSize)
+		if err := p.commands.sendStdin(p.ctx, p.pid, data[written:end], defaultCommandRequestConfig()); err != nil {
+			return written, err
+		}
+		written = end
+	}
+	return written, nil
+}
+
+// Close implements io.Closer by closing the command's stdin.
+func (p *commandStdinPipe) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+	return p.commands.CloseStdin(p.ctx, p.pid)
+}
+
+// RunArgs executes a program with the given arguments and waits for it to
+// complete. Unlike Run, the program is started directly without a shell, so
+// arguments are passed verbatim and are never subject to quoting, expansion
+// or command substitution. The program is resolved through PATH when name
+// contains no slash.
+//
+// If the command exits with a non-zero exit code, it returns a CommandExitError.
+// The WithShell option is ignored.
+//
+// Example:
+//
+//	// The file name is passed as a single argument, spaces and all
+//	result, err := sandbox.Commands.RunArgs(ctx, "wc", []string{"-l", "/tmp/my notes.txt"})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Println(result.Stdout)
+func (c *Commands) RunArgs(ctx context.Context, name string, args []string, opts ...CommandOption) (*CommandResult, error) {
+	handle, err := c.RunArgsBackground(ctx, name, args, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return handle.Wait(ctx)
+}
+
+// RunArgsBackground starts a program with the given arguments in the
+// background, without a shell, and returns a handle to interact with it.
+// See RunArgs for how the arguments are passed and RunBackground for how to
+// use the handle.
+//
+// Example:
+//
+//	handle, err := sandbox.Commands.RunArgsBackground(ctx, "python3", []string{"server.py", "--port", "8080"},
+//	    OnCommandStdout(func(output string) {
+//	        fmt.Print(output)
+//	    }),
+//	)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (c *Commands) RunArgsBackground(ctx context.Context, name string, args []string, opts ...CommandOption) (*CommandHandle, error) {
+	if name == "" {
+		return nil, fmt.Errorf("%w: program name is required", ErrInvalidArgument)
+	}
+
+	cfg := defaultCommandConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return c.startProcess(ctx, cfg, name, args)
+}
+
+// start is the internal method that starts a shell command and returns a handle.
 func (c *Commands) start(ctx context.Context, cmd string, opts ...CommandOption) (*CommandHandle, error) {
 	cfg := defaultCommandConfig()
 	for _, opt := range opts {
 		opt(cfg)
 	}
 
+	if cfg.shell == "" {
+		return nil, fmt.Errorf("%w: shell path is required", ErrInvalidArgument)
+	}
+
+	// The command is the last shell argument: /bin/bash -l -c cmd by default
+	args := make([]string, 0, len(cfg.shellArgs)+1)
+	args = append(args, cfg.shellArgs...)
+	args = append(args, cmd)
+
+	return c.startProcess(ctx, cfg, cfg.shell, args)
+}
+
+// startProcess starts the program name with args as its argv and returns a
+// handle once the process has started.
+func (c *Commands) startProcess(ctx context.Context, cfg *commandConfig, name string, args []string) (*CommandHandle, error) {
 	// Check version for stdin support.
 	// Explicitly setting stdin to false requires envd version >= 0.3.0.
 	// On older versions, stdin is always enabled and cannot be disabled.
 	if cfg.stdin != nil && !*cfg.stdin && c.compareVersion(EnvdVersionCommandsStdin) < 0 {
 		return nil, fmt.Errorf("%w: sandbox envd version %s cannot specify stdin=false, it's always enabled; please rebuild your template if you need this feature",
-			ErrInvalidArgument, c.envdVersion)
+			ErrInvalidArgument, c.envdVersion())
 	}
 
 	// Build the process config
-	// Python SDK uses: /bin/bash -l -c cmd
 	processConfig := &processpb.ProcessConfig{
-		Cmd:  "/bin/bash",
-		Args: []string{"-l", "-c", cmd},
+		Cmd:  name,
+		Args: args,
 		Envs: cfg.envs,
 	}
 
@@ -249,7 +444,7 @@ func (c *Commands) start(ctx context.Context, cmd string, opts ...CommandOption)
 	var streamCtx context.Context
 	var streamCancel context.CancelFunc
 	if cfg.timeout > 0 {
-		streamCtx, streamCancel = context.WithTimeout(ctx, cfg.timeout)
+		streamCtx, streamCancel = context.WithTimeoutCause(ctx, cfg.timeout, errCommandTimeout)
 	} else {
 		streamCtx, streamCancel = context.WithCancel(ctx)
 	}
@@ -286,8 +481,10 @@ func (c *Commands) start(ctx context.Context, cmd string, opts ...CommandOption)
 					pid:    0,
 					done:   make(chan struct{}),
 					result: result,
+					onExit: cfg.onExit,
 				}
 				close(handle.done)
+				go handle.notifyExit()
 				return handle, nil
 			}
 
@@ -336,15 +533,14 @@ func (c *Commands) start(ctx context.Context, cmd string, opts ...CommandOption)
 				Stderr:   string(earlyStderr),
 				ExitCode: exitCode,
 				Error:    errorMsg,
+				Status:   endEvent.GetStatus(),
 			}
 
 			if exitCode != 0 {
-				return nil, &CommandExitError{
-					Stdout:       result.Stdout,
-					Stderr:       result.Stderr,
-					ExitCode:     exitCode,
-					ErrorMessage: errorMsg,
+				if cfg.onExit != nil {
+					go cfg.onExit(result, result.exitError())
 				}
+				return nil, result.exitError()
 			}
 
 			// Create a dummy handle that is already done
@@ -352,8 +548,10 @@ func (c *Commands) start(ctx context.Context, cmd string, opts ...CommandOption)
 				pid:    0,
 				done:   make(chan struct{}),
 				result: result,
+				onExit: cfg.onExit,
 			}
 			close(handle.done)
+			go handle.notifyExit()
 			return handle, nil
 		}
 
@@ -365,17 +563,28 @@ func (c *Commands) start(ctx context.Context, cmd string, opts ...CommandOption)
 		return nil, fmt.Errorf("failed to start process: received %d events but no start event", eventCount)
 	}
 
+	// A detached process keeps running without an open event stream
+	if cfg.detach {
+		streamCancel()
+		handle := newDetachedCommandHandle(pid, func(ctx context.Context) (bool, error) {
+			return c.Kill(ctx, pid)
+		})
+		handle.commands = c
+		return handle, nil
+	}
+
 	// Create the handle with a kill function that cancels the stream
 	handle := newCommandHandle(
 		pid,
 		stream,
+		streamCtx,
 		func(ctx context.Context) (bool, error) {
 			streamCancel()
 			return c.Kill(ctx, pid)
 		},
-		cfg.onStdout,
-		cfg.onStderr,
+		cfg,
 	)
+	handle.commands = c
 
 	// Process any early data that was received before the start event
 	if len(earlyStdout) > 0 {
@@ -394,6 +603,71 @@ func (c *Commands) start(ctx context.Context, cmd string, opts ...CommandOption)
 	return handle, nil
 }
 
+// runToWriter runs cmd through the configured shell and copies its stdout
+// to w as it arrives, without buffering it. Stderr is collected for the
+// *CommandExitError returned on a non-zero exit code. If it returns before
+// the command exited, for example because w returned an error, the command
+// is killed.
+func (c *Commands) runToWriter(ctx context.Context, cmd string, w io.Writer, cfg *commandConfig) error {
+	args := make([]string, 0, len(cfg.shellArgs)+1)
+	args = append(args, cfg.shellArgs...)
+	args = append(args, cmd)
+
+	stdin := false
+	req := connect.NewRequest(&processpb.StartRequest{
+		Process: &processpb.ProcessConfig{Cmd: cfg.shell, Args: args, Envs: cfg.envs},
+		Stdin:   &stdin,
+	})
+	c.setStreamingHeadersWithUser(req, cfg.user)
+
+	streamCtx, streamCancel := context.WithCancel(ctx)
+	defer streamCancel()
+
+	stream, err := c.processClient.Start(streamCtx, req)
+	if err != nil {
+		return c.wrapRPCError(ctx, err)
+	}
+	defer stream.Close()
+
+	var pid uint32
+	exited := false
+	defer func() {
+		if pid != 0 && !exited {
+			_, _ = c.Kill(context.WithoutCancel(ctx), pid)
+		}
+	}()
+
+	var stderr strings.Builder
+	for stream.Receive() {
+		switch e := stream.Msg().GetEvent().GetEvent().(type) {
+		case *processpb.ProcessEvent_Start:
+			pid = e.Start.GetPid()
+		case *processpb.ProcessEvent_Data:
+			if out := e.Data.GetStdout(); out != nil {
+				if _, err := w.Write(out); err != nil {
+					return err
+				}
+			}
+			stderr.Write(e.Data.GetStderr())
+		case *processpb.ProcessEvent_End:
+			exited = true
+			if e.End.GetExitCode() != 0 {
+				return &CommandExitError{
+					Stderr:       stderr.String(),
+					ExitCode:     int(e.End.GetExitCode()),
+					ErrorMessage: e.End.GetError(),
+				}
+			}
+			return nil
+		}
+	}
+
+	if err := stream.Err(); err != nil {
+		return c.wrapRPCError(ctx, err)
+	}
+	return fmt.Errorf("%w: stream ended before the command exited", ErrCommandStream)
+}
+
 // Connect connects to a running command and returns a handle to interact with it.
 // You can use the handle to wait for the command to finish and get execution results.
 //
@@ -471,6 +745,7 @@ func (c *Commands) Connect(ctx context.Context, pid uint32, opts ...CommandConne
 		cfg.onStdout,
 		cfg.onStderr,
 	)
+	handle.commands = c
 
 	return handle, nil
 }
@@ -483,6 +758,11 @@ func (c *Commands) wrapRPCError(ctx context.Context, err error) error {
 		return NewRequestTimeoutError()
 	}
 
+	// Requests through a read-only handle are rejected by the transport
+	if errors.Is(err, ErrReadOnly) {
+		return ErrReadOnly
+	}
+
 	if connectErr, ok := err.(*connect.Error); ok {
 		switch connectErr.Code() {
 		case connect.CodeNotFound:
@@ -492,13 +772,29 @@ func (c *Commands) wrapRPCError(ctx context.Context, err error) error {
 		case connect.CodeDeadlineExceeded:
 			return NewRequestTimeoutError()
 		case connect.CodeUnavailable:
-			return fmt.Errorf("sandbox unavailable: %s", connectErr.Message())
+			return fmt.Errorf("%w: %s", ErrSandboxUnavailable, connectErr.Message())
 		case connect.CodeResourceExhausted:
 			return fmt.Errorf("%w: %s; please try again later", ErrRateLimit, connectErr.Message())
 		default:
-			return fmt.Errorf("rpc error (%s): %s", connectErr.Code(), connectErr.Message())
+			return &rpcError{err: connectErr}
 		}
 	}
 
 	return err
 }
+
+// rpcError reports an RPC failure that has no dedicated sentinel error while
+// keeping the underlying *connect.Error available to errors.As.
+type rpcError struct {
+	err *connect.Error
+}
+
+// Error implements the error interface.
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("rpc error (%s): %s", e.err.Code(), e.err.Message())
+}
+
+// Unwrap returns the underlying connect error.
+func (e *rpcError) Unwrap() error {
+	return e.err
+}
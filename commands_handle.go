@@ -2,8 +2,11 @@ package e2b
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	"connectrpc.com/connect"
 	processpb "github.com/xerpa-ai/e2b-go/internal/proto/process"
@@ -26,29 +29,51 @@ type CommandHandle struct {
 
 	onStdout func(string)
 	onStderr func(string)
+	onExit   func(*CommandResult, error)
+	onData   func([]byte)
+
+	// commands is the client that started or connected to the command
+	commands *Commands
 
 	// PTY support
 	pty           *Pty
 	stream        *connect.ServerStreamForClient[processpb.StartResponse]
 	connectStream *connect.ServerStreamForClient[processpb.ConnectResponse]
 	isPty         bool
-	exitCode      int
+	terminal      *ptyTerminal
+
+	// detached indicates the handle has no event stream (see WithCommandDetach)
+	detached bool
+
+	// streamCtx is the context of the event stream, which is cancelled with
+	// errCommandTimeout when the command timeout elapses
+	streamCtx        context.Context
+	timeout          time.Duration
+	killOnWaitCancel bool
 }
 
+// errCommandTimeout is the cancellation cause of a command's event stream
+// when its timeout elapses.
+var errCommandTimeout = errors.New("command timeout elapsed")
+
 // newCommandHandle creates a new CommandHandle for Start responses and starts processing events.
 func newCommandHandle(
 	pid uint32,
 	stream *connect.ServerStreamForClient[processpb.StartResponse],
+	streamCtx context.Context,
 	handleKill func(ctx context.Context) (bool, error),
-	onStdout func(string),
-	onStderr func(string),
+	cfg *commandConfig,
 ) *CommandHandle {
 	h := &CommandHandle{
-		pid:        pid,
-		handleKill: handleKill,
-		done:       make(chan struct{}),
-		onStdout:   onStdout,
-		onStderr:   onStderr,
+		pid:              pid,
+		handleKill:       handleKill,
+		done:             make(chan struct{}),
+		onStdout:         cfg.onStdout,
+		onStderr:         cfg.onStderr,
+		onExit:           cfg.onExit,
+		streamCtx:        streamCtx,
+		timeout:          cfg.timeout,
+		killOnWaitCancel: cfg.killOnWaitCancel,
 	}
 
 	// Start background goroutine to process events
@@ -57,6 +82,16 @@ func newCommandHandle(
 	return h
 }
 
+// newDetachedCommandHandle creates a CommandHandle for a detached process without an event stream.
+func newDetachedCommandHandle(pid uint32, handleKill func(ctx context.Context) (bool, error)) *CommandHandle {
+	return &CommandHandle{
+		pid:        pid,
+		handleKill: handleKill,
+		done:       make(chan struct{}),
+		detached:   true,
+	}
+}
+
 // newCommandHandleFromConnect creates a new CommandHandle for Connect responses and starts processing events.
 func newCommandHandleFromConnect(
 	pid uint32,
@@ -81,6 +116,7 @@ func newCommandHandleFromConnect(
 
 // processStartEvents reads events from a Start stream and updates internal state.
 func (h *CommandHandle) processStartEvents(stream *connect.ServerStreamForClient[processpb.StartResponse]) {
+	defer h.notifyExit()
 	defer close(h.done)
 
 	for stream.Receive() {
@@ -106,10 +142,39 @@ func (h *CommandHandle) processStartEvents(stream *connect.ServerStreamForClient
 		}
 		h.mu.Unlock()
 	}
+
+	// Cutting the stream leaves the process running, so kill it when the
+	// command timeout elapsed before it exited
+	if h.streamCtx != nil && context.Cause(h.streamCtx) == errCommandTimeout {
+		h.mu.RLock()
+		exited := h.result != nil
+		h.mu.RUnlock()
+		if !exited {
+			h.setTimeoutError(context.Background(), h.timeout)
+		}
+	}
+}
+
+// setTimeoutError kills the command and records a CommandTimeoutError with
+// the output received so far.
+func (h *CommandHandle) setTimeoutError(ctx context.Context, timeout time.Duration) *CommandTimeoutError {
+	killed, _ := h.KillWithContext(ctx)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.err = &CommandTimeoutError{
+		PID:     h.pid,
+		Timeout: timeout,
+		Stdout:  h.stdout.String(),
+		Stderr:  h.stderr.String(),
+		Killed:  killed,
+	}
+	return h.err.(*CommandTimeoutError)
 }
 
 // processConnectEvents reads events from a Connect stream and updates internal state.
 func (h *CommandHandle) processConnectEvents(stream *connect.ServerStreamForClient[processpb.ConnectResponse]) {
+	defer h.notifyExit()
 	defer close(h.done)
 
 	for stream.Receive() {
@@ -139,6 +204,7 @@ func (h *CommandHandle) processConnectEvents(stream *connect.ServerStreamForClie
 
 // processPtyEvents reads events from a PTY start stream and updates internal state.
 func (h *CommandHandle) processPtyEvents() {
+	defer h.notifyExit()
 	defer close(h.done)
 
 	for h.stream.Receive() {
@@ -168,6 +234,7 @@ func (h *CommandHandle) processPtyEvents() {
 
 // processPtyConnectEvents reads events from a PTY connect stream and updates internal state.
 func (h *CommandHandle) processPtyConnectEvents() {
+	defer h.notifyExit()
 	defer close(h.done)
 
 	for h.connectStream.Receive() {
@@ -242,12 +309,18 @@ func (h *CommandHandle) handleDataEvent(data *processpb.ProcessEvent_DataEvent)
 		out := string(pty)
 		h.mu.Lock()
 		h.stdout.WriteString(out)
-		callback := h.onStdout
+		callback, dataCallback, terminal := h.onStdout, h.onData, h.terminal
 		h.mu.Unlock()
 
+		if dataCallback != nil {
+			dataCallback(pty)
+		}
 		if callback != nil {
 			callback(out)
 		}
+		if terminal != nil {
+			terminal.push(pty)
+		}
 	}
 }
 
@@ -267,6 +340,7 @@ func (h *CommandHandle) handleEndEvent(end *processpb.ProcessEvent_EndEvent) {
 		Stderr:   h.stderr.String(),
 		ExitCode: exitCode,
 		Error:    errorMsg,
+		Status:   end.GetStatus(),
 	}
 }
 
@@ -275,13 +349,22 @@ func (h *CommandHandle) PID() uint32 {
 	return h.pid
 }
 
-// Stdout returns the accumulated stdout output.
+// Stdout returns the accumulated stdout output. For PTY handles, this is
+// the terminal output.
 func (h *CommandHandle) Stdout() string {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 	return h.stdout.String()
 }
 
+// StdoutBytes returns a copy of the accumulated stdout output. For PTY
+// handles, this is the raw terminal output.
+func (h *CommandHandle) StdoutBytes() []byte {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return []byte(h.stdout.String())
+}
+
 // Stderr returns the accumulated stderr output.
 func (h *CommandHandle) Stderr() string {
 	h.mu.RLock()
@@ -328,21 +411,127 @@ func (h *CommandHandle) ExitCode() *int {
 	return &exitCode
 }
 
+// Done returns a channel that is closed when the command finishes or its
+// event stream ends. The channel of a detached handle is never closed.
+//
+// Example:
+//
+//	select {
+//	case <-handle.Done():
+//	    result, _ := handle.Result()
+//	    fmt.Println(result.ExitCode)
+//	case <-shutdown:
+//	    handle.KillWithContext(ctx)
+//	}
+func (h *CommandHandle) Done() <-chan struct{} {
+	return h.done
+}
+
+// Result returns the command result without blocking. The boolean is false
+// while the command is still running, or if its event stream ended without
+// reporting an exit; use Wait to learn why. Unlike Wait, a non-zero exit code
+// is not reported as an error.
+func (h *CommandHandle) Result() (*CommandResult, bool) {
+	select {
+	case <-h.done:
+	default:
+		return nil, false
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.result, h.result != nil
+}
+
 // Wait waits for the command to finish and returns the result.
 // If the command exits with a non-zero exit code, it returns a CommandExitError.
+//
+// If ctx is cancelled or times out first, Wait returns an error wrapping both
+// ErrWaitCanceled and the context error; the command keeps running and Wait
+// can be called again. If the command's event stream fails, the error wraps
+// ErrCommandStream.
+//
+// Example:
+//
+//	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+//	defer cancel()
+//	result, err := handle.Wait(waitCtx)
+//	if errors.Is(err, e2b.ErrWaitCanceled) {
+//	    // Still running, check again later
+//	}
 func (h *CommandHandle) Wait(ctx context.Context) (*CommandResult, error) {
+	if h.detached {
+		return nil, fmt.Errorf("%w: command %d is detached, use Commands.Connect to wait for it", ErrInvalidArgument, h.pid)
+	}
+
 	select {
 	case <-ctx.Done():
-		return nil, ctx.Err()
+		if h.killOnWaitCancel {
+			return nil, h.killOnCancel(ctx)
+		}
+		return nil, fmt.Errorf("%w for command %d: %w", ErrWaitCanceled, h.pid, ctx.Err())
 	case <-h.done:
 		// Command finished
 	}
 
+	result, err := h.outcome()
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// WaitExit waits for the command to finish and returns its exit code.
+// Unlike Wait, a non-zero exit code is not reported as an error, which
+// suits interactive PTY sessions where the shell's exit status is
+// informational. Use Result to tell a normal exit from a kill by a signal
+// (see CommandResult.Status).
+//
+// Errors are reported as by Wait: ErrWaitCanceled if ctx ends first and
+// ErrCommandStream if the event stream fails.
+//
+// Example:
+//
+//	terminal, err := sandbox.Pty.Create(ctx, e2b.PtySize{Rows: 24, Cols: 80})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	_ = terminal.SendStdin(ctx, "exit 3\n")
+//	code, err := terminal.WaitExit(ctx)
+//	fmt.Println(code) // 3
+func (h *CommandHandle) WaitExit(ctx context.Context) (int, error) {
+	result, err := h.Wait(ctx)
+	if err != nil {
+		var exitErr *CommandExitError
+		if !errors.As(err, &exitErr) {
+			return 0, err
+		}
+		return exitErr.ExitCode, nil
+	}
+	return result.ExitCode, nil
+}
+
+// killOnCancel kills the command after the context passed to Wait is done
+// (see WithKillOnWaitCancel) and returns the error Wait reports.
+func (h *CommandHandle) killOnCancel(ctx context.Context) error {
+	if ctx.Err() == context.DeadlineExceeded {
+		return h.setTimeoutError(context.WithoutCancel(ctx), 0)
+	}
+	_, _ = h.KillWithContext(context.WithoutCancel(ctx))
+	return fmt.Errorf("%w for command %d: %w", ErrWaitCanceled, h.pid, ctx.Err())
+}
+
+// outcome returns the result of a finished command and the error Wait
+// reports for it. The result is also returned with a CommandExitError.
+func (h *CommandHandle) outcome() (*CommandResult, error) {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
+	if timeoutErr, ok := h.err.(*CommandTimeoutError); ok {
+		return nil, timeoutErr
+	}
 	if h.err != nil {
-		return nil, h.err
+		return h.result, fmt.Errorf("%w for command %d: %w", ErrCommandStream, h.pid, h.err)
 	}
 
 	if h.result == nil {
@@ -350,17 +539,25 @@ func (h *CommandHandle) Wait(ctx context.Context) (*CommandResult, error) {
 	}
 
 	if h.result.ExitCode != 0 {
-		return nil, &CommandExitError{
-			Stdout:       h.result.Stdout,
-			Stderr:       h.result.Stderr,
-			ExitCode:     h.result.ExitCode,
-			ErrorMessage: h.result.Error,
-		}
+		return h.result, h.result.exitError()
 	}
 
 	return h.result, nil
 }
 
+// notifyExit calls the exit callback once the event stream has ended,
+// unless the handle was disconnected.
+func (h *CommandHandle) notifyExit() {
+	h.mu.RLock()
+	onExit, canceled := h.onExit, h.canceled
+	h.mu.RUnlock()
+
+	if onExit == nil || canceled {
+		return
+	}
+	onExit(h.outcome())
+}
+
 // KillWithContext terminates the command with context support.
 // It uses SIGKILL signal to kill the command.
 // Returns true if the command was killed, false if the command was not found.
@@ -390,6 +587,54 @@ func (h *CommandHandle) SendInput(ctx context.Context, data []byte) error {
 	return h.pty.SendStdin(ctx, h.pid, data)
 }
 
+// SendStdin sends data to the stdin of the command. For a PTY, this is
+// equivalent to typing in the terminal.
+//
+// The command must have been started with WithStdin(true), unless it runs
+// in a PTY.
+//
+// Example:
+//
+//	handle, err := sandbox.Commands.RunBackground(ctx, "python3 -i", e2b.WithStdin(true))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if err := handle.SendStdin(ctx, "print(1 + 1)\n"); err != nil {
+//	    log.Fatal(err)
+//	}
+func (h *CommandHandle) SendStdin(ctx context.Context, data string) error {
+	if h.isPty && h.pty != nil {
+		return h.pty.SendStdin(ctx, h.pid, []byte(data))
+	}
+	if h.commands == nil {
+		return fmt.Errorf("%w: handle is not attached to a command", ErrInvalidArgument)
+	}
+	return h.commands.SendStdin(ctx, h.pid, data)
+}
+
+// CloseStdin signals the end of input to the command. For a PTY, it sends
+// the end-of-file character (Ctrl-D) to the terminal; otherwise the
+// command's stdin is closed (see Commands.CloseStdin).
+//
+// Example:
+//
+//	handle, err := sandbox.Commands.RunBackground(ctx, "wc -l", e2b.WithStdin(true))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	_ = handle.SendStdin(ctx, "one\ntwo\n")
+//	_ = handle.CloseStdin(ctx)
+//	result, err := handle.Wait(ctx)
+func (h *CommandHandle) CloseStdin(ctx context.Context) error {
+	if h.isPty && h.pty != nil {
+		return h.pty.SendStdin(ctx, h.pid, []byte{0x04})
+	}
+	if h.commands == nil {
+		return fmt.Errorf("%w: handle is not attached to a command", ErrInvalidArgument)
+	}
+	return h.commands.CloseStdin(ctx, h.pid)
+}
+
 // Resize changes the terminal size for PTY handles.
 // This has no effect on non-PTY handles.
 func (h *CommandHandle) Resize(ctx context.Context, rows, cols uint32) error {
@@ -11,15 +11,23 @@ type commandConfig struct {
 	requestTimeout time.Duration
 	onStdout       func(output string)
 	onStderr       func(output string)
+	onExit         func(result *CommandResult, err error)
 	stdin          *bool
 	tag            *string
+	detach         bool
+	shell          string
+	shellArgs      []string
+
+	killOnWaitCancel bool
 }
 
 // defaultCommandConfig returns the default command configuration.
 // Default timeout is 60 seconds as per official SDKs.
 func defaultCommandConfig() *commandConfig {
 	return &commandConfig{
-		timeout: 60 * time.Second,
+		timeout:   60 * time.Second,
+		shell:     "/bin/bash",
+		shellArgs: []string{"-l", "-c"},
 	}
 }
 
@@ -70,8 +78,51 @@ func WithTag(tag string) CommandOption {
 	}
 }
 
-// WithCommandTimeout sets the timeout for the command connection in seconds.
-// Using 0 will not limit the command connection time.
+// WithCommandDetach starts the command fully detached from the SDK connection,
+// like nohup ... &.
+// Only the start event is read to get the PID, then the event stream is
+// closed, so the process keeps running regardless of the client connection
+// and the command timeout. Output is not captured; redirect it to a file or
+// reattach later with Commands.Connect.
+//
+// The returned handle can be used to get the PID and kill the process, but
+// its Wait method returns an error.
+//
+// Example:
+//
+//	handle, err := sandbox.Commands.RunBackground(ctx,
+//	    "python -m http.server 8080 > /tmp/server.log 2>&1",
+//	    e2b.WithCommandDetach(true),
+//	)
+//	pid := handle.PID()
+func WithCommandDetach(detach bool) CommandOption {
+	return func(c *commandConfig) {
+		c.detach = detach
+	}
+}
+
+// WithShell sets the shell used to run the command. The command is passed as
+// the last argument after args. If no args are given, "-c" is used.
+// Defaults to /bin/bash -l -c.
+//
+// Example:
+//
+//	// Use a plain POSIX shell without sourcing login profiles
+//	result, err := sandbox.Commands.Run(ctx, "echo $0", e2b.WithShell("/bin/sh", "-c"))
+func WithShell(path string, args ...string) CommandOption {
+	return func(c *commandConfig) {
+		c.shell = path
+		c.shellArgs = args
+		if len(args) == 0 {
+			c.shellArgs = []string{"-c"}
+		}
+	}
+}
+
+// WithCommandTimeout sets how long the command may run. When it elapses,
+// the command is killed in the sandbox and Wait returns a
+// *CommandTimeoutError with the output received so far.
+// Using 0 will not limit the command's run time.
 // Default is 60 seconds.
 func WithCommandTimeout(d time.Duration) CommandOption {
 	return func(c *commandConfig) {
@@ -79,6 +130,17 @@ func WithCommandTimeout(d time.Duration) CommandOption {
 	}
 }
 
+// WithKillOnWaitCancel kills the command in the sandbox when the context
+// passed to CommandHandle.Wait (or Commands.Run) is done before the command
+// exits. If the context's deadline passed, Wait returns a
+// *CommandTimeoutError; otherwise it returns an error wrapping
+// ErrWaitCanceled. By default the command keeps running.
+func WithKillOnWaitCancel(kill bool) CommandOption {
+	return func(c *commandConfig) {
+		c.killOnWaitCancel = kill
+	}
+}
+
 // WithCommandRequestTimeout sets the timeout for the API request.
 func WithCommandRequestTimeout(d time.Duration) CommandOption {
 	return func(c *commandConfig) {
@@ -102,6 +164,34 @@ func OnCommandStderr(handler func(output string)) CommandOption {
 	}
 }
 
+// OnCommandExit sets a callback that is called exactly once when the
+// command ends, with the same outcome Wait reports: a nil error on a zero
+// exit code, a *CommandExitError on a non-zero one, or an error wrapping
+// ErrCommandStream if the event stream failed. Unlike Wait, the result is
+// passed alongside a *CommandExitError. The callback runs on a background
+// goroutine and is not called after CommandHandle.Disconnect or for
+// detached commands.
+//
+// Example:
+//
+//	var supervise func()
+//	supervise = func() {
+//	    sandbox.Commands.RunBackground(ctx, "python server.py",
+//	        e2b.OnCommandExit(func(result *e2b.CommandResult, err error) {
+//	            if err != nil {
+//	                log.Printf("server exited: %v, restarting", err)
+//	                supervise()
+//	            }
+//	        }),
+//	    )
+//	}
+//	supervise()
+func OnCommandExit(handler func(result *CommandResult, err error)) CommandOption {
+	return func(c *commandConfig) {
+		c.onExit = handler
+	}
+}
+
 // commandConnectConfig holds configuration for connecting to a command.
 type commandConnectConfig struct {
 	timeout        time.Duration
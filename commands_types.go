@@ -2,6 +2,7 @@ package e2b
 
 import (
 	"fmt"
+	"time"
 
 	processpb "github.com/xerpa-ai/e2b-go/internal/proto/process"
 )
@@ -20,6 +21,21 @@ type CommandResult struct {
 
 	// Error is the error message from command execution, if any.
 	Error string
+
+	// Status is the process status reported by the sandbox, such as
+	// "exit status 1" or "signal: killed" for a command killed by a signal.
+	// It is empty if the sandbox did not report one.
+	Status string
+}
+
+// exitError returns the CommandExitError describing the result.
+func (r *CommandResult) exitError() *CommandExitError {
+	return &CommandExitError{
+		Stdout:       r.Stdout,
+		Stderr:       r.Stderr,
+		ExitCode:     r.ExitCode,
+		ErrorMessage: r.Error,
+	}
 }
 
 // ProcessInfo contains information about a running process in the sandbox.
@@ -110,3 +126,51 @@ func (e *CommandExitError) Error() string {
 	}
 	return fmt.Sprintf("command exited with code %d", e.ExitCode)
 }
+
+// CommandTimeoutError is returned when a command is stopped because its
+// timeout (see WithCommandTimeout) elapsed, or because the context passed
+// to Wait expired with WithKillOnWaitCancel set. It matches ErrTimeout with
+// errors.Is.
+//
+// Example:
+//
+//	_, err := sandbox.Commands.Run(ctx, "./slow-job.sh", e2b.WithCommandTimeout(time.Minute))
+//	var timeoutErr *e2b.CommandTimeoutError
+//	if errors.As(err, &timeoutErr) {
+//	    fmt.Println("partial output:", timeoutErr.Stdout)
+//	}
+type CommandTimeoutError struct {
+	// PID is the process ID of the command.
+	PID uint32
+
+	// Timeout is the command timeout that elapsed, or zero if the context
+	// passed to Wait expired.
+	Timeout time.Duration
+
+	// Stdout is the standard output received before the timeout.
+	Stdout string
+
+	// Stderr is the standard error output received before the timeout.
+	Stderr string
+
+	// Killed reports whether the process was killed in the sandbox. It is
+	// false if the process had already exited or the kill request failed.
+	Killed bool
+}
+
+// Error implements the error interface.
+func (e *CommandTimeoutError) Error() string {
+	msg := fmt.Sprintf("command %d timed out", e.PID)
+	if e.Timeout > 0 {
+		msg += fmt.Sprintf(" after %s", e.Timeout)
+	}
+	if e.Killed {
+		msg += " and was killed"
+	}
+	return msg
+}
+
+// Is reports whether target is ErrTimeout.
+func (e *CommandTimeoutError) Is(target error) bool {
+	return target == ErrTimeout
+}
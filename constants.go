@@ -19,6 +19,9 @@ const (
 	// EnvdPort is the port for the envd service.
 	EnvdPort = 49983
 
+	// McpPort is the port where the MCP gateway runs.
+	McpPort = 50005
+
 	// DefaultSandboxTimeout is the default timeout for sandbox lifetime.
 	// After this timeout, the sandbox will be automatically killed.
 	DefaultSandboxTimeout = 300 * time.Second // 5 minutes
@@ -29,6 +32,38 @@ const (
 	// DefaultRequestTimeout is the default timeout for HTTP requests.
 	DefaultRequestTimeout = 60 * time.Second
 
+	// DefaultRetryAttempts is the default number of attempts for sandbox
+	// create, connect, kill and timeout API calls.
+	DefaultRetryAttempts = 3
+
+	// DefaultRetryBaseDelay is the default delay before the first retry of a
+	// sandbox API call. It doubles with each further attempt.
+	DefaultRetryBaseDelay = 500 * time.Millisecond
+
+	// DefaultUploadBatchSize is the default maximum size in bytes of the
+	// files sent in one upload request by Filesystem.UploadDir.
+	DefaultUploadBatchSize = 32 << 20
+
+	// DefaultDownloadConcurrency is the default number of files downloaded
+	// in parallel by Filesystem.DownloadDir.
+	DefaultDownloadConcurrency = 4
+
+	// DefaultWalkConcurrency is the default number of directories listed
+	// in parallel by Filesystem.WalkDir.
+	DefaultWalkConcurrency = 4
+
+	// DefaultMimeSniffBytes is the default number of bytes read by
+	// Filesystem.GetMimeType to detect a file's type.
+	DefaultMimeSniffBytes = 512
+
+	// DefaultTempDir is the default directory Filesystem.MakeTemp and
+	// Filesystem.MakeTempDir create entries in.
+	DefaultTempDir = "/tmp"
+
+	// DefaultMaxStreamLineSize is the default maximum size in bytes of a
+	// single line of code execution output.
+	DefaultMaxStreamLineSize = 10 << 20
+
 	// KeepalivePingHeader is the header for keepalive ping interval.
 	KeepalivePingHeader = "Keepalive-Ping-Interval"
 
@@ -55,6 +90,14 @@ const (
 
 	// maxStartupEvents is the safety limit for events to receive before getting a start event.
 	maxStartupEvents = 100
+
+	// maxStdinChunkSize is the largest amount of data sent to a command's
+	// stdin in a single request.
+	maxStdinChunkSize = 64 * 1024
+
+	// ptyTerminalBufferSize is the number of PTY output chunks buffered for
+	// a terminal before the event stream waits for them to be read.
+	ptyTerminalBufferSize = 64
 )
 
 // Language constants for code execution.
@@ -1,5 +1,11 @@
 package e2b
 
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
 // Context represents an execution context for code.
 // Contexts maintain isolated state for code execution.
 type Context struct {
@@ -11,20 +17,95 @@ type Context struct {
 
 	// CWD is the current working directory of the context.
 	CWD string `json:"cwd"`
+
+	// CreatedAt is when the context was created. It is reported by the
+	// sandbox when available; otherwise CreateContext records the time the
+	// context was created on the client, and ListContexts leaves it zero.
+	CreatedAt time.Time `json:"created_at,omitzero"`
+
+	// sandbox is the sandbox the context belongs to, set by CreateContext
+	// and ListContexts
+	sandbox *Sandbox
+}
+
+// errContextNotBound returns the error for methods called on a Context that
+// was not obtained from a sandbox.
+func (c *Context) errContextNotBound() error {
+	return fmt.Errorf("%w: context %q is not bound to a sandbox, use Sandbox.CreateContext or Sandbox.ListContexts", ErrInvalidArgument, c.ID)
+}
+
+// RunCode executes code in the context. It is equivalent to calling
+// Sandbox.RunCode with WithContext(c).
+//
+// Passing WithContext with a different context, or WithLanguage with a
+// language other than the context's, returns an error wrapping
+// ErrInvalidArgument. A Context that was not returned by
+// Sandbox.CreateContext or Sandbox.ListContexts is not bound to a sandbox,
+// and its methods return an error wrapping ErrInvalidArgument.
+//
+// Example:
+//
+//	pyCtx, err := sandbox.CreateContext(ctx, e2b.WithContextLanguage(e2b.LanguagePython))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer pyCtx.Remove(ctx)
+//	pyCtx.RunCode(ctx, "x = 42")
+//	execution, err := pyCtx.RunCode(ctx, "x * 2")
+func (c *Context) RunCode(ctx context.Context, code string, opts ...RunOption) (*Execution, error) {
+	if c.sandbox == nil {
+		return nil, c.errContextNotBound()
+	}
+
+	cfg := defaultRunConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.context != nil && cfg.context.ID != c.ID {
+		return nil, fmt.Errorf("%w: cannot run code in context %q with WithContext(%q)", ErrInvalidArgument, c.ID, cfg.context.ID)
+	}
+	if cfg.language != "" && cfg.language != c.Language {
+		return nil, fmt.Errorf("%w: cannot run %s code in a %s context", ErrInvalidArgument, cfg.language, c.Language)
+	}
+
+	// The context determines the language, so a matching WithLanguage is
+	// cleared rather than rejected by Sandbox.RunCode
+	return c.sandbox.RunCode(ctx, code, append(opts[:len(opts):len(opts)], WithContext(c), WithLanguage(""))...)
+}
+
+// Restart restarts the context, clearing its state. It is equivalent to
+// calling Sandbox.RestartContext with the context's ID.
+func (c *Context) Restart(ctx context.Context) error {
+	if c.sandbox == nil {
+		return c.errContextNotBound()
+	}
+	return c.sandbox.RestartContext(ctx, c.ID)
+}
+
+// Remove removes the context. It is equivalent to calling
+// Sandbox.RemoveContext with the context's ID.
+func (c *Context) Remove(ctx context.Context) error {
+	if c.sandbox == nil {
+		return c.errContextNotBound()
+	}
+	return c.sandbox.RemoveContext(ctx, c.ID)
 }
 
 // contextResponse is used for JSON unmarshaling from API responses.
 type contextResponse struct {
-	ID       string `json:"id"`
-	Language string `json:"language"`
-	CWD      string `json:"cwd"`
+	ID        string    `json:"id"`
+	Language  string    `json:"language"`
+	CWD       string    `json:"cwd"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
-// toContext converts a contextResponse to a Context.
-func (c *contextResponse) toContext() *Context {
+// toContext converts a contextResponse to a Context bound to sandbox.
+func (c *contextResponse) toContext(sandbox *Sandbox) *Context {
 	return &Context{
-		ID:       c.ID,
-		Language: c.Language,
-		CWD:      c.CWD,
+		ID:        c.ID,
+		Language:  c.Language,
+		CWD:       c.CWD,
+		CreatedAt: c.CreatedAt,
+		sandbox:   sandbox,
 	}
 }
@@ -0,0 +1,169 @@
+package e2b
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// checkpointPythonCode serializes the user globals of a Python context to a
+// file with pickle and prints a JSON summary of what was saved and skipped.
+const checkpointPythonCode = `def __e2b_checkpoint(path):
+    import json, pickle, types
+    ignored = {"In", "Out", "exit", "quit", "get_ipython"}
+    state, skipped = {}, {}
+    for name, value in list(globals().items()):
+        if name.startswith("_") or name in ignored or isinstance(value, types.ModuleType):
+            continue
+        if isinstance(value, (types.FunctionType, type)) and getattr(value, "__module__", None) == "__main__":
+            skipped[name] = "defined in the session; re-run its definition after restoring"
+            continue
+        try:
+            pickle.dumps(value)
+        except Exception as e:
+            skipped[name] = f"{type(e).__name__}: {e}"
+            continue
+        state[name] = value
+    with open(path, "wb") as f:
+        pickle.dump(state, f)
+    print(json.dumps({"saved": sorted(state), "skipped": skipped}))
+__e2b_checkpoint(%s)
+del __e2b_checkpoint
+`
+
+// restorePythonCode loads a pickle checkpoint into the globals of a Python context.
+const restorePythonCode = `def __e2b_restore(path):
+    import json, pickle
+    with open(path, "rb") as f:
+        state = pickle.load(f)
+    globals().update(state)
+    print(json.dumps({"saved": sorted(state)}))
+__e2b_restore(%s)
+del __e2b_restore
+`
+
+// checkpointSummary is the JSON summary printed by the checkpoint scripts.
+type checkpointSummary struct {
+	Saved   []string          `json:"saved"`
+	Skipped map[string]string `json:"skipped"`
+}
+
+// CheckpointContext saves the serializable state of a context to a file in the sandbox.
+//
+// This is a best-effort way to persist kernel state across RestartContext or
+// sandbox pauses. Only Python contexts are supported: user globals are written
+// with pickle, skipping modules and names starting with an underscore.
+//
+// Objects that cannot be pickled (open files, sockets, generators, locks, ...)
+// and functions or classes defined in the session are not saved. In that case
+// the checkpoint is still written with the remaining variables and a
+// *CheckpointError listing the skipped names is returned.
+//
+// Example:
+//
+//	err := sandbox.CheckpointContext(ctx, execCtx, "/home/user/.checkpoint.pkl")
+//	var cpErr *e2b.CheckpointError
+//	if errors.As(err, &cpErr) {
+//	    log.Printf("not saved: %v", cpErr.Names())
+//	} else if err != nil {
+//	    log.Fatal(err)
+//	}
+func (s *Sandbox) CheckpointContext(ctx context.Context, c *Context, path string) error {
+	summary, err := s.runCheckpointScript(ctx, c, path, checkpointPythonCode)
+	if err != nil {
+		return fmt.Errorf("failed to checkpoint context: %w", err)
+	}
+
+	if len(summary.Skipped) > 0 {
+		return &CheckpointError{Path: path, Skipped: summary.Skipped}
+	}
+	return nil
+}
+
+// RestoreContext loads state saved by CheckpointContext back into a context.
+//
+// Restored variables overwrite existing globals with the same name.
+// Objects whose classes were defined in the session can only be restored
+// after their definitions have been re-run in the context.
+//
+// Example:
+//
+//	if err := sandbox.RestartContext(ctx, execCtx.ID); err != nil {
+//	    log.Fatal(err)
+//	}
+//	if err := sandbox.RestoreContext(ctx, execCtx, "/home/user/.checkpoint.pkl"); err != nil {
+//	    log.Fatal(err)
+//	}
+func (s *Sandbox) RestoreContext(ctx context.Context, c *Context, path string) error {
+	if _, err := s.runCheckpointScript(ctx, c, path, restorePythonCode); err != nil {
+		return fmt.Errorf("failed to restore context: %w", err)
+	}
+	return nil
+}
+
+// runCheckpointScript runs a checkpoint script in the given context and parses its summary.
+func (s *Sandbox) runCheckpointScript(ctx context.Context, c *Context, path, script string) (*checkpointSummary, error) {
+	if c == nil {
+		return nil, fmt.Errorf("%w: context is required", ErrInvalidArgument)
+	}
+	if path == "" {
+		return nil, fmt.Errorf("%w: path is required", ErrInvalidArgument)
+	}
+	if c.Language != "" && c.Language != LanguagePython {
+		return nil, fmt.Errorf("%w: checkpointing is not supported for language %q", ErrInvalidArgument, c.Language)
+	}
+
+	// A JSON string is a valid Python string literal
+	quoted, err := json.Marshal(path)
+	if err != nil {
+		return nil, err
+	}
+
+	execution, err := s.RunCode(ctx, fmt.Sprintf(script, quoted), WithContext(c))
+	if err != nil {
+		return nil, err
+	}
+	if execution.Error != nil {
+		return nil, execution.Error
+	}
+
+	var summary checkpointSummary
+	output := strings.TrimSpace(strings.Join(execution.Logs.Stdout, ""))
+	if err := json.Unmarshal([]byte(output), &summary); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint output: %w", err)
+	}
+	return &summary, nil
+}
+
+// CheckpointError is returned by CheckpointContext when some variables could
+// not be serialized. The checkpoint file is still written with the rest.
+type CheckpointError struct {
+	// Path is the checkpoint file path.
+	Path string
+
+	// Skipped maps each variable that was not saved to the reason.
+	Skipped map[string]string
+}
+
+// Names returns the sorted names of the variables that were not saved.
+func (e *CheckpointError) Names() []string {
+	names := make([]string, 0, len(e.Skipped))
+	for name := range e.Skipped {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Error implements the error interface.
+func (e *CheckpointError) Error() string {
+	names := e.Names()
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s (%s)", name, e.Skipped[name])
+	}
+	return fmt.Sprintf("checkpoint %s: %d variable(s) could not be serialized: %s",
+		e.Path, len(names), strings.Join(parts, ", "))
+}
@@ -90,8 +90,11 @@
 // The SDK provides typed errors for common error conditions:
 //
 //	execution, err := sandbox.RunCode(ctx, code)
-//	if errors.Is(err, e2b.ErrTimeout) {
-//	    // Handle execution timeout
+//	if errors.Is(err, e2b.ErrExecutionTimeout) {
+//	    // Handle code execution timeout
+//	}
+//	if errors.Is(err, e2b.ErrRequestTimeout) {
+//	    // Handle HTTP/transport timeout
 //	}
 //	if errors.Is(err, e2b.ErrNotFound) {
 //	    // Handle resource not found
@@ -1,14 +1,23 @@
 package e2b
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
 )
 
 // Sentinel errors for common error conditions.
 var (
-	// ErrTimeout indicates that the code execution timed out.
-	ErrTimeout = errors.New("e2b: execution timeout")
+	// ErrTimeout indicates that an operation timed out.
+	// Both ErrExecutionTimeout and ErrRequestTimeout match ErrTimeout.
+	ErrTimeout = errors.New("e2b: timeout")
+
+	// ErrExecutionTimeout indicates that the code execution timed out.
+	ErrExecutionTimeout = errors.New("e2b: execution timeout")
 
 	// ErrRequestTimeout indicates that the HTTP request timed out.
 	ErrRequestTimeout = errors.New("e2b: request timeout")
@@ -23,13 +32,43 @@ var (
 	ErrSandboxClosed = errors.New("e2b: sandbox is closed")
 
 	// ErrRateLimit indicates that the rate limit has been exceeded.
+	// Use errors.As with *RateLimitError to get the suggested retry delay.
 	ErrRateLimit = errors.New("e2b: rate limit exceeded")
 
+	// ErrRateLimited is an alias of ErrRateLimit; both match the same errors.
+	ErrRateLimited = ErrRateLimit
+
 	// ErrAuthentication indicates an authentication failure.
 	ErrAuthentication = errors.New("e2b: authentication error")
 
 	// ErrNotEnoughSpace indicates insufficient disk space in the sandbox.
 	ErrNotEnoughSpace = errors.New("e2b: not enough disk space")
+
+	// ErrExecutionCanceled indicates that an execution was abandoned with ExecutionHandle.Cancel.
+	ErrExecutionCanceled = errors.New("e2b: execution canceled")
+
+	// ErrReadOnly indicates an operation that is not allowed on a read-only sandbox handle.
+	ErrReadOnly = errors.New("e2b: sandbox handle is read-only")
+
+	// ErrSandboxUnavailable indicates that the sandbox could not be reached,
+	// for example after a dropped connection. Call Sandbox.Reconnect and retry.
+	ErrSandboxUnavailable = errors.New("e2b: sandbox unavailable")
+
+	// ErrWaitCanceled indicates that waiting for a command was abandoned
+	// because the context passed to Wait was cancelled or timed out. The
+	// command itself may still be running.
+	ErrWaitCanceled = errors.New("e2b: wait canceled")
+
+	// ErrCommandStream indicates that the event stream of a command failed
+	// before the command reported its exit.
+	ErrCommandStream = errors.New("e2b: command stream failed")
+
+	// ErrPoolClosed indicates the sandbox pool has been closed.
+	ErrPoolClosed = errors.New("e2b: sandbox pool is closed")
+
+	// ErrStreamLineTooLong indicates that a line of code execution output
+	// exceeded the limit set with WithMaxStreamLineSize.
+	ErrStreamLineTooLong = errors.New("e2b: stream line too long")
 )
 
 // SandboxError represents an error returned by the sandbox API.
@@ -77,6 +116,69 @@ func (e *SandboxError) Is(target error) bool {
 	}
 }
 
+// APIError represents an error response from the E2B API, such as when
+// creating, connecting to or killing a sandbox, or building a template. It
+// matches the sentinel errors for its status code with errors.Is, like
+// SandboxError.
+//
+// Example:
+//
+//	_, err := e2b.Connect(ctx, sandboxID)
+//	var apiErr *e2b.APIError
+//	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusForbidden {
+//	    log.Fatalf("no access to sandbox %s (request %s)", sandboxID, apiErr.RequestID)
+//	}
+type APIError struct {
+	// StatusCode is the HTTP status code.
+	StatusCode int
+
+	// Code is the error code from the response body, usually equal to
+	// StatusCode, or zero if the body had none.
+	Code int
+
+	// Message is the error message from the response body, or the body
+	// itself if it was not a JSON error.
+	Message string
+
+	// RequestID is the ID the API assigned to the request, if it returned
+	// one, for reporting issues.
+	RequestID string
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	return fmt.Sprintf("api error (status %d): %s", e.StatusCode, e.Message)
+}
+
+// Is checks if the error matches the target.
+func (e *APIError) Is(target error) bool {
+	return (&SandboxError{StatusCode: e.StatusCode}).Is(target)
+}
+
+// newAPIError creates an APIError from an API response and its body,
+// parsing the JSON error envelope {"code": ..., "message": ...}.
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		RequestID:  resp.Header.Get("X-Request-Id"),
+	}
+
+	var envelope struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &envelope) == nil && envelope.Message != "" {
+		apiErr.Code = envelope.Code
+		apiErr.Message = envelope.Message
+	} else {
+		apiErr.Message = strings.TrimSpace(string(body))
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = "unknown error"
+	}
+	return apiErr
+}
+
 // NewSandboxError creates a new SandboxError.
 func NewSandboxError(statusCode int, message string) *SandboxError {
 	return &SandboxError{
@@ -103,12 +205,14 @@ func (e *TimeoutError) Error() string {
 }
 
 // Is checks if the error matches the target.
+// Every timeout matches ErrTimeout; execution timeouts additionally match
+// ErrExecutionTimeout and request timeouts match ErrRequestTimeout.
 func (e *TimeoutError) Is(target error) bool {
 	switch e.Type {
 	case "execution":
-		return target == ErrTimeout
+		return target == ErrTimeout || target == ErrExecutionTimeout
 	case "request":
-		return target == ErrRequestTimeout
+		return target == ErrTimeout || target == ErrRequestTimeout
 	default:
 		return false
 	}
@@ -128,6 +232,149 @@ func NewRequestTimeoutError() *TimeoutError {
 	}
 }
 
+// BuildTimeoutError is returned when waiting for a template build exceeds
+// the limit set with WithBuildMaxWait. The build keeps running; pass
+// LogsOffset to WithBuildLogsOffset to resume waiting with WaitForBuild.
+// It matches ErrTimeout with errors.Is.
+type BuildTimeoutError struct {
+	// TemplateID is the ID of the template being built.
+	TemplateID string
+
+	// BuildID is the ID of the build.
+	BuildID string
+
+	// Status is the last observed build status.
+	Status TemplateBuildStatus
+
+	// LogsOffset is the number of build log entries received so far.
+	LogsOffset int
+
+	// Waited is how long the build was waited for.
+	Waited time.Duration
+}
+
+// Error implements the error interface.
+func (e *BuildTimeoutError) Error() string {
+	return fmt.Sprintf("template build %s still %s after waiting %s", e.BuildID, e.Status, e.Waited)
+}
+
+// Is reports whether target is ErrTimeout.
+func (e *BuildTimeoutError) Is(target error) bool {
+	return target == ErrTimeout
+}
+
+// RateLimitError represents a request rejected with HTTP 429 Too Many
+// Requests. It matches ErrRateLimit (and ErrRateLimited) with errors.Is.
+//
+// Example:
+//
+//	execution, err := sandbox.RunCode(ctx, code)
+//	var rateErr *e2b.RateLimitError
+//	if errors.As(err, &rateErr) && rateErr.RetryAfter > 0 {
+//	    time.Sleep(rateErr.RetryAfter)
+//	}
+type RateLimitError struct {
+	// StatusCode is the HTTP status code.
+	StatusCode int
+
+	// RetryAfter is the delay requested by the Retry-After header, or zero
+	// if the response did not include one.
+	RetryAfter time.Duration
+
+	// Message is the error message returned by the server.
+	Message string
+}
+
+// Error implements the error interface.
+func (e *RateLimitError) Error() string {
+	msg := "rate limit exceeded"
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	if e.RetryAfter > 0 {
+		msg += fmt.Sprintf(" (retry after %s)", e.RetryAfter)
+	}
+	return msg
+}
+
+// Is checks if the error matches the target.
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimit
+}
+
+// newRateLimitError creates a RateLimitError from a 429 response's headers
+// and message.
+func newRateLimitError(header http.Header, message string) *RateLimitError {
+	retryAfter, _ := parseRetryAfter(header.Get("Retry-After"))
+	return &RateLimitError{
+		StatusCode: http.StatusTooManyRequests,
+		RetryAfter: retryAfter,
+		Message:    strings.TrimSpace(message),
+	}
+}
+
+// UploadDirError reports the files that could not be uploaded by
+// Filesystem.UploadDir or Filesystem.WriteDir. Files that were uploaded are
+// still returned alongside it.
+type UploadDirError struct {
+	// Failed maps the remote path of each file that was not uploaded to the
+	// reason it failed.
+	Failed map[string]error
+}
+
+// Error implements the error interface.
+func (e *UploadDirError) Error() string {
+	paths := make([]string, 0, len(e.Failed))
+	for p := range e.Failed {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	const maxListed = 5
+	listed := paths
+	if len(listed) > maxListed {
+		listed = listed[:maxListed]
+	}
+	msg := fmt.Sprintf("failed to upload %d files: %s", len(paths), strings.Join(listed, ", "))
+	if len(paths) > maxListed {
+		msg += ", ..."
+	}
+	if len(paths) > 0 {
+		msg += fmt.Sprintf(" (%s: %v)", paths[0], e.Failed[paths[0]])
+	}
+	return msg
+}
+
+// Unwrap returns the errors of the failed files.
+func (e *UploadDirError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failed))
+	for _, err := range e.Failed {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// DownloadDirError reports a Filesystem.DownloadDir call that stopped
+// before all files were downloaded, because a download failed or the
+// context was cancelled.
+type DownloadDirError struct {
+	// Completed lists the remote paths of the files that were downloaded.
+	Completed []string
+
+	// Err is the error that stopped the download.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *DownloadDirError) Error() string {
+	return fmt.Sprintf("directory download stopped after %d files: %v", len(e.Completed), e.Err)
+}
+
+// Unwrap returns the error that stopped the download.
+func (e *DownloadDirError) Unwrap() error {
+	return e.Err
+}
+
 // formatHTTPError converts an HTTP response to an appropriate error.
 func formatHTTPError(statusCode int, body string) error {
 	message := body
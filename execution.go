@@ -18,6 +18,12 @@ type Execution struct {
 
 	// ExecutionCount is the cell execution count.
 	ExecutionCount int `json:"execution_count,omitempty"`
+
+	// CellID identifies the execution. See WithCellID.
+	CellID string `json:"cell_id,omitempty"`
+
+	// Metadata is the caller-supplied metadata set with WithExecutionMetadata.
+	Metadata map[string]string `json:"metadata,omitempty"`
 }
 
 // Text returns the text representation of the main result.
@@ -40,6 +46,24 @@ func (e *Execution) MarshalJSON() ([]byte, error) {
 	})
 }
 
+// UnmarshalJSON implements json.Unmarshaler, restoring an Execution encoded
+// by MarshalJSON, including the charts of its results.
+//
+// Example:
+//
+//	data, err := json.Marshal(execution)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	var restored e2b.Execution
+//	if err := json.Unmarshal(data, &restored); err != nil {
+//	    log.Fatal(err)
+//	}
+func (e *Execution) UnmarshalJSON(data []byte) error {
+	type Alias Execution
+	return json.Unmarshal(data, (*Alias)(e))
+}
+
 // ExecutionError represents an error that occurred during code execution.
 type ExecutionError struct {
 	// Name is the error type name.
@@ -123,6 +147,29 @@ type Result struct {
 	Extra map[string]any `json:"extra,omitempty"`
 }
 
+// UnmarshalJSON implements json.Unmarshaler. The chart is decoded with
+// DeserializeChart, so a Result encoded with json.Marshal is restored with
+// its typed chart.
+func (r *Result) UnmarshalJSON(data []byte) error {
+	type Alias Result
+	aux := &struct {
+		*Alias
+		Chart map[string]any `json:"chart,omitempty"`
+	}{
+		Alias: (*Alias)(r),
+	}
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+
+	chart, err := DeserializeChart(aux.Chart)
+	if err != nil {
+		return fmt.Errorf("failed to decode result chart: %w", err)
+	}
+	r.Chart = chart
+	return nil
+}
+
 // Formats returns all available formats of the result.
 func (r *Result) Formats() []string {
 	var formats []string
@@ -187,3 +234,18 @@ type OutputMessage struct {
 func (o OutputMessage) String() string {
 	return o.Line
 }
+
+// ImageResult is an image decoded from a result by Execution.ImageResults.
+type ImageResult struct {
+	// Data is the decoded image.
+	Data []byte
+
+	// ContentType is the MIME type detected from Data, such as "image/png".
+	ContentType string
+
+	// Format is the result format the image was taken from, "png" or "jpeg".
+	Format string
+
+	// Result is the result the image belongs to.
+	Result *Result
+}
@@ -0,0 +1,340 @@
+package e2b
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ImageBytes returns the decoded image of the result and its MIME type,
+// preferring PNG, then JPEG, then SVG.
+//
+// A result without an image returns an error wrapping ErrNotFound, and
+// malformed base64 data returns an error wrapping ErrInvalidArgument.
+//
+// Example:
+//
+//	for _, result := range execution.Results {
+//	    data, mimeType, err := result.ImageBytes()
+//	    if err != nil {
+//	        continue
+//	    }
+//	    if mimeType == "image/png" {
+//	        os.WriteFile("plot.png", data, 0o644)
+//	    }
+//	}
+func (r *Result) ImageBytes() ([]byte, string, error) {
+	switch {
+	case r.PNG != "":
+		data, err := decodeBase64Image(r.PNG, "PNG")
+		return data, "image/png", err
+	case r.JPEG != "":
+		data, err := decodeBase64Image(r.JPEG, "JPEG")
+		return data, "image/jpeg", err
+	case r.SVG != "":
+		return []byte(r.SVG), "image/svg+xml", nil
+	default:
+		return nil, "", fmt.Errorf("%w: result has no image", ErrNotFound)
+	}
+}
+
+// decodeBase64Image decodes base64 image data, ignoring line breaks.
+func decodeBase64Image(data, format string) ([]byte, error) {
+	data = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\r' || r == ' ' {
+			return -1
+		}
+		return r
+	}, data)
+
+	decoded, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed base64 %s data: %v", ErrInvalidArgument, format, err)
+	}
+	return decoded, nil
+}
+
+// DataFrame interprets the Data or JSON payload of the result as a table
+// and returns its rows, each mapping column names to values.
+//
+// The layouts produced by pandas' to_dict and to_json are recognized: split
+// ({"columns": [...], "data": [[...]]}), records under a "data" or
+// "records" key, and column-oriented maps where each column holds a list or
+// an index-to-value map. Rows of index-keyed columns are ordered by index.
+// A payload that does not look tabular returns an error wrapping
+// ErrInvalidArgument.
+//
+// Example:
+//
+//	execution, _ := sandbox.RunCode(ctx, "df")
+//	for _, result := range execution.Results {
+//	    rows, err := result.DataFrame()
+//	    if err != nil {
+//	        continue
+//	    }
+//	    for _, row := range rows {
+//	        fmt.Println(row["name"], row["score"])
+//	    }
+//	}
+func (r *Result) DataFrame() ([]map[string]any, error) {
+	for _, payload := range []map[string]any{r.Data, r.JSON} {
+		if payload == nil {
+			continue
+		}
+		if rows, ok := tableRows(payload); ok {
+			return rows, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: result has no tabular data", ErrInvalidArgument)
+}
+
+// tableRows converts a tabular payload to rows.
+func tableRows(payload map[string]any) ([]map[string]any, bool) {
+	// Split orientation
+	if columns, ok := payload["columns"].([]any); ok {
+		if data, ok := payload["data"].([]any); ok {
+			return splitRows(columns, data)
+		}
+	}
+
+	// Record orientation under a well-known key
+	for _, key := range []string{"data", "records"} {
+		if records, ok := payload[key].([]any); ok {
+			return recordRows(records)
+		}
+	}
+
+	return columnRows(payload)
+}
+
+// splitRows converts split-oriented columns and row values to rows.
+func splitRows(columns, data []any) ([]map[string]any, bool) {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = fmt.Sprint(c)
+	}
+
+	rows := make([]map[string]any, 0, len(data))
+	for _, d := range data {
+		values, ok := d.([]any)
+		if !ok || len(values) != len(names) {
+			return nil, false
+		}
+		row := make(map[string]any, len(names))
+		for i, name := range names {
+			row[name] = values[i]
+		}
+		rows = append(rows, row)
+	}
+	return rows, true
+}
+
+// recordRows converts a list of records to rows.
+func recordRows(records []any) ([]map[string]any, bool) {
+	rows := make([]map[string]any, 0, len(records))
+	for _, record := range records {
+		row, ok := record.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		rows = append(rows, row)
+	}
+	return rows, true
+}
+
+// columnRows converts a column-oriented payload, where every column is a
+// list of equal length or an index-to-value map, to rows.
+func columnRows(payload map[string]any) ([]map[string]any, bool) {
+	if len(payload) == 0 {
+		return nil, false
+	}
+
+	var rows []map[string]any
+	var index []string
+	for name, column := range payload {
+		switch values := column.(type) {
+		case []any:
+			if rows == nil && index == nil {
+				rows = make([]map[string]any, len(values))
+				for i := range rows {
+					rows[i] = make(map[string]any, len(payload))
+				}
+			}
+			if index != nil || len(values) != len(rows) {
+				return nil, false
+			}
+			for i, v := range values {
+				rows[i][name] = v
+			}
+		case map[string]any:
+			if rows == nil {
+				index = sortedIndex(values)
+				rows = make([]map[string]any, len(index))
+				for i := range rows {
+					rows[i] = make(map[string]any, len(payload))
+				}
+			}
+			if index == nil || len(values) != len(index) {
+				return nil, false
+			}
+			for i, key := range index {
+				v, ok := values[key]
+				if !ok {
+					return nil, false
+				}
+				rows[i][name] = v
+			}
+		default:
+			return nil, false
+		}
+	}
+
+	return rows, true
+}
+
+// sortedIndex returns the keys of an index-to-value map, ordered
+// numerically when all keys are integers.
+func sortedIndex(values map[string]any) []string {
+	keys := make([]string, 0, len(values))
+	numeric := true
+	for key := range values {
+		keys = append(keys, key)
+		if _, err := strconv.Atoi(key); err != nil {
+			numeric = false
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if numeric {
+			a, _ := strconv.Atoi(keys[i])
+			b, _ := strconv.Atoi(keys[j])
+			return a < b
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// FirstChart returns the first chart among the results, or nil if there is
+// none.
+//
+// Example:
+//
+//	if chart := execution.FirstChart(); chart != nil {
+//	    fmt.Println(chart.ChartType(), chart.ChartTitle())
+//	}
+func (e *Execution) FirstChart() Chart {
+	for _, r := range e.Results {
+		if r.Chart != nil {
+			return r.Chart
+		}
+	}
+	return nil
+}
+
+// AllCharts returns the leaf charts of all results, in result order, with
+// superchart results flattened into their sub-charts by FlattenCharts.
+//
+// Example:
+//
+//	for _, chart := range execution.AllCharts() {
+//	    spec, err := e2b.ChartToVegaLite(chart)
+//	    if err != nil {
+//	        log.Println(err)
+//	        continue
+//	    }
+//	    json.NewEncoder(w).Encode(spec)
+//	}
+func (e *Execution) AllCharts() []Chart {
+	var charts []Chart
+	for _, r := range e.Results {
+		charts = append(charts, FlattenCharts(r.Chart)...)
+	}
+	return charts
+}
+
+// Images returns the decoded images of all results, in result order, using
+// the same preference as Result.ImageBytes. Results without an image or
+// with malformed image data are skipped; call ImageBytes on the individual
+// results to see decoding errors.
+//
+// Example:
+//
+//	for i, img := range execution.Images() {
+//	    os.WriteFile(fmt.Sprintf("figure-%d", i), img, 0o644)
+//	}
+func (e *Execution) Images() [][]byte {
+	var images [][]byte
+	for _, r := range e.Results {
+		if data, _, err := r.ImageBytes(); err == nil {
+			images = append(images, data)
+		}
+	}
+	return images
+}
+
+// ImageResults decodes the PNG and JPEG images of all results, in result
+// order, with a result holding both formats yielding both images.
+//
+// The content type of each image is detected from its decoded bytes, so an
+// image is reported correctly even if the kernel labelled it with the wrong
+// format. Unlike Images, malformed base64 data is not skipped but returns an
+// error wrapping ErrInvalidArgument.
+//
+// Example:
+//
+//	images, err := execution.ImageResults()
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for i, img := range images {
+//	    os.WriteFile(fmt.Sprintf("figure-%d.%s", i, img.Format), img.Data, 0o644)
+//	}
+func (e *Execution) ImageResults() ([]ImageResult, error) {
+	var images []ImageResult
+	for i, r := range e.Results {
+		for _, f := range []struct{ format, data, mimeType string }{
+			{"png", r.PNG, "image/png"},
+			{"jpeg", r.JPEG, "image/jpeg"},
+		} {
+			if f.data == "" {
+				continue
+			}
+			data, err := decodeBase64Image(f.data, strings.ToUpper(f.format))
+			if err != nil {
+				return nil, fmt.Errorf("result %d: %w", i, err)
+			}
+
+			contentType := http.DetectContentType(data)
+			if !strings.HasPrefix(contentType, "image/") {
+				contentType = f.mimeType
+			}
+			images = append(images, ImageResult{Data: data, ContentType: contentType, Format: f.format, Result: r})
+		}
+	}
+	return images, nil
+}
+
+// ResultsByFormat returns the results that have format, one of the names
+// returned by Result.Formats such as "png", "html" or "chart", in result
+// order. The format is matched case-insensitively.
+//
+// Example:
+//
+//	for _, result := range execution.ResultsByFormat("html") {
+//	    fmt.Println(result.HTML)
+//	}
+func (e *Execution) ResultsByFormat(format string) []*Result {
+	var results []*Result
+	for _, r := range e.Results {
+		for _, f := range r.Formats() {
+			if strings.EqualFold(f, format) {
+				results = append(results, r)
+				break
+			}
+		}
+	}
+	return results
+}
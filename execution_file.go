@@ -0,0 +1,142 @@
+package e2b
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// fileLanguages maps lower-cased file extensions to the language RunCodeFile
+// runs them as.
+var fileLanguages = map[string]string{
+	".py":   LanguagePython,
+	".js":   LanguageJavaScript,
+	".ts":   LanguageTypeScript,
+	".r":    LanguageR,
+	".sh":   LanguageBash,
+	".java": LanguageJava,
+}
+
+// languageForFile returns the language of the file at p based on its
+// extension.
+func languageForFile(p string) (string, bool) {
+	lang, ok := fileLanguages[strings.ToLower(filepath.Ext(p))]
+	return lang, ok
+}
+
+// RunCodeFile reads the local file at localPath and executes its contents in
+// the sandbox.
+//
+// The language is inferred from the file extension: .py, .js, .ts, .r, .sh
+// and .java run as Python, JavaScript, TypeScript, R, Bash and Java. Other
+// extensions return an error wrapping ErrInvalidArgument unless the
+// language is set with WithLanguage or a context is set with WithContext.
+//
+// Code runs in the code interpreter like RunCode. If the language is Bash
+// and WithPreferCommands is set, the script is instead uploaded to a
+// temporary file and run with Commands.Run, so it gets a regular shell
+// instead of a kernel. Its output is then returned in Execution.Logs, and a
+// non-zero exit code is reported in Execution.Error like an error raised by
+// interpreted code.
+//
+// Example:
+//
+//	execution, err := sandbox.RunCodeFile(ctx, "scripts/analyze.py")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Println(execution.Logs.Stdout)
+func (s *Sandbox) RunCodeFile(ctx context.Context, localPath string, opts ...RunOption) (*Execution, error) {
+	cfg := defaultRunConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	lang := cfg.language
+	if lang == "" && cfg.context == nil {
+		var ok bool
+		if lang, ok = languageForFile(localPath); !ok {
+			return nil, fmt.Errorf("%w: cannot infer the language of %s, use WithLanguage", ErrInvalidArgument, localPath)
+		}
+	}
+
+	code, err := os.ReadFile(localPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", localPath, err)
+	}
+
+	if cfg.preferCommands && cfg.context == nil && lang == LanguageBash {
+		return s.runScriptCommand(ctx, code, cfg)
+	}
+
+	if cfg.context == nil {
+		opts = append(opts[:len(opts):len(opts)], WithLanguage(lang))
+	}
+	return s.RunCode(ctx, string(code), opts...)
+}
+
+// runScriptCommand uploads a Bash script to a temporary file, runs it with
+// Commands.Run and returns its output as an Execution.
+func (s *Sandbox) runScriptCommand(ctx context.Context, script []byte, cfg *runConfig) (*Execution, error) {
+	timeout := DefaultCodeExecutionTimeout
+	if cfg.timeout != nil {
+		timeout = *cfg.timeout
+	}
+
+	scriptPath, err := s.Files.MakeTemp(ctx, WithTempPrefix("run-"), WithTempRequestTimeout(cfg.requestTimeout))
+	if err != nil {
+		return nil, err
+	}
+	defer s.Files.RemoveTemp(context.WithoutCancel(ctx), scriptPath)
+
+	if _, err := s.Files.Write(ctx, scriptPath, script, WithWriteRequestTimeout(cfg.requestTimeout)); err != nil {
+		return nil, err
+	}
+
+	// Output callbacks are called from a single goroutine and Run returns
+	// only after the last one, so the logs need no locking
+	execution := &Execution{
+		Results:  make([]*Result, 0),
+		Logs:     NewLogs(),
+		Metadata: cfg.metadata,
+	}
+	cmdOpts := []CommandOption{
+		WithCommandEnvs(cfg.envVars),
+		WithCommandTimeout(timeout),
+		WithCommandRequestTimeout(cfg.requestTimeout),
+		OnCommandStdout(func(output string) {
+			execution.Logs.Stdout = append(execution.Logs.Stdout, output)
+			if cfg.onStdout != nil {
+				cfg.onStdout(OutputMessage{Line: output, Timestamp: time.Now().UnixNano()})
+			}
+		}),
+		OnCommandStderr(func(output string) {
+			execution.Logs.Stderr = append(execution.Logs.Stderr, output)
+			if cfg.onStderr != nil {
+				cfg.onStderr(OutputMessage{Line: output, Timestamp: time.Now().UnixNano(), Error: true})
+			}
+		}),
+	}
+
+	_, err = s.Commands.Run(ctx, "bash "+shellQuote(scriptPath), cmdOpts...)
+	var exitErr *CommandExitError
+	switch {
+	case err == nil:
+	case errors.As(err, &exitErr):
+		execution.Error = &ExecutionError{
+			Name:      "CommandExitError",
+			Value:     fmt.Sprintf("exit status %d", exitErr.ExitCode),
+			Traceback: exitErr.Stderr,
+		}
+		if cfg.onError != nil {
+			cfg.onError(execution.Error)
+		}
+	default:
+		return nil, err
+	}
+	return execution, nil
+}
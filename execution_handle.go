@@ -0,0 +1,130 @@
+package e2b
+
+import (
+	"context"
+	"sync"
+)
+
+// ExecutionHandle represents a code execution running in the background.
+// It is returned by RunUntilFirstResult.
+type ExecutionHandle struct {
+	cellID  string
+	sandbox *Sandbox
+
+	done      chan struct{}
+	execution *Execution
+	err       error
+}
+
+// CellID returns the cell ID of the execution. It matches Execution.CellID.
+func (h *ExecutionHandle) CellID() string {
+	return h.cellID
+}
+
+// Done returns a channel that is closed when the execution finishes.
+func (h *ExecutionHandle) Done() <-chan struct{} {
+	return h.done
+}
+
+// Wait waits for the execution to finish and returns the full execution,
+// including the first result.
+func (h *ExecutionHandle) Wait(ctx context.Context) (*Execution, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-h.done:
+		return h.execution, h.err
+	}
+}
+
+// Cancel abandons the execution.
+// Wait returns ErrExecutionCanceled afterwards.
+func (h *ExecutionHandle) Cancel() {
+	h.sandbox.execMu.Lock()
+	e, ok := h.sandbox.executions[h.cellID]
+	h.sandbox.execMu.Unlock()
+
+	if ok {
+		e.cancel(ErrExecutionCanceled)
+	}
+}
+
+// RunUntilFirstResult executes code and returns as soon as the first result
+// arrives, leaving the execution running in the background.
+//
+// The returned handle can be used to wait for the rest of the execution or to
+// cancel it. If the execution finishes without producing a result, the
+// returned result is nil and the execution's error (a RunCode error or the
+// *ExecutionError reported by the kernel) is returned. Use
+// WithFirstResultTimeout to bound how long to wait for the first result.
+//
+// Example:
+//
+//	result, handle, err := sandbox.RunUntilFirstResult(ctx, code,
+//	    e2b.WithFirstResultTimeout(10*time.Second),
+//	    e2b.WithRunTimeout(0),
+//	)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Println(result.Text)
+//	defer handle.Cancel()
+func (s *Sandbox) RunUntilFirstResult(ctx context.Context, code string, opts ...RunOption) (*Result, *ExecutionHandle, error) {
+	cfg := defaultRunConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	cellID := cfg.cellID
+	if cellID == "" {
+		cellID = newCellID()
+	}
+
+	handle := &ExecutionHandle{
+		cellID:  cellID,
+		sandbox: s,
+		done:    make(chan struct{}),
+	}
+
+	first := make(chan *Result, 1)
+	var once sync.Once
+	onResult := cfg.onResult
+
+	runOpts := make([]RunOption, 0, len(opts)+2)
+	runOpts = append(runOpts, opts...)
+	runOpts = append(runOpts,
+		WithCellID(cellID),
+		OnResult(func(r *Result) {
+			once.Do(func() { first <- r })
+			if onResult != nil {
+				onResult(r)
+			}
+		}),
+	)
+
+	go func() {
+		defer close(handle.done)
+		handle.execution, handle.err = s.RunCode(ctx, code, runOpts...)
+	}()
+
+	select {
+	case result := <-first:
+		return result, handle, nil
+	case <-handle.done:
+	}
+
+	// The execution finished; a result may still have been delivered
+	select {
+	case result := <-first:
+		return result, handle, nil
+	default:
+	}
+
+	if handle.err != nil {
+		return nil, handle, handle.err
+	}
+	if handle.execution.Error != nil {
+		return nil, handle, handle.execution.Error
+	}
+	return nil, handle, nil
+}
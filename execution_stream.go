@@ -0,0 +1,140 @@
+package e2b
+
+import (
+	"context"
+	"fmt"
+)
+
+// ExecutionEventType identifies the kind of an ExecutionEvent.
+type ExecutionEventType string
+
+const (
+	// ExecutionEventStdout is a line written to stdout.
+	ExecutionEventStdout ExecutionEventType = "stdout"
+	// ExecutionEventStderr is a line written to stderr.
+	ExecutionEventStderr ExecutionEventType = "stderr"
+	// ExecutionEventResult is a result produced by the execution.
+	ExecutionEventResult ExecutionEventType = "result"
+	// ExecutionEventError is an error raised by the code or a failure of the execution itself.
+	ExecutionEventError ExecutionEventType = "error"
+	// ExecutionEventCount reports the execution count of the cell.
+	ExecutionEventCount ExecutionEventType = "count"
+)
+
+// ExecutionEvent is a single event emitted by RunCodeStream.
+// Type determines which of the other fields is set.
+type ExecutionEvent struct {
+	// Type is the kind of event.
+	Type ExecutionEventType
+
+	// Output is the output line (stdout and stderr events).
+	Output OutputMessage
+
+	// Result is the produced result (result events).
+	Result *Result
+
+	// Error is the error raised by the executed code (error events).
+	Error *ExecutionError
+
+	// Err is set instead of Error when the execution itself failed, for
+	// example on a timeout or a network error (error events). It is always
+	// the last event.
+	Err error
+
+	// ExecutionCount is the execution count of the cell (count events).
+	ExecutionCount int
+}
+
+// RunCodeStream executes code and returns a channel of execution events as
+// they arrive, instead of buffering the whole Execution like RunCode.
+//
+// The channel is closed when the execution finishes. If the execution fails,
+// an error event with Err set is sent last. The caller must either drain the
+// channel or cancel ctx, which stops the execution and closes the channel.
+// Callbacks set with OnStdout, OnStderr, OnResult and OnError are still called.
+//
+// Example:
+//
+//	events, err := sandbox.RunCodeStream(ctx, code)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for event := range events {
+//	    switch event.Type {
+//	    case e2b.ExecutionEventStdout:
+//	        fmt.Print(event.Output.Line)
+//	    case e2b.ExecutionEventResult:
+//	        fmt.Println(event.Result.Text)
+//	    case e2b.ExecutionEventError:
+//	        log.Println(event.Error, event.Err)
+//	    }
+//	}
+func (s *Sandbox) RunCodeStream(ctx context.Context, code string, opts ...RunOption) (<-chan ExecutionEvent, error) {
+	s.mu.RLock()
+	if s.closed {
+		s.mu.RUnlock()
+		return nil, ErrSandboxClosed
+	}
+	if s.readOnly {
+		s.mu.RUnlock()
+		return nil, ErrReadOnly
+	}
+	s.mu.RUnlock()
+
+	cfg := defaultRunConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if cfg.language != "" && cfg.context != nil {
+		return nil, fmt.Errorf("%w: cannot provide both language and context", ErrInvalidArgument)
+	}
+
+	events := make(chan ExecutionEvent)
+	send := func(event ExecutionEvent) {
+		select {
+		case events <- event:
+		case <-ctx.Done():
+		}
+	}
+
+	runOpts := make([]RunOption, 0, len(opts)+1)
+	runOpts = append(runOpts, opts...)
+	runOpts = append(runOpts, func(c *runConfig) {
+		c.onStdout = chainCallback(cfg.onStdout, func(m OutputMessage) {
+			send(ExecutionEvent{Type: ExecutionEventStdout, Output: m})
+		})
+		c.onStderr = chainCallback(cfg.onStderr, func(m OutputMessage) {
+			send(ExecutionEvent{Type: ExecutionEventStderr, Output: m})
+		})
+		c.onResult = chainCallback(cfg.onResult, func(r *Result) {
+			send(ExecutionEvent{Type: ExecutionEventResult, Result: r})
+		})
+		c.onError = chainCallback(cfg.onError, func(e *ExecutionError) {
+			send(ExecutionEvent{Type: ExecutionEventError, Error: e})
+		})
+		c.onExecutionCount = func(n int) {
+			send(ExecutionEvent{Type: ExecutionEventCount, ExecutionCount: n})
+		}
+	})
+
+	go func() {
+		defer close(events)
+		if _, err := s.RunCode(ctx, code, runOpts...); err != nil {
+			send(ExecutionEvent{Type: ExecutionEventError, Err: err})
+		}
+	}()
+
+	return events, nil
+}
+
+// chainCallback returns a callback that calls first, if set, and then next.
+func chainCallback[T any](first, next func(T)) func(T) {
+	if first == nil {
+		return next
+	}
+	return func(v T) {
+		first(v)
+		next(v)
+	}
+}
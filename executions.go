@@ -0,0 +1,46 @@
+package e2b
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+)
+
+// trackedExecution is an in-flight execution registered by RunCode.
+type trackedExecution struct {
+	cancel context.CancelCauseFunc
+}
+
+// trackExecution registers an in-flight execution and returns a context that
+// is cancelled by ExecutionHandle.Cancel. The returned function unregisters
+// it.
+func (s *Sandbox) trackExecution(ctx context.Context, cellID string) (context.Context, func(), error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	s.execMu.Lock()
+	defer s.execMu.Unlock()
+
+	if _, exists := s.executions[cellID]; exists {
+		cancel(nil)
+		return nil, nil, fmt.Errorf("%w: execution %s is already running", ErrInvalidArgument, cellID)
+	}
+	if s.executions == nil {
+		s.executions = make(map[string]*trackedExecution)
+	}
+	s.executions[cellID] = &trackedExecution{cancel: cancel}
+
+	return ctx, func() {
+		s.execMu.Lock()
+		delete(s.executions, cellID)
+		s.execMu.Unlock()
+		cancel(nil)
+	}, nil
+}
+
+// newCellID returns a random execution cell ID.
+func newCellID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
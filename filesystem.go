@@ -3,12 +3,19 @@ package e2b
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
 	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
 	"time"
 
 	"connectrpc.com/connect"
@@ -16,6 +23,9 @@ import (
 	"github.com/xerpa-ai/e2b-go/internal/proto/filesystem/filesystempbconnect"
 )
 
+// readManyWorkers is the maximum number of concurrent reads made by ReadMany.
+const readManyWorkers = 8
+
 // HTTP header constants
 const (
 	headerAccessToken  = "X-Access-Token"
@@ -37,9 +47,10 @@ type Filesystem struct {
 func newFilesystem(sandbox *Sandbox) *Filesystem {
 	base := newRPCClient(sandbox)
 
+	client, baseURL := base.connectClient()
 	filesystemClient := filesystempbconnect.NewFilesystemClient(
-		base.httpClient,
-		base.envdBaseURL,
+		client,
+		baseURL,
 		connect.WithGRPCWeb(),
 	)
 
@@ -52,7 +63,7 @@ func newFilesystem(sandbox *Sandbox) *Filesystem {
 
 // buildFileURL builds a URL for file operations.
 func (fs *Filesystem) buildFileURL(path, user string) (string, error) {
-	u, err := url.Parse(fs.envdBaseURL + filesAPIPath)
+	u, err := url.Parse(fs.envdBaseURL() + filesAPIPath)
 	if err != nil {
 		return "", err
 	}
@@ -61,7 +72,7 @@ func (fs *Filesystem) buildFileURL(path, user string) (string, error) {
 	if path != "" {
 		q.Set("path", path)
 	}
-	if user != "" {
+	if user = fs.userOrDefault(user); user != "" {
 		q.Set("username", user)
 	}
 	u.RawQuery = q.Encode()
@@ -154,7 +165,7 @@ func (fs *Filesystem) ReadStream(ctx context.Context, path string, opts ...ReadO
 		body, _ := io.ReadAll(resp.Body)
 		resp.Body.Close()
 		cancel()
-		return nil, fs.handleHTTPError(resp.StatusCode, body)
+		return nil, fs.handleHTTPError(resp.StatusCode, resp.Header, body)
 	}
 
 	// Return a wrapper that cancels context when closed
@@ -164,6 +175,112 @@ func (fs *Filesystem) ReadStream(ctx context.Context, path string, opts ...ReadO
 	}, nil
 }
 
+// Download streams the content of a file to dst without holding it in memory.
+// It returns the number of bytes written.
+//
+// The request timeout applies to the whole transfer; use
+// WithReadRequestTimeout to allow more time for large files.
+//
+// Example:
+//
+//	n, err := sandbox.Files.Download(ctx, "/home/user/output.csv", os.Stdout)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (fs *Filesystem) Download(ctx context.Context, remotePath string, dst io.Writer, opts ...ReadOption) (int64, error) {
+	if dst == nil {
+		return 0, fmt.Errorf("%w: destination writer is required", ErrInvalidArgument)
+	}
+
+	stream, err := fs.ReadStream(ctx, remotePath, opts...)
+	if err != nil {
+		return 0, err
+	}
+	defer stream.Close()
+
+	n, err := io.Copy(dst, stream)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return n, NewRequestTimeoutError()
+		}
+		return n, fmt.Errorf("failed to download %s: %w", remotePath, err)
+	}
+
+	return n, nil
+}
+
+// DownloadToFile downloads a file to localPath.
+//
+// The content is streamed to a temporary file next to localPath, which is
+// renamed to localPath once the download completes. On error the partial
+// file is removed and an existing file at localPath is left untouched.
+// The file is created with 0644 permissions.
+//
+// Example:
+//
+//	err := sandbox.Files.DownloadToFile(ctx, "/home/user/model.bin", "./model.bin")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (fs *Filesystem) DownloadToFile(ctx context.Context, remotePath, localPath string, opts ...ReadOption) error {
+	if localPath == "" {
+		return fmt.Errorf("%w: local path is required", ErrInvalidArgument)
+	}
+
+	_, err := fs.downloadToFile(ctx, remotePath, localPath, nil, opts...)
+	return err
+}
+
+// downloadToFile downloads a file to localPath through a temporary file and
+// returns the number of bytes written. If onWrite is set, it is called with
+// the running total after every write. Errors creating or writing the local
+// file wrap an *os.PathError.
+func (fs *Filesystem) downloadToFile(ctx context.Context, remotePath, localPath string, onWrite func(int64), opts ...ReadOption) (n int64, err error) {
+	tmp, err := os.CreateTemp(filepath.Dir(localPath), "."+filepath.Base(localPath)+".*.part")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create file: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	var dst io.Writer = tmp
+	if onWrite != nil {
+		dst = &progressWriter{w: tmp, onWrite: onWrite}
+	}
+	if n, err = fs.Download(ctx, remotePath, dst, opts...); err != nil {
+		return 0, err
+	}
+	if err = tmp.Chmod(0o644); err != nil {
+		return 0, fmt.Errorf("failed to set file permissions: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return 0, fmt.Errorf("failed to close file: %w", err)
+	}
+	if err = os.Rename(tmp.Name(), localPath); err != nil {
+		return 0, fmt.Errorf("failed to rename file: %w", err)
+	}
+
+	return n, nil
+}
+
+// progressWriter reports the running number of bytes written through it.
+type progressWriter struct {
+	w       io.Writer
+	written int64
+	onWrite func(int64)
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.written += int64(n)
+	p.onWrite(p.written)
+	return n, err
+}
+
 // streamReadCloser wraps an io.ReadCloser and cancels the context when closed.
 type streamReadCloser struct {
 	body   io.ReadCloser
@@ -223,19 +340,196 @@ func (fs *Filesystem) ReadBytes(ctx context.Context, path string, opts ...ReadOp
 	// Check status
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fs.handleHTTPError(resp.StatusCode, body)
+		return nil, fs.handleHTTPError(resp.StatusCode, resp.Header, body)
 	}
 
 	// Read response
 	return io.ReadAll(resp.Body)
 }
 
+// ReadOffset reads up to length bytes of a file starting at offset, without
+// downloading the rest of it. A length of 0 reads until the end of the
+// file, and a negative offset counts from the end of the file, so an offset
+// of -4096 reads the last 4 KiB like tail -c. Reading at or past the end of
+// the file returns no data. A negative length returns an error wrapping
+// ErrInvalidArgument.
+//
+// The range is requested with an HTTP Range header; if envd returns the
+// whole file instead, the range is cut out of it while reading.
+//
+// Example:
+//
+//	tail, err := sandbox.Files.ReadOffset(ctx, "/var/log/app.log", -64*1024, 0)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Print(string(tail))
+func (fs *Filesystem) ReadOffset(ctx context.Context, path string, offset, length int64, opts ...ReadOption) ([]byte, error) {
+	if length < 0 {
+		return nil, fmt.Errorf("%w: length must not be negative", ErrInvalidArgument)
+	}
+
+	cfg := defaultReadConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	ctx, cancel := fs.applyTimeout(ctx, cfg.requestTimeout)
+	defer cancel()
+
+	reqURL, err := fs.buildFileURL(path, cfg.user)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	fs.setHTTPHeaders(req)
+	req.Header.Set("Range", byteRange(offset, length))
+
+	resp, err := fs.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, NewRequestTimeoutError()
+		}
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var data []byte
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		data, err = io.ReadAll(resp.Body)
+	case http.StatusOK:
+		data, err = readRange(resp.Body, offset, length)
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The offset is at or past the end of the file
+		return []byte{}, nil
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fs.handleHTTPError(resp.StatusCode, resp.Header, body)
+	}
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, NewRequestTimeoutError()
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	// A suffix range cannot also limit the length
+	if offset < 0 && length > 0 && int64(len(data)) > length {
+		data = data[:length]
+	}
+	return data, nil
+}
+
+// byteRange returns the Range header value for reading length bytes at
+// offset, as described for ReadOffset.
+func byteRange(offset, length int64) string {
+	switch {
+	case offset < 0:
+		return fmt.Sprintf("bytes=%d", offset)
+	case length == 0:
+		return fmt.Sprintf("bytes=%d-", offset)
+	default:
+		return fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)
+	}
+}
+
+// readRange reads the range described by offset and length from r, which
+// holds the whole file.
+func readRange(r io.Reader, offset, length int64) ([]byte, error) {
+	if offset < 0 {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		return data[max(0, int64(len(data))+offset):], nil
+	}
+
+	if _, err := io.CopyN(io.Discard, r, offset); err != nil {
+		if errors.Is(err, io.EOF) {
+			return []byte{}, nil
+		}
+		return nil, err
+	}
+	if length > 0 {
+		r = io.LimitReader(r, length)
+	}
+	return io.ReadAll(r)
+}
+
+// ReadMany reads several files concurrently.
+//
+// Files are fetched by a bounded pool of workers. Contents and errors are
+// returned keyed by path; every path appears in exactly one of the maps.
+// Missing files report an error matching ErrNotFound.
+//
+// Example:
+//
+//	contents, errs := sandbox.Files.ReadMany(ctx, []string{
+//	    "/home/user/project/package.json",
+//	    "/home/user/project/package-lock.json",
+//	})
+//	for path, err := range errs {
+//	    if errors.Is(err, e2b.ErrNotFound) {
+//	        fmt.Println("missing:", path)
+//	    }
+//	}
+func (fs *Filesystem) ReadMany(ctx context.Context, paths []string, opts ...ReadOption) (map[string][]byte, map[string]error) {
+	contents := make(map[string][]byte, len(paths))
+	errs := make(map[string]error)
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+
+	jobs := make(chan string)
+	for range min(readManyWorkers, len(paths)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range jobs {
+				data, err := fs.ReadBytes(ctx, path, opts...)
+
+				mu.Lock()
+				if err != nil {
+					errs[path] = err
+				} else {
+					contents[path] = data
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	seen := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		jobs <- path
+	}
+	close(jobs)
+	wg.Wait()
+
+	return contents, errs
+}
+
 // Write writes content to a file.
 //
 // Writing to a file that doesn't exist creates the file.
 // Writing to a file that already exists overwrites the file.
 // Writing to a file at a path that doesn't exist creates the necessary directories.
 //
+// String and []byte data is sent as a single buffered request. io.Reader
+// data, or any write with WithProgress, is streamed so it is never held in
+// memory in full. WithMaxWriteSize rejects content over a size limit.
+//
 // Example:
 //
 //	info, err := sandbox.Files.Write(ctx, "/home/user/file.txt", "Hello, World!")
@@ -258,7 +552,7 @@ func (fs *Filesystem) Write(ctx context.Context, path string, data any, opts ...
 	}
 
 	// Create multipart form
-	body, contentType, err := fs.createMultipartBody([]fileData{{path: path, reader: dataReader}})
+	body, contentType, err := createMultipartBody([]fileData{{path: path, reader: dataReader}}, cfg, false)
 	if err != nil {
 		return nil, err
 	}
@@ -282,6 +576,59 @@ func (fs *Filesystem) Write(ctx context.Context, path string, data any, opts ...
 	return &infos[0], nil
 }
 
+// Upload writes the content read from r to a file without buffering it in memory.
+//
+// The multipart request body is generated while the request is being sent,
+// so arbitrarily large files can be uploaded with bounded memory. Like Write,
+// it creates the file and any missing parent directories, and overwrites an
+// existing file.
+//
+// Example:
+//
+//	f, err := os.Open("model.bin")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer f.Close()
+//	info, err := sandbox.Files.Upload(ctx, "/home/user/model.bin", f)
+func (fs *Filesystem) Upload(ctx context.Context, path string, r io.Reader, opts ...WriteOption) (*WriteInfo, error) {
+	if r == nil {
+		return nil, fmt.Errorf("%w: reader is required", ErrInvalidArgument)
+	}
+
+	cfg := defaultWriteConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	ctx, cancel := fs.applyTimeout(ctx, cfg.requestTimeout)
+	defer cancel()
+
+	// Build URL
+	reqURL, err := fs.buildFileURL(path, cfg.user)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	// Create multipart form, always streamed
+	body, contentType, err := createMultipartBody([]fileData{{path: path, reader: r}}, cfg, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// Execute request
+	infos, err := fs.doWriteRequest(ctx, reqURL, body, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(infos) == 0 {
+		return nil, fmt.Errorf("no file information returned")
+	}
+
+	return &infos[0], nil
+}
+
 // WriteFiles writes multiple files to the sandbox.
 //
 // Example:
@@ -314,7 +661,7 @@ func (fs *Filesystem) WriteFiles(ctx context.Context, files []WriteEntry, opts .
 	}
 
 	// Create multipart form
-	body, contentType, err := fs.createMultipartBody(fileDataList)
+	body, contentType, err := createMultipartBody(fileDataList, cfg, false)
 	if err != nil {
 		return nil, err
 	}
@@ -339,6 +686,97 @@ func (fs *Filesystem) WriteFiles(ctx context.Context, files []WriteEntry, opts .
 	return result, nil
 }
 
+// WriteIfChanged writes content to a file unless the file already has
+// identical content. It reports whether the file was written; the returned
+// WriteInfo carries the SHA-256 hash of the content either way. Like Write,
+// data can be a string, []byte or io.Reader; readers are read into memory
+// to be hashed.
+//
+// The existing content is compared by its checksum, computed in the sandbox
+// with GetChecksum. If command execution is not permitted, files of the same
+// size are downloaded and compared instead.
+//
+// Example:
+//
+//	info, written, err := sandbox.Files.WriteIfChanged(ctx, "/home/user/app/config.yaml", config)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if !written {
+//	    fmt.Println("unchanged:", info.Hash)
+//	}
+func (fs *Filesystem) WriteIfChanged(ctx context.Context, filePath string, data any, opts ...WriteOption) (*WriteInfo, bool, error) {
+	cfg := defaultWriteConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	ctx, cancel := fs.applyTimeout(ctx, cfg.requestTimeout)
+	defer cancel()
+
+	reader, err := toReader(data)
+	if err != nil {
+		return nil, false, err
+	}
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read data: %w", err)
+	}
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+
+	unchanged, err := fs.hasContent(ctx, filePath, content, hash, &cfg.filesystemConfig)
+	if err != nil {
+		return nil, false, err
+	}
+	if unchanged {
+		return &WriteInfo{Name: path.Base(filePath), Type: FileTypeFile, Path: filePath, Hash: hash}, false, nil
+	}
+
+	info, err := fs.Write(ctx, filePath, content, opts...)
+	if err != nil {
+		return nil, false, err
+	}
+	if info.Hash == "" {
+		info.Hash = hash
+	}
+
+	return info, true, nil
+}
+
+// hasContent reports whether the file at filePath exists with the given content
+// and SHA-256 hash. Missing files and non-regular files never match.
+func (fs *Filesystem) hasContent(ctx context.Context, filePath string, content []byte, hash string, cfg *filesystemConfig) (bool, error) {
+	remote, err := fs.GetChecksum(ctx, filePath, WithChecksumUser(cfg.user), WithChecksumRequestTimeout(cfg.requestTimeout))
+	switch {
+	case err == nil:
+		return remote == hash, nil
+	case errors.Is(err, ErrNotFound), errors.Is(err, ErrInvalidArgument):
+		return false, nil
+	case !isCommandsUnavailable(err):
+		return false, err
+	}
+
+	// Without command execution, compare sizes and only download files
+	// that could match
+	info, err := fs.GetInfo(ctx, filePath, WithUser(cfg.user), WithFilesystemRequestTimeout(cfg.requestTimeout))
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if info.Type != FileTypeFile || info.Size != int64(len(content)) {
+		return false, nil
+	}
+
+	existing, err := fs.ReadBytes(ctx, filePath, WithReadUser(cfg.user), WithReadRequestTimeout(cfg.requestTimeout))
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(existing, content), nil
+}
+
 // fileData holds file path and reader for multipart upload.
 type fileData struct {
 	path   string
@@ -360,29 +798,95 @@ func toReader(data any) (io.Reader, error) {
 }
 
 // createMultipartBody creates a multipart form body for file upload.
-func (fs *Filesystem) createMultipartBody(files []fileData) (*bytes.Buffer, string, error) {
-	var buf bytes.Buffer
-	writer := multipart.NewWriter(&buf)
+//
+// In-memory data (string or []byte) is encoded up front so the request has
+// a known length. Other readers, and any upload reporting progress or if
+// stream is set, are encoded through a pipe while the request is sent, so
+// the content is never fully buffered. A streamed body is an io.ReadCloser
+// that must be closed to release the encoding goroutine.
+func createMultipartBody(files []fileData, cfg *writeConfig, stream bool) (io.Reader, string, error) {
+	var size int64
+	if cfg.onProgress != nil {
+		stream = true
+	}
+	for _, f := range files {
+		r, ok := f.reader.(*bytes.Reader)
+		if !ok {
+			stream = true
+			break
+		}
+		size += int64(r.Len())
+	}
+
+	if !stream {
+		if cfg.maxSize > 0 && size > cfg.maxSize {
+			return nil, "", writeSizeLimitError(cfg.maxSize)
+		}
+
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
+		if err := writeMultipartFiles(writer, files, cfg); err != nil {
+			return nil, "", err
+		}
+		return &buf, writer.FormDataContentType(), nil
+	}
+
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+	go func() {
+		pw.CloseWithError(writeMultipartFiles(writer, files, cfg))
+	}()
+
+	return pr, writer.FormDataContentType(), nil
+}
+
+// writeMultipartFiles encodes files as a multipart form, reporting progress
+// and enforcing the size limit of cfg.
+func writeMultipartFiles(writer *multipart.Writer, files []fileData, cfg *writeConfig) error {
+	progress := &progressWriter{onWrite: func(int64) {}}
+	if cfg.onProgress != nil {
+		progress.onWrite = cfg.onProgress
+	}
 
 	for _, f := range files {
 		part, err := writer.CreateFormFile("file", f.path)
 		if err != nil {
-			return nil, "", fmt.Errorf("failed to create form file: %w", err)
+			return fmt.Errorf("failed to create form file: %w", err)
+		}
+		progress.w = part
+
+		// Hide io.WriterTo so data is copied, and progress reported, in chunks
+		src := struct{ io.Reader }{f.reader}
+		if cfg.maxSize > 0 {
+			src.Reader = io.LimitReader(f.reader, cfg.maxSize-progress.written+1)
 		}
-		if _, err := io.Copy(part, f.reader); err != nil {
-			return nil, "", fmt.Errorf("failed to write data: %w", err)
+		if _, err := io.Copy(progress, src); err != nil {
+			return fmt.Errorf("failed to write data: %w", err)
+		}
+		if cfg.maxSize > 0 && progress.written > cfg.maxSize {
+			return writeSizeLimitError(cfg.maxSize)
 		}
 	}
 
 	if err := writer.Close(); err != nil {
-		return nil, "", fmt.Errorf("failed to close multipart writer: %w", err)
+		return fmt.Errorf("failed to close multipart writer: %w", err)
 	}
+	return nil
+}
 
-	return &buf, writer.FormDataContentType(), nil
+// writeSizeLimitError returns the error for content exceeding the write
+// size limit.
+func writeSizeLimitError(limit int64) error {
+	return fmt.Errorf("%w: content exceeds the write size limit of %d bytes", ErrInvalidArgument, limit)
 }
 
 // doWriteRequest executes a file write request.
-func (fs *Filesystem) doWriteRequest(ctx context.Context, reqURL string, body *bytes.Buffer, contentType string) ([]WriteInfo, error) {
+func (fs *Filesystem) doWriteRequest(ctx context.Context, reqURL string, body io.Reader, contentType string) ([]WriteInfo, error) {
+	// Closing a streamed body stops its encoding goroutine on early failures
+	if c, ok := body.(io.Closer); ok {
+		defer c.Close()
+	}
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, reqURL, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -402,7 +906,7 @@ func (fs *Filesystem) doWriteRequest(ctx context.Context, reqURL string, body *b
 
 	if resp.StatusCode != http.StatusOK {
 		respBody, _ := io.ReadAll(resp.Body)
-		return nil, fs.handleHTTPError(resp.StatusCode, respBody)
+		return nil, fs.handleHTTPError(resp.StatusCode, resp.Header, respBody)
 	}
 
 	var infos []WriteInfo
@@ -414,7 +918,7 @@ func (fs *Filesystem) doWriteRequest(ctx context.Context, reqURL string, body *b
 }
 
 // handleHTTPError converts HTTP errors to appropriate error types.
-func (fs *Filesystem) handleHTTPError(statusCode int, body []byte) error {
+func (fs *Filesystem) handleHTTPError(statusCode int, header http.Header, body []byte) error {
 	var errResp struct {
 		Message string `json:"message"`
 	}
@@ -433,7 +937,7 @@ func (fs *Filesystem) handleHTTPError(statusCode int, body []byte) error {
 	case http.StatusNotFound:
 		return fmt.Errorf("%w: %s", ErrNotFound, message)
 	case http.StatusTooManyRequests:
-		return fmt.Errorf("rate limited: %s", message)
+		return newRateLimitError(header, message)
 	case http.StatusBadGateway:
 		return NewRequestTimeoutError()
 	case http.StatusInsufficientStorage:
@@ -558,6 +1062,16 @@ func (fs *Filesystem) Rename(ctx context.Context, oldPath, newPath string, opts
 	ctx, cancel := fs.applyTimeout(ctx, cfg.requestTimeout)
 	defer cancel()
 
+	info, err := fs.move(ctx, oldPath, newPath, cfg)
+	if err != nil {
+		return nil, fs.wrapRPCError(ctx, err)
+	}
+	return info, nil
+}
+
+// move calls the Move RPC and returns its error unwrapped, so callers can
+// inspect the Connect error code.
+func (fs *Filesystem) move(ctx context.Context, oldPath, newPath string, cfg *filesystemConfig) (*EntryInfo, error) {
 	req := connect.NewRequest(&filesystempb.MoveRequest{
 		Source:      oldPath,
 		Destination: newPath,
@@ -566,7 +1080,7 @@ func (fs *Filesystem) Rename(ctx context.Context, oldPath, newPath string, opts
 
 	resp, err := fs.filesystemClient.Move(ctx, req)
 	if err != nil {
-		return nil, fs.wrapRPCError(ctx, err)
+		return nil, err
 	}
 
 	return entryInfoFromProto(resp.Msg.Entry), nil
@@ -637,6 +1151,14 @@ func (fs *Filesystem) wrapRPCError(ctx context.Context, err error) error {
 	if ctx.Err() == context.DeadlineExceeded {
 		return NewRequestTimeoutError()
 	}
+	if ctx.Err() == context.Canceled {
+		return ctx.Err()
+	}
+
+	// Requests through a read-only handle are rejected by the transport
+	if errors.Is(err, ErrReadOnly) {
+		return ErrReadOnly
+	}
 
 	if connectErr, ok := err.(*connect.Error); ok {
 		switch connectErr.Code() {
@@ -647,7 +1169,7 @@ func (fs *Filesystem) wrapRPCError(ctx context.Context, err error) error {
 		case connect.CodeDeadlineExceeded:
 			return NewRequestTimeoutError()
 		case connect.CodeUnavailable:
-			return fmt.Errorf("sandbox unavailable: %s", connectErr.Message())
+			return fmt.Errorf("%w: %s", ErrSandboxUnavailable, connectErr.Message())
 		default:
 			return fmt.Errorf("rpc error (%s): %s", connectErr.Code(), connectErr.Message())
 		}
@@ -0,0 +1,271 @@
+package e2b
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// archiveFormat identifies how an archive is created and unpacked.
+type archiveFormat struct {
+	// tarFlag is the tar compression flag, empty for an uncompressed tar
+	tarFlag string
+	zip     bool
+}
+
+// archiveFormats maps the supported archive suffixes to their formats.
+// Longer suffixes are listed first so ".tar.gz" is not mistaken for ".gz".
+var archiveFormats = []struct {
+	suffix string
+	format archiveFormat
+}{
+	{".tar.gz", archiveFormat{tarFlag: "z"}},
+	{".tar.bz2", archiveFormat{tarFlag: "j"}},
+	{".tar.xz", archiveFormat{tarFlag: "J"}},
+	{".tgz", archiveFormat{tarFlag: "z"}},
+	{".tar", archiveFormat{}},
+	{".zip", archiveFormat{zip: true}},
+}
+
+// detectArchiveFormat returns the format of the archive at p based on its
+// file extension.
+func detectArchiveFormat(p string) (archiveFormat, bool) {
+	name := strings.ToLower(p)
+	for _, f := range archiveFormats {
+		if strings.HasSuffix(name, f.suffix) {
+			return f.format, true
+		}
+	}
+	return archiveFormat{}, false
+}
+
+// archiveFormatByName returns the archive format named name, such as
+// "tar.gz" or "zip".
+func archiveFormatByName(name string) (archiveFormat, bool) {
+	suffix := "." + strings.ToLower(name)
+	for _, f := range archiveFormats {
+		if f.suffix == suffix {
+			return f.format, true
+		}
+	}
+	return archiveFormat{}, false
+}
+
+// Extract unpacks the archive at archivePath into destDir, both paths in the
+// sandbox. destDir and any missing parents are created.
+//
+// The format is detected from the file extension: .tar, .tar.gz, .tgz,
+// .tar.bz2, .tar.xz and .zip are supported; other extensions return an error
+// wrapping ErrInvalidArgument. Existing files in destDir are overwritten. Use
+// WithExtractFilter to extract only matching entries and
+// WithExtractStripComponents to drop leading path components. If the archive
+// does not exist, an error wrapping ErrNotFound is returned.
+//
+// envd has no archive RPC, so the archive is unpacked with tar or unzip,
+// which must be installed in the sandbox.
+//
+// Example:
+//
+//	if _, err := sandbox.Files.Write(ctx, "/tmp/project.tar.gz", archive); err != nil {
+//	    log.Fatal(err)
+//	}
+//	err := sandbox.Files.Extract(ctx, "/tmp/project.tar.gz", "/home/user/project",
+//	    e2b.WithExtractStripComponents(1),
+//	)
+func (fs *Filesystem) Extract(ctx context.Context, archivePath, destDir string, opts ...ExtractOption) error {
+	if archivePath == "" || destDir == "" {
+		return fmt.Errorf("%w: archive path and destination are required", ErrInvalidArgument)
+	}
+
+	cfg := defaultExtractConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if cfg.stripComponents < 0 {
+		return fmt.Errorf("%w: strip components must not be negative", ErrInvalidArgument)
+	}
+	for _, pattern := range cfg.filters {
+		// unzip would parse such a pattern as an option
+		if strings.HasPrefix(pattern, "-") {
+			return fmt.Errorf("%w: filter pattern must not start with '-': %s", ErrInvalidArgument, pattern)
+		}
+	}
+	format, ok := detectArchiveFormat(archivePath)
+	if !ok {
+		return fmt.Errorf("%w: unsupported archive format: %s", ErrInvalidArgument, archivePath)
+	}
+
+	script := fmt.Sprintf("%s && mkdir -p -- %s && %s",
+		shellRequireExists(archivePath), shellQuote(destDir), extractCommand(archivePath, destDir, format, cfg))
+	_, err := fs.runShell(ctx, script, &cfg.filesystemConfig)
+	return err
+}
+
+// extractCommand returns the shell command unpacking archivePath into the
+// existing directory destDir.
+func extractCommand(archivePath, destDir string, format archiveFormat, cfg *extractConfig) string {
+	var filters strings.Builder
+	for _, pattern := range cfg.filters {
+		filters.WriteString(" " + shellQuote(pattern))
+	}
+
+	if !format.zip {
+		cmd := fmt.Sprintf("tar -x%sf %s -C %s", format.tarFlag, shellQuote(archivePath), shellQuote(destDir))
+		if cfg.stripComponents > 0 {
+			cmd += fmt.Sprintf(" --strip-components=%d", cfg.stripComponents)
+		}
+		if len(cfg.filters) > 0 {
+			cmd += " --wildcards --" + filters.String()
+		}
+		return cmd
+	}
+
+	if cfg.stripComponents == 0 {
+		return fmt.Sprintf("unzip -q -o -d %s %s%s", shellQuote(destDir), shellQuote(archivePath), filters.String())
+	}
+
+	// unzip cannot strip components, so unpack into a staging directory and
+	// copy the contents of the directories n levels down into place
+	return fmt.Sprintf(`tmp=$(mktemp -d) && trap 'rm -rf "$tmp"' EXIT && unzip -q -o -d "$tmp" %s%s && for d in "$tmp"%s/; do [ -d "$d" ] || continue; cp -a "$d". %s/ || exit; done`,
+		shellQuote(archivePath), filters.String(), strings.Repeat("/*", cfg.stripComponents), shellQuote(destDir))
+}
+
+// Archive creates an archive of the file or directory at sourcePath and
+// writes it to archivePath, both paths in the sandbox. Missing parent
+// directories of archivePath are created and an existing archive is
+// replaced.
+//
+// Entries are stored relative to the parent of sourcePath, so archiving
+// "/home/user/project" stores "project/...". The format is set with
+// WithArchiveFormat; otherwise it is taken from the extension of
+// archivePath, falling back to "tar.gz". An unsupported format returns an
+// error wrapping ErrInvalidArgument, and a missing sourcePath an error
+// wrapping ErrNotFound. The archive can then be read with ReadBytes or
+// downloaded through Sandbox.DownloadURL.
+//
+// envd has no archive RPC, so the archive is created with tar or zip, which
+// must be installed in the sandbox.
+//
+// Example:
+//
+//	err := sandbox.Files.Archive(ctx, "/home/user/project", "/tmp/project.tar.gz",
+//	    e2b.WithArchiveExclude("node_modules", "*.log"),
+//	)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	url, _ := sandbox.DownloadURL("/tmp/project.tar.gz")
+func (fs *Filesystem) Archive(ctx context.Context, sourcePath, archivePath string, opts ...ArchiveOption) error {
+	if sourcePath == "" || archivePath == "" {
+		return fmt.Errorf("%w: source path and archive path are required", ErrInvalidArgument)
+	}
+
+	cfg := defaultArchiveConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	format, err := cfg.resolveFormat(archivePath)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a failed run does not leave a
+	// truncated archive behind
+	tmpPath := shellQuote(tempSiblingPath(archivePath, "archive"))
+	script := fmt.Sprintf("%s && mkdir -p -- %s && { ( %s ) > %s && mv -f -- %s %s; } || { status=$?; rm -f -- %s; exit $status; }",
+		shellRequireExists(sourcePath), shellQuote(path.Dir(archivePath)), archiveCommand(sourcePath, format, cfg.excludes),
+		tmpPath, tmpPath, shellQuote(archivePath), tmpPath)
+	_, err = fs.runShell(ctx, script, &cfg.filesystemConfig)
+	return err
+}
+
+// ArchiveAndDownload creates an archive of the file or directory at
+// sourcePath and streams it to w as it is produced, without writing it to
+// the sandbox's disk.
+//
+// The archive layout and options are the same as for Archive; the format
+// is "tar.gz" unless set with WithArchiveFormat. If creating the archive
+// fails midway, the data already written to w is incomplete and an error is
+// returned.
+//
+// Example:
+//
+//	f, err := os.Create("project.tar.gz")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer f.Close()
+//	if err := sandbox.Files.ArchiveAndDownload(ctx, "/home/user/project", f); err != nil {
+//	    log.Fatal(err)
+//	}
+func (fs *Filesystem) ArchiveAndDownload(ctx context.Context, sourcePath string, w io.Writer, opts ...ArchiveOption) error {
+	if sourcePath == "" {
+		return fmt.Errorf("%w: source path is required", ErrInvalidArgument)
+	}
+	if w == nil {
+		return fmt.Errorf("%w: writer is required", ErrInvalidArgument)
+	}
+
+	cfg := defaultArchiveConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	format, err := cfg.resolveFormat("")
+	if err != nil {
+		return err
+	}
+
+	script := fmt.Sprintf("%s && %s", shellRequireExists(sourcePath), archiveCommand(sourcePath, format, cfg.excludes))
+	return fs.runShellToWriter(ctx, script, w, &cfg.filesystemConfig)
+}
+
+// resolveFormat returns the configured archive format, or the one matching
+// the extension of archivePath, defaulting to tar.gz.
+func (c *archiveConfig) resolveFormat(archivePath string) (archiveFormat, error) {
+	if c.format != "" {
+		format, ok := archiveFormatByName(c.format)
+		if !ok {
+			return archiveFormat{}, fmt.Errorf("%w: unsupported archive format %q", ErrInvalidArgument, c.format)
+		}
+		return format, nil
+	}
+	if format, ok := detectArchiveFormat(archivePath); ok {
+		return format, nil
+	}
+	return archiveFormat{tarFlag: "z"}, nil
+}
+
+// archiveCommand returns the shell command writing an archive of
+// sourcePath to stdout.
+func archiveCommand(sourcePath string, format archiveFormat, excludes []string) string {
+	sourcePath = path.Clean(sourcePath)
+	parent, base := shellQuote(path.Dir(sourcePath)), shellQuote(path.Base(sourcePath))
+
+	var cmd strings.Builder
+	if !format.zip {
+		fmt.Fprintf(&cmd, "tar -c%sf - -C %s", format.tarFlag, parent)
+		for _, pattern := range excludes {
+			cmd.WriteString(" --exclude=" + shellQuote(pattern))
+		}
+		cmd.WriteString(" -- " + base)
+		return cmd.String()
+	}
+
+	// zip matches exclude patterns against whole paths, so match them
+	// against every path component the way tar does
+	fmt.Fprintf(&cmd, "cd %s && zip -q -r - %s", parent, base)
+	if len(excludes) > 0 {
+		cmd.WriteString(" -x")
+		for _, pattern := range excludes {
+			for _, p := range []string{pattern, pattern + "/*", "*/" + pattern, "*/" + pattern + "/*"} {
+				cmd.WriteString(" " + shellQuote(p))
+			}
+		}
+	}
+	return cmd.String()
+}
@@ -0,0 +1,101 @@
+package e2b
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+
+	"connectrpc.com/connect"
+)
+
+// WriteAtomic writes content to a file like Write, but readers never
+// observe a partially written file.
+//
+// The content is first written to a temporary file created with MakeTemp
+// in the same directory as path, which is then renamed over path. The
+// temporary file is given the permissions of the file it replaces, or
+// 0644 for a new file, and is removed if any step fails. Like Write, it
+// creates missing parent directories and takes the same options, so it
+// can be used as a drop-in replacement.
+//
+// If envd does not implement the Move RPC, the rename is done with mv in
+// the sandbox, which is atomic on the same filesystem.
+//
+// Example:
+//
+//	info, err := sandbox.Files.WriteAtomic(ctx, "/home/user/config.yaml", config)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (fs *Filesystem) WriteAtomic(ctx context.Context, filePath string, data any, opts ...WriteOption) (*WriteInfo, error) {
+	if filePath == "" {
+		return nil, fmt.Errorf("%w: path is required", ErrInvalidArgument)
+	}
+
+	cfg := defaultWriteConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	ctx, cancel := fs.applyTimeout(ctx, cfg.requestTimeout)
+	defer cancel()
+
+	dir := path.Dir(filePath)
+	tempOpts := []TempOption{
+		WithTempDir(dir),
+		WithTempPrefix("." + path.Base(filePath) + ".atomic-"),
+		WithTempUser(cfg.user),
+		WithTempRequestTimeout(cfg.requestTimeout),
+	}
+	tmpPath, err := fs.MakeTemp(ctx, tempOpts...)
+	if errors.Is(err, ErrNotFound) {
+		// Write creates missing parent directories, so WriteAtomic does too
+		if _, err := fs.MakeDir(ctx, dir, WithUser(cfg.user), WithFilesystemRequestTimeout(cfg.requestTimeout)); err != nil {
+			return nil, err
+		}
+		tmpPath, err = fs.MakeTemp(ctx, tempOpts...)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := fs.replaceWithTemp(ctx, tmpPath, filePath, data, cfg, opts)
+	if err != nil {
+		_ = fs.Remove(context.WithoutCancel(ctx), tmpPath, WithUser(cfg.user), WithFilesystemRequestTimeout(cfg.requestTimeout))
+		return nil, err
+	}
+	return info, nil
+}
+
+// replaceWithTemp writes data to the temporary file tmpPath and renames it
+// over filePath.
+func (fs *Filesystem) replaceWithTemp(ctx context.Context, tmpPath, filePath string, data any, cfg *writeConfig, opts []WriteOption) (*WriteInfo, error) {
+	written, err := fs.Write(ctx, tmpPath, data, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// mktemp creates the file readable by its owner only
+	q, qTmp := shellQuote(filePath), shellQuote(tmpPath)
+	script := fmt.Sprintf("chmod --reference=%s -- %s 2>/dev/null || chmod 644 -- %s", q, qTmp, qTmp)
+	if _, err := fs.runShell(ctx, script, &cfg.filesystemConfig); err != nil {
+		return nil, err
+	}
+
+	if _, err := fs.move(ctx, tmpPath, filePath, &cfg.filesystemConfig); err != nil {
+		if connect.CodeOf(err) != connect.CodeUnimplemented {
+			return nil, fs.wrapRPCError(ctx, err)
+		}
+		if _, err := fs.runShell(ctx, fmt.Sprintf("mv -f -- %s %s", qTmp, q), &cfg.filesystemConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	return &WriteInfo{
+		Name: path.Base(filePath),
+		Type: FileTypeFile,
+		Path: filePath,
+		Hash: written.Hash,
+	}, nil
+}
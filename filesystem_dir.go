@@ -0,0 +1,510 @@
+package e2b
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// uploadDirBatchFiles limits the number of files sent in a single upload
+// request by UploadDir.
+const uploadDirBatchFiles = 100
+
+// localFile is a local file to be uploaded to remotePath.
+type localFile struct {
+	localPath  string
+	remotePath string
+	size       int64
+}
+
+// UploadDir uploads a local directory tree to remotePath in the sandbox,
+// preserving the structure relative to localPath, and returns information
+// about every file written.
+//
+// Files are streamed from disk in batches of multipart requests, bounded by
+// WithUploadDirBatchSize. Empty directories are created as well. Symbolic
+// links are followed unless WithUploadDirResolveSymlinks(false) is set, in
+// which case they are skipped. Use WithUploadDirIgnorePatterns to leave out
+// files such as build output or version control metadata.
+//
+// A failed batch does not stop the upload. If any file could not be
+// uploaded, the files that were uploaded are returned together with an
+// *UploadDirError listing the failures.
+//
+// Example:
+//
+//	infos, err := sandbox.Files.UploadDir(ctx, "./project", "/home/user/project",
+//	    e2b.WithUploadDirIgnorePatterns(".git", "node_modules"),
+//	)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("uploaded %d files\n", len(infos))
+func (fs *Filesystem) UploadDir(ctx context.Context, localPath, remotePath string, opts ...UploadDirOption) ([]*WriteInfo, error) {
+	if localPath == "" || remotePath == "" {
+		return nil, fmt.Errorf("%w: local and remote paths are required", ErrInvalidArgument)
+	}
+
+	cfg := defaultUploadDirConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.maxBatchBytes <= 0 {
+		return nil, fmt.Errorf("%w: batch size must be positive", ErrInvalidArgument)
+	}
+	for _, pattern := range cfg.ignorePatterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("%w: invalid ignore pattern %q", ErrInvalidArgument, pattern)
+		}
+	}
+
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read local directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%w: %s is not a directory", ErrInvalidArgument, localPath)
+	}
+
+	var files []localFile
+	emptyDirs := make(map[string]bool)
+	visited := make(map[string]bool)
+	if err := collectLocalFiles(localPath, remotePath, "", cfg, visited, emptyDirs, &files); err != nil {
+		return nil, err
+	}
+
+	writeOpts := []WriteOption{WithWriteUser(cfg.user), WithWriteRequestTimeout(cfg.requestTimeout)}
+	failed := make(map[string]error)
+	results := fs.uploadLocalBatches(ctx, files, cfg.maxBatchBytes, writeOpts, failed)
+
+	dirOpts := []FilesystemOption{WithUser(cfg.user), WithFilesystemRequestTimeout(cfg.requestTimeout)}
+	for dir, empty := range emptyDirs {
+		if !empty {
+			continue
+		}
+		if _, err := fs.MakeDir(ctx, dir, dirOpts...); err != nil {
+			failed[dir] = err
+		}
+	}
+
+	if len(failed) > 0 {
+		return results, &UploadDirError{Failed: failed}
+	}
+	return results, nil
+}
+
+// WriteDir uploads a local directory tree to remoteDir in the sandbox. It is
+// equivalent to UploadDir and accepts the same options.
+//
+// Example:
+//
+//	infos, err := sandbox.Files.WriteDir(ctx, "./project", "/home/user/project",
+//	    e2b.WithUploadDirIgnorePatterns(".git"),
+//	)
+//	var uploadErr *e2b.UploadDirError
+//	if errors.As(err, &uploadErr) {
+//	    for path, err := range uploadErr.Failed {
+//	        log.Printf("%s: %v", path, err)
+//	    }
+//	}
+func (fs *Filesystem) WriteDir(ctx context.Context, localDir, remoteDir string, opts ...WriteDirOption) ([]*WriteInfo, error) {
+	return fs.UploadDir(ctx, localDir, remoteDir, opts...)
+}
+
+// uploadLocalBatches uploads local files in batches of at most
+// maxBatchBytes, streaming larger files in requests of their own. Files that
+// could not be uploaded are recorded in failed.
+func (fs *Filesystem) uploadLocalBatches(ctx context.Context, files []localFile, maxBatchBytes int64, opts []WriteOption, failed map[string]error) []*WriteInfo {
+	results := make([]*WriteInfo, 0, len(files))
+	for start := 0; start < len(files); {
+		end, size := start, int64(0)
+		for end < len(files) && end-start < uploadDirBatchFiles && (end == start || size+files[end].size <= maxBatchBytes) {
+			size += files[end].size
+			end++
+		}
+
+		var infos []*WriteInfo
+		var err error
+		if size > maxBatchBytes {
+			// Stream files over the limit instead of buffering the request
+			infos, err = fs.uploadLocalFile(ctx, files[start], opts)
+		} else {
+			infos, err = fs.uploadLocalFiles(ctx, files[start:end], opts, failed)
+		}
+		results = append(results, infos...)
+		if err != nil {
+			for _, f := range files[start:end] {
+				if _, ok := failed[f.remotePath]; !ok {
+					failed[f.remotePath] = err
+				}
+			}
+		}
+		start = end
+	}
+	return results
+}
+
+// uploadLocalFiles uploads a batch of local files in a single request.
+// Files that cannot be opened are recorded in failed and left out of the
+// request; the returned error applies to the files that were sent.
+func (fs *Filesystem) uploadLocalFiles(ctx context.Context, files []localFile, opts []WriteOption, failed map[string]error) ([]*WriteInfo, error) {
+	entries := make([]WriteEntry, 0, len(files))
+	for _, f := range files {
+		file, err := os.Open(f.localPath)
+		if err != nil {
+			failed[f.remotePath] = fmt.Errorf("failed to open %s: %w", f.localPath, err)
+			continue
+		}
+		entries = append(entries, WriteEntry{Path: f.remotePath, Data: file})
+	}
+	defer closeWriteEntries(entries)
+
+	return fs.WriteFiles(ctx, entries, opts...)
+}
+
+// uploadLocalFile streams a single local file in its own request.
+func (fs *Filesystem) uploadLocalFile(ctx context.Context, f localFile, opts []WriteOption) ([]*WriteInfo, error) {
+	file, err := os.Open(f.localPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", f.localPath, err)
+	}
+	defer file.Close()
+
+	info, err := fs.Upload(ctx, f.remotePath, file, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return []*WriteInfo{info}, nil
+}
+
+// closeWriteEntries closes the files opened for a batch upload.
+func closeWriteEntries(entries []WriteEntry) {
+	for _, entry := range entries {
+		if c, ok := entry.Data.(io.Closer); ok {
+			c.Close()
+		}
+	}
+}
+
+// collectLocalFiles walks dir and records the files to upload and the
+// directories that must be created explicitly because nothing is uploaded
+// into them. rel is the
+// slash-separated path of dir relative to the uploaded root. Symbolic links
+// to directories are walked if enabled, skipping directories already visited.
+func collectLocalFiles(dir, remoteDir, rel string, cfg *uploadDirConfig, visited, emptyDirs map[string]bool, files *[]localFile) error {
+	realDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", dir, err)
+	}
+	if visited[realDir] {
+		return nil
+	}
+	visited[realDir] = true
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read local directory: %w", err)
+	}
+
+	emptyDirs[remoteDir] = true
+	for _, entry := range entries {
+		entryRel := path.Join(rel, entry.Name())
+		if matchIgnorePatterns(cfg.ignorePatterns, entryRel) {
+			continue
+		}
+
+		localPath := filepath.Join(dir, entry.Name())
+		remotePath := path.Join(remoteDir, entry.Name())
+
+		info, err := entry.Info()
+		if err != nil {
+			return fmt.Errorf("failed to stat %s: %w", localPath, err)
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			if !cfg.resolveSymlinks {
+				continue
+			}
+			if info, err = os.Stat(localPath); err != nil {
+				return fmt.Errorf("failed to resolve %s: %w", localPath, err)
+			}
+		}
+
+		switch {
+		case info.IsDir():
+			if err := collectLocalFiles(localPath, remotePath, entryRel, cfg, visited, emptyDirs, files); err != nil {
+				return err
+			}
+			emptyDirs[remoteDir] = false
+		case info.Mode().IsRegular():
+			*files = append(*files, localFile{localPath: localPath, remotePath: remotePath, size: info.Size()})
+			emptyDirs[remoteDir] = false
+		}
+	}
+
+	return nil
+}
+
+// matchIgnorePatterns reports whether the slash-separated relative path rel
+// matches one of the ignore patterns.
+func matchIgnorePatterns(patterns []string, rel string) bool {
+	segments := strings.Split(rel, "/")
+	for _, pattern := range patterns {
+		pattern = strings.Trim(pattern, "/")
+		if strings.Contains(pattern, "/") {
+			if matchGlobSegments(strings.Split(pattern, "/"), segments) {
+				return true
+			}
+			continue
+		}
+		for _, segment := range segments {
+			if ok, _ := path.Match(pattern, segment); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DownloadDir downloads a sandbox directory tree to localPath, creating
+// local directories as needed, and reports how many files and bytes were
+// written.
+//
+// The remote tree is enumerated with a single recursive List, and each file
+// is streamed to disk so large files are never held in memory. Up to
+// DefaultDownloadConcurrency files are downloaded in parallel; see
+// WithDownloadDirConcurrency. The permission bits recorded in the sandbox
+// are applied to downloaded files and directories. Symbolic links in the
+// sandbox are not followed into directories. Use WithDownloadDirGlob to
+// download only some of the files and WithDownloadDirProgress to observe
+// transfers.
+//
+// If an entry cannot be created locally, DownloadDir fails with an error
+// naming the entry, unless WithDownloadDirSkipLocalErrors is set. Remote
+// paths that would resolve outside localPath are always skipped.
+//
+// If a download fails or ctx is cancelled, in-flight downloads are stopped
+// and a *DownloadDirError listing the completed files is returned along with
+// the partial result.
+//
+// Example:
+//
+//	result, err := sandbox.Files.DownloadDir(ctx, "/home/user/plots", "./plots",
+//	    e2b.WithDownloadDirGlob("**/*.png"),
+//	)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("downloaded %d files (%d bytes)\n", result.Files, result.Bytes)
+func (fs *Filesystem) DownloadDir(ctx context.Context, remotePath, localPath string, opts ...DownloadDirOption) (*DownloadDirResult, error) {
+	if remotePath == "" || localPath == "" {
+		return nil, fmt.Errorf("%w: remote and local paths are required", ErrInvalidArgument)
+	}
+
+	cfg := defaultDownloadDirConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.concurrency < 1 {
+		return nil, fmt.Errorf("%w: concurrency must be at least 1", ErrInvalidArgument)
+	}
+	if _, err := path.Match(cfg.glob, ""); err != nil {
+		return nil, fmt.Errorf("%w: invalid glob pattern %q", ErrInvalidArgument, cfg.glob)
+	}
+
+	// Entries are listed by their canonical paths, so resolve the root first
+	root, err := fs.GetInfo(ctx, remotePath, WithUser(cfg.user), WithFilesystemRequestTimeout(cfg.requestTimeout))
+	if err != nil {
+		return nil, err
+	}
+	if root.Type != FileTypeDir {
+		return nil, fmt.Errorf("%w: %s is not a directory", ErrInvalidArgument, remotePath)
+	}
+	remotePath = root.Path
+
+	entries, err := fs.List(ctx, remotePath, WithDepth(globUnlimitedDepth), WithListUser(cfg.user), WithListRequestTimeout(cfg.requestTimeout))
+	if err != nil {
+		return nil, err
+	}
+	// Parents sort before their children
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+
+	if err := os.MkdirAll(localPath, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create local directory: %w", err)
+	}
+
+	d := &dirDownload{fs: fs, cfg: cfg, result: &DownloadDirResult{}}
+	var files []*EntryInfo
+	var dirs []*EntryInfo
+	prefix := strings.TrimSuffix(remotePath, "/") + "/"
+	for _, entry := range entries {
+		rel := strings.TrimPrefix(entry.Path, prefix)
+		if !strings.HasPrefix(entry.Path, prefix) || !filepath.IsLocal(filepath.FromSlash(rel)) {
+			d.result.Skipped = append(d.result.Skipped, entry.Path)
+			continue
+		}
+		target := filepath.Join(localPath, filepath.FromSlash(rel))
+
+		if entry.Type == FileTypeDir {
+			// With a filter, only directories holding matching files are created
+			if cfg.glob != "" {
+				continue
+			}
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				if err := d.localError(entry, err); err != nil {
+					return d.result, err
+				}
+				continue
+			}
+			dirs = append(dirs, entry)
+			continue
+		}
+
+		if cfg.glob != "" && !matchRelGlob(cfg.glob, rel) {
+			continue
+		}
+		files = append(files, entry)
+	}
+
+	if err := d.run(ctx, files, prefix, localPath); err != nil {
+		return d.result, err
+	}
+
+	// Apply directory modes last so read-only directories can be filled first
+	for i := len(dirs) - 1; i >= 0; i-- {
+		rel := filepath.FromSlash(strings.TrimPrefix(dirs[i].Path, prefix))
+		if mode := os.FileMode(dirs[i].Mode).Perm(); mode != 0 {
+			_ = os.Chmod(filepath.Join(localPath, rel), mode)
+		}
+	}
+
+	return d.result, nil
+}
+
+// dirDownload tracks the state of a DownloadDir call.
+type dirDownload struct {
+	fs     *Filesystem
+	cfg    *downloadDirConfig
+	mu     sync.Mutex
+	result *DownloadDirResult
+	done   []string
+}
+
+// run downloads files with a bounded number of workers. The first failure
+// cancels the remaining downloads.
+func (d *dirDownload) run(ctx context.Context, files []*EntryInfo, prefix, localPath string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan *EntryInfo)
+	var firstErr error
+	var wg sync.WaitGroup
+	for range min(d.cfg.concurrency, max(len(files), 1)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for entry := range jobs {
+				target := filepath.Join(localPath, filepath.FromSlash(strings.TrimPrefix(entry.Path, prefix)))
+				if err := d.download(ctx, entry, target); err != nil {
+					d.mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					d.mu.Unlock()
+					cancel()
+				}
+			}
+		}()
+	}
+
+feed:
+	for _, entry := range files {
+		select {
+		case jobs <- entry:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr == nil && ctx.Err() != nil {
+		firstErr = ctx.Err()
+	}
+	if firstErr != nil {
+		return &DownloadDirError{Completed: d.done, Err: firstErr}
+	}
+	return nil
+}
+
+// download downloads a single file to target and records the outcome.
+func (d *dirDownload) download(ctx context.Context, entry *EntryInfo, target string) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	var onWrite func(int64)
+	if d.cfg.onProgress != nil {
+		onWrite = func(written int64) {
+			d.cfg.onProgress(entry.Path, written, entry.Size)
+		}
+	}
+
+	err := os.MkdirAll(filepath.Dir(target), 0o755)
+	var n int64
+	if err == nil {
+		n, err = d.fs.downloadToFile(ctx, entry.Path, target, onWrite, WithReadUser(d.cfg.user), WithReadRequestTimeout(d.cfg.requestTimeout))
+	}
+	if err == nil {
+		if mode := os.FileMode(entry.Mode).Perm(); mode != 0 {
+			err = os.Chmod(target, mode)
+		}
+	}
+	if err != nil {
+		return d.localError(entry, err)
+	}
+
+	d.mu.Lock()
+	d.result.Files++
+	d.result.Bytes += n
+	d.done = append(d.done, entry.Path)
+	d.mu.Unlock()
+	return nil
+}
+
+// localError returns err unless it is a local filesystem error that is
+// skipped by configuration, in which case the entry is recorded as skipped.
+func (d *dirDownload) localError(entry *EntryInfo, err error) error {
+	var pathErr *os.PathError
+	if !errors.As(err, &pathErr) {
+		return err
+	}
+	if !d.cfg.skipLocalErrors {
+		return fmt.Errorf("failed to create local path for %s: %w", entry.Path, err)
+	}
+
+	d.mu.Lock()
+	d.result.Skipped = append(d.result.Skipped, entry.Path)
+	d.mu.Unlock()
+	return nil
+}
+
+// matchRelGlob matches a slash-separated relative path against a glob
+// pattern. Patterns containing a slash match the whole path and may use
+// "**"; other patterns match the base name.
+func matchRelGlob(pattern, rel string) bool {
+	pattern = strings.Trim(pattern, "/")
+	if strings.Contains(pattern, "/") {
+		return matchGlobSegments(strings.Split(pattern, "/"), strings.Split(rel, "/"))
+	}
+	ok, _ := path.Match(pattern, path.Base(rel))
+	return ok
+}
@@ -0,0 +1,236 @@
+package e2b
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FindFiles returns the entries below root whose name matches namePattern,
+// descending into subdirectories, sorted by path. root itself is not
+// included.
+//
+// namePattern uses shell glob syntax ("*", "?" and "[...]") and is matched
+// against the base name of each entry, like find -name. "**" and patterns
+// containing "/" are rejected unless WithFindExtendedGlob(true) is set; a
+// pattern containing "/" is then matched against the path relative to
+// root, where "**" matches any number of directories, so "src/**/*.go"
+// matches Go files at any depth below root/src.
+//
+// The results can be narrowed with WithFindType, WithFindMinSize,
+// WithFindMaxSize, WithFindNewerThan and WithFindMaxDepth. Directories the
+// user cannot read are skipped. An empty slice is returned if nothing
+// matches and an error wrapping ErrNotFound if root does not exist.
+//
+// envd has no find RPC, so the search runs find in the sandbox. Its output
+// is parsed as it streams in rather than buffered.
+//
+// Example:
+//
+//	entries, err := sandbox.Files.FindFiles(ctx, "/home/user/project", "*.log",
+//	    e2b.WithFindType(e2b.FileTypeFile),
+//	    e2b.WithFindMinSize(1<<20),
+//	)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, entry := range entries {
+//	    fmt.Println(entry.Path, entry.Size)
+//	}
+func (fs *Filesystem) FindFiles(ctx context.Context, root, namePattern string, opts ...FindOption) ([]*EntryInfo, error) {
+	if root == "" || namePattern == "" {
+		return nil, fmt.Errorf("%w: root and name pattern are required", ErrInvalidArgument)
+	}
+
+	cfg := defaultFindConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if _, err := path.Match(namePattern, ""); err != nil {
+		return nil, fmt.Errorf("%w: invalid name pattern %q", ErrInvalidArgument, namePattern)
+	}
+	if !cfg.extendedGlob && (strings.Contains(namePattern, "**") || strings.Contains(namePattern, "/")) {
+		return nil, fmt.Errorf("%w: name pattern %q needs WithFindExtendedGlob for \"**\" or \"/\"", ErrInvalidArgument, namePattern)
+	}
+	if cfg.maxDepth < 0 {
+		return nil, fmt.Errorf("%w: max depth must not be negative", ErrInvalidArgument)
+	}
+	if cfg.minSize < 0 || (cfg.maxSizeSet && cfg.maxSize < cfg.minSize) {
+		return nil, fmt.Errorf("%w: invalid size range", ErrInvalidArgument)
+	}
+	switch cfg.fileType {
+	case "", FileTypeFile, FileTypeDir:
+	default:
+		return nil, fmt.Errorf("%w: unsupported file type %q", ErrInvalidArgument, cfg.fileType)
+	}
+
+	root = path.Clean(root)
+	collector := &findCollector{}
+	if strings.Contains(namePattern, "/") {
+		collector.root = root
+		collector.pattern = strings.Split(strings.Trim(namePattern, "/"), "/")
+	}
+
+	script := fmt.Sprintf("%s && %s", shellRequireExists(root), findCommand(root, namePattern, cfg))
+	err := fs.runShellToWriter(ctx, script, collector, &cfg.filesystemConfig)
+
+	var exitErr *CommandExitError
+	switch {
+	case err == nil:
+	case errors.As(err, &exitErr) && exitErr.ExitCode == 1 && exitErr.Stderr == "":
+		// Unreadable directories are skipped silently but still fail the exit code
+	default:
+		return nil, err
+	}
+
+	return collector.results(), nil
+}
+
+// findCommand returns the find command searching root for namePattern.
+// For each entry it prints a NUL-terminated record of its type, the type
+// of its target, size, permission bits, owner, group, modification time
+// and path, separated by tabs, followed by the NUL-terminated symbolic
+// link target.
+func findCommand(root, namePattern string, cfg *findConfig) string {
+	args := []string{"find", shellQuote(root), "-mindepth 1"}
+	if cfg.maxDepth > 0 {
+		args = append(args, fmt.Sprintf("-maxdepth %d", cfg.maxDepth))
+	}
+	// Patterns with "/" are matched against the relative path while reading
+	if !strings.Contains(namePattern, "/") {
+		args = append(args, "-name", shellQuote(strings.ReplaceAll(namePattern, "**", "*")))
+	}
+	switch cfg.fileType {
+	case FileTypeFile:
+		args = append(args, "-xtype f")
+	case FileTypeDir:
+		args = append(args, "-xtype d")
+	}
+	// Sizes are in bytes: -size +Nc matches more than N bytes
+	if cfg.minSize > 0 {
+		args = append(args, fmt.Sprintf("-size +%dc", cfg.minSize-1))
+	}
+	if cfg.maxSizeSet {
+		args = append(args, fmt.Sprintf("-size -%dc", cfg.maxSize+1))
+	}
+	if !cfg.newerThan.IsZero() {
+		args = append(args, "-newermt", shellQuote(cfg.newerThan.UTC().Format("2006-01-02 15:04:05.999999999 -0700")))
+	}
+	args = append(args, `-printf '%y\t%Y\t%s\t%m\t%u\t%g\t%T@\t%p\0%l\0' 2>/dev/null`)
+	return strings.Join(args, " ")
+}
+
+// findCollector parses find output written to it into EntryInfo values.
+type findCollector struct {
+	// root and pattern are set for patterns matched against the path
+	// relative to root
+	root    string
+	pattern []string
+
+	partial []byte
+	fields  []string
+	entries []*EntryInfo
+}
+
+// Write implements io.Writer.
+func (c *findCollector) Write(p []byte) (int, error) {
+	c.partial = append(c.partial, p...)
+	for {
+		i := bytes.IndexByte(c.partial, 0)
+		if i < 0 {
+			break
+		}
+		c.fields = append(c.fields, string(c.partial[:i]))
+		c.partial = c.partial[i+1:]
+		if len(c.fields) == 2 {
+			c.addEntry(c.fields[0], c.fields[1])
+			c.fields = c.fields[:0]
+		}
+	}
+	return len(p), nil
+}
+
+// addEntry parses a record printed by findCommand and the link target
+// following it.
+func (c *findCollector) addEntry(record, linkTarget string) {
+	parts := strings.SplitN(record, "\t", 8)
+	if len(parts) != 8 {
+		return
+	}
+	kind, targetKind, entryPath := parts[0], parts[1], parts[7]
+
+	if c.pattern != nil {
+		rel := strings.TrimPrefix(strings.TrimPrefix(entryPath, c.root), "/")
+		if !matchGlobSegments(c.pattern, strings.Split(rel, "/")) {
+			return
+		}
+	}
+
+	size, _ := strconv.ParseInt(parts[2], 10, 64)
+	perm, _ := strconv.ParseUint(parts[3], 8, 32)
+	mode := os.FileMode(perm).Perm()
+	for bit, flag := range map[uint64]os.FileMode{0o4000: os.ModeSetuid, 0o2000: os.ModeSetgid, 0o1000: os.ModeSticky} {
+		if perm&bit != 0 {
+			mode |= flag
+		}
+	}
+	switch kind {
+	case "d":
+		mode |= os.ModeDir
+		size = 0
+	case "l":
+		mode |= os.ModeSymlink
+	}
+
+	info := &EntryInfo{
+		Name:        path.Base(entryPath),
+		Type:        findFileType(targetKind),
+		Path:        entryPath,
+		Size:        size,
+		Mode:        uint32(mode),
+		Permissions: mode.Perm().String(),
+		Owner:       parts[4],
+		Group:       parts[5],
+	}
+	// %T@ prints seconds with a fractional part
+	seconds, fraction, _ := strings.Cut(parts[6], ".")
+	if sec, err := strconv.ParseInt(seconds, 10, 64); err == nil {
+		nsec, _ := strconv.ParseInt((fraction + "000000000")[:9], 10, 64)
+		info.ModifiedTime = time.Unix(sec, nsec)
+	}
+	if kind == "l" {
+		info.SymlinkTarget = &linkTarget
+	}
+	c.entries = append(c.entries, info)
+}
+
+// findFileType maps a find %Y type letter to a FileType.
+func findFileType(kind string) FileType {
+	switch kind {
+	case "f":
+		return FileTypeFile
+	case "d":
+		return FileTypeDir
+	default:
+		return ""
+	}
+}
+
+// results returns the collected entries sorted by path.
+func (c *findCollector) results() []*EntryInfo {
+	if c.entries == nil {
+		return []*EntryInfo{}
+	}
+	sort.Slice(c.entries, func(i, j int) bool {
+		return c.entries[i].Path < c.entries[j].Path
+	})
+	return c.entries
+}
@@ -0,0 +1,211 @@
+package e2b
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// globUnlimitedDepth is the listing depth used for "**" patterns without a
+// maximum depth. It is deeper than any realistic directory tree.
+const globUnlimitedDepth = 1024
+
+// Glob returns all entries matching a glob pattern, sorted by path.
+//
+// Patterns use path.Match syntax for each path segment, plus "**", which
+// matches any number of directories (including none). For example,
+// "/home/user/**/*.py" matches Python files at any depth below /home/user.
+// A pattern without matches returns an empty slice and no error.
+//
+// Example:
+//
+//	entries, err := sandbox.Files.Glob(ctx, "/home/user/**/*.py",
+//	    e2b.WithGlobType(e2b.FileTypeFile),
+//	)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, entry := range entries {
+//	    fmt.Println(entry.Path)
+//	}
+func (fs *Filesystem) Glob(ctx context.Context, pattern string, opts ...GlobOption) ([]*EntryInfo, error) {
+	cfg := defaultGlobConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if pattern == "" {
+		return nil, fmt.Errorf("%w: pattern is required", ErrInvalidArgument)
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("%w: invalid glob pattern %q", ErrInvalidArgument, pattern)
+	}
+	if cfg.maxDepth < 0 {
+		return nil, fmt.Errorf("%w: max depth must not be negative", ErrInvalidArgument)
+	}
+
+	base, rest := splitGlobPattern(pattern)
+	statOpts := []FilesystemOption{WithUser(cfg.user), WithFilesystemRequestTimeout(cfg.requestTimeout)}
+
+	// Without wildcards, the pattern is a plain path
+	if len(rest) == 0 {
+		info, err := fs.GetInfo(ctx, base, statOpts...)
+		if errors.Is(err, ErrNotFound) {
+			return []*EntryInfo{}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return filterGlobType([]*EntryInfo{info}, cfg.fileType), nil
+	}
+
+	root, err := fs.GetInfo(ctx, base, statOpts...)
+	if errors.Is(err, ErrNotFound) {
+		return []*EntryInfo{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	depth := len(rest)
+	for _, segment := range rest {
+		if segment == "**" {
+			depth = globUnlimitedDepth
+			break
+		}
+	}
+	if cfg.maxDepth > 0 && cfg.maxDepth < depth {
+		depth = cfg.maxDepth
+	}
+
+	var entries []*EntryInfo
+	visited := map[string]bool{root.Path: true}
+	if err := fs.globWalk(ctx, root.Path, root.Path, uint32(depth), cfg, visited, &entries); err != nil {
+		return nil, err
+	}
+
+	matches := make([]*EntryInfo, 0)
+	seen := make(map[string]bool, len(entries))
+	rootPrefix := strings.TrimSuffix(root.Path, "/") + "/"
+	for _, entry := range entries {
+		if seen[entry.Path] {
+			continue
+		}
+		seen[entry.Path] = true
+
+		rel := strings.TrimPrefix(entry.Path, rootPrefix)
+		if matchGlobSegments(rest, strings.Split(rel, "/")) {
+			matches = append(matches, entry)
+		}
+	}
+
+	matches = filterGlobType(matches, cfg.fileType)
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].Path < matches[j].Path
+	})
+
+	return matches, nil
+}
+
+// globWalk lists realDir up to depth levels and appends the entries with
+// their paths rewritten to be under shownDir. Symbolic links are traversed
+// if enabled, skipping targets that were already visited.
+func (fs *Filesystem) globWalk(ctx context.Context, realDir, shownDir string, depth uint32, cfg *globConfig, visited map[string]bool, out *[]*EntryInfo) error {
+	entries, err := fs.List(ctx, realDir, WithDepth(depth), WithListUser(cfg.user), WithListRequestTimeout(cfg.requestTimeout))
+	if err != nil {
+		return err
+	}
+
+	realPrefix := strings.TrimSuffix(realDir, "/") + "/"
+	for _, entry := range entries {
+		rel := strings.TrimPrefix(entry.Path, realPrefix)
+		shown := *entry
+		shown.Path = path.Join(shownDir, rel)
+		*out = append(*out, &shown)
+
+		if !cfg.followSymlinks || entry.SymlinkTarget == nil {
+			continue
+		}
+		entryDepth := uint32(strings.Count(rel, "/") + 1)
+		if entryDepth >= depth {
+			continue
+		}
+
+		target := resolveSymlinkTarget(entry.Path, *entry.SymlinkTarget)
+		if visited[target] {
+			continue
+		}
+		visited[target] = true
+
+		// Links to files or unreadable targets are not traversed
+		if err := fs.globWalk(ctx, target, shown.Path, depth-entryDepth, cfg, visited, out); err != nil && ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// splitGlobPattern splits a pattern into the directory before the first
+// segment containing a wildcard and the remaining segments.
+func splitGlobPattern(pattern string) (string, []string) {
+	segments := strings.Split(path.Clean(pattern), "/")
+	for i, segment := range segments {
+		if strings.ContainsAny(segment, "*?[\\") {
+			base := strings.Join(segments[:i], "/")
+			if base == "" {
+				// Relative patterns are resolved against the user's home directory
+				base = "."
+				if strings.HasPrefix(pattern, "/") {
+					base = "/"
+				}
+			}
+			return base, segments[i:]
+		}
+	}
+	return path.Clean(pattern), nil
+}
+
+// matchGlobSegments matches path segments against pattern segments,
+// where "**" matches any number of segments.
+func matchGlobSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchGlobSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+		return false
+	}
+	return matchGlobSegments(pattern[1:], segments[1:])
+}
+
+// filterGlobType keeps only entries of the given type, or all entries if
+// fileType is empty.
+func filterGlobType(entries []*EntryInfo, fileType FileType) []*EntryInfo {
+	if fileType == "" {
+		return entries
+	}
+
+	filtered := entries[:0]
+	for _, entry := range entries {
+		if entry.Type == fileType {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,108 @@
+package e2b
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteGzip compresses data with gzip and writes it to a file, appending
+// ".gz" to path unless it already ends with it.
+//
+// data can be a string, []byte or io.Reader; a reader is compressed while
+// it is uploaded, without buffering it in memory. The compression level is
+// set with WithGzipLevel. The file is compressed in the SDK, so gzip does
+// not need to be installed in the sandbox. The returned WriteInfo holds the
+// path of the written file; read it back with ReadGzip.
+//
+// Example:
+//
+//	info, err := sandbox.Files.WriteGzip(ctx, "/home/user/output.log", logs,
+//	    e2b.WithGzipLevel(gzip.BestCompression),
+//	)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Println(info.Path) // Output: /home/user/output.log.gz
+func (fs *Filesystem) WriteGzip(ctx context.Context, path string, data any, opts ...WriteOption) (*WriteInfo, error) {
+	cfg := defaultWriteConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	r, err := toReader(data)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.gzipLevel < gzip.HuffmanOnly || cfg.gzipLevel > gzip.BestCompression {
+		return nil, fmt.Errorf("%w: invalid gzip level %d", ErrInvalidArgument, cfg.gzipLevel)
+	}
+	if !strings.HasSuffix(path, ".gz") {
+		path += ".gz"
+	}
+
+	// In-memory data is compressed up front so the upload has a known
+	// length; readers are compressed through a pipe as they are read
+	if _, ok := r.(*bytes.Reader); ok {
+		var buf bytes.Buffer
+		if err := gzipCopy(&buf, r, cfg.gzipLevel); err != nil {
+			return nil, err
+		}
+		return fs.Write(ctx, path, buf.Bytes(), opts...)
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(gzipCopy(pw, r, cfg.gzipLevel))
+	}()
+	defer pr.Close()
+
+	return fs.Write(ctx, path, pr, opts...)
+}
+
+// gzipCopy writes the gzip-compressed content of r to w.
+func gzipCopy(w io.Writer, r io.Reader, level int) error {
+	zw, err := gzip.NewWriterLevel(w, level)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidArgument, err)
+	}
+	if _, err := io.Copy(zw, r); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// ReadGzip reads a gzip-compressed file, such as one written by WriteGzip,
+// and returns its decompressed content.
+//
+// The file is decompressed in the SDK. If it is not valid gzip, an error
+// naming the file is returned; a missing file returns an error wrapping
+// ErrNotFound.
+//
+// Example:
+//
+//	data, err := sandbox.Files.ReadGzip(ctx, "/home/user/output.log.gz")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (fs *Filesystem) ReadGzip(ctx context.Context, path string, opts ...ReadOption) ([]byte, error) {
+	compressed, err := fs.ReadBytes(ctx, path, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, fmt.Errorf("file %s is not valid gzip: %w", path, err)
+	}
+	defer zr.Close()
+
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress %s: %w", path, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,80 @@
+package e2b
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+)
+
+// ReadHead returns the first n lines or bytes of the file at path,
+// depending on unit, like head(1). The whole file is returned if it is
+// shorter. Lines keep their line terminators.
+//
+// Only the requested part is transferred: head runs in the sandbox instead
+// of the file being downloaded. An error wrapping ErrNotFound is returned
+// if the file does not exist.
+//
+// Example:
+//
+//	header, err := sandbox.Files.ReadHead(ctx, "/home/user/data.csv", 1, e2b.HeadTailLines)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (fs *Filesystem) ReadHead(ctx context.Context, path string, n int, unit HeadTailUnit, opts ...ReadOption) ([]byte, error) {
+	return fs.readHeadTail(ctx, "head", path, n, unit, opts)
+}
+
+// ReadLast returns the last n lines or bytes of the file at path,
+// depending on unit, like tail(1). The whole file is returned if it is
+// shorter. Lines keep their line terminators.
+//
+// Only the requested part is transferred: tail runs in the sandbox instead
+// of the file being downloaded, so reading the end of a large log is
+// cheap. An error wrapping ErrNotFound is returned if the file does not
+// exist.
+//
+// Example:
+//
+//	last, err := sandbox.Files.ReadLast(ctx, "/home/user/app.log", 100, e2b.HeadTailLines)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Print(string(last))
+func (fs *Filesystem) ReadLast(ctx context.Context, path string, n int, unit HeadTailUnit, opts ...ReadOption) ([]byte, error) {
+	return fs.readHeadTail(ctx, "tail", path, n, unit, opts)
+}
+
+// readHeadTail runs head or tail on the file at path.
+func (fs *Filesystem) readHeadTail(ctx context.Context, command, path string, n int, unit HeadTailUnit, opts []ReadOption) ([]byte, error) {
+	if path == "" {
+		return nil, fmt.Errorf("%w: path is required", ErrInvalidArgument)
+	}
+	if n < 0 {
+		return nil, fmt.Errorf("%w: count must not be negative", ErrInvalidArgument)
+	}
+
+	var flag string
+	switch unit {
+	case HeadTailLines:
+		flag = "-n"
+	case HeadTailBytes:
+		flag = "-c"
+	default:
+		return nil, fmt.Errorf("%w: unknown unit %q", ErrInvalidArgument, unit)
+	}
+
+	cfg := defaultReadConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	q := shellQuote(path)
+	script := fmt.Sprintf("%s && { [ ! -d %s ] || { echo %s >&2; exit %d; }; } && %s %s %d -- %s",
+		shellRequireExists(path), q, shellQuote("is a directory: "+path), shellExitExists, command, flag, n, q)
+
+	var buf bytes.Buffer
+	if err := fs.runShellToWriter(ctx, script, &buf, &cfg.filesystemConfig); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
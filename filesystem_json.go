@@ -0,0 +1,88 @@
+package e2b
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// ReadJSON reads a file and decodes its JSON content into v, which must be
+// a non-nil pointer.
+//
+// A missing file returns an error wrapping ErrNotFound.
+//
+// Example:
+//
+//	var pkg struct {
+//	    Name    string `json:"name"`
+//	    Version string `json:"version"`
+//	}
+//	if err := sandbox.Files.ReadJSON(ctx, "/home/user/app/package.json", &pkg); err != nil {
+//	    log.Fatal(err)
+//	}
+func (fs *Filesystem) ReadJSON(ctx context.Context, path string, v any, opts ...ReadOption) error {
+	if rv := reflect.ValueOf(v); rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("%w: ReadJSON requires a non-nil pointer, got %T", ErrInvalidArgument, v)
+	}
+
+	data, err := fs.ReadBytes(ctx, path, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to read JSON file %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to decode JSON file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// WriteJSON encodes v as JSON and writes it to a file.
+//
+// Missing parent directories are created. The content is first written to a
+// temporary file in the same directory, which is then renamed over path, so
+// readers never observe a partially written file. Use WithJSONIndent for
+// human-readable output.
+//
+// Example:
+//
+//	settings := map[string]any{"theme": "dark", "fontSize": 14}
+//	err := sandbox.Files.WriteJSON(ctx, "/home/user/.config/app.json", settings, e2b.WithJSONIndent("  "))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (fs *Filesystem) WriteJSON(ctx context.Context, filePath string, v any, opts ...WriteOption) error {
+	cfg := defaultWriteConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	var data []byte
+	var err error
+	if cfg.jsonIndent != "" {
+		data, err = json.MarshalIndent(v, "", cfg.jsonIndent)
+	} else {
+		data, err = json.Marshal(v)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: failed to encode JSON: %v", ErrInvalidArgument, err)
+	}
+
+	ctx, cancel := fs.applyTimeout(ctx, cfg.requestTimeout)
+	defer cancel()
+
+	tmpPath := tempSiblingPath(filePath, "tmp")
+
+	if _, err := fs.Write(ctx, tmpPath, data, opts...); err != nil {
+		return err
+	}
+
+	renameOpts := []FilesystemOption{WithUser(cfg.user), WithFilesystemRequestTimeout(cfg.requestTimeout)}
+	if _, err := fs.Rename(ctx, tmpPath, filePath, renameOpts...); err != nil {
+		_ = fs.Remove(context.WithoutCancel(ctx), tmpPath, renameOpts...)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package e2b
+
+import (
+	"context"
+	"strings"
+)
+
+// ReadLines reads a text file and returns its lines.
+//
+// Lines are split on "\n" and a trailing "\r" is removed from each line, so
+// files with Windows line endings are handled too. A final line separator
+// does not produce a trailing empty line. Use WithSkipEmpty to drop blank
+// lines.
+//
+// Example:
+//
+//	lines, err := sandbox.Files.ReadLines(ctx, "/var/log/app.log", e2b.WithSkipEmpty(true))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, line := range lines {
+//	    fmt.Println(line)
+//	}
+func (fs *Filesystem) ReadLines(ctx context.Context, path string, opts ...ReadOption) ([]string, error) {
+	cfg := defaultReadConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	data, err := fs.ReadBytes(ctx, path, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return splitLines(string(data), cfg.skipEmpty), nil
+}
+
+// WriteLines writes lines of text to a file, terminating each line with the
+// separator set by WithLineSeparator ("\n" by default).
+//
+// Like Write, it creates the file and any missing parent directories, and
+// overwrites an existing file.
+//
+// Example:
+//
+//	info, err := sandbox.Files.WriteLines(ctx, "/home/user/requirements.txt", []string{
+//	    "numpy==2.1.0",
+//	    "pandas==2.2.2",
+//	})
+func (fs *Filesystem) WriteLines(ctx context.Context, path string, lines []string, opts ...WriteLineOption) (*WriteInfo, error) {
+	cfg := defaultWriteLinesConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return fs.Write(ctx, path, joinLines(lines, cfg.separator), cfg.writeOptions()...)
+}
+
+// WriteLinesFiles writes several files line by line in a single upload
+// request. Each file is written as by WriteLines.
+//
+// Example:
+//
+//	infos, err := sandbox.Files.WriteLinesFiles(ctx, []e2b.WriteLinesEntry{
+//	    {Path: "/home/user/a.txt", Lines: []string{"one", "two"}},
+//	    {Path: "/home/user/b.txt", Lines: []string{"three"}},
+//	})
+func (fs *Filesystem) WriteLinesFiles(ctx context.Context, files []WriteLinesEntry, opts ...WriteLineOption) ([]*WriteInfo, error) {
+	cfg := defaultWriteLinesConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	entries := make([]WriteEntry, len(files))
+	for i, f := range files {
+		entries[i] = WriteEntry{Path: f.Path, Data: joinLines(f.Lines, cfg.separator)}
+	}
+
+	return fs.WriteFiles(ctx, entries, cfg.writeOptions()...)
+}
+
+// writeOptions converts the line writing configuration into write options.
+func (c *writeLinesConfig) writeOptions() []WriteOption {
+	return []WriteOption{WithWriteUser(c.user), WithWriteRequestTimeout(c.requestTimeout)}
+}
+
+// splitLines splits text into lines, accepting both "\n" and "\r\n" line
+// endings and ignoring a final line ending.
+func splitLines(text string, skipEmpty bool) []string {
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return []string{}
+	}
+
+	lines := strings.Split(text, "\n")
+	result := lines[:0]
+	for _, line := range lines {
+		line = strings.TrimSuffix(line, "\r")
+		if skipEmpty && strings.TrimSpace(line) == "" {
+			continue
+		}
+		result = append(result, line)
+	}
+
+	return result
+}
+
+// joinLines joins lines, terminating each one with sep.
+func joinLines(lines []string, sep string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, sep) + sep
+}
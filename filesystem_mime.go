@@ -0,0 +1,90 @@
+package e2b
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// GetMimeType detects the MIME type of a file, such as "text/plain",
+// "image/png" or "application/zip", without downloading all of it.
+//
+// The type is sniffed from the first bytes of the file (512 unless set with
+// WithSniffBytes) using the algorithm of http.DetectContentType. When the
+// content only identifies the file as generic text or binary data, the file
+// extension is consulted, so "data.json" is reported as "application/json".
+// The returned type has no parameters such as charset. If path does not
+// exist, an error wrapping ErrNotFound is returned.
+//
+// Example:
+//
+//	mimeType, err := sandbox.Files.GetMimeType(ctx, "/home/user/output/plot")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if strings.HasPrefix(mimeType, "image/") {
+//	    data, _ := sandbox.Files.ReadBytes(ctx, "/home/user/output/plot")
+//	    render(data)
+//	}
+func (fs *Filesystem) GetMimeType(ctx context.Context, filePath string, opts ...MimeTypeOption) (string, error) {
+	if filePath == "" {
+		return "", fmt.Errorf("%w: path is required", ErrInvalidArgument)
+	}
+
+	cfg := defaultMimeTypeConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if cfg.sniffBytes < 1 {
+		return "", fmt.Errorf("%w: sniff bytes must be positive", ErrInvalidArgument)
+	}
+
+	stream, err := fs.ReadStream(ctx, filePath, WithReadUser(cfg.user), WithReadRequestTimeout(cfg.requestTimeout))
+	if err != nil {
+		return "", err
+	}
+	// Closing early stops the rest of the file from being transferred
+	defer stream.Close()
+
+	head, err := io.ReadAll(io.LimitReader(stream, int64(cfg.sniffBytes)))
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", filePath, err)
+	}
+
+	return detectMimeType(filePath, head), nil
+}
+
+// detectMimeType returns the MIME type of the file at filePath starting
+// with head.
+func detectMimeType(filePath string, head []byte) string {
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+
+	// Sniffing only tells text from binary data for most formats, so let
+	// a matching extension be more specific
+	if sniffed != "text/plain" && sniffed != "application/octet-stream" {
+		return sniffed
+	}
+	byExt, _, err := mime.ParseMediaType(mime.TypeByExtension(path.Ext(filePath)))
+	if err != nil || sniffed == "text/plain" && !isTextMimeType(byExt) {
+		return sniffed
+	}
+	return byExt
+}
+
+// isTextMimeType reports whether files of the given MIME type are text.
+func isTextMimeType(mimeType string) bool {
+	if strings.HasPrefix(mimeType, "text/") || strings.HasSuffix(mimeType, "+xml") || strings.HasSuffix(mimeType, "+json") {
+		return true
+	}
+	switch mimeType {
+	case "application/json", "application/xml", "application/javascript", "application/x-javascript",
+		"application/x-sh", "application/sql", "application/yaml", "application/x-yaml", "application/toml":
+		return true
+	}
+	return false
+}
@@ -1,6 +1,10 @@
 package e2b
 
-import "time"
+import (
+	"compress/gzip"
+	"os"
+	"time"
+)
 
 // filesystemConfig holds configuration for filesystem operations.
 type filesystemConfig struct {
@@ -75,6 +79,10 @@ type watchConfig struct {
 	recursive bool
 	timeoutMs int64
 	onExit    func(error)
+	debounce  time.Duration
+
+	// initialRead makes WatchFile report the current content first
+	initialRead bool
 }
 
 // defaultWatchConfig returns the default watch configuration.
@@ -124,10 +132,38 @@ func OnWatchExit(handler func(error)) WatchOption {
 	}
 }
 
+// WithWatchDebounce coalesces rapid events, such as the writes and mode
+// changes of an editor saving a file. Events are held until no further
+// event for the same path arrives for d, and then onEvent is called once
+// with the last of them. Events still held when the watch stops are
+// delivered before it ends. Default is 0, which reports every event.
+//
+// Example:
+//
+//	handle, err := sandbox.Files.WatchDir(ctx, "/home/user/project", onChange,
+//	    e2b.WithRecursive(true),
+//	    e2b.WithWatchDebounce(200*time.Millisecond),
+//	)
+func WithWatchDebounce(d time.Duration) WatchOption {
+	return func(c *watchConfig) {
+		c.debounce = d
+	}
+}
+
+// WithWatchFileInitialRead makes WatchFile call onEvent once with the
+// current content of the file, as an EventTypeWrite event with Content
+// set, before any change is reported. Other watches ignore it.
+func WithWatchFileInitialRead(initialRead bool) WatchOption {
+	return func(c *watchConfig) {
+		c.initialRead = initialRead
+	}
+}
+
 // readConfig holds configuration for reading files.
 type readConfig struct {
 	filesystemConfig
-	format ReadFormat
+	format    ReadFormat
+	skipEmpty bool
 }
 
 // defaultReadConfig returns the default read configuration.
@@ -161,14 +197,30 @@ func WithFormat(format ReadFormat) ReadOption {
 	}
 }
 
+// WithSkipEmpty makes ReadLines drop blank lines, including lines that
+// contain only whitespace. Other reads ignore it.
+//
+// Example:
+//
+//	hosts, err := sandbox.Files.ReadLines(ctx, "/etc/hosts", e2b.WithSkipEmpty(true))
+func WithSkipEmpty(skip bool) ReadOption {
+	return func(c *readConfig) {
+		c.skipEmpty = skip
+	}
+}
+
 // writeConfig holds configuration for writing files.
 type writeConfig struct {
 	filesystemConfig
+	jsonIndent string
+	onProgress func(bytesWritten int64)
+	maxSize    int64
+	gzipLevel  int
 }
 
 // defaultWriteConfig returns the default write configuration.
 func defaultWriteConfig() *writeConfig {
-	return &writeConfig{}
+	return &writeConfig{gzipLevel: gzip.DefaultCompression}
 }
 
 // WriteOption configures file writing operations.
@@ -187,3 +239,1101 @@ func WithWriteRequestTimeout(d time.Duration) WriteOption {
 		c.requestTimeout = d
 	}
 }
+
+// WithJSONIndent makes WriteJSON indent the encoded JSON, writing each
+// nested level on its own line prefixed by indent. Other writes ignore it.
+//
+// Example:
+//
+//	err := sandbox.Files.WriteJSON(ctx, "/home/user/config.json", cfg, e2b.WithJSONIndent("  "))
+func WithJSONIndent(indent string) WriteOption {
+	return func(c *writeConfig) {
+		c.jsonIndent = indent
+	}
+}
+
+// WithProgress sets a callback that is called periodically while file
+// content is sent, with the total number of content bytes written so far.
+// Setting it streams the upload instead of buffering the request body.
+//
+// Example:
+//
+//	info, err := sandbox.Files.Write(ctx, "/home/user/data.csv", f,
+//	    e2b.WithProgress(func(n int64) {
+//	        fmt.Printf("\ruploaded %d bytes", n)
+//	    }),
+//	)
+func WithProgress(fn func(bytesWritten int64)) WriteOption {
+	return func(c *writeConfig) {
+		c.onProgress = fn
+	}
+}
+
+// WithMaxWriteSize limits the total size of the content written by a single
+// call. Exceeding the limit aborts the upload with an error wrapping
+// ErrInvalidArgument. Zero, the default, means no limit.
+func WithMaxWriteSize(limit int64) WriteOption {
+	return func(c *writeConfig) {
+		c.maxSize = limit
+	}
+}
+
+// WithGzipLevel sets the compression level used by WriteGzip, from
+// gzip.HuffmanOnly to gzip.BestCompression. Default is
+// gzip.DefaultCompression. Other writes ignore it.
+//
+// Example:
+//
+//	info, err := sandbox.Files.WriteGzip(ctx, "/home/user/out.json", data,
+//	    e2b.WithGzipLevel(gzip.BestSpeed),
+//	)
+func WithGzipLevel(level int) WriteOption {
+	return func(c *writeConfig) {
+		c.gzipLevel = level
+	}
+}
+
+// writeLinesConfig holds configuration for writing lines of text.
+type writeLinesConfig struct {
+	filesystemConfig
+	separator string
+}
+
+// defaultWriteLinesConfig returns the default line writing configuration.
+func defaultWriteLinesConfig() *writeLinesConfig {
+	return &writeLinesConfig{
+		separator: "\n",
+	}
+}
+
+// WriteLineOption configures line writing operations.
+type WriteLineOption func(*writeLinesConfig)
+
+// WithWriteLinesUser sets the user for the line writing operation.
+func WithWriteLinesUser(user string) WriteLineOption {
+	return func(c *writeLinesConfig) {
+		c.user = user
+	}
+}
+
+// WithWriteLinesRequestTimeout sets the request timeout for the line writing operation.
+func WithWriteLinesRequestTimeout(d time.Duration) WriteLineOption {
+	return func(c *writeLinesConfig) {
+		c.requestTimeout = d
+	}
+}
+
+// WithLineSeparator sets the separator written after each line.
+// Defaults to "\n".
+//
+// Example:
+//
+//	_, err := sandbox.Files.WriteLines(ctx, "/home/user/report.csv", rows, e2b.WithLineSeparator("\r\n"))
+func WithLineSeparator(sep string) WriteLineOption {
+	return func(c *writeLinesConfig) {
+		c.separator = sep
+	}
+}
+
+// chmodConfig holds configuration for changing file permissions.
+type chmodConfig struct {
+	filesystemConfig
+	recursive bool
+}
+
+// defaultChmodConfig returns the default chmod configuration.
+func defaultChmodConfig() *chmodConfig {
+	return &chmodConfig{}
+}
+
+// ChmodOption configures permission changes.
+type ChmodOption func(*chmodConfig)
+
+// WithChmodUser sets the user for the chmod operation.
+func WithChmodUser(user string) ChmodOption {
+	return func(c *chmodConfig) {
+		c.user = user
+	}
+}
+
+// WithChmodRequestTimeout sets the request timeout for the chmod operation.
+func WithChmodRequestTimeout(d time.Duration) ChmodOption {
+	return func(c *chmodConfig) {
+		c.requestTimeout = d
+	}
+}
+
+// WithChmodRecursive applies the mode to a directory and every entry under it.
+func WithChmodRecursive(recursive bool) ChmodOption {
+	return func(c *chmodConfig) {
+		c.recursive = recursive
+	}
+}
+
+// chownConfig holds configuration for changing file ownership.
+type chownConfig struct {
+	filesystemConfig
+	recursive bool
+}
+
+// defaultChownConfig returns the default chown configuration.
+func defaultChownConfig() *chownConfig {
+	return &chownConfig{}
+}
+
+// ChownOption configures ownership changes.
+type ChownOption func(*chownConfig)
+
+// WithChownUser sets the user performing the chown operation. Changing the
+// owner of a file usually requires "root".
+func WithChownUser(user string) ChownOption {
+	return func(c *chownConfig) {
+		c.user = user
+	}
+}
+
+// WithChownRequestTimeout sets the request timeout for the chown operation.
+func WithChownRequestTimeout(d time.Duration) ChownOption {
+	return func(c *chownConfig) {
+		c.requestTimeout = d
+	}
+}
+
+// WithChownRecursive applies the ownership to a directory and every entry under it.
+func WithChownRecursive(recursive bool) ChownOption {
+	return func(c *chownConfig) {
+		c.recursive = recursive
+	}
+}
+
+// symlinkConfig holds configuration for creating symbolic links.
+type symlinkConfig struct {
+	filesystemConfig
+	force bool
+}
+
+// defaultSymlinkConfig returns the default symlink configuration.
+func defaultSymlinkConfig() *symlinkConfig {
+	return &symlinkConfig{}
+}
+
+// SymlinkOption configures symbolic link creation.
+type SymlinkOption func(*symlinkConfig)
+
+// WithSymlinkUser sets the user for the symlink operation.
+func WithSymlinkUser(user string) SymlinkOption {
+	return func(c *symlinkConfig) {
+		c.user = user
+	}
+}
+
+// WithSymlinkRequestTimeout sets the request timeout for the symlink operation.
+func WithSymlinkRequestTimeout(d time.Duration) SymlinkOption {
+	return func(c *symlinkConfig) {
+		c.requestTimeout = d
+	}
+}
+
+// WithSymlinkForce replaces an existing file or link at the link path.
+// An existing directory is never replaced.
+func WithSymlinkForce(force bool) SymlinkOption {
+	return func(c *symlinkConfig) {
+		c.force = force
+	}
+}
+
+// hardLinkConfig holds configuration for creating hard links.
+type hardLinkConfig struct {
+	filesystemConfig
+	force bool
+}
+
+// defaultHardLinkConfig returns the default hard link configuration.
+func defaultHardLinkConfig() *hardLinkConfig {
+	return &hardLinkConfig{}
+}
+
+// HardLinkOption configures hard link creation.
+type HardLinkOption func(*hardLinkConfig)
+
+// WithHardLinkUser sets the user for the hard link operation.
+func WithHardLinkUser(user string) HardLinkOption {
+	return func(c *hardLinkConfig) {
+		c.user = user
+	}
+}
+
+// WithHardLinkRequestTimeout sets the request timeout for the hard link operation.
+func WithHardLinkRequestTimeout(d time.Duration) HardLinkOption {
+	return func(c *hardLinkConfig) {
+		c.requestTimeout = d
+	}
+}
+
+// WithHardLinkForce replaces an existing file or link at the new path.
+// An existing directory is never replaced.
+func WithHardLinkForce(force bool) HardLinkOption {
+	return func(c *hardLinkConfig) {
+		c.force = force
+	}
+}
+
+// fileCopyConfig holds configuration for copying files within the sandbox.
+type fileCopyConfig struct {
+	filesystemConfig
+	mode            os.FileMode
+	resolveSymlinks bool
+	nonRecursive    bool
+	overwrite       bool
+}
+
+// defaultFileCopyConfig returns the default file copy configuration.
+func defaultFileCopyConfig() *fileCopyConfig {
+	return &fileCopyConfig{
+		resolveSymlinks: true,
+		overwrite:       true,
+	}
+}
+
+// FilesCopyOption configures Files.Copy.
+type FilesCopyOption func(*fileCopyConfig)
+
+// WithFilesCopyUser sets the user performing the copy.
+func WithFilesCopyUser(user string) FilesCopyOption {
+	return func(c *fileCopyConfig) {
+		c.user = user
+	}
+}
+
+// WithFilesCopyRequestTimeout sets the request timeout for the copy.
+func WithFilesCopyRequestTimeout(d time.Duration) FilesCopyOption {
+	return func(c *fileCopyConfig) {
+		c.requestTimeout = d
+	}
+}
+
+// WithFilesCopyMode sets the mode of the copied entry. The permission bits
+// are applied along with the setuid, setgid and sticky bits, as in
+// Files.Chmod.
+func WithFilesCopyMode(mode os.FileMode) FilesCopyOption {
+	return func(c *fileCopyConfig) {
+		c.mode = mode
+	}
+}
+
+// WithFilesCopyResolveSymlinks sets whether to resolve symlinks when copying.
+// If true, the target of a link is copied; if false, the link itself.
+// Defaults to true.
+func WithFilesCopyResolveSymlinks(resolve bool) FilesCopyOption {
+	return func(c *fileCopyConfig) {
+		c.resolveSymlinks = resolve
+	}
+}
+
+// WithFilesCopyNonRecursive disables recursive copying, so copying a
+// directory fails.
+func WithFilesCopyNonRecursive() FilesCopyOption {
+	return func(c *fileCopyConfig) {
+		c.nonRecursive = true
+	}
+}
+
+// WithFilesCopyOverwrite sets whether an existing destination is overwritten.
+// Defaults to true. If false, copying to an existing path fails.
+func WithFilesCopyOverwrite(overwrite bool) FilesCopyOption {
+	return func(c *fileCopyConfig) {
+		c.overwrite = overwrite
+	}
+}
+
+// checksumConfig holds configuration for computing file checksums.
+type checksumConfig struct {
+	filesystemConfig
+	algorithm string
+}
+
+// defaultChecksumConfig returns the default checksum configuration.
+func defaultChecksumConfig() *checksumConfig {
+	return &checksumConfig{algorithm: "sha256"}
+}
+
+// ChecksumOption configures checksum computation.
+type ChecksumOption func(*checksumConfig)
+
+// WithChecksumUser sets the user for reading the file.
+func WithChecksumUser(user string) ChecksumOption {
+	return func(c *checksumConfig) {
+		c.user = user
+	}
+}
+
+// WithChecksumRequestTimeout sets the request timeout for computing the checksum.
+func WithChecksumRequestTimeout(d time.Duration) ChecksumOption {
+	return func(c *checksumConfig) {
+		c.requestTimeout = d
+	}
+}
+
+// WithChecksumAlgorithm sets the hash algorithm: "sha256" (the default),
+// "sha512" or "md5".
+func WithChecksumAlgorithm(algo string) ChecksumOption {
+	return func(c *checksumConfig) {
+		c.algorithm = algo
+	}
+}
+
+// extractConfig holds configuration for extracting archives.
+type extractConfig struct {
+	filesystemConfig
+	filters         []string
+	stripComponents int
+}
+
+// defaultExtractConfig returns the default archive extraction configuration.
+func defaultExtractConfig() *extractConfig {
+	return &extractConfig{}
+}
+
+// ExtractOption configures archive extraction.
+type ExtractOption func(*extractConfig)
+
+// WithExtractUser sets the user for the extraction.
+func WithExtractUser(user string) ExtractOption {
+	return func(c *extractConfig) {
+		c.user = user
+	}
+}
+
+// WithExtractRequestTimeout sets the request timeout for the extraction.
+func WithExtractRequestTimeout(d time.Duration) ExtractOption {
+	return func(c *extractConfig) {
+		c.requestTimeout = d
+	}
+}
+
+// WithExtractFilter extracts only the archive entries matching pattern.
+// Patterns are shell wildcards matched against the entry names stored in
+// the archive, e.g. "project/src/*". The option may be repeated to extract
+// entries matching any of the patterns. Patterns starting with '-' are
+// rejected.
+func WithExtractFilter(pattern string) ExtractOption {
+	return func(c *extractConfig) {
+		c.filters = append(c.filters, pattern)
+	}
+}
+
+// WithExtractStripComponents strips n leading path components from the
+// entry names, like tar --strip-components. Entries with fewer components
+// are skipped.
+func WithExtractStripComponents(n int) ExtractOption {
+	return func(c *extractConfig) {
+		c.stripComponents = n
+	}
+}
+
+// archiveConfig holds configuration for creating archives.
+type archiveConfig struct {
+	filesystemConfig
+	format   string
+	excludes []string
+}
+
+// defaultArchiveConfig returns the default archive creation configuration.
+func defaultArchiveConfig() *archiveConfig {
+	return &archiveConfig{}
+}
+
+// ArchiveOption configures archive creation.
+type ArchiveOption func(*archiveConfig)
+
+// WithArchiveUser sets the user for creating the archive.
+func WithArchiveUser(user string) ArchiveOption {
+	return func(c *archiveConfig) {
+		c.user = user
+	}
+}
+
+// WithArchiveRequestTimeout sets the request timeout for creating the
+// archive. For ArchiveAndDownload it bounds the whole download.
+func WithArchiveRequestTimeout(d time.Duration) ArchiveOption {
+	return func(c *archiveConfig) {
+		c.requestTimeout = d
+	}
+}
+
+// WithArchiveFormat sets the archive format: "tar.gz" (or "tgz"), "tar",
+// "tar.bz2", "tar.xz" or "zip". By default Archive uses the format matching
+// the archive path's extension, and "tar.gz" if it has none.
+func WithArchiveFormat(format string) ArchiveOption {
+	return func(c *archiveConfig) {
+		c.format = format
+	}
+}
+
+// WithArchiveExclude excludes the entries matching any of the patterns.
+// Patterns are shell wildcards matched against every path component, like
+// tar --exclude, e.g. "node_modules" or "*.log".
+func WithArchiveExclude(patterns ...string) ArchiveOption {
+	return func(c *archiveConfig) {
+		c.excludes = append(c.excludes, patterns...)
+	}
+}
+
+// mimeTypeConfig holds configuration for detecting MIME types.
+type mimeTypeConfig struct {
+	filesystemConfig
+	sniffBytes int
+}
+
+// defaultMimeTypeConfig returns the default MIME type detection configuration.
+func defaultMimeTypeConfig() *mimeTypeConfig {
+	return &mimeTypeConfig{sniffBytes: DefaultMimeSniffBytes}
+}
+
+// MimeTypeOption configures MIME type detection.
+type MimeTypeOption func(*mimeTypeConfig)
+
+// WithMimeTypeUser sets the user for reading the file.
+func WithMimeTypeUser(user string) MimeTypeOption {
+	return func(c *mimeTypeConfig) {
+		c.user = user
+	}
+}
+
+// WithMimeTypeRequestTimeout sets the request timeout for reading the file.
+func WithMimeTypeRequestTimeout(d time.Duration) MimeTypeOption {
+	return func(c *mimeTypeConfig) {
+		c.requestTimeout = d
+	}
+}
+
+// WithSniffBytes sets how many bytes are read from the start of the file
+// to detect its type. The default is DefaultMimeSniffBytes; content
+// sniffing considers at most 512 bytes.
+func WithSniffBytes(n int) MimeTypeOption {
+	return func(c *mimeTypeConfig) {
+		c.sniffBytes = n
+	}
+}
+
+// uploadDirConfig holds configuration for uploading directories.
+type uploadDirConfig struct {
+	filesystemConfig
+	ignorePatterns  []string
+	resolveSymlinks bool
+	maxBatchBytes   int64
+}
+
+// defaultUploadDirConfig returns the default directory upload configuration.
+func defaultUploadDirConfig() *uploadDirConfig {
+	return &uploadDirConfig{
+		resolveSymlinks: true,
+		maxBatchBytes:   DefaultUploadBatchSize,
+	}
+}
+
+// UploadDirOption configures directory uploads.
+type UploadDirOption func(*uploadDirConfig)
+
+// WriteDirOption configures directory uploads made with WriteDir.
+// It accepts the same options as UploadDir.
+type WriteDirOption = UploadDirOption
+
+// WithUploadDirUser sets the user for the directory upload.
+func WithUploadDirUser(user string) UploadDirOption {
+	return func(c *uploadDirConfig) {
+		c.user = user
+	}
+}
+
+// WithUploadDirRequestTimeout sets the request timeout for each upload
+// request of the directory upload.
+func WithUploadDirRequestTimeout(d time.Duration) UploadDirOption {
+	return func(c *uploadDirConfig) {
+		c.requestTimeout = d
+	}
+}
+
+// WithUploadDirBatchSize sets the maximum total size in bytes of the files
+// sent in one upload request. A file larger than the limit is sent in a
+// request of its own. Defaults to DefaultUploadBatchSize.
+//
+// Example:
+//
+//	infos, err := sandbox.Files.UploadDir(ctx, "./data", "/home/user/data",
+//	    e2b.WithUploadDirBatchSize(8<<20),
+//	)
+func WithUploadDirBatchSize(maxBytes int64) UploadDirOption {
+	return func(c *uploadDirConfig) {
+		c.maxBatchBytes = maxBytes
+	}
+}
+
+// WithUploadDirIgnorePatterns sets glob patterns for local files and
+// directories to skip. A pattern containing a slash is matched against the
+// slash-separated path relative to the uploaded directory and may use "**";
+// other patterns are matched against every path element. Ignoring a
+// directory skips everything under it.
+//
+// Example:
+//
+//	infos, err := sandbox.Files.UploadDir(ctx, "./app", "/home/user/app",
+//	    e2b.WithUploadDirIgnorePatterns(".git", "node_modules", "*.pyc", "build/**/*.tmp"),
+//	)
+func WithUploadDirIgnorePatterns(patterns ...string) UploadDirOption {
+	return func(c *uploadDirConfig) {
+		c.ignorePatterns = patterns
+	}
+}
+
+// WithUploadDirResolveSymlinks sets whether symbolic links are followed and
+// their targets uploaded. When false, symbolic links are skipped.
+// Defaults to true.
+func WithUploadDirResolveSymlinks(resolve bool) UploadDirOption {
+	return func(c *uploadDirConfig) {
+		c.resolveSymlinks = resolve
+	}
+}
+
+// downloadDirConfig holds configuration for downloading directories.
+type downloadDirConfig struct {
+	filesystemConfig
+	skipLocalErrors bool
+	concurrency     int
+	glob            string
+	onProgress      func(path string, written, total int64)
+}
+
+// defaultDownloadDirConfig returns the default directory download configuration.
+func defaultDownloadDirConfig() *downloadDirConfig {
+	return &downloadDirConfig{
+		concurrency: DefaultDownloadConcurrency,
+	}
+}
+
+// DownloadDirOption configures directory downloads.
+type DownloadDirOption func(*downloadDirConfig)
+
+// WithDownloadDirUser sets the user for the directory download.
+func WithDownloadDirUser(user string) DownloadDirOption {
+	return func(c *downloadDirConfig) {
+		c.user = user
+	}
+}
+
+// WithDownloadDirRequestTimeout sets the request timeout for listing the
+// remote directory and for each file download.
+func WithDownloadDirRequestTimeout(d time.Duration) DownloadDirOption {
+	return func(c *downloadDirConfig) {
+		c.requestTimeout = d
+	}
+}
+
+// WithDownloadDirSkipLocalErrors makes DownloadDir skip entries that cannot
+// be created locally, such as names that are invalid on the local
+// filesystem, instead of failing. Skipped entries are reported in
+// DownloadDirResult.Skipped.
+func WithDownloadDirSkipLocalErrors(skip bool) DownloadDirOption {
+	return func(c *downloadDirConfig) {
+		c.skipLocalErrors = skip
+	}
+}
+
+// WithDownloadDirConcurrency sets how many files DownloadDir downloads in
+// parallel. Defaults to DefaultDownloadConcurrency.
+func WithDownloadDirConcurrency(n int) DownloadDirOption {
+	return func(c *downloadDirConfig) {
+		c.concurrency = n
+	}
+}
+
+// WithDownloadDirGlob makes DownloadDir download only files whose path
+// relative to the downloaded directory matches pattern. A pattern containing
+// a slash is matched against the whole relative path and may use "**";
+// other patterns are matched against the file name. Only directories that
+// contain matching files are created.
+//
+// Example:
+//
+//	result, err := sandbox.Files.DownloadDir(ctx, "/home/user/run", "./run",
+//	    e2b.WithDownloadDirGlob("checkpoints/**/*.pt"),
+//	)
+func WithDownloadDirGlob(pattern string) DownloadDirOption {
+	return func(c *downloadDirConfig) {
+		c.glob = pattern
+	}
+}
+
+// WithDownloadDirProgress sets a callback that receives the number of bytes
+// written so far for a file, along with its size as listed in the sandbox.
+// The callback is called concurrently for files downloaded in parallel.
+//
+// Example:
+//
+//	e2b.WithDownloadDirProgress(func(path string, written, total int64) {
+//	    log.Printf("%s: %d/%d bytes", path, written, total)
+//	})
+func WithDownloadDirProgress(fn func(path string, written, total int64)) DownloadDirOption {
+	return func(c *downloadDirConfig) {
+		c.onProgress = fn
+	}
+}
+
+// globConfig holds configuration for glob matching.
+type globConfig struct {
+	filesystemConfig
+	maxDepth       int
+	followSymlinks bool
+	fileType       FileType
+}
+
+// defaultGlobConfig returns the default glob configuration.
+func defaultGlobConfig() *globConfig {
+	return &globConfig{}
+}
+
+// GlobOption configures glob matching operations.
+type GlobOption func(*globConfig)
+
+// WithGlobUser sets the user for the glob operation.
+func WithGlobUser(user string) GlobOption {
+	return func(c *globConfig) {
+		c.user = user
+	}
+}
+
+// WithGlobRequestTimeout sets the request timeout for each directory listing
+// made by the glob operation.
+func WithGlobRequestTimeout(d time.Duration) GlobOption {
+	return func(c *globConfig) {
+		c.requestTimeout = d
+	}
+}
+
+// WithGlobMaxDepth limits how many directory levels below the first
+// wildcard are searched. Zero (the default) means no limit.
+func WithGlobMaxDepth(depth int) GlobOption {
+	return func(c *globConfig) {
+		c.maxDepth = depth
+	}
+}
+
+// WithGlobFollowSymlinks sets whether symbolic links to directories are
+// traversed. Defaults to false.
+func WithGlobFollowSymlinks(follow bool) GlobOption {
+	return func(c *globConfig) {
+		c.followSymlinks = follow
+	}
+}
+
+// WithGlobType restricts results to entries of the given type.
+func WithGlobType(fileType FileType) GlobOption {
+	return func(c *globConfig) {
+		c.fileType = fileType
+	}
+}
+
+// walkConfig holds configuration for walking directory trees.
+type walkConfig struct {
+	filesystemConfig
+	maxDepth        int
+	followSymlinks  bool
+	concurrency     int
+	continueOnError bool
+}
+
+// defaultWalkConfig returns the default walk configuration.
+func defaultWalkConfig() *walkConfig {
+	return &walkConfig{
+		concurrency: DefaultWalkConcurrency,
+	}
+}
+
+// WalkOption configures directory tree walks.
+type WalkOption func(*walkConfig)
+
+// WithWalkUser sets the user for the walk.
+func WithWalkUser(user string) WalkOption {
+	return func(c *walkConfig) {
+		c.user = user
+	}
+}
+
+// WithWalkRequestTimeout sets the request timeout for each directory listing.
+func WithWalkRequestTimeout(d time.Duration) WalkOption {
+	return func(c *walkConfig) {
+		c.requestTimeout = d
+	}
+}
+
+// WithWalkMaxDepth limits how deep WalkDir descends below the root. The
+// root's children are at depth 1; directories at the maximum depth are
+// visited but not listed. Zero, the default, means no limit.
+func WithWalkMaxDepth(n int) WalkOption {
+	return func(c *walkConfig) {
+		c.maxDepth = n
+	}
+}
+
+// WithWalkFollowSymlinks makes WalkDir descend into symbolic links to
+// directories. Directories reached through a link are visited once.
+func WithWalkFollowSymlinks(follow bool) WalkOption {
+	return func(c *walkConfig) {
+		c.followSymlinks = follow
+	}
+}
+
+// WithWalkConcurrency sets how many directories WalkDir lists in parallel.
+// Defaults to DefaultWalkConcurrency.
+func WithWalkConcurrency(n int) WalkOption {
+	return func(c *walkConfig) {
+		c.concurrency = n
+	}
+}
+
+// WithWalkContinueOnError makes WalkDir keep walking when the callback
+// returns an error. The errors are joined and returned once the walk ends.
+func WithWalkContinueOnError(continueOnError bool) WalkOption {
+	return func(c *walkConfig) {
+		c.continueOnError = continueOnError
+	}
+}
+
+// syncConfig holds configuration for syncing directories.
+type syncConfig struct {
+	filesystemConfig
+	delete bool
+	ignore []string
+}
+
+// defaultSyncConfig returns the default sync configuration.
+func defaultSyncConfig() *syncConfig {
+	return &syncConfig{}
+}
+
+// SyncOption configures directory syncs.
+type SyncOption func(*syncConfig)
+
+// WithSyncUser sets the user for the sync.
+func WithSyncUser(user string) SyncOption {
+	return func(c *syncConfig) {
+		c.user = user
+	}
+}
+
+// WithSyncRequestTimeout sets the request timeout for each request made by
+// the sync.
+func WithSyncRequestTimeout(d time.Duration) SyncOption {
+	return func(c *syncConfig) {
+		c.requestTimeout = d
+	}
+}
+
+// WithSyncDelete makes SyncDir remove files and directories in the sandbox
+// that do not exist locally. Ignored paths are never removed.
+func WithSyncDelete(del bool) SyncOption {
+	return func(c *syncConfig) {
+		c.delete = del
+	}
+}
+
+// WithSyncIgnore sets gitignore-style patterns for paths that SyncDir leaves
+// out. Patterns are matched against slash-separated paths relative to the
+// synced directory: a pattern without a slash matches a name at any depth,
+// a leading or inner slash anchors it to the root, a trailing slash matches
+// only directories, "**" matches any number of directories and a leading
+// "!" re-includes paths excluded by earlier patterns. Blank lines and lines
+// starting with "#" are ignored.
+//
+// Example:
+//
+//	result, err := sandbox.Files.SyncDir(ctx, "./app", "/home/user/app",
+//	    e2b.WithSyncIgnore(".git/", "node_modules/", "*.log", "!keep.log"),
+//	)
+func WithSyncIgnore(patterns ...string) SyncOption {
+	return func(c *syncConfig) {
+		c.ignore = append(c.ignore, patterns...)
+	}
+}
+
+// searchConfig holds configuration for content searches.
+type searchConfig struct {
+	filesystemConfig
+	regex         bool
+	caseSensitive bool
+	maxResults    int
+	contextLines  int
+}
+
+// defaultSearchConfig returns the default content search configuration.
+func defaultSearchConfig() *searchConfig {
+	return &searchConfig{caseSensitive: true}
+}
+
+// SearchOption configures a content search.
+type SearchOption func(*searchConfig)
+
+// WithSearchUser sets the user for the search.
+func WithSearchUser(user string) SearchOption {
+	return func(c *searchConfig) {
+		c.user = user
+	}
+}
+
+// WithSearchRequestTimeout sets the request timeout for the search.
+func WithSearchRequestTimeout(d time.Duration) SearchOption {
+	return func(c *searchConfig) {
+		c.requestTimeout = d
+	}
+}
+
+// WithSearchRegex treats the pattern as a regular expression instead of a
+// literal string. The expression must be valid in both Go's regexp syntax
+// and grep's extended syntax, which covers the common constructs.
+func WithSearchRegex(regex bool) SearchOption {
+	return func(c *searchConfig) {
+		c.regex = regex
+	}
+}
+
+// WithSearchCaseSensitive sets whether the pattern is matched case
+// sensitively. Default is true.
+func WithSearchCaseSensitive(caseSensitive bool) SearchOption {
+	return func(c *searchConfig) {
+		c.caseSensitive = caseSensitive
+	}
+}
+
+// WithSearchMaxResults limits the number of matches returned. Using 0 will
+// not limit the results.
+func WithSearchMaxResults(n int) SearchOption {
+	return func(c *searchConfig) {
+		c.maxResults = n
+	}
+}
+
+// WithSearchContext includes n lines before and after each match in
+// SearchMatch.Context.
+func WithSearchContext(n int) SearchOption {
+	return func(c *searchConfig) {
+		c.contextLines = n
+	}
+}
+
+// findConfig holds configuration for finding files by name.
+type findConfig struct {
+	filesystemConfig
+	fileType     FileType
+	minSize      int64
+	maxSize      int64
+	maxSizeSet   bool
+	newerThan    time.Time
+	maxDepth     int
+	extendedGlob bool
+}
+
+// defaultFindConfig returns the default find configuration.
+func defaultFindConfig() *findConfig {
+	return &findConfig{}
+}
+
+// FindOption configures Files.FindFiles.
+type FindOption func(*findConfig)
+
+// WithFindUser sets the user for the search.
+func WithFindUser(user string) FindOption {
+	return func(c *findConfig) {
+		c.user = user
+	}
+}
+
+// WithFindRequestTimeout sets the request timeout for the search.
+func WithFindRequestTimeout(d time.Duration) FindOption {
+	return func(c *findConfig) {
+		c.requestTimeout = d
+	}
+}
+
+// WithFindType only returns entries of the given type. Symbolic links are
+// returned by the type of their target.
+func WithFindType(fileType FileType) FindOption {
+	return func(c *findConfig) {
+		c.fileType = fileType
+	}
+}
+
+// WithFindMinSize only returns entries of at least the given size in bytes.
+func WithFindMinSize(bytes int64) FindOption {
+	return func(c *findConfig) {
+		c.minSize = bytes
+	}
+}
+
+// WithFindMaxSize only returns entries of at most the given size in bytes.
+func WithFindMaxSize(bytes int64) FindOption {
+	return func(c *findConfig) {
+		c.maxSize = bytes
+		c.maxSizeSet = true
+	}
+}
+
+// WithFindNewerThan only returns entries modified after t.
+func WithFindNewerThan(t time.Time) FindOption {
+	return func(c *findConfig) {
+		c.newerThan = t
+	}
+}
+
+// WithFindMaxDepth limits the search to n levels below the root, 1 being
+// its direct children. Using 0 will not limit the depth.
+func WithFindMaxDepth(n int) FindOption {
+	return func(c *findConfig) {
+		c.maxDepth = n
+	}
+}
+
+// WithFindExtendedGlob allows "**" and "/" in the name pattern of
+// Files.FindFiles, to match paths relative to the root.
+func WithFindExtendedGlob(extended bool) FindOption {
+	return func(c *findConfig) {
+		c.extendedGlob = extended
+	}
+}
+
+// tempConfig holds configuration for temporary files and directories.
+type tempConfig struct {
+	filesystemConfig
+	dir    string
+	prefix string
+}
+
+// defaultTempConfig returns the default temporary file configuration.
+func defaultTempConfig() *tempConfig {
+	return &tempConfig{dir: DefaultTempDir, prefix: "tmp."}
+}
+
+// TempOption configures the creation and removal of temporary files and
+// directories.
+type TempOption func(*tempConfig)
+
+// WithTempUser sets the user owning the temporary file or directory.
+func WithTempUser(user string) TempOption {
+	return func(c *tempConfig) {
+		c.user = user
+	}
+}
+
+// WithTempRequestTimeout sets the request timeout for the operation.
+func WithTempRequestTimeout(d time.Duration) TempOption {
+	return func(c *tempConfig) {
+		c.requestTimeout = d
+	}
+}
+
+// WithTempDir sets the directory the temporary file or directory is created
+// in. Default is DefaultTempDir.
+func WithTempDir(dir string) TempOption {
+	return func(c *tempConfig) {
+		c.dir = dir
+	}
+}
+
+// WithTempPrefix sets the prefix of the temporary file or directory name.
+// Default is "tmp.".
+func WithTempPrefix(prefix string) TempOption {
+	return func(c *tempConfig) {
+		c.prefix = prefix
+	}
+}
+
+// touchConfig holds configuration for touching files.
+type touchConfig struct {
+	filesystemConfig
+	time     time.Time
+	noCreate bool
+	mkdirAll bool
+}
+
+// defaultTouchConfig returns the default touch configuration.
+func defaultTouchConfig() *touchConfig {
+	return &touchConfig{}
+}
+
+// TouchOption configures a touch operation.
+type TouchOption func(*touchConfig)
+
+// WithTouchUser sets the user for the touch operation.
+func WithTouchUser(user string) TouchOption {
+	return func(c *touchConfig) {
+		c.user = user
+	}
+}
+
+// WithTouchRequestTimeout sets the request timeout for the touch operation.
+func WithTouchRequestTimeout(d time.Duration) TouchOption {
+	return func(c *touchConfig) {
+		c.requestTimeout = d
+	}
+}
+
+// WithTouchTime sets the access and modification time to t instead of the
+// current time.
+func WithTouchTime(t time.Time) TouchOption {
+	return func(c *touchConfig) {
+		c.time = t
+	}
+}
+
+// WithTouchNoCreate only updates the timestamps of an existing file, like
+// touch -c. A missing file is not created. Default is false.
+func WithTouchNoCreate(noCreate bool) TouchOption {
+	return func(c *touchConfig) {
+		c.noCreate = noCreate
+	}
+}
+
+// WithTouchMkdirAll creates missing parent directories before creating the
+// file. Default is false.
+func WithTouchMkdirAll(mkdirAll bool) TouchOption {
+	return func(c *touchConfig) {
+		c.mkdirAll = mkdirAll
+	}
+}
+
+// diskUsageConfig holds configuration for a disk usage operation.
+type diskUsageConfig struct {
+	filesystemConfig
+	maxDepth int
+}
+
+// defaultDiskUsageConfig returns the default disk usage configuration.
+func defaultDiskUsageConfig() *diskUsageConfig {
+	return &diskUsageConfig{}
+}
+
+// DiskUsageOption configures a disk usage operation.
+type DiskUsageOption func(*diskUsageConfig)
+
+// WithDiskUsageUser sets the user for the disk usage operation.
+func WithDiskUsageUser(user string) DiskUsageOption {
+	return func(c *diskUsageConfig) {
+		c.user = user
+	}
+}
+
+// WithDiskUsageRequestTimeout sets the request timeout for the disk usage
+// operation.
+func WithDiskUsageRequestTimeout(d time.Duration) DiskUsageOption {
+	return func(c *diskUsageConfig) {
+		c.requestTimeout = d
+	}
+}
+
+// WithDiskUsageMaxDepth also reports the usage of each subdirectory up to n
+// levels below the path in DiskUsageInfo.Subdirectories. Default is 0,
+// which only reports the total.
+func WithDiskUsageMaxDepth(n int) DiskUsageOption {
+	return func(c *diskUsageConfig) {
+		c.maxDepth = n
+	}
+}
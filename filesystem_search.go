@@ -0,0 +1,232 @@
+package e2b
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// errSearchLimitReached stops reading grep output once enough matches have
+// been collected.
+var errSearchLimitReached = errors.New("search result limit reached")
+
+// SearchContent searches the contents of the file or directory at path for
+// lines matching pattern, descending into subdirectories, and returns the
+// matching lines.
+//
+// The pattern is matched literally unless WithSearchRegex is set, and case
+// sensitively unless WithSearchCaseSensitive(false) is set. Binary files
+// and files the user cannot read are skipped. Matches are returned in the
+// order they are found; an empty slice is returned if nothing matches and
+// an error wrapping ErrNotFound if path does not exist.
+//
+// envd has no search RPC, so the search runs grep in the sandbox. Its
+// output is parsed as it streams in, and the search stops early once
+// WithSearchMaxResults matches have been collected.
+//
+// Example:
+//
+//	matches, err := sandbox.Files.SearchContent(ctx, "/home/user/project", "TODO",
+//	    e2b.WithSearchCaseSensitive(false),
+//	    e2b.WithSearchMaxResults(100),
+//	)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, m := range matches {
+//	    fmt.Printf("%s:%d: %s\n", m.Path, m.LineNumber, m.Line)
+//	}
+func (fs *Filesystem) SearchContent(ctx context.Context, path, pattern string, opts ...SearchOption) ([]*SearchMatch, error) {
+	if path == "" || pattern == "" {
+		return nil, fmt.Errorf("%w: path and pattern are required", ErrInvalidArgument)
+	}
+
+	cfg := defaultSearchConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if cfg.maxResults < 0 {
+		return nil, fmt.Errorf("%w: max results must not be negative", ErrInvalidArgument)
+	}
+	if cfg.contextLines < 0 {
+		return nil, fmt.Errorf("%w: context lines must not be negative", ErrInvalidArgument)
+	}
+
+	expr := regexp.QuoteMeta(pattern)
+	if cfg.regex {
+		expr = pattern
+	}
+	if !cfg.caseSensitive {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid search pattern %q: %v", ErrInvalidArgument, pattern, err)
+	}
+
+	collector := &searchCollector{re: re, contextLines: cfg.contextLines, maxResults: cfg.maxResults}
+	script := fmt.Sprintf("%s && %s", shellRequireExists(path), searchCommand(path, pattern, cfg))
+	err = fs.runShellToWriter(ctx, script, collector, &cfg.filesystemConfig)
+
+	var exitErr *CommandExitError
+	switch {
+	case err == nil, errors.Is(err, errSearchLimitReached):
+	case errors.As(err, &exitErr) && exitErr.ExitCode == 1:
+		// grep exits with 1 when nothing matched
+	case errors.As(err, &exitErr) && exitErr.ExitCode == 2 && exitErr.Stderr == "":
+		// Unreadable files are skipped silently but still fail the exit code
+	default:
+		return nil, err
+	}
+
+	return collector.results(), nil
+}
+
+// searchCommand returns the grep command searching path for pattern. Each
+// line it prints is the file name, a NUL byte, the line number, ':' for a
+// matching line or '-' for a context line, and the line itself.
+func searchCommand(path, pattern string, cfg *searchConfig) string {
+	cmd := "grep -r -n -H -Z -I -s"
+	if cfg.regex {
+		cmd += " -E"
+	} else {
+		cmd += " -F"
+	}
+	if !cfg.caseSensitive {
+		cmd += " -i"
+	}
+	if cfg.maxResults > 0 {
+		// -m limits matches per file; the total is limited while reading
+		cmd += fmt.Sprintf(" -m %d", cfg.maxResults)
+	}
+	if cfg.contextLines > 0 {
+		cmd += fmt.Sprintf(" -C %d", cfg.contextLines)
+	}
+	return fmt.Sprintf("%s -e %s -- %s", cmd, shellQuote(pattern), shellQuote(path))
+}
+
+// searchLine is a matching or context line printed by grep.
+type searchLine struct {
+	path   string
+	number int
+	text   string
+	match  bool
+}
+
+// searchCollector parses grep output written to it into SearchMatch values.
+// Lines are grouped into runs of consecutive lines of one file so that each
+// match can be given the context lines around it.
+type searchCollector struct {
+	re           *regexp.Regexp
+	contextLines int
+	maxResults   int
+
+	partial []byte
+	group   []searchLine
+	matches []*SearchMatch
+}
+
+// Write implements io.Writer. It returns errSearchLimitReached once
+// maxResults matches have been collected.
+func (c *searchCollector) Write(p []byte) (int, error) {
+	c.partial = append(c.partial, p...)
+	for {
+		i := bytes.IndexByte(c.partial, '\n')
+		if i < 0 {
+			break
+		}
+		c.addLine(c.partial[:i])
+		c.partial = c.partial[i+1:]
+	}
+	if c.limitReached() {
+		return len(p), errSearchLimitReached
+	}
+	return len(p), nil
+}
+
+// addLine parses a line of grep output.
+func (c *searchCollector) addLine(raw []byte) {
+	name, rest, ok := bytes.Cut(raw, []byte{0})
+	if !ok {
+		// "--" separates runs of lines that are not adjacent
+		c.flush()
+		return
+	}
+
+	digits := 0
+	for digits < len(rest) && rest[digits] >= '0' && rest[digits] <= '9' {
+		digits++
+	}
+	if digits == 0 || digits == len(rest) {
+		return
+	}
+	number, err := strconv.Atoi(string(rest[:digits]))
+	if err != nil {
+		return
+	}
+
+	line := searchLine{
+		path:   string(name),
+		number: number,
+		text:   string(rest[digits+1:]),
+		match:  rest[digits] == ':',
+	}
+	if n := len(c.group); n > 0 && (c.group[n-1].path != line.path || c.group[n-1].number+1 != line.number) {
+		c.flush()
+	}
+	c.group = append(c.group, line)
+	if c.contextLines == 0 {
+		c.flush()
+	}
+}
+
+// flush turns the matching lines of the current group into SearchMatch
+// values.
+func (c *searchCollector) flush() {
+	for i, line := range c.group {
+		if !line.match || c.limitReached() {
+			continue
+		}
+
+		match := &SearchMatch{
+			Path:       line.path,
+			Line:       line.text,
+			LineNumber: line.number,
+		}
+		if loc := c.re.FindStringIndex(line.text); loc != nil {
+			match.Column = loc[0] + 1
+		}
+		if c.contextLines > 0 {
+			start := max(0, i-c.contextLines)
+			end := min(len(c.group), i+c.contextLines+1)
+			match.ContextStart = c.group[start].number
+			for _, l := range c.group[start:end] {
+				match.Context = append(match.Context, l.text)
+			}
+		}
+		c.matches = append(c.matches, match)
+	}
+	c.group = c.group[:0]
+}
+
+// limitReached reports whether maxResults matches have been collected.
+func (c *searchCollector) limitReached() bool {
+	return c.maxResults > 0 && len(c.matches) >= c.maxResults
+}
+
+// results returns the collected matches after the output ended.
+func (c *searchCollector) results() []*SearchMatch {
+	if len(c.partial) > 0 {
+		c.addLine(c.partial)
+		c.partial = nil
+	}
+	c.flush()
+	if c.matches == nil {
+		return []*SearchMatch{}
+	}
+	return c.matches
+}
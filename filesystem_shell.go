@@ -0,0 +1,639 @@
+package e2b
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"connectrpc.com/connect"
+)
+
+// Exit codes used by shell-backed filesystem operations to report
+// well-known failures.
+const (
+	shellExitNotFound = 64
+	shellExitExists   = 65
+)
+
+// runShell runs a shell script for filesystem operations that envd has no
+// RPC for. Well-known exit codes are mapped to ErrNotFound and
+// ErrInvalidArgument, other failures are returned as *CommandExitError.
+func (fs *Filesystem) runShell(ctx context.Context, script string, cfg *filesystemConfig) (*CommandResult, error) {
+	ctx, cancel := fs.applyTimeout(ctx, cfg.requestTimeout)
+	defer cancel()
+
+	user := fs.userOrDefault(cfg.user)
+	var opts []CommandOption
+	if user != "" {
+		opts = append(opts, WithCommandUser(user))
+	}
+
+	result, err := fs.sandbox.Commands.Run(ctx, script, opts...)
+	if err != nil {
+		return nil, mapShellError(err)
+	}
+
+	return result, nil
+}
+
+// runShellToWriter runs a shell script like runShell, copying its stdout to
+// w as it arrives instead of buffering it.
+func (fs *Filesystem) runShellToWriter(ctx context.Context, script string, w io.Writer, cfg *filesystemConfig) error {
+	if cfg.requestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.requestTimeout)
+		defer cancel()
+	}
+
+	commandCfg := defaultCommandConfig()
+	commandCfg.user = fs.userOrDefault(cfg.user)
+	return mapShellError(fs.sandbox.Commands.runToWriter(ctx, script, w, commandCfg))
+}
+
+// mapShellError maps the well-known exit codes of shell-backed operations
+// to ErrNotFound and ErrInvalidArgument.
+func mapShellError(err error) error {
+	var exitErr *CommandExitError
+	if !errors.As(err, &exitErr) {
+		return err
+	}
+	message := strings.TrimSpace(exitErr.Stderr)
+	switch exitErr.ExitCode {
+	case shellExitNotFound:
+		return fmt.Errorf("%w: %s", ErrNotFound, message)
+	case shellExitExists:
+		return fmt.Errorf("%w: %s", ErrInvalidArgument, message)
+	}
+	return err
+}
+
+// shellRequireExists returns a shell snippet that fails with shellExitNotFound
+// if p does not exist. Dangling symbolic links count as existing.
+func shellRequireExists(p string) string {
+	q := shellQuote(p)
+	return fmt.Sprintf("{ [ -e %s ] || [ -L %s ]; } || { echo %s >&2; exit %d; }",
+		q, q, shellQuote("no such file or directory: "+p), shellExitNotFound)
+}
+
+// tempSiblingPath returns a hidden, randomly named path next to p for
+// staging content before it is moved or appended into place.
+func tempSiblingPath(p, tag string) string {
+	suffix := make([]byte, 8)
+	_, _ = rand.Read(suffix)
+	dir, name := path.Split(p)
+	return dir + "." + name + "." + tag + "-" + hex.EncodeToString(suffix)
+}
+
+// Append appends data to the end of a file, creating the file and any
+// missing parent directories if it does not exist. Like Write, data can be
+// a string, []byte or io.Reader.
+//
+// The data is uploaded to a temporary file next to path and then appended
+// inside the sandbox under an exclusive flock(1) lock on the file, so
+// concurrent appends do not interleave or overwrite each other. If flock is
+// not installed in the sandbox, the data is appended without the lock and
+// large concurrent appends may interleave. The order in which concurrent
+// callers' data lands in the file is not guaranteed. The temporary file is
+// removed on failure, even if ctx was cancelled.
+//
+// Example:
+//
+//	_, err := sandbox.Files.Append(ctx, "/home/user/app.log", "started\n")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (fs *Filesystem) Append(ctx context.Context, filePath string, data any, opts ...WriteOption) (*WriteInfo, error) {
+	cfg := defaultWriteConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	ctx, cancel := fs.applyTimeout(ctx, cfg.requestTimeout)
+	defer cancel()
+
+	tmpPath := tempSiblingPath(filePath, "append")
+	removeTemp := func() {
+		_ = fs.Remove(context.WithoutCancel(ctx), tmpPath, WithUser(cfg.user), WithFilesystemRequestTimeout(cfg.requestTimeout))
+	}
+	if _, err := fs.Write(ctx, tmpPath, data, opts...); err != nil {
+		removeTemp()
+		return nil, err
+	}
+
+	// Hold an exclusive lock on the target where flock is available so large
+	// appends from concurrent callers are not interleaved
+	script := fmt.Sprintf("{ exec 9>>%s && { ! command -v flock >/dev/null || flock 9; } && cat %s >&9; }; status=$?; rm -f %s; exit $status",
+		shellQuote(filePath), shellQuote(tmpPath), shellQuote(tmpPath))
+	if _, err := fs.runShell(ctx, script, &cfg.filesystemConfig); err != nil {
+		removeTemp()
+		return nil, err
+	}
+
+	return &WriteInfo{Name: path.Base(filePath), Type: FileTypeFile, Path: filePath}, nil
+}
+
+// Truncate changes the size of a file to size bytes, like truncate(2). A
+// size of 0 empties the file, and a size larger than the file extends it
+// with null bytes. A missing file is created with the given size, but its
+// parent directory must exist; otherwise an error wrapping ErrNotFound is
+// returned. Truncating a directory or passing a negative size returns an
+// error wrapping ErrInvalidArgument.
+//
+// Example:
+//
+//	// Pre-allocate a 10 MiB log file
+//	if err := sandbox.Files.Truncate(ctx, "/home/user/app.log", 10<<20); err != nil {
+//	    log.Fatal(err)
+//	}
+func (fs *Filesystem) Truncate(ctx context.Context, filePath string, size int64, opts ...FilesystemOption) error {
+	if filePath == "" {
+		return fmt.Errorf("%w: path is required", ErrInvalidArgument)
+	}
+	if size < 0 {
+		return fmt.Errorf("%w: size must not be negative", ErrInvalidArgument)
+	}
+
+	cfg := defaultFilesystemConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	q := shellQuote(filePath)
+	script := fmt.Sprintf("%s && { [ ! -d %s ] || { echo %s >&2; exit %d; }; } && truncate -s %d -- %s",
+		shellRequireExists(path.Dir(filePath)), q, shellQuote("is a directory: "+filePath), shellExitExists, size, q)
+	_, err := fs.runShell(ctx, script, cfg)
+	return err
+}
+
+// Touch creates an empty file at filePath if it does not exist and sets the
+// access and modification times of the file to the current time, like
+// touch(1), returning information about the file.
+//
+// WithTouchTime sets a specific time instead. With WithTouchNoCreate, a
+// missing file is not created and an error wrapping ErrNotFound is
+// returned. The parent directory must exist, and an error wrapping
+// ErrNotFound is returned otherwise, unless WithTouchMkdirAll is set to
+// create it.
+//
+// Example:
+//
+//	// Mark the build output as stale
+//	_, err := sandbox.Files.Touch(ctx, "/home/user/project/src/main.c",
+//	    e2b.WithTouchNoCreate(true),
+//	)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (fs *Filesystem) Touch(ctx context.Context, filePath string, opts ...TouchOption) (*EntryInfo, error) {
+	if filePath == "" {
+		return nil, fmt.Errorf("%w: path is required", ErrInvalidArgument)
+	}
+
+	cfg := defaultTouchConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	var script []string
+	switch {
+	case cfg.noCreate:
+		script = append(script, shellRequireExists(filePath))
+	case cfg.mkdirAll:
+		script = append(script, "mkdir -p -- "+shellQuote(path.Dir(filePath)))
+	default:
+		script = append(script, shellRequireExists(path.Dir(filePath)))
+	}
+
+	touch := "touch"
+	if !cfg.time.IsZero() {
+		touch += " -d " + shellQuote(cfg.time.UTC().Format("2006-01-02 15:04:05.999999999 -0700"))
+	}
+	script = append(script, touch+" -- "+shellQuote(filePath))
+
+	if _, err := fs.runShell(ctx, strings.Join(script, " && "), &cfg.filesystemConfig); err != nil {
+		return nil, err
+	}
+
+	return fs.GetInfo(ctx, filePath, WithUser(cfg.user), WithFilesystemRequestTimeout(cfg.requestTimeout))
+}
+
+// Copy copies a file or directory within the sandbox and returns
+// information about the destination.
+//
+// Directories are copied recursively unless WithFilesCopyNonRecursive is set.
+// Missing parent directories of dst are created. If dst exists, it is
+// overwritten unless WithFilesCopyOverwrite(false) is set; a directory src
+// is merged into a directory dst instead of being copied into it, so files
+// that exist only in dst are kept.
+// Symbolic links are resolved unless WithFilesCopyResolveSymlinks(false) is
+// set, in which case the links themselves are copied. WithFilesCopyUser sets
+// the user performing the copy and WithFilesCopyMode the mode of the copied
+// entry.
+// If src does not exist, an error wrapping ErrNotFound is returned.
+//
+// The copy runs as a command in the sandbox. If command execution is not
+// permitted, a single file is instead copied by downloading and re-uploading
+// it through the file API, without preserving its mode or timestamps; copying
+// a directory or setting a mode then returns an error wrapping
+// ErrInvalidArgument.
+//
+// Example:
+//
+//	info, err := sandbox.Files.Copy(ctx, "/home/user/project", "/home/user/project-backup")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (fs *Filesystem) Copy(ctx context.Context, src, dst string, opts ...FilesCopyOption) (*EntryInfo, error) {
+	if src == "" || dst == "" {
+		return nil, fmt.Errorf("%w: source and destination are required", ErrInvalidArgument)
+	}
+
+	cfg := defaultFileCopyConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	flags := "-T -p"
+	if !cfg.nonRecursive {
+		flags += " -R"
+	}
+	if cfg.resolveSymlinks {
+		flags += " -L"
+	} else {
+		flags += " -P"
+	}
+
+	script := []string{shellRequireExists(src)}
+	if !cfg.overwrite {
+		q := shellQuote(dst)
+		script = append(script, fmt.Sprintf("{ [ ! -e %s ] && [ ! -L %s ]; } || { echo %s >&2; exit %d; }",
+			q, q, shellQuote("destination already exists: "+dst), shellExitExists))
+	}
+	script = append(script,
+		fmt.Sprintf("mkdir -p -- %s", shellQuote(path.Dir(dst))),
+		fmt.Sprintf("cp %s -- %s %s", flags, shellQuote(src), shellQuote(dst)),
+	)
+	if cfg.mode != 0 {
+		script = append(script, fmt.Sprintf("chmod %04o -- %s", unixMode(cfg.mode), shellQuote(dst)))
+	}
+
+	if _, err := fs.runShell(ctx, strings.Join(script, " && "), &cfg.filesystemConfig); err != nil {
+		if !isCommandsUnavailable(err) {
+			return nil, err
+		}
+		return fs.copyFileHTTP(ctx, src, dst, cfg)
+	}
+
+	return fs.GetInfo(ctx, dst, WithUser(cfg.user), WithFilesystemRequestTimeout(cfg.requestTimeout))
+}
+
+// copyFileHTTP copies a single file by streaming it through the file API.
+// It is used when commands cannot be run in the sandbox.
+func (fs *Filesystem) copyFileHTTP(ctx context.Context, src, dst string, cfg *fileCopyConfig) (*EntryInfo, error) {
+	ctx, cancel := fs.applyTimeout(ctx, cfg.requestTimeout)
+	defer cancel()
+
+	infoOpts := []FilesystemOption{WithUser(cfg.user), WithFilesystemRequestTimeout(cfg.requestTimeout)}
+	info, err := fs.GetInfo(ctx, src, infoOpts...)
+	if err != nil {
+		return nil, err
+	}
+	if info.Type == FileTypeDir {
+		return nil, fmt.Errorf("%w: copying directory %s requires command execution in the sandbox", ErrInvalidArgument, src)
+	}
+	if cfg.mode != 0 {
+		return nil, fmt.Errorf("%w: setting the mode of a copy requires command execution in the sandbox", ErrInvalidArgument)
+	}
+	if !cfg.overwrite {
+		exists, err := fs.Exists(ctx, dst, infoOpts...)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, fmt.Errorf("%w: destination already exists: %s", ErrInvalidArgument, dst)
+		}
+	}
+
+	r, err := fs.ReadStream(ctx, src, WithReadUser(cfg.user), WithReadRequestTimeout(cfg.requestTimeout))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	if _, err := fs.Upload(ctx, dst, r, WithWriteUser(cfg.user), WithWriteRequestTimeout(cfg.requestTimeout)); err != nil {
+		return nil, err
+	}
+
+	return fs.GetInfo(ctx, dst, infoOpts...)
+}
+
+// isCommandsUnavailable reports whether err shows that the sandbox refuses to
+// run commands, as opposed to a command that ran and failed.
+func isCommandsUnavailable(err error) bool {
+	var connectErr *connect.Error
+	if !errors.As(err, &connectErr) {
+		return false
+	}
+	code := connectErr.Code()
+	return code == connect.CodeUnimplemented || code == connect.CodePermissionDenied
+}
+
+// Chmod changes the permission bits of a file or directory.
+//
+// The permission bits of mode are applied along with the setuid, setgid and
+// sticky bits; other mode bits are ignored. Use WithChmodRecursive to apply
+// the mode to everything under a directory. If path does not exist, an error
+// wrapping ErrNotFound is returned.
+//
+// Example:
+//
+//	if _, err := sandbox.Files.Write(ctx, "/home/user/run.sh", script); err != nil {
+//	    log.Fatal(err)
+//	}
+//	if err := sandbox.Files.Chmod(ctx, "/home/user/run.sh", 0o755); err != nil {
+//	    log.Fatal(err)
+//	}
+func (fs *Filesystem) Chmod(ctx context.Context, filePath string, mode os.FileMode, opts ...ChmodOption) error {
+	if filePath == "" {
+		return fmt.Errorf("%w: path is required", ErrInvalidArgument)
+	}
+
+	cfg := defaultChmodConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	flags := ""
+	if cfg.recursive {
+		flags = "-R "
+	}
+	script := fmt.Sprintf("%s && chmod %s%04o -- %s", shellRequireExists(filePath), flags, unixMode(mode), shellQuote(filePath))
+
+	_, err := fs.runShell(ctx, script, &cfg.filesystemConfig)
+	return err
+}
+
+// Chown changes the owner and group of a file or directory.
+//
+// owner and group are user and group names, resolved in the sandbox, or
+// numeric IDs such as "1000". An empty owner or group leaves it unchanged,
+// but at least one must be set. Names may only contain letters, digits, '.',
+// '_' and '-'. Use WithChownRecursive to apply the ownership to everything
+// under a directory. If path does not exist, an error wrapping ErrNotFound is
+// returned.
+//
+// Changing ownership usually requires root, so pass WithChownUser("root")
+// unless the sandbox's default user is privileged.
+//
+// Example:
+//
+//	err := sandbox.Files.Chown(ctx, "/srv/app", "www-data", "www-data",
+//	    e2b.WithChownUser("root"),
+//	    e2b.WithChownRecursive(true),
+//	)
+func (fs *Filesystem) Chown(ctx context.Context, filePath, owner, group string, opts ...ChownOption) error {
+	if filePath == "" {
+		return fmt.Errorf("%w: path is required", ErrInvalidArgument)
+	}
+	if owner == "" && group == "" {
+		return fmt.Errorf("%w: owner or group is required", ErrInvalidArgument)
+	}
+	for _, name := range []string{owner, group} {
+		if name != "" && !isValidAccountName(name) {
+			return fmt.Errorf("%w: invalid user or group name %q", ErrInvalidArgument, name)
+		}
+	}
+
+	cfg := defaultChownConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	spec := owner
+	if group != "" {
+		spec += ":" + group
+	}
+	flags := ""
+	if cfg.recursive {
+		flags = "-R "
+	}
+	script := fmt.Sprintf("%s && chown %s%s -- %s", shellRequireExists(filePath), flags, spec, shellQuote(filePath))
+
+	_, err := fs.runShell(ctx, script, &cfg.filesystemConfig)
+	return err
+}
+
+// isValidAccountName reports whether name is a user or group name or a
+// numeric ID that is safe to pass to chown unquoted.
+func isValidAccountName(name string) bool {
+	if name == "" || name[0] == '-' {
+		return false
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '.' || r == '_' || r == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Symlink creates a symbolic link at linkPath pointing to target and returns
+// information about the link.
+//
+// target may be absolute or relative; a relative target is resolved against
+// the directory containing the link when the link is followed, and it does
+// not need to exist. If linkPath already exists, an error wrapping
+// ErrInvalidArgument is returned unless WithSymlinkForce(true) is set.
+//
+// Example:
+//
+//	info, err := sandbox.Files.Symlink(ctx, "releases/v2", "/srv/app/current",
+//	    e2b.WithSymlinkForce(true),
+//	)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Println(*info.SymlinkTarget)
+func (fs *Filesystem) Symlink(ctx context.Context, target, linkPath string, opts ...SymlinkOption) (*EntryInfo, error) {
+	if target == "" || linkPath == "" {
+		return nil, fmt.Errorf("%w: target and link path are required", ErrInvalidArgument)
+	}
+
+	cfg := defaultSymlinkConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	q := shellQuote(linkPath)
+	flags := "-s -T"
+	if cfg.force {
+		flags += " -f"
+	}
+	script := fmt.Sprintf("ln %s -- %s %s", flags, shellQuote(target), q)
+	if !cfg.force {
+		script = fmt.Sprintf("{ { [ ! -e %s ] && [ ! -L %s ]; } || { echo %s >&2; exit %d; }; } && %s",
+			q, q, shellQuote("link path already exists: "+linkPath), shellExitExists, script)
+	}
+	if _, err := fs.runShell(ctx, script, &cfg.filesystemConfig); err != nil {
+		return nil, err
+	}
+
+	info, err := fs.GetInfo(ctx, linkPath, WithUser(cfg.user), WithFilesystemRequestTimeout(cfg.requestTimeout))
+	if err != nil {
+		return nil, err
+	}
+	if info.SymlinkTarget == nil {
+		info.SymlinkTarget = &target
+	}
+
+	return info, nil
+}
+
+// HardLink creates a hard link at newPath to the file at existingPath and
+// returns information about the new link. Both paths then refer to the same
+// content, so files can be deduplicated without copying them.
+//
+// If existingPath does not exist, an error wrapping ErrNotFound is
+// returned. Linux does not support hard links to directories, or across
+// filesystems; both return an error wrapping ErrInvalidArgument. If newPath
+// already exists, an error wrapping ErrInvalidArgument is returned unless
+// WithHardLinkForce(true) is set.
+//
+// envd has no hard link RPC, so the link is created with ln in the sandbox.
+//
+// Example:
+//
+//	info, err := sandbox.Files.HardLink(ctx, "/data/model.bin", "/home/user/model.bin",
+//	    e2b.WithHardLinkForce(true),
+//	)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (fs *Filesystem) HardLink(ctx context.Context, existingPath, newPath string, opts ...HardLinkOption) (*EntryInfo, error) {
+	if existingPath == "" || newPath == "" {
+		return nil, fmt.Errorf("%w: existing path and new path are required", ErrInvalidArgument)
+	}
+
+	cfg := defaultHardLinkConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	qOld, qNew := shellQuote(existingPath), shellQuote(newPath)
+	flags := "-T"
+	if cfg.force {
+		flags += " -f"
+	}
+	script := fmt.Sprintf("%s && { [ ! -d %s ] || { echo %s >&2; exit %d; }; }",
+		shellRequireExists(existingPath), qOld, shellQuote("cannot hard link a directory: "+existingPath), shellExitExists)
+	if !cfg.force {
+		script += fmt.Sprintf(" && { { [ ! -e %s ] && [ ! -L %s ]; } || { echo %s >&2; exit %d; }; }",
+			qNew, qNew, shellQuote("new path already exists: "+newPath), shellExitExists)
+	}
+	// ln reports EXDEV as "Invalid cross-device link"
+	script += fmt.Sprintf(" && { out=$(ln %s -- %s %s 2>&1) || { case \"$out\" in "+
+		"*cross-device*) echo %s >&2; exit %d;; *) echo \"$out\" >&2; exit 1;; esac; }; }",
+		flags, qOld, qNew, shellQuote("cannot hard link across filesystems: "+existingPath+" -> "+newPath), shellExitExists)
+	if _, err := fs.runShell(ctx, script, &cfg.filesystemConfig); err != nil {
+		return nil, err
+	}
+
+	return fs.GetInfo(ctx, newPath, WithUser(cfg.user), WithFilesystemRequestTimeout(cfg.requestTimeout))
+}
+
+// checksumDigestSizes maps supported checksum algorithms to the length of
+// their hex-encoded digests.
+var checksumDigestSizes = map[string]int{
+	"md5":    32,
+	"sha256": 64,
+	"sha512": 128,
+}
+
+// GetChecksum returns the hex-encoded hash of a file's content, computed
+// inside the sandbox so the file is never transferred.
+//
+// The algorithm is "sha256" unless WithChecksumAlgorithm selects "sha512" or
+// "md5". envd has no checksum RPC, so the hash is computed with the
+// coreutils sha256sum, sha512sum or md5sum command. If path does not exist,
+// an error wrapping ErrNotFound is returned; a path that is not a regular
+// file returns an error wrapping ErrInvalidArgument.
+//
+// Example:
+//
+//	sum, err := sandbox.Files.GetChecksum(ctx, "/home/user/build.sh")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if sum != expectedSHA256 {
+//	    log.Fatal("build script was modified")
+//	}
+func (fs *Filesystem) GetChecksum(ctx context.Context, filePath string, opts ...ChecksumOption) (string, error) {
+	if filePath == "" {
+		return "", fmt.Errorf("%w: path is required", ErrInvalidArgument)
+	}
+
+	cfg := defaultChecksumConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	algorithm := strings.ToLower(cfg.algorithm)
+	if _, ok := checksumDigestSizes[algorithm]; !ok {
+		return "", fmt.Errorf("%w: unsupported checksum algorithm %q", ErrInvalidArgument, cfg.algorithm)
+	}
+
+	// Reading from stdin keeps the output free of the file name, which
+	// would otherwise be escaped for names with special characters
+	q := shellQuote(filePath)
+	script := fmt.Sprintf("%s && { [ -f %s ] || { echo %s >&2; exit %d; }; } && %ssum < %s",
+		shellRequireExists(filePath), q, shellQuote("not a regular file: "+filePath), shellExitExists, algorithm, q)
+	result, err := fs.runShell(ctx, script, &cfg.filesystemConfig)
+	if err != nil {
+		return "", err
+	}
+
+	return parseChecksumOutput(result.Stdout, algorithm)
+}
+
+// parseChecksumOutput extracts the digest from the output of a coreutils
+// *sum command.
+func parseChecksumOutput(output, algorithm string) (string, error) {
+	fields := strings.Fields(output)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("empty %s checksum output", algorithm)
+	}
+
+	sum := strings.ToLower(fields[0])
+	if len(sum) != checksumDigestSizes[algorithm] {
+		return "", fmt.Errorf("unexpected %s checksum output: %q", algorithm, output)
+	}
+	if _, err := hex.DecodeString(sum); err != nil {
+		return "", fmt.Errorf("unexpected %s checksum output: %q", algorithm, output)
+	}
+	return sum, nil
+}
+
+// unixMode converts an os.FileMode to Unix permission bits, including the
+// setuid, setgid and sticky bits.
+func unixMode(mode os.FileMode) uint32 {
+	bits := uint32(mode.Perm())
+	if mode&os.ModeSetuid != 0 {
+		bits |= 0o4000
+	}
+	if mode&os.ModeSetgid != 0 {
+		bits |= 0o2000
+	}
+	if mode&os.ModeSticky != 0 {
+		bits |= 0o1000
+	}
+	return bits
+}
@@ -0,0 +1,471 @@
+package e2b
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// syncChecksumWorkers is the maximum number of files compared concurrently
+// by SyncDir.
+const syncChecksumWorkers = 8
+
+// SyncDir makes remotePath in the sandbox mirror the local directory tree at
+// localPath, uploading only files that are missing or whose content differs,
+// and reports what changed.
+//
+// Files of equal size are compared by SHA-256, computed locally and in the
+// sandbox with GetChecksum; if command execution is not permitted, such
+// files are uploaded again. Changed files are uploaded in batches with
+// WriteFiles, and files larger than DefaultUploadBatchSize are streamed in
+// requests of their own. Empty local directories are created as well.
+// Symbolic links to files are followed; links to directories are skipped.
+//
+// Use WithSyncIgnore to leave out paths with gitignore-style patterns and
+// WithSyncDelete to remove sandbox files that do not exist locally.
+// Cancelling ctx stops the sync at the next file. If any file could not be
+// uploaded, the result is returned together with an *UploadDirError listing
+// the failures.
+//
+// Example:
+//
+//	result, err := sandbox.Files.SyncDir(ctx, "./src", "/home/user/src",
+//	    e2b.WithSyncIgnore(".git/", "__pycache__/"),
+//	    e2b.WithSyncDelete(true),
+//	)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("%d added, %d updated, %d deleted\n", result.Added, result.Updated, result.Deleted)
+func (fs *Filesystem) SyncDir(ctx context.Context, localPath, remotePath string, opts ...SyncOption) (*SyncResult, error) {
+	if localPath == "" || remotePath == "" {
+		return nil, fmt.Errorf("%w: local and remote paths are required", ErrInvalidArgument)
+	}
+
+	cfg := defaultSyncConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	rules, err := parseIgnoreRules(cfg.ignore)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read local directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%w: %s is not a directory", ErrInvalidArgument, localPath)
+	}
+
+	remote, root, err := fs.listSyncTarget(ctx, remotePath, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	files, dirs, err := collectSyncFiles(ctx, localPath, root, rules)
+	if err != nil {
+		return nil, err
+	}
+
+	// Files that are missing or differ in size need no checksum
+	result := &SyncResult{}
+	added := make(map[string]bool)
+	var upload, compare []localFile
+	for _, f := range files {
+		entry, ok := remote[f.rel]
+		switch {
+		case !ok || entry.Type != FileTypeFile:
+			added[f.remotePath] = true
+			upload = append(upload, f.localFile)
+		case entry.Size != f.size:
+			upload = append(upload, f.localFile)
+		default:
+			compare = append(compare, f.localFile)
+		}
+	}
+
+	changed, err := fs.changedFiles(ctx, compare, cfg)
+	if err != nil {
+		return nil, err
+	}
+	result.Unchanged = len(compare) - len(changed)
+	upload = append(upload, changed...)
+
+	writeOpts := []WriteOption{WithWriteUser(cfg.user), WithWriteRequestTimeout(cfg.requestTimeout)}
+	failed := make(map[string]error)
+	fs.uploadLocalBatches(ctx, upload, DefaultUploadBatchSize, writeOpts, failed)
+	for _, f := range upload {
+		switch {
+		case failed[f.remotePath] != nil:
+		case added[f.remotePath]:
+			result.Added++
+		default:
+			result.Updated++
+		}
+	}
+
+	// Uploads create parent directories, so only create the others
+	covered := make(map[string]bool)
+	for _, f := range files {
+		for dir := path.Dir(f.rel); dir != "."; dir = path.Dir(dir) {
+			covered[dir] = true
+		}
+	}
+	dirOpts := []FilesystemOption{WithUser(cfg.user), WithFilesystemRequestTimeout(cfg.requestTimeout)}
+	for rel := range dirs {
+		if entry, ok := remote[rel]; covered[rel] || (ok && entry.Type == FileTypeDir) {
+			continue
+		}
+		dir := path.Join(root, rel)
+		if _, err := fs.MakeDir(ctx, dir, dirOpts...); err != nil {
+			failed[dir] = err
+		}
+	}
+
+	if cfg.delete {
+		local := make(map[string]bool, len(files)+len(dirs))
+		for _, f := range files {
+			local[f.rel] = true
+		}
+		for rel := range dirs {
+			local[rel] = true
+		}
+		deleted, err := fs.deleteSyncExtras(ctx, root, remote, local, rules, cfg)
+		result.Deleted = deleted
+		if err != nil {
+			return result, err
+		}
+	}
+
+	if len(failed) > 0 {
+		return result, &UploadDirError{Failed: failed}
+	}
+	return result, nil
+}
+
+// syncFile is a local file to sync, with its slash-separated path relative
+// to the synced directory.
+type syncFile struct {
+	localFile
+	rel string
+}
+
+// listSyncTarget lists the remote directory recursively, keyed by relative
+// path, and returns its canonical path. A missing directory has no entries.
+func (fs *Filesystem) listSyncTarget(ctx context.Context, remotePath string, cfg *syncConfig) (map[string]*EntryInfo, string, error) {
+	remote := make(map[string]*EntryInfo)
+
+	// Entries are listed by their canonical paths, so resolve the root first
+	root, err := fs.GetInfo(ctx, remotePath, WithUser(cfg.user), WithFilesystemRequestTimeout(cfg.requestTimeout))
+	if errors.Is(err, ErrNotFound) {
+		return remote, remotePath, nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if root.Type != FileTypeDir {
+		return nil, "", fmt.Errorf("%w: %s is not a directory", ErrInvalidArgument, remotePath)
+	}
+
+	entries, err := fs.List(ctx, root.Path, WithDepth(globUnlimitedDepth), WithListUser(cfg.user), WithListRequestTimeout(cfg.requestTimeout))
+	if err != nil {
+		return nil, "", err
+	}
+	prefix := strings.TrimSuffix(root.Path, "/") + "/"
+	for _, entry := range entries {
+		if rel, ok := strings.CutPrefix(entry.Path, prefix); ok {
+			remote[rel] = entry
+		}
+	}
+
+	return remote, root.Path, nil
+}
+
+// collectSyncFiles walks localPath and returns the regular files to sync and
+// the relative paths of the directories, leaving out ignored paths.
+func collectSyncFiles(ctx context.Context, localPath, remoteRoot string, rules []ignoreRule) ([]syncFile, map[string]bool, error) {
+	var files []syncFile
+	dirs := make(map[string]bool)
+
+	err := filepath.WalkDir(localPath, func(p string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", p, err)
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if p == localPath {
+			return nil
+		}
+
+		rel, err := filepath.Rel(localPath, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		info, err := entry.Info()
+		if err != nil {
+			return fmt.Errorf("failed to stat %s: %w", p, err)
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			if info, err = os.Stat(p); err != nil {
+				return fmt.Errorf("failed to resolve %s: %w", p, err)
+			}
+			if info.IsDir() {
+				return nil
+			}
+		}
+
+		if matchIgnoreRules(rules, rel, info.IsDir()) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		switch {
+		case entry.IsDir():
+			dirs[rel] = true
+		case info.Mode().IsRegular():
+			files = append(files, syncFile{
+				localFile: localFile{localPath: p, remotePath: path.Join(remoteRoot, rel), size: info.Size()},
+				rel:       rel,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return files, dirs, nil
+}
+
+// changedFiles returns the files whose content differs from the sandbox
+// copy, comparing SHA-256 checksums concurrently.
+func (fs *Filesystem) changedFiles(ctx context.Context, files []localFile, cfg *syncConfig) ([]localFile, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex
+	var changed []localFile
+	var firstErr error
+	fail := func(err error) {
+		mu.Lock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+		mu.Unlock()
+	}
+
+	jobs := make(chan localFile)
+	var wg sync.WaitGroup
+	for range min(syncChecksumWorkers, len(files)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for f := range jobs {
+				same, err := fs.sameContent(ctx, f, cfg)
+				if err != nil {
+					fail(err)
+					continue
+				}
+				if !same {
+					mu.Lock()
+					changed = append(changed, f)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for _, f := range files {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- f
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr == nil {
+		firstErr = ctx.Err()
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	// Keep the upload order stable
+	sort.Slice(changed, func(i, j int) bool {
+		return changed[i].remotePath < changed[j].remotePath
+	})
+	return changed, nil
+}
+
+// sameContent reports whether a local file has the same SHA-256 checksum as
+// its sandbox copy. Without command execution, files are assumed to differ.
+func (fs *Filesystem) sameContent(ctx context.Context, f localFile, cfg *syncConfig) (bool, error) {
+	remote, err := fs.GetChecksum(ctx, f.remotePath, WithChecksumUser(cfg.user), WithChecksumRequestTimeout(cfg.requestTimeout))
+	if isCommandsUnavailable(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	local, err := hashLocalFile(f.localPath)
+	if err != nil {
+		return false, err
+	}
+	return local == remote, nil
+}
+
+// hashLocalFile returns the hex-encoded SHA-256 checksum of a local file.
+func hashLocalFile(p string) (string, error) {
+	file, err := os.Open(p)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", p, err)
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", p, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// deleteSyncExtras removes the remote entries that do not exist locally and
+// are not ignored, and returns the number of files removed, including files
+// inside removed directories.
+func (fs *Filesystem) deleteSyncExtras(ctx context.Context, root string, remote map[string]*EntryInfo, local map[string]bool, rules []ignoreRule, cfg *syncConfig) (int, error) {
+	rels := make([]string, 0, len(remote))
+	for rel := range remote {
+		rels = append(rels, rel)
+	}
+	// Parents sort before their children
+	sort.Strings(rels)
+
+	opts := []FilesystemOption{WithUser(cfg.user), WithFilesystemRequestTimeout(cfg.requestTimeout)}
+	deleted := 0
+	removed := ""
+	for _, rel := range rels {
+		entry := remote[rel]
+		if removed != "" && strings.HasPrefix(rel, removed+"/") {
+			if entry.Type != FileTypeDir {
+				deleted++
+			}
+			continue
+		}
+		if local[rel] || matchIgnoreRules(rules, rel, entry.Type == FileTypeDir) {
+			continue
+		}
+
+		if err := fs.Remove(ctx, path.Join(root, rel), opts...); err != nil && !errors.Is(err, ErrNotFound) {
+			return deleted, err
+		}
+		if entry.Type == FileTypeDir {
+			removed = rel
+		} else {
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+// ignoreRule is a parsed gitignore-style pattern.
+type ignoreRule struct {
+	segments []string
+	anchored bool
+	dirOnly  bool
+	negate   bool
+}
+
+// parseIgnoreRules parses gitignore-style patterns.
+func parseIgnoreRules(patterns []string) ([]ignoreRule, error) {
+	var rules []ignoreRule
+	for _, pattern := range patterns {
+		p := strings.TrimSpace(pattern)
+		if p == "" || strings.HasPrefix(p, "#") {
+			continue
+		}
+
+		var rule ignoreRule
+		if rest, ok := strings.CutPrefix(p, "!"); ok {
+			rule.negate = true
+			p = rest
+		}
+		if strings.HasSuffix(p, "/") {
+			rule.dirOnly = true
+			p = strings.TrimRight(p, "/")
+		}
+		if strings.Contains(p, "/") {
+			rule.anchored = true
+			p = strings.TrimPrefix(p, "/")
+		}
+		if p == "" {
+			continue
+		}
+
+		rule.segments = strings.Split(p, "/")
+		for _, segment := range rule.segments {
+			if _, err := path.Match(segment, ""); err != nil {
+				return nil, fmt.Errorf("%w: invalid ignore pattern %q", ErrInvalidArgument, pattern)
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// matchIgnoreRules reports whether the slash-separated relative path rel is
+// ignored, either itself or through one of its parent directories. The last
+// matching rule wins, as in gitignore.
+func matchIgnoreRules(rules []ignoreRule, rel string, isDir bool) bool {
+	if len(rules) == 0 {
+		return false
+	}
+
+	segments := strings.Split(rel, "/")
+	for i := 1; i < len(segments); i++ {
+		if matchIgnoreSegments(rules, segments[:i], true) {
+			return true
+		}
+	}
+	return matchIgnoreSegments(rules, segments, isDir)
+}
+
+// matchIgnoreSegments applies the rules to a single path.
+func matchIgnoreSegments(rules []ignoreRule, segments []string, isDir bool) bool {
+	ignored := false
+	for _, rule := range rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+
+		var ok bool
+		if rule.anchored {
+			ok = matchGlobSegments(rule.segments, segments)
+		} else {
+			ok, _ = path.Match(rule.segments[0], segments[len(segments)-1])
+		}
+		if ok {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
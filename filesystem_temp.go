@@ -0,0 +1,120 @@
+package e2b
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// MakeTemp creates a new, empty temporary file in the sandbox and returns
+// its path.
+//
+// The file is created in DefaultTempDir unless WithTempDir is set, with a
+// name made of the WithTempPrefix prefix followed by random characters. It
+// is readable and writable only by the user creating it. If the directory
+// does not exist, an error wrapping ErrNotFound is returned. The file is not
+// removed automatically; remove it with RemoveTemp when done.
+//
+// envd has no temporary file RPC, so the file is created with mktemp in the
+// sandbox.
+//
+// Example:
+//
+//	tmp, err := sandbox.Files.MakeTemp(ctx, e2b.WithTempPrefix("upload-"))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer sandbox.Files.RemoveTemp(ctx, tmp)
+//	if _, err := sandbox.Files.Write(ctx, tmp, data); err != nil {
+//	    log.Fatal(err)
+//	}
+func (fs *Filesystem) MakeTemp(ctx context.Context, opts ...TempOption) (string, error) {
+	return fs.makeTemp(ctx, false, opts)
+}
+
+// MakeTempDir creates a new, empty temporary directory in the sandbox and
+// returns its path. It takes the same options as MakeTemp.
+//
+// Example:
+//
+//	dir, err := sandbox.Files.MakeTempDir(ctx, e2b.WithTempPrefix("build-"))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer sandbox.Files.RemoveTemp(ctx, dir)
+func (fs *Filesystem) MakeTempDir(ctx context.Context, opts ...TempOption) (string, error) {
+	return fs.makeTemp(ctx, true, opts)
+}
+
+// makeTemp creates a temporary file or directory with mktemp.
+func (fs *Filesystem) makeTemp(ctx context.Context, dir bool, opts []TempOption) (string, error) {
+	cfg := defaultTempConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if cfg.dir == "" {
+		return "", fmt.Errorf("%w: temp directory is required", ErrInvalidArgument)
+	}
+	if strings.Contains(cfg.prefix, "/") {
+		return "", fmt.Errorf("%w: temp prefix must not contain '/': %q", ErrInvalidArgument, cfg.prefix)
+	}
+
+	cmd := "mktemp"
+	if dir {
+		cmd += " -d"
+	}
+	template := path.Join(cfg.dir, cfg.prefix+"XXXXXXXXXX")
+	script := fmt.Sprintf("%s && %s -- %s", shellRequireExists(cfg.dir), cmd, shellQuote(template))
+	result, err := fs.runShell(ctx, script, &cfg.filesystemConfig)
+	if err != nil {
+		return "", err
+	}
+
+	created := strings.TrimSpace(result.Stdout)
+	if created == "" {
+		return "", fmt.Errorf("empty mktemp output")
+	}
+	return created, nil
+}
+
+// RemoveTemp removes a temporary file or directory created by MakeTemp or
+// MakeTempDir, including the contents of a directory.
+//
+// To guard against removing the wrong path, p must be inside the temporary
+// directory set with WithTempDir, DefaultTempDir by default; otherwise an
+// error wrapping ErrInvalidArgument is returned and nothing is removed.
+//
+// Example:
+//
+//	dir, err := sandbox.Files.MakeTempDir(ctx, e2b.WithTempDir("/home/user/.cache"))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer sandbox.Files.RemoveTemp(ctx, dir, e2b.WithTempDir("/home/user/.cache"))
+func (fs *Filesystem) RemoveTemp(ctx context.Context, p string, opts ...TempOption) error {
+	cfg := defaultTempConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if !isInsideDir(p, cfg.dir) {
+		return fmt.Errorf("%w: %s is not inside the temp directory %s", ErrInvalidArgument, p, cfg.dir)
+	}
+
+	return fs.Remove(ctx, p, WithUser(cfg.user), WithFilesystemRequestTimeout(cfg.requestTimeout))
+}
+
+// isInsideDir reports whether p is strictly inside dir once both are
+// cleaned.
+func isInsideDir(p, dir string) bool {
+	if p == "" || dir == "" {
+		return false
+	}
+	p, dir = path.Clean(p), path.Clean(dir)
+	if dir == "/" {
+		return p != "/" && path.IsAbs(p)
+	}
+	return strings.HasPrefix(p, dir+"/")
+}
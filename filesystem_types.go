@@ -138,6 +138,10 @@ type WriteInfo struct {
 
 	// Path is the full path to the file.
 	Path string
+
+	// Hash is the hex-encoded SHA-256 digest of the written content, if
+	// envd reports it or the write was made with WriteIfChanged.
+	Hash string
 }
 
 // WriteEntry represents a file to be written.
@@ -156,6 +160,10 @@ type FilesystemEvent struct {
 
 	// Type is the type of event that occurred.
 	Type EventType
+
+	// Content is the content of the file for the initial event of
+	// WatchFile with WithWatchFileInitialRead. It is nil for other events.
+	Content []byte
 }
 
 // filesystemEventFromProto converts a protobuf FilesystemEvent to our FilesystemEvent.
@@ -181,6 +189,17 @@ const (
 	ReadFormatStream ReadFormat = "stream"
 )
 
+// HeadTailUnit specifies whether Files.ReadHead and Files.ReadLast count
+// lines or bytes.
+type HeadTailUnit string
+
+const (
+	// HeadTailLines counts lines, like head -n and tail -n.
+	HeadTailLines HeadTailUnit = "lines"
+	// HeadTailBytes counts bytes, like head -c and tail -c.
+	HeadTailBytes HeadTailUnit = "bytes"
+)
+
 // FileContent represents the content of a file in various formats.
 type FileContent struct {
 	text  string
@@ -238,3 +257,83 @@ func (b bytesReader) Read(p []byte) (n int, err error) {
 	}
 	return n, io.EOF
 }
+
+// WriteLinesEntry represents a file to be written line by line.
+type WriteLinesEntry struct {
+	// Path is the path where the file should be written.
+	Path string
+
+	// Lines are the lines to write, without separators.
+	Lines []string
+}
+
+// DownloadDirResult summarizes a directory download.
+type DownloadDirResult struct {
+	// Files is the number of files written locally.
+	Files int
+
+	// Bytes is the total number of bytes written locally.
+	Bytes int64
+
+	// Skipped lists the remote paths that could not be created locally and
+	// were skipped (see WithDownloadDirSkipLocalErrors).
+	Skipped []string
+}
+
+// SyncResult summarizes a directory sync.
+type SyncResult struct {
+	// Added is the number of files uploaded that did not exist in the sandbox.
+	Added int
+
+	// Updated is the number of files uploaded whose content had changed.
+	Updated int
+
+	// Deleted is the number of files removed from the sandbox
+	// (see WithSyncDelete).
+	Deleted int
+
+	// Unchanged is the number of files that already had the same content.
+	Unchanged int
+}
+
+// SearchMatch is a line matching a content search.
+type SearchMatch struct {
+	// Path is the path of the file containing the match.
+	Path string
+
+	// Line is the matching line, without the line terminator.
+	Line string
+
+	// LineNumber is the 1-based number of the matching line.
+	LineNumber int
+
+	// Column is the 1-based byte offset of the first match in Line, or 0
+	// if it could not be located.
+	Column int
+
+	// Context holds the matching line and up to n lines before and after
+	// it, in file order (see WithSearchContext). It is nil without context.
+	Context []string
+
+	// ContextStart is the line number of the first line in Context.
+	ContextStart int
+}
+
+// DiskUsageInfo is the disk usage of a file or directory tree.
+type DiskUsageInfo struct {
+	// Path is the path of the file or directory.
+	Path string
+
+	// TotalBytes is the total apparent size in bytes of the file or of all
+	// entries in the directory tree, like du -sb.
+	TotalBytes int64
+
+	// FileCount is the number of entries other than directories in the
+	// tree, such as regular files and symbolic links.
+	FileCount int64
+
+	// Subdirectories holds the usage of each subdirectory up to the depth
+	// set with WithDiskUsageMaxDepth, sorted by path. It is nil without a
+	// depth and only set on the returned DiskUsageInfo.
+	Subdirectories []DiskUsageInfo
+}
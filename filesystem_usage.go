@@ -0,0 +1,132 @@
+package e2b
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DiskUsage returns the disk usage of the file or directory tree at p: its
+// total apparent size in bytes and the number of files it contains.
+//
+// WithDiskUsageMaxDepth additionally reports the usage of each
+// subdirectory up to the given depth. Entries the user cannot read are
+// left out of the totals. An error wrapping ErrNotFound is returned if p
+// does not exist.
+//
+// envd has no disk usage RPC, so the sizes are computed by du and the file
+// counts by find in the sandbox. Their output is NUL-separated, so paths
+// containing spaces or newlines are handled.
+//
+// Example:
+//
+//	usage, err := sandbox.Files.DiskUsage(ctx, "/home/user/output",
+//	    e2b.WithDiskUsageMaxDepth(1),
+//	)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if usage.TotalBytes > 1<<30 {
+//	    for _, dir := range usage.Subdirectories {
+//	        fmt.Printf("%s: %d bytes in %d files\n", dir.Path, dir.TotalBytes, dir.FileCount)
+//	    }
+//	}
+func (fs *Filesystem) DiskUsage(ctx context.Context, p string, opts ...DiskUsageOption) (*DiskUsageInfo, error) {
+	if p == "" {
+		return nil, fmt.Errorf("%w: path is required", ErrInvalidArgument)
+	}
+
+	cfg := defaultDiskUsageConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if cfg.maxDepth < 0 {
+		return nil, fmt.Errorf("%w: max depth must not be negative", ErrInvalidArgument)
+	}
+
+	root := path.Clean(p)
+	result, err := fs.runShell(ctx, diskUsageScript(root, cfg.maxDepth), &cfg.filesystemConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseDiskUsage(root, result.Stdout, cfg.maxDepth)
+}
+
+// diskUsageScript returns the script printing the disk usage of root. It
+// prints the NUL-terminated "size\tpath" records of du for root and each
+// directory up to maxDepth, an empty record, and then NUL-terminated
+// "count prefix" records counting the files below root by their leading
+// path components.
+func diskUsageScript(root string, maxDepth int) string {
+	q := shellQuote(root)
+	return fmt.Sprintf("%s && { du -b -0 --max-depth=%d -- %s 2>/dev/null; printf '\\0'; "+
+		"find %s ! -type d -printf '%%P\\0' 2>/dev/null | cut -z -d/ -f1-%d | sort -z | uniq -zc; }",
+		shellRequireExists(root), maxDepth, q, q, max(maxDepth, 1))
+}
+
+// parseDiskUsage parses the output of diskUsageScript.
+func parseDiskUsage(root, out string, maxDepth int) (*DiskUsageInfo, error) {
+	sizes, counts, _ := strings.Cut(out, "\x00\x00")
+
+	// Directories by their path relative to root, "" being root itself
+	dirs := make(map[string]*DiskUsageInfo)
+	for _, record := range strings.Split(sizes, "\x00") {
+		size, name, ok := strings.Cut(record, "\t")
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseInt(size, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse disk usage %q: %w", record, err)
+		}
+		rel := strings.TrimPrefix(strings.TrimPrefix(name, root), "/")
+		dirs[rel] = &DiskUsageInfo{Path: path.Join(root, rel), TotalBytes: n}
+	}
+
+	usage, ok := dirs[""]
+	if !ok {
+		return nil, fmt.Errorf("failed to get disk usage of %s", root)
+	}
+
+	for _, record := range strings.Split(counts, "\x00") {
+		count, prefix, ok := strings.Cut(strings.TrimLeft(record, " "), " ")
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseInt(count, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse file count %q: %w", record, err)
+		}
+
+		usage.FileCount += n
+		if maxDepth == 0 || prefix == "" {
+			continue
+		}
+		// The files count towards each directory among the components
+		parts := strings.Split(prefix, "/")
+		for i := range parts {
+			if dir, ok := dirs[strings.Join(parts[:i+1], "/")]; ok {
+				dir.FileCount += n
+			}
+		}
+	}
+
+	if maxDepth > 0 {
+		usage.Subdirectories = make([]DiskUsageInfo, 0, len(dirs)-1)
+		for rel, dir := range dirs {
+			if rel != "" {
+				usage.Subdirectories = append(usage.Subdirectories, *dir)
+			}
+		}
+		sort.Slice(usage.Subdirectories, func(i, j int) bool {
+			return usage.Subdirectories[i].Path < usage.Subdirectories[j].Path
+		})
+	}
+
+	return usage, nil
+}
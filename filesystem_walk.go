@@ -0,0 +1,257 @@
+package e2b
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// WalkDir walks the directory tree rooted at root, calling fn for each file
+// or directory in the tree, including root.
+//
+// It follows the contract of filepath.WalkDir: entries are visited in
+// lexical order, fn is called for a directory before its contents, and a
+// directory that cannot be listed is reported by a second call with the
+// error. Returning filepath.SkipDir from fn skips the directory (or, for a
+// file, the remaining entries of its directory) and filepath.SkipAll stops
+// the walk. Paths passed to fn are joined to root as given. If root cannot
+// be read, fn is called with a nil info and the error.
+//
+// Directories are listed ahead of the callback, up to WithWalkConcurrency
+// at a time, while fn is always called from a single goroutine. Any other
+// error returned by fn stops the walk and is returned, unless
+// WithWalkContinueOnError is set. Symbolic links are reported but not
+// descended into unless WithWalkFollowSymlinks is set; if root itself is a
+// link to a directory, its target is walked.
+//
+// Example:
+//
+//	err := sandbox.Files.WalkDir(ctx, "/home/user/project", func(p string, info *e2b.EntryInfo, err error) error {
+//	    if err != nil {
+//	        return err
+//	    }
+//	    if info.Type == e2b.FileTypeDir && info.Name == "node_modules" {
+//	        return filepath.SkipDir
+//	    }
+//	    fmt.Println(p, info.Size)
+//	    return nil
+//	}, e2b.WithWalkMaxDepth(3))
+func (fs *Filesystem) WalkDir(ctx context.Context, root string, fn func(path string, info *EntryInfo, err error) error, opts ...WalkOption) error {
+	if fn == nil {
+		return fmt.Errorf("%w: walk function is required", ErrInvalidArgument)
+	}
+
+	cfg := defaultWalkConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if cfg.maxDepth < 0 {
+		return fmt.Errorf("%w: max depth must not be negative", ErrInvalidArgument)
+	}
+	if cfg.concurrency < 1 {
+		return fmt.Errorf("%w: concurrency must be at least 1", ErrInvalidArgument)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	w := &dirWalk{
+		fs:      fs,
+		ctx:     ctx,
+		cfg:     cfg,
+		fn:      fn,
+		sem:     make(chan struct{}, cfg.concurrency),
+		visited: make(map[string]bool),
+	}
+	// Stop listings that are still in flight when the walk ends early
+	defer func() {
+		cancel()
+		w.wg.Wait()
+	}()
+
+	info, err := fs.GetInfo(ctx, root, WithUser(cfg.user), WithFilesystemRequestTimeout(cfg.requestTimeout))
+	switch {
+	case err != nil:
+		err = w.call(root, nil, err)
+	case info.Type == FileTypeDir:
+		real := info.Path
+		if info.SymlinkTarget != nil {
+			real = resolveSymlinkTarget(info.Path, *info.SymlinkTarget)
+		}
+		w.visited[real] = true
+		err = w.walkDir(root, info, 0, w.prefetch(real))
+	default:
+		err = w.call(root, info, nil)
+	}
+
+	if err == filepath.SkipDir || err == filepath.SkipAll {
+		err = nil
+	}
+	if len(w.errs) == 0 {
+		return err
+	}
+	return errors.Join(append(w.errs, err)...)
+}
+
+// dirWalk holds the state of a WalkDir call.
+type dirWalk struct {
+	fs      *Filesystem
+	ctx     context.Context
+	cfg     *walkConfig
+	fn      func(path string, info *EntryInfo, err error) error
+	sem     chan struct{}
+	wg      sync.WaitGroup
+	visited map[string]bool
+	errs    []error
+}
+
+// walkListing is the pending result of listing a directory.
+type walkListing struct {
+	done    chan struct{}
+	entries []*EntryInfo
+	err     error
+}
+
+// prefetch starts listing dir in the background.
+func (w *dirWalk) prefetch(dir string) *walkListing {
+	listing := &walkListing{done: make(chan struct{})}
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		defer close(listing.done)
+
+		select {
+		case w.sem <- struct{}{}:
+		case <-w.ctx.Done():
+			listing.err = w.ctx.Err()
+			return
+		}
+		defer func() { <-w.sem }()
+
+		listing.entries, listing.err = w.fs.List(w.ctx, dir,
+			WithListUser(w.cfg.user),
+			WithListRequestTimeout(w.cfg.requestTimeout),
+		)
+	}()
+
+	return listing
+}
+
+// call invokes the walk function, collecting its errors instead of
+// returning them if the walk continues on errors.
+func (w *dirWalk) call(p string, info *EntryInfo, err error) error {
+	err = w.fn(p, info, err)
+	if err != nil && err != filepath.SkipDir && err != filepath.SkipAll && w.cfg.continueOnError {
+		w.errs = append(w.errs, err)
+		return nil
+	}
+	return err
+}
+
+// walkDir visits the directory shown at shownPath and, if listing is not
+// nil, its contents.
+func (w *dirWalk) walkDir(shownPath string, info *EntryInfo, depth int, listing *walkListing) error {
+	if err := w.call(shownPath, info, nil); err != nil {
+		if err == filepath.SkipDir {
+			return nil
+		}
+		return err
+	}
+	if listing == nil {
+		return nil
+	}
+
+	<-listing.done
+	if listing.err != nil {
+		if err := w.call(shownPath, info, listing.err); err != nil && err != filepath.SkipDir {
+			return err
+		}
+		return nil
+	}
+
+	entries := listing.entries
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+
+	// Decide up front which subdirectories are descended into, so their
+	// listings can be started ahead of the callback
+	descend := w.cfg.maxDepth == 0 || depth+1 < w.cfg.maxDepth
+	targets := make([]string, len(entries))
+	var pending []int
+	if descend {
+		for i, entry := range entries {
+			if target, ok := w.dirTarget(entry); ok {
+				targets[i] = target
+				pending = append(pending, i)
+			}
+		}
+	}
+	listings := make([]*walkListing, len(entries))
+
+	started, consumed := 0, 0
+	for i, entry := range entries {
+		if err := w.ctx.Err(); err != nil {
+			return err
+		}
+
+		for started < len(pending) && started < consumed+w.cfg.concurrency {
+			listings[pending[started]] = w.prefetch(targets[pending[started]])
+			started++
+		}
+
+		p := path.Join(shownPath, entry.Name)
+		if entry.Type == FileTypeDir {
+			if targets[i] != "" {
+				consumed++
+			}
+			if err := w.walkDir(p, entry, depth+1, listings[i]); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := w.call(p, entry, nil); err != nil {
+			if err == filepath.SkipDir {
+				return nil
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// dirTarget returns the path to list for a directory entry, or false if the
+// entry is not descended into.
+func (w *dirWalk) dirTarget(entry *EntryInfo) (string, bool) {
+	if entry.Type != FileTypeDir {
+		return "", false
+	}
+
+	target := entry.Path
+	if entry.SymlinkTarget != nil {
+		if !w.cfg.followSymlinks {
+			return "", false
+		}
+		target = resolveSymlinkTarget(entry.Path, *entry.SymlinkTarget)
+		if w.visited[target] {
+			return "", false
+		}
+	}
+
+	w.visited[target] = true
+	return target, true
+}
+
+// resolveSymlinkTarget returns the absolute target of the link at linkPath.
+func resolveSymlinkTarget(linkPath, target string) string {
+	if path.IsAbs(target) {
+		return target
+	}
+	return path.Join(path.Dir(linkPath), target)
+}
@@ -3,7 +3,10 @@ package e2b
 import (
 	"context"
 	"fmt"
+	"path"
+	"sort"
 	"sync"
+	"time"
 
 	"connectrpc.com/connect"
 	filesystempb "github.com/xerpa-ai/e2b-go/internal/proto/filesystem"
@@ -85,7 +88,7 @@ func (fs *Filesystem) WatchDir(
 	// Check if recursive watch is supported
 	if cfg.recursive && fs.compareVersion(EnvdVersionRecursiveWatch) < 0 {
 		return nil, fmt.Errorf("%w: recursive watch requires envd version >= %s (current: %s)",
-			ErrInvalidArgument, EnvdVersionRecursiveWatch, fs.envdVersion)
+			ErrInvalidArgument, EnvdVersionRecursiveWatch, fs.envdVersion())
 	}
 
 	// Create cancellable context for the entire watch operation
@@ -127,6 +130,12 @@ func (fs *Filesystem) WatchDir(
 		done:   make(chan struct{}),
 	}
 
+	var debouncer *eventDebouncer
+	if cfg.debounce > 0 && onEvent != nil {
+		debouncer = newEventDebouncer(cfg.debounce, onEvent)
+		onEvent = debouncer.add
+	}
+
 	// Start event processing goroutine
 	go func() {
 		defer close(handle.done)
@@ -150,6 +159,10 @@ func (fs *Filesystem) WatchDir(
 			}
 		}
 
+		if debouncer != nil {
+			debouncer.flush()
+		}
+
 		// Check for errors
 		if err := stream.Err(); err != nil {
 			handle.setError(err)
@@ -166,6 +179,171 @@ func (fs *Filesystem) WatchDir(
 	return handle, nil
 }
 
+// eventDebouncer coalesces the events of each path for WithWatchDebounce.
+// A timer per path is reset on every event, and the last event is passed
+// on once the timer fires. Events are passed on one at a time.
+type eventDebouncer struct {
+	delay   time.Duration
+	onEvent func(FilesystemEvent)
+
+	mu      sync.Mutex
+	pending map[string]*debouncedEvent
+
+	// deliverMu keeps onEvent from being called concurrently
+	deliverMu sync.Mutex
+	// firing counts the timers passing on an event, so flush can wait
+	// for them
+	firing sync.WaitGroup
+}
+
+// debouncedEvent is the last event held for a path.
+type debouncedEvent struct {
+	event FilesystemEvent
+	timer *time.Timer
+}
+
+// newEventDebouncer returns a debouncer passing events on to onEvent.
+func newEventDebouncer(delay time.Duration, onEvent func(FilesystemEvent)) *eventDebouncer {
+	return &eventDebouncer{
+		delay:   delay,
+		onEvent: onEvent,
+		pending: make(map[string]*debouncedEvent),
+	}
+}
+
+// add holds event, replacing the event held for its path.
+func (d *eventDebouncer) add(event FilesystemEvent) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if p, ok := d.pending[event.Name]; ok {
+		p.event = event
+		p.timer.Reset(d.delay)
+		return
+	}
+	p := &debouncedEvent{event: event}
+	p.timer = time.AfterFunc(d.delay, func() { d.fire(event.Name, p) })
+	d.pending[event.Name] = p
+}
+
+// fire passes on the event held for name once its timer fires.
+func (d *eventDebouncer) fire(name string, p *debouncedEvent) {
+	d.mu.Lock()
+	if d.pending[name] != p {
+		// Already passed on by flush
+		d.mu.Unlock()
+		return
+	}
+	delete(d.pending, name)
+	event := p.event
+	d.firing.Add(1)
+	d.mu.Unlock()
+
+	defer d.firing.Done()
+	d.deliver(event)
+}
+
+// flush passes on all held events, in path order, and waits for events
+// being passed on by their timers.
+func (d *eventDebouncer) flush() {
+	d.mu.Lock()
+	names := make([]string, 0, len(d.pending))
+	for name, p := range d.pending {
+		p.timer.Stop()
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	events := make([]FilesystemEvent, 0, len(names))
+	for _, name := range names {
+		events = append(events, d.pending[name].event)
+		delete(d.pending, name)
+	}
+	d.mu.Unlock()
+
+	for _, event := range events {
+		d.deliver(event)
+	}
+	d.firing.Wait()
+}
+
+// deliver calls onEvent with event.
+func (d *eventDebouncer) deliver(event FilesystemEvent) {
+	d.deliverMu.Lock()
+	defer d.deliverMu.Unlock()
+	d.onEvent(event)
+}
+
+// WatchFile watches a single file for filesystem events, such as a config
+// file or a lock file. The file does not need to exist yet, so its
+// creation is reported as well.
+//
+// The parent directory is watched with WatchDir and only the events for
+// the file are passed to onEvent; their Name is the base name of the file.
+// With WithWatchFileInitialRead, onEvent is first called with the current
+// content of the file, and an error wrapping ErrNotFound is returned if it
+// does not exist. WithRecursive is ignored.
+//
+// Example:
+//
+//	handle, err := sandbox.Files.WatchFile(ctx, "/home/user/config.yaml", func(event e2b.FilesystemEvent) {
+//	    if event.Type == e2b.EventTypeWrite {
+//	        fmt.Println("config changed")
+//	    }
+//	}, e2b.WithWatchFileInitialRead(true))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer handle.Stop()
+func (fs *Filesystem) WatchFile(
+	ctx context.Context,
+	filePath string,
+	onEvent func(FilesystemEvent),
+	opts ...WatchOption,
+) (*WatchHandle, error) {
+	if filePath == "" {
+		return nil, fmt.Errorf("%w: path is required", ErrInvalidArgument)
+	}
+
+	cfg := defaultWatchConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	name := path.Base(filePath)
+
+	// Hold back changes until the initial content has been reported
+	initialDone := make(chan struct{})
+	if !cfg.initialRead {
+		close(initialDone)
+	}
+
+	dirOpts := append(opts[:len(opts):len(opts)], WithRecursive(false))
+	handle, err := fs.WatchDir(ctx, path.Dir(filePath), func(event FilesystemEvent) {
+		if event.Name != name || onEvent == nil {
+			return
+		}
+		<-initialDone
+		onEvent(event)
+	}, dirOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.initialRead {
+		content, err := fs.ReadBytes(ctx, filePath, WithReadUser(cfg.user), WithReadRequestTimeout(cfg.requestTimeout))
+		if err == nil && onEvent != nil {
+			onEvent(FilesystemEvent{Name: name, Type: EventTypeWrite, Content: content})
+		}
+		close(initialDone)
+		if err != nil {
+			handle.Stop()
+			return nil, err
+		}
+	}
+
+	return handle, nil
+}
+
 // CreateWatcher creates a non-streaming watcher for a directory.
 // Use GetWatcherEvents to poll for events and RemoveWatcher to stop watching.
 //
@@ -188,7 +366,7 @@ func (fs *Filesystem) CreateWatcher(ctx context.Context, path string, opts ...Wa
 	// Check if recursive watch is supported
 	if cfg.recursive && fs.compareVersion(EnvdVersionRecursiveWatch) < 0 {
 		return "", fmt.Errorf("%w: recursive watch requires envd version >= %s (current: %s)",
-			ErrInvalidArgument, EnvdVersionRecursiveWatch, fs.envdVersion)
+			ErrInvalidArgument, EnvdVersionRecursiveWatch, fs.envdVersion())
 	}
 
 	ctx, cancel := fs.applyTimeout(ctx, cfg.requestTimeout)
@@ -0,0 +1,302 @@
+package e2b
+
+import (
+	"html"
+	"strings"
+)
+
+// rawHTMLExtraKey is the Result.Extra key holding the unsanitized HTML
+// when WithSanitizeHTML is enabled.
+const rawHTMLExtraKey = "raw_html"
+
+// sanitizeAllowedTags is the conservative allowlist of tags kept by sanitizeHTML.
+// It covers the markup produced by common notebook outputs (tables, text
+// formatting, lists, links and images).
+var sanitizeAllowedTags = map[string]bool{
+	"a": true, "abbr": true, "b": true, "blockquote": true, "br": true,
+	"caption": true, "code": true, "col": true, "colgroup": true, "dd": true,
+	"del": true, "details": true, "div": true, "dl": true, "dt": true,
+	"em": true, "figcaption": true, "figure": true, "h1": true, "h2": true,
+	"h3": true, "h4": true, "h5": true, "h6": true, "hr": true, "i": true,
+	"img": true, "ins": true, "li": true, "mark": true, "ol": true, "p": true,
+	"pre": true, "s": true, "small": true, "span": true, "strong": true,
+	"sub": true, "summary": true, "sup": true, "table": true, "tbody": true,
+	"td": true, "tfoot": true, "th": true, "thead": true, "tr": true,
+	"u": true, "ul": true,
+}
+
+// sanitizeDroppedContentTags are tags removed together with their content.
+var sanitizeDroppedContentTags = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true,
+	"embed": true, "noscript": true, "template": true, "textarea": true,
+	"title": true, "xmp": true, "noembed": true, "noframes": true,
+	"svg": true, "math": true,
+}
+
+// sanitizeGlobalAttrs are attributes allowed on every allowed tag.
+// style is left out: CSS escapes and comments make it impractical to vet.
+var sanitizeGlobalAttrs = map[string]bool{
+	"class": true, "title": true, "dir": true, "lang": true, "align": true,
+}
+
+// sanitizeTagAttrs are attributes allowed on specific tags.
+var sanitizeTagAttrs = map[string]map[string]bool{
+	"a":     {"href": true, "target": true, "rel": true},
+	"img":   {"src": true, "alt": true, "width": true, "height": true},
+	"table": {"border": true, "cellpadding": true, "cellspacing": true},
+	"td":    {"colspan": true, "rowspan": true, "valign": true},
+	"th":    {"colspan": true, "rowspan": true, "valign": true, "scope": true},
+	"col":   {"span": true},
+	"ol":    {"start": true, "type": true},
+}
+
+// sanitizeHTML removes potentially dangerous markup from HTML using an allowlist.
+//
+// Tags outside the allowlist are removed while their text is kept, except for
+// tags such as script and style whose content is removed too. Event handler
+// and style attributes and any attribute outside the allowlist are dropped,
+// and URLs are restricted to http, https, mailto and relative references
+// (plus base64 raster images for img sources).
+func sanitizeHTML(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+
+	for i := 0; i < len(s); {
+		lt := strings.IndexByte(s[i:], '<')
+		if lt < 0 {
+			b.WriteString(s[i:])
+			break
+		}
+		b.WriteString(s[i : i+lt])
+		i += lt
+
+		// Comments, doctypes and processing instructions are dropped
+		if strings.HasPrefix(s[i:], "<!--") {
+			end := strings.Index(s[i+4:], "-->")
+			if end < 0 {
+				break
+			}
+			i += 4 + end + 3
+			continue
+		}
+		if strings.HasPrefix(s[i:], "<!") || strings.HasPrefix(s[i:], "<?") {
+			end := strings.IndexByte(s[i:], '>')
+			if end < 0 {
+				break
+			}
+			i += end + 1
+			continue
+		}
+
+		tag, next, ok := parseSanitizeTag(s, i)
+		if !ok {
+			// Not a tag; escape the bracket so it renders as text
+			b.WriteString("&lt;")
+			i++
+			continue
+		}
+		i = next
+
+		switch {
+		case sanitizeDroppedContentTags[tag.name]:
+			if !tag.closing && !tag.selfClosing {
+				i = skipSanitizeRawText(s, i, tag.name)
+			}
+		case sanitizeAllowedTags[tag.name]:
+			writeSanitizedTag(&b, tag)
+		}
+	}
+
+	return b.String()
+}
+
+// sanitizeTag is a parsed start or end tag.
+type sanitizeTag struct {
+	name        string
+	closing     bool
+	selfClosing bool
+	attrs       [][2]string
+}
+
+// parseSanitizeTag parses the tag starting at s[i] == '<'.
+// It returns the tag, the index after it and whether a tag was found.
+func parseSanitizeTag(s string, i int) (sanitizeTag, int, bool) {
+	var tag sanitizeTag
+	j := i + 1
+	if j < len(s) && s[j] == '/' {
+		tag.closing = true
+		j++
+	}
+
+	start := j
+	for j < len(s) && isSanitizeNameChar(s[j]) {
+		j++
+	}
+	if j == start || !isASCIILetter(s[start]) {
+		return tag, i, false
+	}
+	tag.name = strings.ToLower(s[start:j])
+
+	for {
+		for j < len(s) && (isSanitizeSpace(s[j]) || s[j] == '/') {
+			if s[j] == '/' {
+				tag.selfClosing = true
+			}
+			j++
+		}
+		if j >= len(s) {
+			return tag, i, false
+		}
+		if s[j] == '>' {
+			return tag, j + 1, true
+		}
+		tag.selfClosing = false
+
+		// Attribute name
+		start = j
+		for j < len(s) && !isSanitizeSpace(s[j]) && s[j] != '=' && s[j] != '>' && s[j] != '/' {
+			j++
+		}
+		name := strings.ToLower(s[start:j])
+		for j < len(s) && isSanitizeSpace(s[j]) {
+			j++
+		}
+
+		// Attribute value
+		value := ""
+		if j < len(s) && s[j] == '=' {
+			j++
+			for j < len(s) && isSanitizeSpace(s[j]) {
+				j++
+			}
+			if j < len(s) && (s[j] == '"' || s[j] == '\'') {
+				quote := s[j]
+				end := strings.IndexByte(s[j+1:], quote)
+				if end < 0 {
+					return tag, i, false
+				}
+				value = s[j+1 : j+1+end]
+				j += end + 2
+			} else {
+				start = j
+				for j < len(s) && !isSanitizeSpace(s[j]) && s[j] != '>' {
+					j++
+				}
+				value = s[start:j]
+			}
+		}
+		if name != "" {
+			tag.attrs = append(tag.attrs, [2]string{name, html.UnescapeString(value)})
+		}
+	}
+}
+
+// skipSanitizeRawText skips to after the end tag of name, or to the end of s.
+func skipSanitizeRawText(s string, i int, name string) int {
+	lower := strings.ToLower(s[i:])
+	end := strings.Index(lower, "</"+name)
+	if end < 0 {
+		return len(s)
+	}
+	gt := strings.IndexByte(lower[end:], '>')
+	if gt < 0 {
+		return len(s)
+	}
+	return i + end + gt + 1
+}
+
+// writeSanitizedTag writes an allowed tag with only its allowed attributes.
+func writeSanitizedTag(b *strings.Builder, tag sanitizeTag) {
+	b.WriteByte('<')
+	if tag.closing {
+		b.WriteByte('/')
+		b.WriteString(tag.name)
+		b.WriteByte('>')
+		return
+	}
+	b.WriteString(tag.name)
+
+	for _, attr := range tag.attrs {
+		name, value := attr[0], attr[1]
+		if !sanitizeGlobalAttrs[name] && !sanitizeTagAttrs[tag.name][name] {
+			continue
+		}
+		if !isSafeSanitizeAttr(tag.name, name, value) {
+			continue
+		}
+		b.WriteByte(' ')
+		b.WriteString(name)
+		b.WriteString(`="`)
+		b.WriteString(html.EscapeString(value))
+		b.WriteByte('"')
+	}
+
+	if tag.selfClosing {
+		b.WriteString(" /")
+	}
+	b.WriteByte('>')
+}
+
+// isSafeSanitizeAttr checks attribute values that can carry active content.
+func isSafeSanitizeAttr(tag, name, value string) bool {
+	switch name {
+	case "href", "src":
+		return isSafeSanitizeURL(tag, value)
+	case "target":
+		return value == "_blank" || value == "_self"
+	default:
+		return true
+	}
+}
+
+// isSafeSanitizeURL allows http(s), mailto, relative and fragment URLs,
+// plus base64 raster images for img sources.
+func isSafeSanitizeURL(tag, value string) bool {
+	normalized := strings.ToLower(stripSanitizeSpace(value))
+
+	colon := strings.IndexByte(normalized, ':')
+	if colon < 0 || strings.ContainsAny(normalized[:colon], "/?#") {
+		// No scheme: relative URL
+		return true
+	}
+
+	switch normalized[:colon] {
+	case "http", "https", "mailto":
+		return true
+	case "data":
+		if tag != "img" {
+			return false
+		}
+		for _, prefix := range []string{"data:image/png;", "data:image/jpeg;", "data:image/gif;", "data:image/webp;"} {
+			if strings.HasPrefix(normalized, prefix) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+// stripSanitizeSpace removes whitespace and control characters, which
+// browsers ignore inside URL schemes.
+func stripSanitizeSpace(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+func isSanitizeSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func isASCIILetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isSanitizeNameChar(c byte) bool {
+	return isASCIILetter(c) || (c >= '0' && c <= '9') || c == '-'
+}
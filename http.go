@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -50,34 +51,35 @@ type streamResponse struct {
 
 // httpClient wraps the standard http.Client with sandbox-specific functionality.
 type httpClient struct {
-	client       *http.Client
-	baseURL      string
-	accessToken  string
-	trafficToken string
+	client *http.Client
+	conn   *envdConn
+
+	// maxLineSize limits the size of a stream line, DefaultMaxStreamLineSize
+	// if not positive
+	maxLineSize int
 }
 
 // newHTTPClient creates a new httpClient.
-func newHTTPClient(client *http.Client, baseURL, accessToken, trafficToken string) *httpClient {
+// Requests go to the Jupyter URL of conn.
+func newHTTPClient(client *http.Client, conn *envdConn) *httpClient {
 	if client == nil {
 		client = &http.Client{}
 	}
 	return &httpClient{
-		client:       client,
-		baseURL:      baseURL,
-		accessToken:  accessToken,
-		trafficToken: trafficToken,
+		client: client,
+		conn:   conn,
 	}
 }
 
 // setHeaders sets common headers for all requests.
-func (c *httpClient) setHeaders(req *http.Request) {
+func (c *httpClient) setHeaders(req *http.Request, conn envdConnInfo) {
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", "e2b-go-sdk/"+Version)
-	if c.accessToken != "" {
-		req.Header.Set("X-Access-Token", c.accessToken)
+	if conn.accessToken != "" {
+		req.Header.Set("X-Access-Token", conn.accessToken)
 	}
-	if c.trafficToken != "" {
-		req.Header.Set("E2B-Traffic-Access-Token", c.trafficToken)
+	if conn.trafficToken != "" {
+		req.Header.Set("E2B-Traffic-Access-Token", conn.trafficToken)
 	}
 }
 
@@ -92,12 +94,13 @@ func (c *httpClient) doRequest(ctx context.Context, method, path string, body an
 		reqBody = bytes.NewReader(data)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
+	conn := c.conn.load()
+	req, err := http.NewRequestWithContext(ctx, method, conn.jupyterURL+path, reqBody)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	c.setHeaders(req)
+	c.setHeaders(req, conn)
 
 	resp, err := c.client.Do(req)
 	if err != nil {
@@ -110,6 +113,10 @@ func (c *httpClient) doRequest(ctx context.Context, method, path string, body an
 		return nil, resp.StatusCode, fmt.Errorf("failed to read response body: %w", err)
 	}
 
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return respBody, resp.StatusCode, newRateLimitError(resp.Header, string(respBody))
+	}
+
 	return respBody, resp.StatusCode, nil
 }
 
@@ -129,12 +136,13 @@ func (c *httpClient) doStreamRequest(
 		reqBody = bytes.NewReader(data)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, reqBody)
+	conn := c.conn.load()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, conn.jupyterURL+path, reqBody)
 	if err != nil {
 		return 0, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	c.setHeaders(req)
+	c.setHeaders(req, conn)
 
 	resp, err := c.client.Do(req)
 	if err != nil {
@@ -144,14 +152,20 @@ func (c *httpClient) doStreamRequest(
 
 	if resp.StatusCode != http.StatusOK {
 		respBody, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return resp.StatusCode, newRateLimitError(resp.Header, string(respBody))
+		}
 		return resp.StatusCode, formatHTTPError(resp.StatusCode, string(respBody))
 	}
 
+	maxLineSize := c.maxLineSize
+	if maxLineSize <= 0 {
+		maxLineSize = DefaultMaxStreamLineSize
+	}
 	scanner := bufio.NewScanner(resp.Body)
 	// Increase buffer size for large responses
-	const maxCapacity = 10 * 1024 * 1024 // 10MB
-	buf := make([]byte, 64*1024)
-	scanner.Buffer(buf, maxCapacity)
+	buf := make([]byte, min(64*1024, maxLineSize))
+	scanner.Buffer(buf, maxLineSize)
 
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -170,6 +184,9 @@ func (c *httpClient) doStreamRequest(
 	}
 
 	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return resp.StatusCode, fmt.Errorf("%w: output line exceeds %d bytes, raise the limit with WithMaxStreamLineSize", ErrStreamLineTooLong, maxLineSize)
+		}
 		return resp.StatusCode, fmt.Errorf("error reading stream: %w", err)
 	}
 
@@ -200,6 +217,17 @@ func parseStreamResponse(
 			Extra:        sr.Extra,
 		}
 
+		// Sanitize HTML, keeping the raw markup in Extra
+		if cfg.sanitizeHTML && result.HTML != "" {
+			extra := make(map[string]any, len(result.Extra)+1)
+			for k, v := range result.Extra {
+				extra[k] = v
+			}
+			extra[rawHTMLExtraKey] = result.HTML
+			result.Extra = extra
+			result.HTML = sanitizeHTML(result.HTML)
+		}
+
 		// Parse chart if present
 		if sr.Chart != nil {
 			chart, err := DeserializeChart(sr.Chart)
@@ -249,6 +277,10 @@ func parseStreamResponse(
 
 	case "number_of_executions":
 		execution.ExecutionCount = sr.ExecutionCount
+
+		if cfg.onExecutionCount != nil {
+			cfg.onExecutionCount(sr.ExecutionCount)
+		}
 	}
 
 	return nil
@@ -0,0 +1,223 @@
+package e2b
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// redacted replaces the value of sensitive headers and query parameters in
+// log records.
+const redacted = "REDACTED"
+
+// sensitiveHeaders are the request headers whose values are never logged.
+var sensitiveHeaders = map[string]bool{
+	"Authorization":            true,
+	"X-Api-Key":                true,
+	"X-Access-Token":           true,
+	"E2b-Traffic-Access-Token": true,
+}
+
+// sensitiveQueryParams are the query parameters whose values are never
+// logged, in lower case: envd URL signatures and the credentials of the
+// presigned GCS and S3 URLs used for template layer uploads.
+var sensitiveQueryParams = map[string]bool{
+	"signature":            true,
+	"x-goog-signature":     true,
+	"x-goog-credential":    true,
+	"x-amz-signature":      true,
+	"x-amz-credential":     true,
+	"x-amz-security-token": true,
+}
+
+// executionMetadataKey is the context key of the metadata set with
+// WithExecutionMetadata.
+type executionMetadataKey struct{}
+
+// withExecutionMetadata returns ctx carrying the execution metadata, or ctx
+// itself if metadata is empty.
+func withExecutionMetadata(ctx context.Context, metadata map[string]string) context.Context {
+	if len(metadata) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, executionMetadataKey{}, metadata)
+}
+
+// ExecutionMetadataFromContext returns the metadata set with
+// WithExecutionMetadata for the execution that made a request, or nil. Use
+// it with the context of a request passed to WithRequestInterceptor or to a
+// custom transport. The returned map must not be modified.
+//
+// Example:
+//
+//	sandbox, err := e2b.New(e2b.WithRequestInterceptor(func(req *http.Request) {
+//	    if taskID := e2b.ExecutionMetadataFromContext(req.Context())["task_id"]; taskID != "" {
+//	        req.Header.Set("X-Task-ID", taskID)
+//	    }
+//	}))
+func ExecutionMetadataFromContext(ctx context.Context) map[string]string {
+	metadata, _ := ctx.Value(executionMetadataKey{}).(map[string]string)
+	return metadata
+}
+
+// metadataAttrs appends the execution metadata of ctx to attrs as a
+// "metadata" group, sorted by key.
+func metadataAttrs(ctx context.Context, attrs ...any) []any {
+	metadata := ExecutionMetadataFromContext(ctx)
+	if len(metadata) == 0 {
+		return attrs
+	}
+
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	group := make([]any, 0, len(keys))
+	for _, key := range keys {
+		group = append(group, slog.String(key, metadata[key]))
+	}
+	return append(attrs, slog.Group("metadata", group...))
+}
+
+// logTransport logs every request and response at Debug level, with
+// sensitive headers and query parameters redacted. Execution metadata on the
+// request context is logged with each record.
+type logTransport struct {
+	base   http.RoundTripper
+	logger *slog.Logger
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *logTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	ctx := req.Context()
+	if !t.logger.Enabled(ctx, slog.LevelDebug) {
+		return base.RoundTrip(req)
+	}
+
+	reqURL := redactURL(req.URL)
+	t.logger.DebugContext(ctx, "e2b: sending request", metadataAttrs(ctx,
+		slog.String("method", req.Method),
+		slog.String("url", reqURL),
+		redactHeaders(req.Header),
+	)...)
+
+	start := time.Now()
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		t.logger.DebugContext(ctx, "e2b: request failed", metadataAttrs(ctx,
+			slog.String("method", req.Method),
+			slog.String("url", reqURL),
+			slog.Duration("duration", time.Since(start)),
+			slog.Any("error", err),
+		)...)
+		return nil, err
+	}
+
+	t.logger.DebugContext(ctx, "e2b: received response", metadataAttrs(ctx,
+		slog.String("method", req.Method),
+		slog.String("url", reqURL),
+		slog.Int("status", resp.StatusCode),
+		slog.Duration("duration", time.Since(start)),
+	)...)
+
+	// Streams (commands, PTY, watchers, code execution) stay open until
+	// the body is closed, so its end is logged too
+	resp.Body = &logBody{ReadCloser: resp.Body, onClose: func(n int64) {
+		t.logger.DebugContext(context.WithoutCancel(ctx), "e2b: response body closed", metadataAttrs(ctx,
+			slog.String("method", req.Method),
+			slog.String("url", reqURL),
+			slog.Int64("bytes", n),
+			slog.Duration("duration", time.Since(start)),
+		)...)
+	}}
+	return resp, nil
+}
+
+// logBody reports the number of bytes read from a response body when it is
+// closed.
+type logBody struct {
+	io.ReadCloser
+	n       atomic.Int64
+	once    sync.Once
+	onClose func(n int64)
+}
+
+func (b *logBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n.Add(int64(n))
+	return n, err
+}
+
+func (b *logBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() { b.onClose(b.n.Load()) })
+	return err
+}
+
+// redactURL returns u as a string with sensitive query parameters redacted.
+func redactURL(u *url.URL) string {
+	query := u.Query()
+	changed := false
+	for name := range query {
+		if sensitiveQueryParams[strings.ToLower(name)] {
+			query.Set(name, redacted)
+			changed = true
+		}
+	}
+	if !changed {
+		return u.String()
+	}
+	c := *u
+	c.RawQuery = query.Encode()
+	return c.String()
+}
+
+// redactHeaders returns the headers as a log attribute group, sorted by
+// name, with sensitive values redacted.
+func redactHeaders(header http.Header) slog.Attr {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	attrs := make([]any, 0, len(names))
+	for _, name := range names {
+		value := header.Get(name)
+		if sensitiveHeaders[http.CanonicalHeaderKey(name)] {
+			value = redacted
+		}
+		attrs = append(attrs, slog.String(name, value))
+	}
+	return slog.Group("headers", attrs...)
+}
+
+// loggedHTTPClient returns a copy of client logging its requests to
+// logger, or client itself if it already does.
+func loggedHTTPClient(client *http.Client, logger *slog.Logger) *http.Client {
+	transport := client.Transport
+	if traced, ok := transport.(*traceTransport); ok {
+		transport = traced.base
+	}
+	if _, logged := transport.(*logTransport); logged {
+		return client
+	}
+
+	logged := *client
+	logged.Transport = &logTransport{base: client.Transport, logger: logger}
+	return &logged
+}
@@ -3,9 +3,15 @@ package e2b
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
+	"maps"
+	"net"
 	"net/http"
+	"net/http/httptrace"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 )
 
@@ -23,6 +29,91 @@ type NetworkOptions struct {
 	MaskRequestHost string
 }
 
+// isZero reports whether no network option is set.
+func (n *NetworkOptions) isZero() bool {
+	return len(n.AllowOut) == 0 && len(n.DenyOut) == 0 && !n.AllowPublicTraffic && n.MaskRequestHost == ""
+}
+
+// validate checks that every AllowOut and DenyOut entry is an IP address,
+// a CIDR block or a hostname.
+func (n *NetworkOptions) validate() error {
+	for _, list := range []struct {
+		name    string
+		entries []string
+	}{{"AllowOut", n.AllowOut}, {"DenyOut", n.DenyOut}} {
+		for _, entry := range list.entries {
+			if !isValidNetworkDestination(entry) {
+				return fmt.Errorf("%w: invalid %s destination %q: must be an IP address, CIDR block or hostname",
+					ErrInvalidArgument, list.name, entry)
+			}
+		}
+	}
+	return nil
+}
+
+// clone returns a deep copy of n.
+func (n *NetworkOptions) clone() *NetworkOptions {
+	c := *n
+	c.AllowOut = append([]string(nil), n.AllowOut...)
+	c.DenyOut = append([]string(nil), n.DenyOut...)
+	return &c
+}
+
+// isValidNetworkDestination reports whether dest is an IP address, a CIDR
+// block or a hostname, optionally with a leading "*." wildcard label.
+func isValidNetworkDestination(dest string) bool {
+	if net.ParseIP(dest) != nil {
+		return true
+	}
+	if _, _, err := net.ParseCIDR(dest); err == nil {
+		return true
+	}
+
+	host := strings.TrimSuffix(strings.TrimPrefix(dest, "*."), ".")
+	if host == "" || len(host) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(host, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// McpServerConfig configures an MCP server started by the sandbox's MCP
+// gateway. Set Command for a server run as a process in the sandbox, or URL
+// for a remote server.
+type McpServerConfig struct {
+	// Command is the executable starting a stdio MCP server.
+	Command string `json:"command,omitempty"`
+	// Args are the arguments passed to Command.
+	Args []string `json:"args,omitempty"`
+	// Env sets environment variables for the server process.
+	Env map[string]string `json:"env,omitempty"`
+	// URL is the address of a remote MCP server.
+	URL string `json:"url,omitempty"`
+}
+
+// validateMcp checks the MCP configuration: server names must not be empty
+// and servers set with WithMcpServers need a command or a URL.
+func validateMcp(mcp map[string]any) error {
+	for name, value := range mcp {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("%w: MCP server name is required", ErrInvalidArgument)
+		}
+		if server, ok := value.(McpServerConfig); ok && server.Command == "" && server.URL == "" {
+			return fmt.Errorf("%w: MCP server %q needs a command or a URL", ErrInvalidArgument, name)
+		}
+	}
+	return nil
+}
+
 // SandboxLifecycle configures the sandbox lifecycle behavior.
 type SandboxLifecycle struct {
 	// OnTimeout specifies what happens when the sandbox times out.
@@ -43,25 +134,38 @@ type VolumeMountConfig struct {
 
 // sandboxConfig holds configuration for creating a Sandbox.
 type sandboxConfig struct {
-	apiKey              string              // E2B API key for authentication
-	accessToken         string              // envd access token for sandbox operations
-	domain              string              // base domain for E2B services (default: e2b.app)
-	apiURL              string              // E2B API URL (default: https://api.{domain})
-	sandboxURL          string              // sandbox connection URL override
-	template            string              // sandbox template name or ID
-	timeoutMs           time.Duration       // sandbox lifetime timeout
-	requestTimeout      time.Duration       // default timeout for HTTP requests
-	httpClient          *http.Client        // HTTP client for API requests
-	debug               bool                // enable debug mode (uses HTTP instead of HTTPS)
-	secure              bool                // enable secure mode for sandbox traffic
-	allowInternetAccess bool                // allow sandbox to access the internet
-	autoPause           bool                // automatically pause sandbox after timeout (deprecated)
-	lifecycle           *SandboxLifecycle   // lifecycle configuration (replaces autoPause)
-	volumeMounts        []VolumeMountConfig // volumes to mount in the sandbox
-	metadata            map[string]string   // custom metadata for the sandbox
-	envVars             map[string]string   // default environment variables
-	network             *NetworkOptions     // network access configuration
-	mcp                 map[string]any      // MCP server configuration
+	apiKey              string                 // E2B API key for authentication
+	accessToken         string                 // envd access token for sandbox operations
+	domain              string                 // base domain for E2B services (default: e2b.app)
+	apiURL              string                 // E2B API URL (default: https://api.{domain})
+	sandboxURL          string                 // sandbox connection URL override
+	template            string                 // sandbox template name or ID
+	timeoutMs           time.Duration          // sandbox lifetime timeout
+	requestTimeout      time.Duration          // default timeout for HTTP requests
+	httpClient          *http.Client           // HTTP client for API requests
+	debug               bool                   // enable debug mode (uses HTTP instead of HTTPS)
+	secure              bool                   // enable secure mode for sandbox traffic
+	allowInternetAccess bool                   // allow sandbox to access the internet
+	autoPause           bool                   // automatically pause sandbox after timeout (deprecated)
+	lifecycle           *SandboxLifecycle      // lifecycle configuration (replaces autoPause)
+	volumeMounts        []VolumeMountConfig    // volumes to mount in the sandbox
+	metadata            map[string]string      // custom metadata for the sandbox
+	envVars             map[string]string      // default environment variables
+	network             *NetworkOptions        // network access configuration
+	mcp                 map[string]any         // MCP server configuration
+	readOnly            bool                   // connect without resuming the sandbox
+	envdUser            string                 // default user for filesystem, command and PTY operations
+	httpTrace           *httptrace.ClientTrace // trace hooks attached to every HTTP request
+	logger              *slog.Logger           // logger for HTTP requests, retries and streams
+	requestInterceptors []func(*http.Request)  // hooks run on every outgoing HTTP request
+	respInterceptors    []func(*http.Response) // hooks run on every HTTP response
+	retryAttempts       int                    // attempts for sandbox lifecycle API calls (1 disables retries)
+	retryBaseDelay      time.Duration          // initial backoff between sandbox lifecycle API attempts
+	maxStreamLineSize   int                    // maximum size of a line of code execution output
+
+	// proxyURL is the proxy set with WithProxy, cleared once it has been
+	// applied to httpClient
+	proxyURL string
 }
 
 // defaultSandboxConfig returns the default sandbox configuration.
@@ -73,6 +177,9 @@ func defaultSandboxConfig() *sandboxConfig {
 		requestTimeout:      DefaultRequestTimeout,
 		secure:              true, // Enable secure mode by default for filesystem access
 		allowInternetAccess: true, // Allow internet access by default
+		retryAttempts:       DefaultRetryAttempts,
+		retryBaseDelay:      DefaultRetryBaseDelay,
+		maxStreamLineSize:   DefaultMaxStreamLineSize,
 	}
 }
 
@@ -155,12 +262,128 @@ func (c *sandboxConfig) computeAPIURL() {
 }
 
 // ensureHTTPClient creates the HTTP client if not already set.
-func (c *sandboxConfig) ensureHTTPClient() {
+func (c *sandboxConfig) ensureHTTPClient() error {
 	if c.httpClient == nil {
 		c.httpClient = &http.Client{
 			Timeout: c.requestTimeout,
 		}
 	}
+
+	// Clear the proxy once applied so a copied configuration does not
+	// apply it again
+	if c.proxyURL != "" {
+		client, err := proxyHTTPClient(c.httpClient, c.proxyURL)
+		if err != nil {
+			return err
+		}
+		c.httpClient = client
+		c.proxyURL = ""
+	}
+
+	// Wrap a copy of the client so a user-provided client is not modified.
+	// Interceptors are cleared once applied, like the proxy
+	if len(c.requestInterceptors) > 0 || len(c.respInterceptors) > 0 {
+		client := *c.httpClient
+		client.Transport = &interceptTransport{
+			base:     client.Transport,
+			request:  c.requestInterceptors,
+			response: c.respInterceptors,
+		}
+		c.httpClient = &client
+		c.requestInterceptors = nil
+		c.respInterceptors = nil
+	}
+	if c.logger != nil {
+		c.httpClient = loggedHTTPClient(c.httpClient, c.logger)
+	}
+	if c.httpTrace != nil {
+		if _, traced := c.httpClient.Transport.(*traceTransport); !traced {
+			client := *c.httpClient
+			client.Transport = &traceTransport{base: client.Transport, trace: c.httpTrace}
+			c.httpClient = &client
+		}
+	}
+	return nil
+}
+
+// proxyHTTPClient returns a copy of client sending its requests through the
+// proxy at proxyURL.
+func proxyHTTPClient(client *http.Client, proxyURL string) (*http.Client, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid proxy URL %q: %v", ErrInvalidArgument, proxyURL, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("%w: unsupported proxy URL scheme %q", ErrInvalidArgument, u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("%w: proxy URL %q has no host", ErrInvalidArgument, proxyURL)
+	}
+
+	var transport *http.Transport
+	switch base := client.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = base.Clone()
+	default:
+		return nil, fmt.Errorf("%w: WithProxy requires the HTTP client's transport to be an *http.Transport, got %T", ErrInvalidArgument, base)
+	}
+	transport.Proxy = http.ProxyURL(u)
+
+	proxied := *client
+	proxied.Transport = transport
+	return &proxied, nil
+}
+
+// traceTransport attaches an httptrace.ClientTrace to every request.
+type traceTransport struct {
+	base  http.RoundTripper
+	trace *httptrace.ClientTrace
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(req.WithContext(httptrace.WithClientTrace(req.Context(), t.trace)))
+}
+
+// interceptTransport runs request and response interceptors around every
+// request.
+type interceptTransport struct {
+	base     http.RoundTripper
+	request  []func(*http.Request)
+	response []func(*http.Response)
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *interceptTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	// A RoundTripper must not modify the request it is given
+	if len(t.request) > 0 {
+		req = req.Clone(req.Context())
+		for _, intercept := range t.request {
+			intercept(req)
+		}
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	for _, intercept := range t.response {
+		intercept(resp)
+	}
+	return resp, nil
 }
 
 // Option configures a Sandbox.
@@ -240,6 +463,38 @@ func WithHTTPClient(client *http.Client) Option {
 	}
 }
 
+// WithProxy sends all HTTP requests, both to the E2B API and to the
+// sandbox, through the proxy at proxyURL, such as
+// "http://proxy.corp.example:3128". The http, https and socks5 schemes are
+// supported, and credentials can be given in the URL.
+//
+// The proxy replaces the HTTP_PROXY and HTTPS_PROXY environment variables
+// otherwise used by the default client. When combined with WithHTTPClient,
+// the client's transport must be nil or an *http.Transport, which is copied
+// rather than modified. An invalid URL makes New and the other functions
+// taking Options return an error wrapping ErrInvalidArgument. An empty URL
+// leaves the proxy unset.
+//
+// Example:
+//
+//	sandbox, err := e2b.New(e2b.WithProxy("http://proxy.corp.example:3128"))
+func WithProxy(proxyURL string) Option {
+	return func(c *sandboxConfig) {
+		c.proxyURL = proxyURL
+	}
+}
+
+// WithMaxStreamLineSize sets the maximum size in bytes of a single line of
+// code execution output, such as a long printed line or a large result.
+// RunCode fails with an error wrapping ErrStreamLineTooLong when a line
+// exceeds it. Values of zero or less use the default.
+// Defaults to DefaultMaxStreamLineSize (10 MiB).
+func WithMaxStreamLineSize(bytes int) Option {
+	return func(c *sandboxConfig) {
+		c.maxStreamLineSize = bytes
+	}
+}
+
 // WithDebug enables debug mode (uses HTTP instead of HTTPS).
 // Defaults to E2B_DEBUG environment variable or false.
 func WithDebug(debug bool) Option {
@@ -351,6 +606,10 @@ func WithTracestate(ts string) Option {
 // WithNetwork sets network options for the sandbox.
 // This allows fine-grained control over network access.
 //
+// AllowOut and DenyOut entries must be IP addresses, CIDR blocks such as
+// "10.0.0.0/8" or hostnames; otherwise New returns an error wrapping
+// ErrInvalidArgument. Options with no field set are not sent to the API.
+//
 // Example:
 //
 //	sandbox, err := e2b.New(e2b.WithNetwork(e2b.NetworkOptions{
@@ -378,17 +637,232 @@ func WithMcp(mcp map[string]any) Option {
 	}
 }
 
+// WithMcpServers configures the MCP servers started by the sandbox's MCP
+// gateway, keyed by server name. Servers are added to the configuration set
+// with WithMcp, replacing entries of the same name. New returns an error
+// wrapping ErrInvalidArgument if a name is empty or a server has neither a
+// Command nor a URL.
+//
+// Example:
+//
+//	sandbox, err := e2b.New(e2b.WithMcpServers(map[string]e2b.McpServerConfig{
+//	    "filesystem": {
+//	        Command: "npx",
+//	        Args:    []string{"-y", "@modelcontextprotocol/server-filesystem", "/home/user"},
+//	    },
+//	    "search": {URL: "https://mcp.example.com/sse"},
+//	}))
+func WithMcpServers(servers map[string]McpServerConfig) Option {
+	return func(c *sandboxConfig) {
+		// Copy so a map passed to WithMcp is not modified
+		mcp := make(map[string]any, len(c.mcp)+len(servers))
+		for name, value := range c.mcp {
+			mcp[name] = value
+		}
+		for name, server := range servers {
+			mcp[name] = server
+		}
+		c.mcp = mcp
+	}
+}
+
+// WithReadOnly makes Connect return a read-only sandbox handle.
+// A read-only handle is created from the sandbox info endpoint instead of the
+// connect endpoint, so paused sandboxes are not resumed (and not billed).
+// It supports GetInfo, GetMetrics and GetLogs; code execution, commands,
+// filesystem and PTY operations return ErrReadOnly. Closing a read-only
+// handle does not kill the sandbox.
+//
+// This option has no effect when creating a new sandbox.
+//
+// Example:
+//
+//	sandbox, err := e2b.ConnectWithContext(ctx, "sandbox-id", e2b.WithReadOnly(true))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	info, err := sandbox.GetInfo(ctx)
+func WithReadOnly(readOnly bool) Option {
+	return func(c *sandboxConfig) {
+		c.readOnly = readOnly
+	}
+}
+
+// WithHTTPTrace attaches an httptrace.ClientTrace to every HTTP request made
+// by the sandbox, including control plane calls (create, connect, kill, ...),
+// code execution and envd requests.
+//
+// The trace hooks are invoked per request, possibly concurrently, so they
+// must be safe for concurrent use. Long-lived envd RPC streams (commands,
+// PTY, watchers) only report connection establishment and the first
+// response; hooks such as GotFirstResponseByte may not fire depending on the
+// transport and protocol in use.
+//
+// Example:
+//
+//	trace := &httptrace.ClientTrace{
+//	    DNSDone: func(info httptrace.DNSDoneInfo) { log.Printf("dns: %v", info.Addrs) },
+//	    TLSHandshakeDone: func(tls.ConnectionState, error) { log.Print("tls done") },
+//	    GotFirstResponseByte: func() { log.Print("first byte") },
+//	}
+//	sandbox, err := e2b.New(e2b.WithHTTPTrace(trace))
+func WithHTTPTrace(trace *httptrace.ClientTrace) Option {
+	return func(c *sandboxConfig) {
+		c.httpTrace = trace
+	}
+}
+
+// WithLogger logs the HTTP requests made by the sandbox to logger at Debug
+// level: control plane calls, code execution, and the envd requests and
+// streams of Files, Commands and Pty. Each request is logged with its
+// method, URL and headers, and each response with its status and duration.
+// When a response body, such as a command or watch stream, is closed, the
+// bytes read and the total duration are logged. Retries of control plane
+// calls are logged with their delay. Code execution requests also carry the
+// metadata set with WithExecutionMetadata.
+//
+// API keys, access tokens and URL signatures are redacted. Without a logger
+// nothing is logged.
+//
+// Example:
+//
+//	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+//	sandbox, err := e2b.New(e2b.WithLogger(logger))
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *sandboxConfig) {
+		c.logger = logger
+	}
+}
+
+// WithRequestInterceptor calls intercept with every HTTP request made by the
+// sandbox before it is sent: control plane calls (create, connect, kill,
+// ...), code execution, and the envd requests and RPC streams of Files,
+// Commands and Pty. intercept may modify the request, for example to add
+// tracing headers. It is called on each retry attempt.
+//
+// The option can be given more than once; interceptors run in the order
+// they were added. They are called concurrently for concurrent requests, so
+// they must be safe for concurrent use.
+//
+// Example:
+//
+//	sandbox, err := e2b.New(e2b.WithRequestInterceptor(func(req *http.Request) {
+//	    otel.GetTextMapPropagator().Inject(req.Context(), propagation.HeaderCarrier(req.Header))
+//	}))
+func WithRequestInterceptor(intercept func(*http.Request)) Option {
+	return func(c *sandboxConfig) {
+		c.requestInterceptors = append(c.requestInterceptors, intercept)
+	}
+}
+
+// WithResponseInterceptor calls intercept with every HTTP response received
+// by the sandbox, for the same requests as WithRequestInterceptor. It is
+// called once the response headers arrive, before the body is read, so for
+// streams such as command output it runs when the stream starts. Requests
+// that fail without a response are not intercepted.
+//
+// The option can be given more than once; interceptors run in the order
+// they were added and must be safe for concurrent use. An interceptor must
+// not read or close the response body.
+//
+// Example:
+//
+//	sandbox, err := e2b.New(e2b.WithResponseInterceptor(func(resp *http.Response) {
+//	    requests.WithLabelValues(resp.Request.URL.Path, strconv.Itoa(resp.StatusCode)).Inc()
+//	}))
+func WithResponseInterceptor(intercept func(*http.Response)) Option {
+	return func(c *sandboxConfig) {
+		c.respInterceptors = append(c.respInterceptors, intercept)
+	}
+}
+
+// WithEnvdUser sets the default user for all filesystem, command and PTY
+// operations on the sandbox.
+// A user passed to an individual operation still takes precedence. Without
+// this option, operations run as the envd default user (or "user" on envd
+// versions older than 0.4.0).
+//
+// Example:
+//
+//	sandbox, err := e2b.New(e2b.WithEnvdUser("root"))
+func WithEnvdUser(user string) Option {
+	return func(c *sandboxConfig) {
+		c.envdUser = user
+	}
+}
+
+// WithRetry configures retries for the sandbox create, connect, kill and
+// set-timeout API calls. Requests that fail with a network error or a 429,
+// 502, 503 or 504 response are attempted up to maxAttempts times in total,
+// while other errors such as 400, 401, 404 and 500 fail immediately. Retries
+// wait baseDelay before the first retry, doubling the delay (with jitter) on
+// each further attempt. A Retry-After header on the response takes precedence
+// over the computed delay.
+// Defaults to DefaultRetryAttempts attempts with DefaultRetryBaseDelay.
+// A maxAttempts of 1 or less disables retries.
+//
+// Example:
+//
+//	sandbox, err := e2b.NewWithContext(ctx, e2b.WithRetry(5, time.Second))
+func WithRetry(maxAttempts int, baseDelay time.Duration) Option {
+	return func(c *sandboxConfig) {
+		c.retryAttempts = maxAttempts
+		c.retryBaseDelay = baseDelay
+	}
+}
+
+// WithoutRetry disables retries of the sandbox create, connect, kill and
+// set-timeout API calls, so the first failure is returned immediately.
+//
+// Example:
+//
+//	sandbox, err := e2b.NewWithContext(ctx, e2b.WithoutRetry())
+func WithoutRetry() Option {
+	return func(c *sandboxConfig) {
+		c.retryAttempts = 1
+	}
+}
+
+// apiClient returns the HTTP client for sandbox lifecycle API calls, wrapped
+// with the configured retry policy.
+func (c *sandboxConfig) apiClient() *http.Client {
+	if c.retryAttempts <= 1 {
+		return c.httpClient
+	}
+
+	// Wrap a copy of the client so envd and Jupyter requests are not retried
+	var client http.Client
+	if c.httpClient != nil {
+		client = *c.httpClient
+	}
+	client.Transport = &retryTransport{
+		base:        client.Transport,
+		maxAttempts: c.retryAttempts,
+		baseDelay:   c.retryBaseDelay,
+		logger:      c.logger,
+	}
+	return &client
+}
+
 // runConfig holds configuration for running code.
 type runConfig struct {
-	language       string
-	context        *Context
-	envVars        map[string]string
-	timeout        *time.Duration // nil = use default, 0 = no timeout, >0 = use that value
-	requestTimeout time.Duration
-	onStdout       func(OutputMessage)
-	onStderr       func(OutputMessage)
-	onResult       func(*Result)
-	onError        func(*ExecutionError)
+	language           string
+	context            *Context
+	envVars            map[string]string
+	timeout            *time.Duration // nil = use default, 0 = no timeout, >0 = use that value
+	requestTimeout     time.Duration
+	onStdout           func(OutputMessage)
+	onStderr           func(OutputMessage)
+	onResult           func(*Result)
+	onError            func(*ExecutionError)
+	cellID             string
+	firstResultTimeout time.Duration
+	metadata           map[string]string
+	sanitizeHTML       bool
+	retryAttempts      int
+	retryBackoff       time.Duration
+	onExecutionCount   func(int)
+	preferCommands     bool
 }
 
 // defaultRunConfig returns the default run configuration.
@@ -437,6 +911,96 @@ func WithRunRequestTimeout(d time.Duration) RunOption {
 	}
 }
 
+// WithFirstResultTimeout fails the execution with an execution timeout error
+// if no result has arrived within d. Once the first result arrives, only the
+// overall WithRunTimeout applies.
+//
+// This is most useful with RunUntilFirstResult.
+func WithFirstResultTimeout(d time.Duration) RunOption {
+	return func(c *runConfig) {
+		c.firstResultTimeout = d
+	}
+}
+
+// WithExecutionMetadata attaches caller-supplied metadata, such as task or
+// trace IDs, to the execution. The metadata is not sent to the sandbox; it is
+// returned in Execution.Metadata for correlating executions with
+// application-level logs and traces.
+//
+// The metadata is also carried on the context of the execution's HTTP
+// requests: WithLogger logs it as a "metadata" attribute group, and request
+// interceptors and custom transports can read it with
+// ExecutionMetadataFromContext. The map is copied.
+//
+// Example:
+//
+//	execution, err := sandbox.RunCode(ctx, code,
+//	    e2b.WithExecutionMetadata(map[string]string{"task_id": taskID}),
+//	)
+func WithExecutionMetadata(metadata map[string]string) RunOption {
+	metadata = maps.Clone(metadata)
+	return func(c *runConfig) {
+		c.metadata = metadata
+	}
+}
+
+// WithSanitizeHTML sanitizes the HTML of results before they are returned.
+//
+// Result.HTML is passed through a conservative allowlist: script and style
+// elements, event handler and style attributes and unsafe URLs are removed,
+// keeping tables, text formatting, links and images. This makes HTML output
+// from untrusted code safe to render in a web UI. The raw HTML is kept in
+// Result.Extra["raw_html"] for trusted contexts.
+//
+// Default is false.
+func WithSanitizeHTML(sanitize bool) RunOption {
+	return func(c *runConfig) {
+		c.sanitizeHTML = sanitize
+	}
+}
+
+// WithRunRetry retries the execution up to maxAttempts times in total when it
+// fails with a transient infrastructure error showing that the code never
+// reached the kernel: a failure to connect, or a 502 or 503 response while
+// the kernel is still starting. The delay between attempts starts at backoff
+// and doubles after each attempt.
+//
+// Errors raised by the executed code (Execution.Error) are never retried, and
+// neither are failures after the request was sent, such as a reset
+// connection or a 504 response, since the code may already have run. All
+// attempts share the overall execution timeout.
+//
+// Example:
+//
+//	execution, err := sandbox.RunCode(ctx, code, e2b.WithRunRetry(3, 500*time.Millisecond))
+func WithRunRetry(maxAttempts int, backoff time.Duration) RunOption {
+	return func(c *runConfig) {
+		c.retryAttempts = maxAttempts
+		c.retryBackoff = backoff
+	}
+}
+
+// WithPreferCommands makes RunCodeFile run Bash scripts with Commands.Run
+// instead of the code interpreter's Bash kernel. Other languages are not
+// affected.
+//
+// Default is false.
+func WithPreferCommands(prefer bool) RunOption {
+	return func(c *runConfig) {
+		c.preferCommands = prefer
+	}
+}
+
+// WithCellID sets the cell ID identifying the execution, returned in
+// Execution.CellID. The ID must be unique among in-flight executions of the
+// sandbox handle.
+// If not set, a random ID is generated.
+func WithCellID(cellID string) RunOption {
+	return func(c *runConfig) {
+		c.cellID = cellID
+	}
+}
+
 // OnStdout sets a callback for stdout output.
 func OnStdout(handler func(OutputMessage)) RunOption {
 	return func(c *runConfig) {
@@ -500,3 +1064,47 @@ func WithContextRequestTimeout(d time.Duration) ContextOption {
 		c.requestTimeout = d
 	}
 }
+
+// DefaultWaitInterval is the default interval between health checks in
+// Sandbox.WaitUntilReady.
+const DefaultWaitInterval = 500 * time.Millisecond
+
+// waitConfig holds configuration for waiting until a sandbox is ready.
+type waitConfig struct {
+	interval    time.Duration
+	maxAttempts int // 0 = no limit
+	onReady     func(*Sandbox)
+}
+
+// defaultWaitConfig returns the default wait configuration.
+func defaultWaitConfig() *waitConfig {
+	return &waitConfig{
+		interval: DefaultWaitInterval,
+	}
+}
+
+// WaitOption configures Sandbox.WaitUntilReady.
+type WaitOption func(*waitConfig)
+
+// WithWaitInterval sets the interval between health checks.
+// Defaults to DefaultWaitInterval.
+func WithWaitInterval(d time.Duration) WaitOption {
+	return func(c *waitConfig) {
+		c.interval = d
+	}
+}
+
+// WithWaitMaxAttempts limits the number of health checks.
+// Zero (the default) polls until the context is done.
+func WithWaitMaxAttempts(n int) WaitOption {
+	return func(c *waitConfig) {
+		c.maxAttempts = n
+	}
+}
+
+// WithWaitOnReady sets a callback invoked once when the sandbox is ready.
+func WithWaitOnReady(fn func(*Sandbox)) WaitOption {
+	return func(c *waitConfig) {
+		c.onReady = fn
+	}
+}
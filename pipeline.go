@@ -0,0 +1,278 @@
+package e2b
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// PipelineStepKind identifies the kind of a pipeline step.
+type PipelineStepKind string
+
+const (
+	// PipelineStepCode is a step that executes code via RunCode.
+	PipelineStepCode PipelineStepKind = "code"
+	// PipelineStepCommand is a step that runs a shell command via Commands.Run.
+	PipelineStepCommand PipelineStepKind = "command"
+)
+
+// StepResult contains the outcome of a single pipeline step.
+type StepResult struct {
+	// Index is the zero-based position of the step in the pipeline.
+	Index int
+
+	// Kind is the kind of step that was executed.
+	Kind PipelineStepKind
+
+	// Execution is the code execution result (code steps only).
+	Execution *Execution
+
+	// Command is the command result (command steps only).
+	// For commands that exit with a non-zero code, it contains the
+	// captured output and exit code.
+	Command *CommandResult
+
+	// Err is the error that caused the step to fail, if any.
+	// For code steps this is the *ExecutionError reported by the kernel,
+	// for command steps a *CommandExitError on non-zero exit.
+	Err error
+}
+
+// Failed reports whether the step failed.
+func (r *StepResult) Failed() bool {
+	return r.Err != nil
+}
+
+// pipelineStep is a single queued step.
+type pipelineStep struct {
+	kind        PipelineStepKind
+	code        string
+	runOpts     []RunOption
+	cmd         string
+	commandOpts []CommandOption
+}
+
+// pipelineConfig holds configuration for a pipeline.
+type pipelineConfig struct {
+	execContext     *Context
+	cwd             string
+	envs            map[string]string
+	continueOnError bool
+}
+
+// PipelineOption configures a Pipeline.
+type PipelineOption func(*pipelineConfig)
+
+// WithPipelineContext sets the execution context shared by all code steps.
+func WithPipelineContext(c *Context) PipelineOption {
+	return func(cfg *pipelineConfig) {
+		cfg.execContext = c
+	}
+}
+
+// WithPipelineCwd sets the working directory shared by all steps.
+// Command steps run in this directory. If no context is set with
+// WithPipelineContext, a temporary context rooted at this directory is
+// created for code steps and removed when the pipeline finishes.
+func WithPipelineCwd(cwd string) PipelineOption {
+	return func(cfg *pipelineConfig) {
+		cfg.cwd = cwd
+	}
+}
+
+// WithPipelineEnvs sets environment variables shared by all steps.
+func WithPipelineEnvs(envs map[string]string) PipelineOption {
+	return func(cfg *pipelineConfig) {
+		cfg.envs = envs
+	}
+}
+
+// WithPipelineContinueOnError keeps executing remaining steps after a step fails.
+// Defaults to false, which stops the pipeline at the first failure.
+func WithPipelineContinueOnError(continueOnError bool) PipelineOption {
+	return func(cfg *pipelineConfig) {
+		cfg.continueOnError = continueOnError
+	}
+}
+
+// Pipeline chains code execution and shell command steps that run in order
+// against a single sandbox.
+//
+// Use Sandbox.NewPipeline to create a pipeline.
+type Pipeline struct {
+	sandbox *Sandbox
+	config  *pipelineConfig
+	steps   []pipelineStep
+}
+
+// NewPipeline creates a new pipeline bound to this sandbox.
+//
+// Example:
+//
+//	results, err := sandbox.NewPipeline(e2b.WithPipelineCwd("/home/user")).
+//	    Code("open('data.txt', 'w').write('hello')").
+//	    Command("gzip data.txt").
+//	    Command("ls -la data.txt.gz").
+//	    Run(ctx)
+func (s *Sandbox) NewPipeline(opts ...PipelineOption) *Pipeline {
+	cfg := &pipelineConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return &Pipeline{
+		sandbox: s,
+		config:  cfg,
+	}
+}
+
+// Code appends a code execution step.
+// Options are applied after the pipeline's shared settings and take precedence.
+//
+// Note: WithLanguage cannot be combined with a shared pipeline context.
+func (p *Pipeline) Code(code string, opts ...RunOption) *Pipeline {
+	p.steps = append(p.steps, pipelineStep{
+		kind:    PipelineStepCode,
+		code:    code,
+		runOpts: opts,
+	})
+	return p
+}
+
+// Command appends a shell command step.
+// Options are applied after the pipeline's shared settings and take precedence.
+func (p *Pipeline) Command(cmd string, opts ...CommandOption) *Pipeline {
+	p.steps = append(p.steps, pipelineStep{
+		kind:        PipelineStepCommand,
+		cmd:         cmd,
+		commandOpts: opts,
+	})
+	return p
+}
+
+// Len returns the number of steps in the pipeline.
+func (p *Pipeline) Len() int {
+	return len(p.steps)
+}
+
+// Run executes the pipeline steps in order.
+//
+// By default, the pipeline stops at the first failing step (a code error or
+// a non-zero command exit) and returns the results collected so far along
+// with an error describing the failed step. With WithPipelineContinueOnError,
+// all steps run and the returned error joins every step failure.
+func (p *Pipeline) Run(ctx context.Context) ([]StepResult, error) {
+	return p.sandbox.RunPipeline(ctx, p)
+}
+
+// RunPipeline executes a pipeline against this sandbox.
+// The pipeline may have been created from a different sandbox.
+func (s *Sandbox) RunPipeline(ctx context.Context, p *Pipeline) ([]StepResult, error) {
+	if p == nil {
+		return nil, fmt.Errorf("%w: pipeline is nil", ErrInvalidArgument)
+	}
+
+	s.mu.RLock()
+	if s.closed {
+		s.mu.RUnlock()
+		return nil, ErrSandboxClosed
+	}
+	s.mu.RUnlock()
+
+	cfg := p.config
+
+	// Create a temporary context rooted at the shared cwd for code steps
+	execContext := cfg.execContext
+	if execContext == nil && cfg.cwd != "" && p.hasStep(PipelineStepCode) {
+		created, err := s.CreateContext(ctx, WithCWD(cfg.cwd))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create pipeline context: %w", err)
+		}
+		execContext = created
+		defer func() {
+			_ = s.RemoveContext(context.WithoutCancel(ctx), created.ID)
+		}()
+	}
+
+	results := make([]StepResult, 0, len(p.steps))
+	var failures []error
+
+	for i, step := range p.steps {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+
+		result := StepResult{Index: i, Kind: step.kind}
+
+		switch step.kind {
+		case PipelineStepCode:
+			opts := make([]RunOption, 0, len(step.runOpts)+2)
+			if execContext != nil {
+				opts = append(opts, WithContext(execContext))
+			}
+			if cfg.envs != nil {
+				opts = append(opts, WithRunEnvVars(cfg.envs))
+			}
+			opts = append(opts, step.runOpts...)
+
+			execution, err := s.RunCode(ctx, step.code, opts...)
+			if err != nil {
+				return append(results, StepResult{Index: i, Kind: step.kind, Err: err}),
+					fmt.Errorf("pipeline step %d (%s): %w", i, step.kind, err)
+			}
+			result.Execution = execution
+			if execution.Error != nil {
+				result.Err = execution.Error
+			}
+
+		case PipelineStepCommand:
+			opts := make([]CommandOption, 0, len(step.commandOpts)+2)
+			if cfg.cwd != "" {
+				opts = append(opts, WithCommandCwd(cfg.cwd))
+			}
+			if cfg.envs != nil {
+				opts = append(opts, WithCommandEnvs(cfg.envs))
+			}
+			opts = append(opts, step.commandOpts...)
+
+			cmdResult, err := s.Commands.Run(ctx, step.cmd, opts...)
+			if err != nil {
+				var exitErr *CommandExitError
+				if !errors.As(err, &exitErr) {
+					return append(results, StepResult{Index: i, Kind: step.kind, Err: err}),
+						fmt.Errorf("pipeline step %d (%s): %w", i, step.kind, err)
+				}
+				cmdResult = &CommandResult{
+					Stdout:   exitErr.Stdout,
+					Stderr:   exitErr.Stderr,
+					ExitCode: exitErr.ExitCode,
+					Error:    exitErr.ErrorMessage,
+				}
+				result.Err = exitErr
+			}
+			result.Command = cmdResult
+		}
+
+		results = append(results, result)
+
+		if result.Err != nil {
+			stepErr := fmt.Errorf("pipeline step %d (%s): %w", i, step.kind, result.Err)
+			if !cfg.continueOnError {
+				return results, stepErr
+			}
+			failures = append(failures, stepErr)
+		}
+	}
+
+	return results, errors.Join(failures...)
+}
+
+// hasStep reports whether the pipeline contains a step of the given kind.
+func (p *Pipeline) hasStep(kind PipelineStepKind) bool {
+	for _, step := range p.steps {
+		if step.kind == kind {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,449 @@
+package e2b
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultPoolSize is the default maximum number of sandboxes in a pool.
+	DefaultPoolSize = 5
+
+	// DefaultPoolHealthCheckInterval is the default interval at which idle
+	// sandboxes in a pool are health-checked.
+	DefaultPoolHealthCheckInterval = 30 * time.Second
+)
+
+// poolConfig holds configuration for a sandbox pool.
+type poolConfig struct {
+	size                int
+	minIdle             int
+	maxWaitTime         time.Duration
+	resetContexts       bool
+	healthCheckInterval time.Duration
+}
+
+// PoolOption configures a SandboxPool.
+type PoolOption func(*poolConfig)
+
+// WithPoolSize sets the maximum number of sandboxes managed by the pool,
+// both idle and acquired. Defaults to DefaultPoolSize.
+func WithPoolSize(n int) PoolOption {
+	return func(cfg *poolConfig) {
+		cfg.size = n
+	}
+}
+
+// WithPoolMinIdle sets the number of idle sandboxes the pool keeps pre-warmed.
+// Replacements are created in the background whenever the number of idle
+// sandboxes drops below n, as long as the pool size allows it.
+func WithPoolMinIdle(n int) PoolOption {
+	return func(cfg *poolConfig) {
+		cfg.minIdle = n
+	}
+}
+
+// WithPoolMaxWaitTime sets how long Acquire waits for a sandbox to be
+// released when the pool is exhausted. Zero (the default) waits until the
+// context passed to Acquire is done.
+func WithPoolMaxWaitTime(d time.Duration) PoolOption {
+	return func(cfg *poolConfig) {
+		cfg.maxWaitTime = d
+	}
+}
+
+// WithPoolResetContexts restarts all execution contexts of a sandbox with
+// RestartContext when it is released back to the pool, so that state from
+// one task does not leak into the next. Sandboxes that fail to reset are
+// killed instead of being reused.
+func WithPoolResetContexts(reset bool) PoolOption {
+	return func(cfg *poolConfig) {
+		cfg.resetContexts = reset
+	}
+}
+
+// WithPoolHealthCheckInterval sets how often idle sandboxes are checked with
+// IsRunning. Sandboxes that are no longer running are evicted from the pool.
+// Defaults to DefaultPoolHealthCheckInterval.
+func WithPoolHealthCheckInterval(d time.Duration) PoolOption {
+	return func(cfg *poolConfig) {
+		cfg.healthCheckInterval = d
+	}
+}
+
+// PoolStats describes the current state of a SandboxPool.
+type PoolStats struct {
+	// Idle is the number of sandboxes ready to be acquired.
+	Idle int
+
+	// InUse is the number of sandboxes currently acquired.
+	InUse int
+
+	// Total is the number of sandboxes managed by the pool, including
+	// sandboxes that are still being created.
+	Total int
+}
+
+// SandboxPool keeps a set of sandboxes alive so that short-lived tasks can
+// reuse them instead of paying the sandbox startup latency every time.
+//
+// A SandboxPool is safe for concurrent use by multiple goroutines.
+//
+// Use NewSandboxPool to create a pool.
+type SandboxPool struct {
+	config      *poolConfig
+	sandboxOpts []Option
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu       sync.Mutex
+	idle     []*Sandbox
+	inUse    map[*Sandbox]struct{}
+	total    int
+	creating int
+	closed   bool
+	changed  chan struct{} // closed and replaced whenever a sandbox becomes available
+	warm     chan struct{}
+}
+
+// NewSandboxPool creates a pool of sandboxes created with the given sandbox options.
+//
+// If WithPoolMinIdle is set, the pool starts pre-warming sandboxes in the
+// background immediately. Call Close to kill all sandboxes held by the pool.
+//
+// Example:
+//
+//	pool, err := e2b.NewSandboxPool(ctx,
+//	    []e2b.PoolOption{e2b.WithPoolSize(10), e2b.WithPoolMinIdle(2)},
+//	    e2b.WithAPIKey("your-api-key"),
+//	)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer pool.Close(ctx)
+//
+//	sandbox, err := pool.Acquire(ctx)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer pool.Release(sandbox)
+func NewSandboxPool(ctx context.Context, poolOpts []PoolOption, opts ...Option) (*SandboxPool, error) {
+	cfg := &poolConfig{
+		size:                DefaultPoolSize,
+		healthCheckInterval: DefaultPoolHealthCheckInterval,
+	}
+	for _, opt := range poolOpts {
+		opt(cfg)
+	}
+
+	if cfg.size <= 0 {
+		return nil, fmt.Errorf("%w: pool size must be positive", ErrInvalidArgument)
+	}
+	if cfg.minIdle < 0 || cfg.minIdle > cfg.size {
+		return nil, fmt.Errorf("%w: pool min idle must be between 0 and the pool size", ErrInvalidArgument)
+	}
+	if cfg.maxWaitTime < 0 {
+		return nil, fmt.Errorf("%w: pool max wait time must not be negative", ErrInvalidArgument)
+	}
+
+	// The pool outlives the call that created it, so only values are kept from ctx
+	poolCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+
+	p := &SandboxPool{
+		config:      cfg,
+		sandboxOpts: opts,
+		ctx:         poolCtx,
+		cancel:      cancel,
+		inUse:       make(map[*Sandbox]struct{}),
+		changed:     make(chan struct{}),
+		warm:        make(chan struct{}, 1),
+	}
+
+	p.wg.Add(1)
+	go p.maintain()
+
+	return p, nil
+}
+
+// Acquire returns an idle sandbox from the pool, creating a new one if the
+// pool has not reached its size. If the pool is exhausted, Acquire waits
+// until a sandbox is released, ctx is done or the max wait time elapses.
+//
+// Every acquired sandbox must be returned with Release.
+func (p *SandboxPool) Acquire(ctx context.Context) (*Sandbox, error) {
+	var waitExpired <-chan time.Time
+	if p.config.maxWaitTime > 0 {
+		timer := time.NewTimer(p.config.maxWaitTime)
+		defer timer.Stop()
+		waitExpired = timer.C
+	}
+
+	for {
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			return nil, ErrPoolClosed
+		}
+
+		if n := len(p.idle); n > 0 {
+			sandbox := p.idle[n-1]
+			p.idle = p.idle[:n-1]
+			p.inUse[sandbox] = struct{}{}
+			p.mu.Unlock()
+			p.requestWarm()
+			return sandbox, nil
+		}
+
+		if p.total < p.config.size {
+			p.total++
+			p.mu.Unlock()
+
+			sandbox, err := NewWithContext(ctx, p.sandboxOpts...)
+			if err != nil {
+				p.mu.Lock()
+				p.total--
+				p.notifyLocked()
+				p.mu.Unlock()
+				return nil, fmt.Errorf("failed to create pooled sandbox: %w", err)
+			}
+
+			p.mu.Lock()
+			if p.closed {
+				p.total--
+				p.mu.Unlock()
+				_ = sandbox.CloseWithContext(context.WithoutCancel(ctx))
+				return nil, ErrPoolClosed
+			}
+			p.inUse[sandbox] = struct{}{}
+			p.mu.Unlock()
+			return sandbox, nil
+		}
+
+		changed := p.changed
+		p.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-waitExpired:
+			return nil, fmt.Errorf("%w: no pooled sandbox available within %s", ErrTimeout, p.config.maxWaitTime)
+		case <-ctx.Done():
+			return nil, fmt.Errorf("no pooled sandbox available: %w", ctx.Err())
+		}
+	}
+}
+
+// Release returns an acquired sandbox to the pool.
+//
+// Sandboxes that were closed while acquired are removed from the pool. If
+// WithPoolResetContexts is set, the sandbox's execution contexts are
+// restarted before it becomes available again. Releasing a sandbox after
+// the pool was closed kills it.
+func (p *SandboxPool) Release(sandbox *Sandbox) error {
+	if sandbox == nil {
+		return fmt.Errorf("%w: sandbox is required", ErrInvalidArgument)
+	}
+
+	p.mu.Lock()
+	if _, ok := p.inUse[sandbox]; !ok {
+		p.mu.Unlock()
+		return fmt.Errorf("%w: sandbox %s was not acquired from this pool", ErrInvalidArgument, sandbox.ID)
+	}
+	delete(p.inUse, sandbox)
+	closed := p.closed
+	p.mu.Unlock()
+
+	keep := !closed && !sandbox.IsClosed()
+	if keep && p.config.resetContexts {
+		keep = p.resetContexts(sandbox) == nil
+	}
+
+	p.mu.Lock()
+	if keep && !p.closed {
+		p.idle = append(p.idle, sandbox)
+		p.notifyLocked()
+		p.mu.Unlock()
+		return nil
+	}
+	p.total--
+	p.notifyLocked()
+	p.mu.Unlock()
+
+	p.requestWarm()
+	return sandbox.CloseWithContext(context.WithoutCancel(p.ctx))
+}
+
+// Stats returns the current state of the pool.
+func (p *SandboxPool) Stats() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return PoolStats{
+		Idle:  len(p.idle),
+		InUse: len(p.inUse),
+		Total: p.total,
+	}
+}
+
+// Close stops pre-warming and kills all idle sandboxes held by the pool.
+// Sandboxes that are still acquired are killed when they are released.
+//
+// After calling Close, Acquire returns ErrPoolClosed.
+func (p *SandboxPool) Close(ctx context.Context) error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	idle := p.idle
+	p.idle = nil
+	p.total -= len(idle)
+	p.notifyLocked()
+	p.mu.Unlock()
+
+	// Stop the background goroutines before killing so no replacements appear
+	p.cancel()
+	p.wg.Wait()
+
+	var errs []error
+	for _, sandbox := range idle {
+		if err := sandbox.CloseWithContext(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("sandbox %s: %w", sandbox.ID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// resetContexts restarts every execution context of a released sandbox.
+func (p *SandboxPool) resetContexts(sandbox *Sandbox) error {
+	contexts, err := sandbox.ListContexts(p.ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range contexts {
+		if err := sandbox.RestartContext(p.ctx, c.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// notifyLocked wakes up all goroutines waiting in Acquire.
+// p.mu must be held.
+func (p *SandboxPool) notifyLocked() {
+	close(p.changed)
+	p.changed = make(chan struct{})
+}
+
+// requestWarm asks the maintenance goroutine to top up idle sandboxes.
+func (p *SandboxPool) requestWarm() {
+	select {
+	case p.warm <- struct{}{}:
+	default:
+	}
+}
+
+// maintain pre-warms idle sandboxes and periodically evicts stale ones
+// until the pool is closed.
+func (p *SandboxPool) maintain() {
+	defer p.wg.Done()
+
+	var tick <-chan time.Time
+	if p.config.healthCheckInterval > 0 {
+		ticker := time.NewTicker(p.config.healthCheckInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	p.prewarm()
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-p.warm:
+		case <-tick:
+			p.evictStale()
+		}
+		p.prewarm()
+	}
+}
+
+// prewarm starts creating sandboxes until the configured number of idle
+// sandboxes is reached or the pool is full.
+func (p *SandboxPool) prewarm() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for !p.closed && len(p.idle)+p.creating < p.config.minIdle && p.total < p.config.size {
+		p.total++
+		p.creating++
+		p.wg.Add(1)
+		go p.createIdle()
+	}
+}
+
+// createIdle creates a sandbox and adds it to the idle list.
+func (p *SandboxPool) createIdle() {
+	defer p.wg.Done()
+
+	sandbox, err := NewWithContext(p.ctx, p.sandboxOpts...)
+
+	p.mu.Lock()
+	p.creating--
+	if err != nil || p.closed {
+		p.total--
+		p.notifyLocked()
+		p.mu.Unlock()
+		if sandbox != nil {
+			_ = sandbox.CloseWithContext(context.WithoutCancel(p.ctx))
+		}
+		return
+	}
+	p.idle = append(p.idle, sandbox)
+	p.notifyLocked()
+	p.mu.Unlock()
+}
+
+// evictStale health-checks idle sandboxes and kills the ones that are no
+// longer running.
+func (p *SandboxPool) evictStale() {
+	p.mu.Lock()
+	idle := append([]*Sandbox(nil), p.idle...)
+	p.mu.Unlock()
+
+	for _, sandbox := range idle {
+		running, err := sandbox.IsRunning(p.ctx)
+		if p.ctx.Err() != nil {
+			return
+		}
+		if err == nil && running {
+			continue
+		}
+
+		// The sandbox may have been acquired while it was being checked
+		p.mu.Lock()
+		removed := false
+		for i, s := range p.idle {
+			if s == sandbox {
+				p.idle = append(p.idle[:i], p.idle[i+1:]...)
+				p.total--
+				p.notifyLocked()
+				removed = true
+				break
+			}
+		}
+		p.mu.Unlock()
+
+		if removed {
+			_ = sandbox.CloseWithContext(p.ctx)
+		}
+	}
+}
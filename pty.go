@@ -28,9 +28,10 @@ type Pty struct {
 func newPty(sandbox *Sandbox) *Pty {
 	base := newRPCClient(sandbox)
 
+	client, baseURL := base.connectClient()
 	processClient := processpbconnect.NewProcessClient(
-		base.httpClient,
-		base.envdBaseURL,
+		client,
+		baseURL,
 		connect.WithGRPCWeb(),
 	)
 
@@ -117,9 +118,9 @@ func (p *Pty) Create(ctx context.Context, size PtySize, opts ...PtyOption) (*Com
 		pty:      p,
 		stream:   stream,
 		done:     make(chan struct{}),
-		exitCode: -1,
 		onStdout: cfg.onStdout,
 		onStderr: cfg.onStderr,
+		onData:   cfg.onData,
 		isPty:    true,
 	}
 
@@ -178,9 +179,9 @@ func (p *Pty) Connect(ctx context.Context, pid uint32, opts ...PtyConnectOption)
 		pty:           p,
 		connectStream: stream,
 		done:          make(chan struct{}),
-		exitCode:      -1,
 		onStdout:      cfg.onStdout,
 		onStderr:      cfg.onStderr,
+		onData:        cfg.onData,
 		isPty:         true,
 	}
 
@@ -11,6 +11,7 @@ type ptyConfig struct {
 	requestTimeout time.Duration
 	onStdout       func(output string)
 	onStderr       func(output string)
+	onData         func(data []byte)
 }
 
 // defaultPtyConfig returns a default ptyConfig.
@@ -73,12 +74,22 @@ func OnPtyStderr(handler func(output string)) PtyOption {
 	}
 }
 
+// OnPtyData sets a callback receiving the raw terminal output of the PTY,
+// including escape sequences, as it arrives. Unlike OnPtyStdout, the bytes
+// are passed unmodified, which suits terminal emulators.
+func OnPtyData(handler func(data []byte)) PtyOption {
+	return func(c *ptyConfig) {
+		c.onData = handler
+	}
+}
+
 // ptyConnectConfig holds configuration for connecting to a PTY.
 type ptyConnectConfig struct {
 	timeout        time.Duration
 	requestTimeout time.Duration
 	onStdout       func(output string)
 	onStderr       func(output string)
+	onData         func(data []byte)
 }
 
 // PtyConnectOption configures PTY connection behavior.
@@ -112,6 +123,14 @@ func OnPtyConnectStderr(handler func(output string)) PtyConnectOption {
 	}
 }
 
+// OnPtyConnectData sets a callback receiving the raw terminal output of the
+// PTY when connecting (see OnPtyData).
+func OnPtyConnectData(handler func(data []byte)) PtyConnectOption {
+	return func(c *ptyConnectConfig) {
+		c.onData = handler
+	}
+}
+
 // ptyRequestConfig holds configuration for PTY requests.
 type ptyRequestConfig struct {
 	requestTimeout time.Duration
@@ -0,0 +1,121 @@
+package e2b
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// Terminal returns the PTY as an io.ReadWriteCloser for connecting it to a
+// terminal emulator, such as xterm.js over a websocket or the local terminal
+// through golang.org/x/term.
+//
+// Read returns the raw PTY output, starting with the output received before
+// Terminal was first called, and io.EOF once the PTY has exited and all
+// output has been read. Write sends input to the PTY and Close kills it.
+// Output is buffered up to a limit; beyond it, the handle waits for the
+// output to be read, so a terminal must be read until EOF or closed.
+// Repeated calls return the same terminal. Terminal returns nil if the
+// handle is not a PTY.
+//
+// Example:
+//
+//	handle, err := sandbox.Pty.Create(ctx, e2b.PtySize{Rows: 24, Cols: 80})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	term := handle.Terminal()
+//	defer term.Close()
+//	go io.Copy(term, os.Stdin)
+//	io.Copy(os.Stdout, term)
+func (h *CommandHandle) Terminal() io.ReadWriteCloser {
+	if !h.isPty || h.pty == nil {
+		return nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.terminal == nil {
+		h.terminal = &ptyTerminal{
+			handle:  h,
+			pending: []byte(h.stdout.String()),
+			data:    make(chan []byte, ptyTerminalBufferSize),
+			closed:  make(chan struct{}),
+		}
+	}
+	return h.terminal
+}
+
+// ptyTerminal adapts a PTY handle to io.ReadWriteCloser.
+type ptyTerminal struct {
+	handle *CommandHandle
+
+	// pending is output that was received but not yet returned by Read. It
+	// is only accessed by Read.
+	pending []byte
+
+	// data carries the PTY output from the event stream to Read
+	data chan []byte
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// push queues PTY output for Read, waiting while the buffer is full unless
+// the terminal is closed.
+func (t *ptyTerminal) push(b []byte) {
+	chunk := make([]byte, len(b))
+	copy(chunk, b)
+
+	select {
+	case t.data <- chunk:
+	case <-t.closed:
+	}
+}
+
+// Read implements io.Reader.
+func (t *ptyTerminal) Read(p []byte) (int, error) {
+	if len(t.pending) == 0 {
+		select {
+		case t.pending = <-t.data:
+		case <-t.closed:
+			return 0, io.ErrClosedPipe
+		case <-t.handle.done:
+			// Output queued before the PTY exited is still returned
+			select {
+			case t.pending = <-t.data:
+			default:
+				return 0, io.EOF
+			}
+		}
+	}
+
+	n := copy(p, t.pending)
+	t.pending = t.pending[n:]
+	return n, nil
+}
+
+// Write implements io.Writer by sending p as input to the PTY.
+func (t *ptyTerminal) Write(p []byte) (int, error) {
+	select {
+	case <-t.closed:
+		return 0, io.ErrClosedPipe
+	default:
+	}
+
+	if err := t.handle.pty.SendStdin(context.Background(), t.handle.pid, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close implements io.Closer by killing the PTY. Pending reads and writes
+// return io.ErrClosedPipe.
+func (t *ptyTerminal) Close() error {
+	var err error
+	t.closeOnce.Do(func() {
+		close(t.closed)
+		_, err = t.handle.KillWithContext(context.Background())
+	})
+	return err
+}
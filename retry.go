@@ -0,0 +1,131 @@
+package e2b
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxRetryDelay caps the backoff between retries, including delays requested
+// through Retry-After.
+const maxRetryDelay = 30 * time.Second
+
+// retryTransport retries requests that fail with a network error or a 429,
+// 502, 503 or 504 response, using exponential backoff with jitter.
+//
+// Only the status line is inspected before retrying, so a response that is
+// returned to the caller is never retried once its body has been read.
+type retryTransport struct {
+	base        http.RoundTripper
+	maxAttempts int
+	baseDelay   time.Duration
+	logger      *slog.Logger // logs retries, if set
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := base.RoundTrip(req)
+		if attempt >= t.maxAttempts || !isRetryableResponse(req.Context(), resp, err) {
+			return resp, err
+		}
+
+		// A request body can only be replayed if it can be recreated
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return resp, err
+			}
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return resp, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		delay := t.backoff(attempt)
+		if resp != nil {
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+				delay = min(retryAfter, maxRetryDelay)
+			}
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+		if t.logger != nil {
+			attrs := []any{
+				slog.String("method", req.Method),
+				slog.String("url", redactURL(req.URL)),
+				slog.Int("attempt", attempt),
+				slog.Duration("delay", delay),
+			}
+			if resp != nil {
+				attrs = append(attrs, slog.Int("status", resp.StatusCode))
+			} else {
+				attrs = append(attrs, slog.Any("error", err))
+			}
+			t.logger.DebugContext(req.Context(), "e2b: retrying request", attrs...)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns the delay before the retry following the given attempt:
+// baseDelay doubled per attempt, with up to half of it replaced by jitter.
+func (t *retryTransport) backoff(attempt int) time.Duration {
+	delay := t.baseDelay << (attempt - 1)
+	if delay <= 0 || delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	half := delay / 2
+	return half + rand.N(half+1)
+}
+
+// isRetryableResponse reports whether a request should be retried given the
+// outcome of an attempt.
+func isRetryableResponse(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		// Cancellation by the caller is final
+		return ctx.Err() == nil && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	// Other errors, including 500, may come from a request that took effect
+	return false
+}
+
+// parseRetryAfter parses a Retry-After header given either in seconds or as
+// an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
@@ -4,6 +4,9 @@ import (
 	"encoding/base64"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync"
 
 	"connectrpc.com/connect"
 )
@@ -11,16 +14,68 @@ import (
 // rpcClient provides common RPC client functionality shared across
 // Commands, Filesystem, and Pty services.
 type rpcClient struct {
-	httpClient   *http.Client
-	envdBaseURL  string
+	httpClient *http.Client
+	conn       *envdConn
+	user       string
+}
+
+// envdConn holds the connection details shared by the envd and Jupyter
+// clients of a sandbox. Refresh, Reconnect and BetaResume update them in
+// place, so the clients never change while operations pick up the new
+// values.
+type envdConn struct {
+	mu   sync.RWMutex
+	info envdConnInfo
+}
+
+// envdConnInfo is a snapshot of the connection details of a sandbox.
+type envdConnInfo struct {
+	envdURL      string
+	jupyterURL   string
 	accessToken  string
 	trafficToken string
 	envdVersion  string
 }
 
+// load returns the current connection details.
+func (c *envdConn) load() envdConnInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.info
+}
+
+// store replaces the connection details.
+func (c *envdConn) store(info envdConnInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.info = info
+}
+
+// envdDoer sends Connect requests to the current envd URL. Connect clients
+// keep the base URL they were created with, so requests are redirected once
+// a reconnect has changed it.
+type envdDoer struct {
+	client  *http.Client
+	conn    *envdConn
+	baseURL string
+}
+
+// Do implements connect.HTTPClient.
+func (d *envdDoer) Do(req *http.Request) (*http.Response, error) {
+	if current := strings.TrimRight(d.conn.load().envdURL, "/"); current != d.baseURL {
+		u, err := url.Parse(current + strings.TrimPrefix(req.URL.String(), d.baseURL))
+		if err != nil {
+			return nil, fmt.Errorf("invalid envd URL: %w", err)
+		}
+		req.URL = u
+		req.Host = ""
+	}
+	return d.client.Do(req)
+}
+
 // newRPCClient creates a new rpcClient with common configuration.
 func newRPCClient(sandbox *Sandbox) rpcClient {
-	httpClient := sandbox.config.httpClient
+	httpClient := sandbox.envdHTTPClient()
 	if httpClient == nil {
 		httpClient = &http.Client{
 			Timeout: sandbox.config.requestTimeout,
@@ -28,14 +83,28 @@ func newRPCClient(sandbox *Sandbox) rpcClient {
 	}
 
 	return rpcClient{
-		httpClient:   httpClient,
-		envdBaseURL:  sandbox.getEnvdURL(),
-		accessToken:  sandbox.accessToken,
-		trafficToken: sandbox.TrafficAccessToken,
-		envdVersion:  sandbox.envdVersion,
+		httpClient: httpClient,
+		conn:       sandbox.conn,
+		user:       sandbox.config.envdUser,
 	}
 }
 
+// connectClient returns the HTTP client and base URL for a Connect client.
+func (r *rpcClient) connectClient() (connect.HTTPClient, string) {
+	baseURL := strings.TrimRight(r.envdBaseURL(), "/")
+	return &envdDoer{client: r.httpClient, conn: r.conn, baseURL: baseURL}, baseURL
+}
+
+// envdBaseURL returns the current envd URL.
+func (r *rpcClient) envdBaseURL() string {
+	return r.conn.load().envdURL
+}
+
+// envdVersion returns the current envd version.
+func (r *rpcClient) envdVersion() string {
+	return r.conn.load().envdVersion
+}
+
 // setRPCHeaders sets authentication headers on the Connect request.
 func (r *rpcClient) setRPCHeaders(req connect.AnyRequest) {
 	r.setRPCHeadersWithUser(req, "")
@@ -44,17 +113,18 @@ func (r *rpcClient) setRPCHeaders(req connect.AnyRequest) {
 // setRPCHeadersWithUser sets authentication headers including user-based Basic auth.
 func (r *rpcClient) setRPCHeadersWithUser(req connect.AnyRequest, user string) {
 	req.Header().Set("User-Agent", "e2b-go-sdk/"+Version)
-	if r.accessToken != "" {
-		req.Header().Set(headerAccessToken, r.accessToken)
+	conn := r.conn.load()
+	if conn.accessToken != "" {
+		req.Header().Set(headerAccessToken, conn.accessToken)
 	}
-	if r.trafficToken != "" {
-		req.Header().Set(headerTrafficToken, r.trafficToken)
+	if conn.trafficToken != "" {
+		req.Header().Set(headerTrafficToken, conn.trafficToken)
 	}
 
 	// Set Authorization header with Basic auth (username:)
 	// If user is not specified and envd version < 0.4.0, default to "user"
-	effectiveUser := user
-	if effectiveUser == "" && r.compareVersion(EnvdVersionDefaultUser) < 0 {
+	effectiveUser := r.userOrDefault(user)
+	if effectiveUser == "" && compareVersions(conn.envdVersion, EnvdVersionDefaultUser) < 0 {
 		effectiveUser = "user"
 	}
 
@@ -75,18 +145,27 @@ func (r *rpcClient) setStreamingHeadersWithUser(req connect.AnyRequest, user str
 	req.Header().Set(KeepalivePingHeader, fmt.Sprintf("%d", KeepalivePingIntervalSec))
 }
 
+// userOrDefault returns user, or the sandbox-level user set with WithEnvdUser if empty.
+func (r *rpcClient) userOrDefault(user string) string {
+	if user == "" {
+		return r.user
+	}
+	return user
+}
+
 // compareVersion compares the envd version with the given version.
 // Returns -1 if envdVersion < version, 0 if equal, 1 if envdVersion > version.
 func (r *rpcClient) compareVersion(version string) int {
-	return compareVersions(r.envdVersion, version)
+	return compareVersions(r.envdVersion(), version)
 }
 
 // setHTTPHeaders sets authentication headers on an HTTP request.
 func (r *rpcClient) setHTTPHeaders(req *http.Request) {
-	if r.accessToken != "" {
-		req.Header.Set(headerAccessToken, r.accessToken)
+	conn := r.conn.load()
+	if conn.accessToken != "" {
+		req.Header.Set(headerAccessToken, conn.accessToken)
 	}
-	if r.trafficToken != "" {
-		req.Header.Set(headerTrafficToken, r.trafficToken)
+	if conn.trafficToken != "" {
+		req.Header.Set(headerTrafficToken, conn.trafficToken)
 	}
 }
@@ -6,8 +6,11 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
+	"net"
 	"net/http"
 	"net/url"
 	"strings"
@@ -48,12 +51,24 @@ type Sandbox struct {
 	config *sandboxConfig
 	// httpClient is used for API requests.
 	httpClient *httpClient
+	// conn holds the connection details shared by httpClient and the envd
+	// clients.
+	conn *envdConn
 	// closed indicates whether the sandbox has been closed.
 	closed bool
 	// accessToken is the envd access token.
 	accessToken string
 	// envdVersion is the version of the envd service.
 	envdVersion string
+	// readOnly indicates the handle was connected without resuming the sandbox.
+	readOnly bool
+	// paused indicates the sandbox was last known to be paused.
+	paused bool
+
+	// execMu protects executions.
+	execMu sync.Mutex
+	// executions tracks in-flight RunCode calls by cell ID.
+	executions map[string]*trackedExecution
 }
 
 // networkRequestOptions represents network options in the API request.
@@ -136,7 +151,18 @@ func NewWithContext(ctx context.Context, opts ...Option) (*Sandbox, error) {
 	// Apply environment variables and compute defaults
 	cfg.applyEnvironment()
 	cfg.computeAPIURL()
-	cfg.ensureHTTPClient()
+	if err := cfg.ensureHTTPClient(); err != nil {
+		return nil, err
+	}
+
+	if cfg.network != nil {
+		if err := cfg.network.validate(); err != nil {
+			return nil, err
+		}
+	}
+	if err := validateMcp(cfg.mcp); err != nil {
+		return nil, err
+	}
 
 	// In debug mode, return a mock sandbox without calling the API
 	if cfg.debug {
@@ -184,7 +210,7 @@ func NewWithContext(ctx context.Context, opts ...Option) (*Sandbox, error) {
 	}
 
 	// Add network options if specified
-	if cfg.network != nil {
+	if cfg.network != nil && !cfg.network.isZero() {
 		createReq.Network = &networkRequestOptions{
 			AllowOut:           cfg.network.AllowOut,
 			DenyOut:            cfg.network.DenyOut,
@@ -193,11 +219,18 @@ func NewWithContext(ctx context.Context, opts ...Option) (*Sandbox, error) {
 		}
 	}
 
-	createResp, err := createSandbox(ctx, cfg.httpClient, cfg.apiURL, cfg.apiKey, createReq)
+	createResp, err := createSandbox(ctx, cfg.apiClient(), cfg.apiURL, cfg.apiKey, createReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create sandbox: %w", err)
 	}
 
+	// If ctx was cancelled after the API created the sandbox, kill it so it
+	// does not keep running without a handle (best-effort)
+	if err := ctx.Err(); err != nil {
+		_ = killSandbox(context.WithoutCancel(ctx), cfg.apiClient(), cfg.apiURL, cfg.apiKey, createResp.SandboxID)
+		return nil, fmt.Errorf("failed to create sandbox: %w", err)
+	}
+
 	// Use the domain from API response, or fallback to configured domain
 	domain := createResp.Domain
 	if domain == "" {
@@ -277,11 +310,11 @@ func createSandbox(ctx context.Context, client *http.Client, apiURL, apiKey stri
 	}
 
 	if resp.StatusCode == http.StatusTooManyRequests {
-		return nil, fmt.Errorf("%w: %s", ErrRateLimit, stringOrDefault(string(respBody), "rate limit exceeded"))
+		return nil, newRateLimitError(resp.Header, string(respBody))
 	}
 
 	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("api error (status %d): %s", resp.StatusCode, stringOrDefault(string(respBody), "unknown error"))
+		return nil, newAPIError(resp, respBody)
 	}
 
 	var createResp sandboxCreateResponse
@@ -312,7 +345,9 @@ func ConnectWithContext(ctx context.Context, sandboxID string, opts ...Option) (
 	// Apply environment variables and compute defaults
 	cfg.applyEnvironment()
 	cfg.computeAPIURL()
-	cfg.ensureHTTPClient()
+	if err := cfg.ensureHTTPClient(); err != nil {
+		return nil, err
+	}
 
 	if sandboxID == "" {
 		return nil, fmt.Errorf("%w: sandbox ID is required", ErrInvalidArgument)
@@ -339,8 +374,13 @@ func ConnectWithContext(ctx context.Context, sandboxID string, opts ...Option) (
 		return nil, fmt.Errorf("%w: API key is required", ErrInvalidArgument)
 	}
 
+	// In read-only mode, only fetch the sandbox info so a paused sandbox is not resumed
+	if cfg.readOnly {
+		return connectReadOnly(ctx, sandboxID, cfg)
+	}
+
 	// Connect to sandbox via E2B API
-	connectResp, err := connectSandbox(ctx, cfg.httpClient, cfg.apiURL, cfg.apiKey, sandboxID, int(cfg.timeoutMs.Seconds()))
+	connectResp, err := connectSandbox(ctx, cfg.apiClient(), cfg.apiURL, cfg.apiKey, sandboxID, int(cfg.timeoutMs.Seconds()))
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to sandbox: %w", err)
 	}
@@ -394,6 +434,49 @@ func Connect(sandboxID string, opts ...Option) (*Sandbox, error) {
 	return ConnectWithContext(context.Background(), sandboxID, opts...)
 }
 
+// connectReadOnly creates a read-only sandbox handle from the sandbox info endpoint.
+func connectReadOnly(ctx context.Context, sandboxID string, cfg *sandboxConfig) (*Sandbox, error) {
+	info, err := GetSandboxInfo(ctx, sandboxID, cfg.httpClient, cfg.apiURL, cfg.apiKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sandbox info: %w", err)
+	}
+
+	sandbox := &Sandbox{
+		ID:          sandboxID,
+		Domain:      cfg.domain,
+		config:      cfg,
+		envdVersion: info.EnvdVersion,
+		readOnly:    true,
+		paused:      info.State == SandboxStatePaused,
+	}
+
+	// Envd clients are still initialized so that their methods fail with
+	// ErrReadOnly instead of panicking on nil services.
+	sandbox.initHTTPClient()
+	sandbox.Files = newFilesystem(sandbox)
+	sandbox.Commands = newCommands(sandbox)
+	sandbox.Pty = newPty(sandbox)
+	sandbox.Git = newGit(sandbox)
+
+	return sandbox, nil
+}
+
+// readOnlyTransport rejects every request made through a read-only sandbox handle.
+type readOnlyTransport struct{}
+
+// RoundTrip implements http.RoundTripper.
+func (readOnlyTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, ErrReadOnly
+}
+
+// envdHTTPClient returns the HTTP client used for envd and Jupyter traffic.
+func (s *Sandbox) envdHTTPClient() *http.Client {
+	if s.readOnly {
+		return &http.Client{Transport: readOnlyTransport{}}
+	}
+	return s.config.httpClient
+}
+
 // connectSandbox calls the E2B API to connect to an existing sandbox.
 func connectSandbox(ctx context.Context, client *http.Client, apiURL, apiKey, sandboxID string, timeout int) (*sandboxConnectResponse, error) {
 	reqBody, err := json.Marshal(&sandboxConnectRequest{Timeout: timeout})
@@ -427,11 +510,11 @@ func connectSandbox(ctx context.Context, client *http.Client, apiURL, apiKey, sa
 	}
 
 	if resp.StatusCode == http.StatusTooManyRequests {
-		return nil, fmt.Errorf("%w: team sandbox limit reached, cannot auto-resume", ErrRateLimit)
+		return nil, newRateLimitError(resp.Header, "team sandbox limit reached, cannot auto-resume")
 	}
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return nil, fmt.Errorf("api error (status %d): %s", resp.StatusCode, string(respBody))
+		return nil, newAPIError(resp, respBody)
 	}
 
 	var connectResp sandboxConnectResponse
@@ -442,27 +525,43 @@ func connectSandbox(ctx context.Context, client *http.Client, apiURL, apiKey, sa
 	return &connectResp, nil
 }
 
-// initHTTPClient initializes the HTTP client for Jupyter API calls.
+// initHTTPClient initializes the connection details and the HTTP client for
+// Jupyter API calls.
 func (s *Sandbox) initHTTPClient() {
+	s.conn = &envdConn{info: s.connInfo()}
+	s.httpClient = newHTTPClient(s.envdHTTPClient(), s.conn)
+	s.httpClient.maxLineSize = s.config.maxStreamLineSize
+}
+
+// connInfo returns the connection details of the sandbox. s.mu must be held,
+// or the sandbox not yet shared.
+func (s *Sandbox) connInfo() envdConnInfo {
 	scheme := "https"
 	if s.config.debug {
 		scheme = "http"
 	}
 
-	// E2B URL format: https://{port}-{sandboxID}.{domain}
-	baseURL := fmt.Sprintf("%s://%s", scheme, s.GetHost(JupyterPort))
-
-	s.httpClient = newHTTPClient(
-		s.config.httpClient,
-		baseURL,
-		s.accessToken,
-		s.TrafficAccessToken,
-	)
+	return envdConnInfo{
+		envdURL: s.getEnvdURL(),
+		// E2B URL format: https://{port}-{sandboxID}.{domain}
+		jupyterURL:   fmt.Sprintf("%s://%s", scheme, s.host(JupyterPort)),
+		accessToken:  s.accessToken,
+		trafficToken: s.TrafficAccessToken,
+		envdVersion:  s.envdVersion,
+	}
 }
 
 // GetHost returns the sandbox host for a given port.
 // The E2B URL format is: {port}-{sandboxID}.{domain}
 func (s *Sandbox) GetHost(port int) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.host(port)
+}
+
+// host returns the sandbox host for a given port. s.mu must be held, or the
+// sandbox not yet shared.
+func (s *Sandbox) host(port int) string {
 	if s.config.debug {
 		return fmt.Sprintf("localhost:%d", port)
 	}
@@ -481,7 +580,7 @@ func (s *Sandbox) getEnvdURL() string {
 		scheme = "http"
 	}
 
-	return fmt.Sprintf("%s://%s", scheme, s.GetHost(EnvdPort))
+	return fmt.Sprintf("%s://%s", scheme, s.host(EnvdPort))
 }
 
 // RunCode executes code in the sandbox.
@@ -505,6 +604,10 @@ func (s *Sandbox) RunCode(ctx context.Context, code string, opts ...RunOption) (
 		s.mu.RUnlock()
 		return nil, ErrSandboxClosed
 	}
+	if s.readOnly {
+		s.mu.RUnlock()
+		return nil, ErrReadOnly
+	}
 	s.mu.RUnlock()
 
 	cfg := defaultRunConfig()
@@ -549,18 +652,71 @@ func (s *Sandbox) RunCode(ctx context.Context, code string, opts ...RunOption) (
 		reqBody.Language = cfg.language
 	}
 
+	// Track the execution so its ExecutionHandle can cancel it
+	cellID := cfg.cellID
+	if cellID == "" {
+		cellID = newCellID()
+	}
+	ctx, untrack, err := s.trackExecution(ctx, cellID)
+	if err != nil {
+		return nil, err
+	}
+	defer untrack()
+	ctx = withExecutionMetadata(ctx, cfg.metadata)
+
+	// Fail if no result arrives within the first result timeout
+	var firstResultTimer *time.Timer
+	if cfg.firstResultTimeout > 0 {
+		var cancelFirstResult context.CancelCauseFunc
+		ctx, cancelFirstResult = context.WithCancelCause(ctx)
+		defer cancelFirstResult(nil)
+
+		timeoutErr := &TimeoutError{Type: "execution", Duration: cfg.firstResultTimeout.String()}
+		firstResultTimer = time.AfterFunc(cfg.firstResultTimeout, func() {
+			cancelFirstResult(timeoutErr)
+		})
+		defer firstResultTimer.Stop()
+	}
+
 	// Initialize execution result
 	execution := &Execution{
-		Results: make([]*Result, 0),
-		Logs:    NewLogs(),
-	}
+		Results:  make([]*Result, 0),
+		Logs:     NewLogs(),
+		CellID:   cellID,
+		Metadata: maps.Clone(cfg.metadata),
+	}
+
+	// Execute streaming request, retrying transient failures that happen
+	// before any output was received
+	started := false
+	for attempt := 1; ; attempt++ {
+		_, err = s.httpClient.doStreamRequest(ctx, "/execute", reqBody, func(sr *streamResponse) error {
+			started = true
+			if sr.Type == "result" && firstResultTimer != nil {
+				firstResultTimer.Stop()
+			}
+			return parseStreamResponse(sr, execution, cfg)
+		})
+		if err == nil || started || attempt >= cfg.retryAttempts || ctx.Err() != nil || !isRetryableRunError(err) {
+			break
+		}
 
-	// Execute streaming request
-	_, err := s.httpClient.doStreamRequest(ctx, "/execute", reqBody, func(sr *streamResponse) error {
-		return parseStreamResponse(sr, execution, cfg)
-	})
+		retryTimer := time.NewTimer(cfg.retryBackoff << (attempt - 1))
+		select {
+		case <-retryTimer.C:
+		case <-ctx.Done():
+			retryTimer.Stop()
+		}
+	}
 
 	if err != nil {
+		// Check for cancellation via ExecutionHandle.Cancel or the first result timeout
+		if context.Cause(ctx) == ErrExecutionCanceled {
+			return nil, ErrExecutionCanceled
+		}
+		if timeoutErr, ok := context.Cause(ctx).(*TimeoutError); ok {
+			return nil, timeoutErr
+		}
 		// Check for context deadline exceeded (timeout)
 		if ctx.Err() == context.DeadlineExceeded {
 			return nil, NewExecutionTimeoutError()
@@ -571,6 +727,25 @@ func (s *Sandbox) RunCode(ctx context.Context, code string, opts ...RunOption) (
 	return execution, nil
 }
 
+// isRetryableRunError reports whether a failed execute request is a transient
+// infrastructure error that proves the code never reached the kernel: a
+// failure to connect, or a gateway error returned while the kernel is not
+// ready yet. Errors after the request was sent, such as a reset connection
+// or a gateway timeout, are not retried since the code may have run.
+func isRetryableRunError(err error) bool {
+	var sandboxErr *SandboxError
+	if errors.As(err, &sandboxErr) {
+		switch sandboxErr.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable:
+			return true
+		}
+		return false
+	}
+
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
 // CreateContext creates a new execution context.
 //
 // Contexts provide isolated state for code execution. Variables and imports
@@ -631,8 +806,11 @@ func (s *Sandbox) CreateContext(ctx context.Context, opts ...ContextOption) (*Co
 	if err := json.Unmarshal(respBody, &ctxResp); err != nil {
 		return nil, fmt.Errorf("failed to parse context response: %w", err)
 	}
+	if ctxResp.CreatedAt.IsZero() {
+		ctxResp.CreatedAt = time.Now()
+	}
 
-	return ctxResp.toContext(), nil
+	return ctxResp.toContext(s), nil
 }
 
 // ListContexts returns all execution contexts in the sandbox.
@@ -671,7 +849,7 @@ func (s *Sandbox) ListContexts(ctx context.Context) ([]*Context, error) {
 
 	contexts := make([]*Context, len(ctxResps))
 	for i, ctxResp := range ctxResps {
-		contexts[i] = ctxResp.toContext()
+		contexts[i] = ctxResp.toContext(s)
 	}
 
 	return contexts, nil
@@ -766,9 +944,9 @@ func (s *Sandbox) CloseWithContext(ctx context.Context) error {
 
 	s.closed = true
 
-	// Kill the sandbox via E2B API (skip in debug mode)
-	if !s.config.debug && s.ID != "" && s.config != nil && s.config.apiKey != "" {
-		_ = killSandbox(ctx, s.config.httpClient, s.config.apiURL, s.config.apiKey, s.ID)
+	// Kill the sandbox via E2B API (skip in debug mode and for read-only handles)
+	if !s.config.debug && !s.readOnly && s.ID != "" && s.config != nil && s.config.apiKey != "" {
+		_ = killSandbox(ctx, s.config.apiClient(), s.config.apiURL, s.config.apiKey, s.ID)
 	}
 
 	return nil
@@ -806,7 +984,7 @@ func killSandbox(ctx context.Context, client *http.Client, apiURL, apiKey, sandb
 	// 204 No Content is success, 404 means already killed
 	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusNotFound {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("api error (status %d): %s", resp.StatusCode, string(body))
+		return newAPIError(resp, body)
 	}
 
 	return nil
@@ -827,7 +1005,7 @@ func (s *Sandbox) SetTimeout(ctx context.Context, d time.Duration) error {
 	}
 
 	// Call API to set timeout
-	if err := setSandboxTimeout(ctx, s.config.httpClient, s.config.apiURL, s.config.apiKey, s.ID, int(d.Seconds())); err != nil {
+	if err := setSandboxTimeout(ctx, s.config.apiClient(), s.config.apiURL, s.config.apiKey, s.ID, int(d.Seconds())); err != nil {
 		return err
 	}
 
@@ -864,7 +1042,7 @@ func setSandboxTimeout(ctx context.Context, client *http.Client, apiURL, apiKey,
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("api error (status %d): %s", resp.StatusCode, string(body))
+		return newAPIError(resp, body)
 	}
 
 	return nil
@@ -884,6 +1062,51 @@ func (s *Sandbox) IsClosed() bool {
 	return s.closed
 }
 
+// State returns the last known state of the sandbox without a network call.
+//
+// The state is updated by Pause, BetaPause, BetaResume, Close and successful
+// IsRunning checks. Use GetInfo to fetch the current state from the API.
+func (s *Sandbox) State() SandboxState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	switch {
+	case s.closed:
+		return SandboxStateClosed
+	case s.paused:
+		return SandboxStatePaused
+	default:
+		return SandboxStateRunning
+	}
+}
+
+// setPaused records whether the sandbox is known to be paused.
+func (s *Sandbox) setPaused(paused bool) {
+	s.mu.Lock()
+	s.paused = paused
+	s.mu.Unlock()
+}
+
+// NetworkConfig returns a copy of the network options the sandbox was
+// created with, or nil if none were set with WithNetwork.
+//
+// Example:
+//
+//	if network := sandbox.NetworkConfig(); network != nil {
+//	    fmt.Println("egress allowed to:", network.AllowOut)
+//	}
+func (s *Sandbox) NetworkConfig() *NetworkOptions {
+	if s.config == nil || s.config.network == nil {
+		return nil
+	}
+	return s.config.network.clone()
+}
+
+// IsReadOnly returns whether this is a read-only handle created with WithReadOnly.
+func (s *Sandbox) IsReadOnly() bool {
+	return s.readOnly
+}
+
 // IsRunning checks if the sandbox is running by calling the health endpoint.
 func (s *Sandbox) IsRunning(ctx context.Context) (bool, error) {
 	s.mu.RLock()
@@ -891,6 +1114,7 @@ func (s *Sandbox) IsRunning(ctx context.Context) (bool, error) {
 		s.mu.RUnlock()
 		return false, nil
 	}
+	host, accessToken := s.host(EnvdPort), s.accessToken
 	s.mu.RUnlock()
 
 	// Build health check URL
@@ -898,7 +1122,7 @@ func (s *Sandbox) IsRunning(ctx context.Context) (bool, error) {
 	if s.config.debug {
 		scheme = "http"
 	}
-	healthURL := fmt.Sprintf("%s://%s/health", scheme, s.GetHost(EnvdPort))
+	healthURL := fmt.Sprintf("%s://%s/health", scheme, host)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, healthURL, nil)
 	if err != nil {
@@ -906,8 +1130,8 @@ func (s *Sandbox) IsRunning(ctx context.Context) (bool, error) {
 	}
 
 	req.Header.Set("User-Agent", "e2b-go-sdk/"+Version)
-	if s.accessToken != "" {
-		req.Header.Set(headerAccessToken, s.accessToken)
+	if accessToken != "" {
+		req.Header.Set(headerAccessToken, accessToken)
 	}
 
 	resp, err := s.config.httpClient.Do(req)
@@ -925,9 +1149,70 @@ func (s *Sandbox) IsRunning(ctx context.Context) (bool, error) {
 		return false, formatHTTPError(resp.StatusCode, string(body))
 	}
 
+	s.setPaused(false)
 	return true, nil
 }
 
+// WaitUntilReady polls IsRunning until the sandbox passes a health check.
+//
+// A 502 response or a transport error means envd is not up yet and polling
+// continues. A 404 response means the sandbox was terminated; an error
+// wrapping ErrNotFound is returned immediately. If the maximum number of
+// attempts set with WithWaitMaxAttempts is reached, an error wrapping
+// ErrTimeout is returned.
+//
+// Example:
+//
+//	sandbox, err := e2b.New(e2b.WithAPIKey("your-api-key"))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if err := sandbox.WaitUntilReady(ctx, e2b.WithWaitInterval(time.Second)); err != nil {
+//	    log.Fatal(err)
+//	}
+func (s *Sandbox) WaitUntilReady(ctx context.Context, opts ...WaitOption) error {
+	cfg := defaultWaitConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	for attempt := 1; ; attempt++ {
+		if s.IsClosed() {
+			return ErrSandboxClosed
+		}
+
+		running, err := s.IsRunning(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if errors.Is(err, ErrNotFound) {
+			return fmt.Errorf("%w: sandbox %s was terminated", ErrNotFound, s.ID)
+		}
+		var sandboxErr *SandboxError
+		if errors.As(err, &sandboxErr) {
+			return err
+		}
+		if running {
+			if cfg.onReady != nil {
+				cfg.onReady(s)
+			}
+			return nil
+		}
+
+		if cfg.maxAttempts > 0 && attempt >= cfg.maxAttempts {
+			return fmt.Errorf("%w: sandbox %s not ready after %d attempts", ErrTimeout, s.ID, attempt)
+		}
+
+		timer := time.NewTimer(cfg.interval)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
 // urlConfig holds configuration for URL generation.
 type urlConfig struct {
 	signatureExpiration int    // seconds, 0 means no expiration
@@ -961,7 +1246,7 @@ func (s *Sandbox) fileURL(path, username string) string {
 
 	u := &url.URL{
 		Scheme: scheme,
-		Host:   s.GetHost(EnvdPort),
+		Host:   s.host(EnvdPort),
 		Path:   "/files",
 	}
 
@@ -997,8 +1282,14 @@ func (s *Sandbox) UploadURL(path string, opts ...URLOption) (string, error) {
 		opt(cfg)
 	}
 
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	// Default user handling for older envd versions
 	user := cfg.user
+	if user == "" {
+		user = s.config.envdUser
+	}
 	if user == "" && s.compareVersion(EnvdVersionDefaultUser) < 0 {
 		user = "user"
 	}
@@ -1039,8 +1330,14 @@ func (s *Sandbox) DownloadURL(path string, opts ...URLOption) (string, error) {
 		opt(cfg)
 	}
 
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	// Default user handling for older envd versions
 	user := cfg.user
+	if user == "" {
+		user = s.config.envdUser
+	}
 	if user == "" && s.compareVersion(EnvdVersionDefaultUser) < 0 {
 		user = "user"
 	}
@@ -1151,7 +1448,7 @@ func GetSandboxInfo(ctx context.Context, sandboxID string, client *http.Client,
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp, body)
 	}
 
 	var info SandboxInfo
@@ -1282,7 +1579,7 @@ func GetSandboxMetrics(ctx context.Context, sandboxID string, client *http.Clien
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp, body)
 	}
 
 	var metrics []SandboxMetrics
@@ -1304,7 +1601,9 @@ func Kill(ctx context.Context, sandboxID string, opts ...Option) error {
 	// Apply environment variables and compute defaults
 	cfg.applyEnvironment()
 	cfg.computeAPIURL()
-	cfg.ensureHTTPClient()
+	if err := cfg.ensureHTTPClient(); err != nil {
+		return err
+	}
 
 	// Skip in debug mode
 	if cfg.debug {
@@ -1315,7 +1614,7 @@ func Kill(ctx context.Context, sandboxID string, opts ...Option) error {
 		return fmt.Errorf("%w: API key is required", ErrInvalidArgument)
 	}
 
-	return killSandbox(ctx, cfg.httpClient, cfg.apiURL, cfg.apiKey, sandboxID)
+	return killSandbox(ctx, cfg.apiClient(), cfg.apiURL, cfg.apiKey, sandboxID)
 }
 
 // Pause pauses this sandbox.
@@ -1342,11 +1641,14 @@ func (s *Sandbox) Pause(ctx context.Context) error {
 	debug := s.config.debug
 	s.mu.RUnlock()
 
-	if debug {
-		return nil
+	if !debug {
+		if err := pauseSandbox(ctx, client, apiURL, apiKey, s.ID); err != nil {
+			return err
+		}
 	}
 
-	return pauseSandbox(ctx, client, apiURL, apiKey, s.ID)
+	s.setPaused(true)
+	return nil
 }
 
 // BetaPause pauses this sandbox.
@@ -1364,11 +1666,14 @@ func (s *Sandbox) BetaPause(ctx context.Context) error {
 	debug := s.config.debug
 	s.mu.RUnlock()
 
-	if debug {
-		return nil
+	if !debug {
+		if err := pauseSandbox(ctx, client, apiURL, apiKey, s.ID); err != nil {
+			return err
+		}
 	}
 
-	return pauseSandbox(ctx, client, apiURL, apiKey, s.ID)
+	s.setPaused(true)
+	return nil
 }
 
 // Pause pauses a sandbox by ID.
@@ -1381,7 +1686,9 @@ func Pause(ctx context.Context, sandboxID string, opts ...Option) error {
 
 	cfg.applyEnvironment()
 	cfg.computeAPIURL()
-	cfg.ensureHTTPClient()
+	if err := cfg.ensureHTTPClient(); err != nil {
+		return err
+	}
 
 	if cfg.debug {
 		return nil
@@ -1406,7 +1713,9 @@ func BetaPause(ctx context.Context, sandboxID string, opts ...Option) error {
 	// Apply environment variables and compute defaults
 	cfg.applyEnvironment()
 	cfg.computeAPIURL()
-	cfg.ensureHTTPClient()
+	if err := cfg.ensureHTTPClient(); err != nil {
+		return err
+	}
 
 	// Skip in debug mode
 	if cfg.debug {
@@ -1420,6 +1729,271 @@ func BetaPause(ctx context.Context, sandboxID string, opts ...Option) error {
 	return pauseSandbox(ctx, cfg.httpClient, cfg.apiURL, cfg.apiKey, sandboxID)
 }
 
+// BetaResume resumes this sandbox after it was paused with BetaPause.
+//
+// The envd connection is re-established with fresh access tokens from the
+// API and the sandbox becomes usable again, even if it was closed. A sandbox
+// that is already running is treated as successfully resumed. If the sandbox
+// no longer exists, an error wrapping ErrNotFound is returned.
+//
+// Example:
+//
+//	if err := sandbox.BetaPause(ctx); err != nil {
+//	    log.Fatal(err)
+//	}
+//	// Later:
+//	if err := sandbox.BetaResume(ctx); err != nil {
+//	    log.Fatal(err)
+//	}
+func (s *Sandbox) BetaResume(ctx context.Context) error {
+	s.mu.RLock()
+	if s.readOnly {
+		s.mu.RUnlock()
+		return ErrReadOnly
+	}
+	cfg := s.config
+	s.mu.RUnlock()
+
+	if cfg.debug {
+		s.mu.Lock()
+		s.closed = false
+		s.paused = false
+		s.mu.Unlock()
+		return nil
+	}
+
+	resp, err := resumeSandbox(ctx, cfg.httpClient, cfg.apiURL, cfg.apiKey, s.ID, int(cfg.timeoutMs.Seconds()))
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = false
+	s.paused = false
+	s.applyConnectResponse(resp)
+
+	return nil
+}
+
+// Reconnect re-establishes the connection to the sandbox after a transient
+// network failure, keeping the same sandbox ID.
+//
+// The connect API is called again and the envd clients (Files, Commands,
+// Pty and Git) switch to the refreshed access tokens. Operations that
+// fail with ErrSandboxUnavailable can be retried after a successful
+// Reconnect. If the sandbox no longer exists, an error wrapping ErrNotFound
+// is returned.
+//
+// Example:
+//
+//	_, err := sandbox.Files.Read(ctx, "/home/user/data.txt")
+//	if errors.Is(err, e2b.ErrSandboxUnavailable) {
+//	    if err := sandbox.Reconnect(ctx); err != nil {
+//	        log.Fatal(err)
+//	    }
+//	    _, err = sandbox.Files.Read(ctx, "/home/user/data.txt")
+//	}
+func (s *Sandbox) Reconnect(ctx context.Context) error {
+	if err := s.reconnect(ctx); err != nil {
+		return fmt.Errorf("failed to reconnect to sandbox: %w", err)
+	}
+	return nil
+}
+
+// Refresh re-syncs the sandbox connection details from the API.
+//
+// The connect API is called again and Domain, TrafficAccessToken, the envd
+// access token and the envd version are updated, so that signed file URLs
+// and the requests of the envd clients (Files, Commands, Pty and Git) use
+// the current values. Use it after the sandbox was resumed elsewhere or its
+// traffic access token was rotated.
+//
+// Refresh is safe to call concurrently with other operations. The clients
+// themselves are never replaced: requests started afterwards use the
+// refreshed details, while requests already in flight finish with the
+// details they started with.
+//
+// Example:
+//
+//	if err := sandbox.Refresh(ctx); err != nil {
+//	    log.Fatal(err)
+//	}
+//	url, _ := sandbox.UploadURL("/home/user/data.txt")
+func (s *Sandbox) Refresh(ctx context.Context) error {
+	if err := s.reconnect(ctx); err != nil {
+		return fmt.Errorf("failed to refresh sandbox: %w", err)
+	}
+	return nil
+}
+
+// reconnect calls the connect API and applies the response.
+func (s *Sandbox) reconnect(ctx context.Context) error {
+	s.mu.RLock()
+	if s.closed {
+		s.mu.RUnlock()
+		return ErrSandboxClosed
+	}
+	if s.readOnly {
+		s.mu.RUnlock()
+		return ErrReadOnly
+	}
+	cfg := s.config
+	resp := &sandboxConnectResponse{EnvdAccessToken: s.accessToken, TrafficAccessToken: s.TrafficAccessToken}
+	s.mu.RUnlock()
+
+	if !cfg.debug {
+		var err error
+		resp, err = connectSandbox(ctx, cfg.apiClient(), cfg.apiURL, cfg.apiKey, s.ID, int(cfg.timeoutMs.Seconds()))
+		if err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrSandboxClosed
+	}
+	s.paused = false
+	s.applyConnectResponse(resp)
+
+	return nil
+}
+
+// applyConnectResponse stores the connection details from a connect or
+// resume response. The envd clients are kept and pick up the new details
+// for their next request. s.mu must be held.
+func (s *Sandbox) applyConnectResponse(resp *sandboxConnectResponse) {
+	if resp.Domain != "" {
+		s.Domain = resp.Domain
+	}
+	if resp.EnvdVersion != "" {
+		s.envdVersion = resp.EnvdVersion
+	}
+	s.accessToken = resp.EnvdAccessToken
+	s.TrafficAccessToken = resp.TrafficAccessToken
+
+	s.conn.store(s.connInfo())
+}
+
+// BetaResume resumes a paused sandbox by ID and returns a handle to it.
+// A sandbox that is already running is treated as successfully resumed.
+//
+// Example:
+//
+//	sandbox, err := e2b.BetaResume(ctx, "sandbox-id", e2b.WithAPIKey("your-api-key"))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer sandbox.Close()
+func BetaResume(ctx context.Context, sandboxID string, opts ...Option) (*Sandbox, error) {
+	cfg := defaultSandboxConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	// Apply environment variables and compute defaults
+	cfg.applyEnvironment()
+	cfg.computeAPIURL()
+	if err := cfg.ensureHTTPClient(); err != nil {
+		return nil, err
+	}
+
+	if sandboxID == "" {
+		return nil, fmt.Errorf("%w: sandbox ID is required", ErrInvalidArgument)
+	}
+
+	if cfg.readOnly {
+		return nil, ErrReadOnly
+	}
+
+	sandbox := &Sandbox{
+		ID:          sandboxID,
+		Domain:      cfg.domain,
+		config:      cfg,
+		envdVersion: EnvdDebugFallback,
+	}
+
+	if !cfg.debug && cfg.apiKey == "" {
+		return nil, fmt.Errorf("%w: API key is required", ErrInvalidArgument)
+	}
+
+	// The clients pick up the connection details of the resume response
+	sandbox.initHTTPClient()
+	sandbox.Files = newFilesystem(sandbox)
+	sandbox.Commands = newCommands(sandbox)
+	sandbox.Pty = newPty(sandbox)
+	sandbox.Git = newGit(sandbox)
+
+	if err := sandbox.BetaResume(ctx); err != nil {
+		return nil, err
+	}
+
+	return sandbox, nil
+}
+
+// resumeSandbox calls the E2B API to resume a paused sandbox.
+// If the sandbox is already running, the connect endpoint is used instead
+// so that fresh access tokens are still returned.
+func resumeSandbox(ctx context.Context, client *http.Client, apiURL, apiKey, sandboxID string, timeout int) (*sandboxConnectResponse, error) {
+	if client == nil {
+		client = &http.Client{Timeout: DefaultRequestTimeout}
+	}
+
+	reqBody, err := json.Marshal(&sandboxConnectRequest{Timeout: timeout})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	reqURL, _ := url.JoinPath(apiURL, "sandboxes", sandboxID, "resume")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, reqURL, bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", apiKey)
+	req.Header.Set("User-Agent", "e2b-go-sdk/"+Version)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	// 409 Conflict means sandbox is already running - treat as success
+	if resp.StatusCode == http.StatusConflict {
+		return connectSandbox(ctx, client, apiURL, apiKey, sandboxID, timeout)
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: sandbox %s not found", ErrNotFound, sandboxID)
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, newRateLimitError(resp.Header, "team sandbox limit reached, cannot resume")
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, newAPIError(resp, respBody)
+	}
+
+	var resumeResp sandboxConnectResponse
+	if err := json.Unmarshal(respBody, &resumeResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &resumeResp, nil
+}
+
 // pauseSandbox calls the E2B API to pause a sandbox.
 func pauseSandbox(ctx context.Context, client *http.Client, apiURL, apiKey, sandboxID string) error {
 	if client == nil {
@@ -1452,7 +2026,7 @@ func pauseSandbox(ctx context.Context, client *http.Client, apiURL, apiKey, sand
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("api error (status %d): %s", resp.StatusCode, string(body))
+		return newAPIError(resp, body)
 	}
 
 	return nil
@@ -1499,6 +2073,9 @@ func (s *Sandbox) GetMcpToken(ctx context.Context) (string, error) {
 //
 // The URL follows the format: https://{sandboxId}-mcp.{domain}
 //
+// Deprecated: Use McpURL, which returns the gateway endpoint on McpPort
+// used by the other E2B SDKs.
+//
 // Example:
 //
 //	mcpUrl := sandbox.GetMcpUrl()
@@ -1512,13 +2089,25 @@ func (s *Sandbox) GetMcpUrl() string {
 	if s.config != nil && s.config.debug {
 		protocol = "http"
 	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return fmt.Sprintf("%s://%s-mcp.%s", protocol, s.ID, s.Domain)
 }
 
-func stringOrDefault(s, def string) string {
-	s = strings.TrimSpace(s)
-	if s == "" {
-		return def
+// McpURL returns the URL of the MCP gateway endpoint, served on McpPort.
+// Authenticate with the token returned by GetMcpToken.
+//
+// Example:
+//
+//	token, err := sandbox.GetMcpToken(ctx)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	// Connect your MCP client to sandbox.McpURL() with Authorization: Bearer {token}
+func (s *Sandbox) McpURL() string {
+	scheme := "https"
+	if s.config.debug {
+		scheme = "http"
 	}
-	return s
+	return fmt.Sprintf("%s://%s/mcp", scheme, s.GetHost(McpPort))
 }
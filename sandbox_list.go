@@ -19,6 +19,9 @@ const (
 	SandboxStateRunning SandboxState = "running"
 	// SandboxStatePaused indicates the sandbox is paused.
 	SandboxStatePaused SandboxState = "paused"
+	// SandboxStateClosed indicates the sandbox handle has been closed.
+	// It is only reported locally by Sandbox.State.
+	SandboxStateClosed SandboxState = "closed"
 )
 
 // SandboxQuery defines the query parameters for listing sandboxes.
@@ -199,7 +202,7 @@ func (p *SandboxPaginator) NextItems(ctx context.Context) ([]SandboxInfo, error)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("api error (status %d): %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp, body)
 	}
 
 	// Parse response body as array directly (API returns array, not wrapped object)
@@ -208,7 +208,7 @@ func getSandboxLogsInternal(ctx context.Context, client *http.Client, apiURL, ap
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("api error (status %d): %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp, body)
 	}
 
 	var logsResp sandboxLogsV2Response
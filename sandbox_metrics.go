@@ -0,0 +1,96 @@
+package e2b
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// collectMetricsWorkers is the maximum number of concurrent metrics requests
+// made by CollectMetrics.
+const collectMetricsWorkers = 10
+
+// CollectMetrics fetches resource usage metrics for many sandboxes concurrently.
+// This is the fleet-level counterpart to Sandbox.GetMetrics.
+//
+// Requests are made by a bounded pool of workers and share the deadline of ctx.
+// Metrics are returned keyed by sandbox ID; sandboxes whose request failed are
+// omitted from the map and reported in the returned errors, each wrapped with
+// the sandbox ID. Sandboxes not yet fetched when ctx is done fail with the
+// context error.
+//
+// Example:
+//
+//	metrics, errs := e2b.CollectMetrics(ctx, []string{"sbx-1", "sbx-2"})
+//	for _, err := range errs {
+//	    log.Println(err)
+//	}
+//	for id, m := range metrics {
+//	    if len(m) > 0 {
+//	        fmt.Printf("%s: CPU %.2f%%\n", id, m[len(m)-1].CPUUsedPct)
+//	    }
+//	}
+func CollectMetrics(ctx context.Context, sandboxIDs []string, opts ...Option) (map[string][]SandboxMetrics, []error) {
+	cfg := defaultSandboxConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	// Apply environment variables and compute defaults
+	cfg.applyEnvironment()
+	cfg.computeAPIURL()
+	if err := cfg.ensureHTTPClient(); err != nil {
+		return nil, []error{err}
+	}
+
+	results := make(map[string][]SandboxMetrics, len(sandboxIDs))
+
+	// Skip in debug mode
+	if cfg.debug {
+		return results, nil
+	}
+
+	if cfg.apiKey == "" {
+		return results, []error{fmt.Errorf("%w: API key is required", ErrInvalidArgument)}
+	}
+
+	var (
+		mu   sync.Mutex
+		errs []error
+		wg   sync.WaitGroup
+	)
+
+	ids := make(chan string)
+	workers := min(collectMetricsWorkers, len(sandboxIDs))
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range ids {
+				var (
+					metrics []SandboxMetrics
+					err     error
+				)
+				if err = ctx.Err(); err == nil {
+					metrics, err = GetSandboxMetrics(ctx, id, cfg.httpClient, cfg.apiURL, cfg.apiKey, nil)
+				}
+
+				mu.Lock()
+				if err != nil {
+					errs = append(errs, fmt.Errorf("sandbox %s: %w", id, err))
+				} else {
+					results[id] = metrics
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, id := range sandboxIDs {
+		ids <- id
+	}
+	close(ids)
+	wg.Wait()
+
+	return results, errs
+}
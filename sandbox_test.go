@@ -1,12 +1,39 @@
 package e2b
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
 	"net/http"
 	"net/http/httptest"
+	"net/http/httptrace"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"connectrpc.com/connect"
+	filesystempb "github.com/xerpa-ai/e2b-go/internal/proto/filesystem"
+	"github.com/xerpa-ai/e2b-go/internal/proto/filesystem/filesystempbconnect"
+	processpb "github.com/xerpa-ai/e2b-go/internal/proto/process"
+	"github.com/xerpa-ai/e2b-go/internal/proto/process/processpbconnect"
 )
 
 func newMockAPIServer(t *testing.T) *httptest.Server {
@@ -190,6 +217,209 @@ func TestResultFormats(t *testing.T) {
 	}
 }
 
+func TestResultImageBytes(t *testing.T) {
+	// 1x1 transparent PNG and a minimal JPEG header
+	const pngFixture = "iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNkYPhfDwAChwGA60e6kgAAAABJRU5ErkJggg=="
+	const jpegFixture = "/9j/4AAQSkZJRgABAQEASABIAAD/2wBDAP//"
+
+	t.Run("prefers PNG", func(t *testing.T) {
+		result := &Result{PNG: pngFixture, JPEG: jpegFixture, SVG: "<svg/>"}
+		data, mimeType, err := result.ImageBytes()
+		if err != nil {
+			t.Fatalf("ImageBytes() error = %v", err)
+		}
+		if mimeType != "image/png" {
+			t.Errorf("mime type = %q, want image/png", mimeType)
+		}
+		if !bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")) {
+			t.Errorf("data does not start with the PNG signature: %x", data[:8])
+		}
+	})
+
+	t.Run("JPEG with line breaks", func(t *testing.T) {
+		result := &Result{JPEG: jpegFixture[:16] + "\n" + jpegFixture[16:]}
+		data, mimeType, err := result.ImageBytes()
+		if err != nil {
+			t.Fatalf("ImageBytes() error = %v", err)
+		}
+		if mimeType != "image/jpeg" || !bytes.HasPrefix(data, []byte{0xff, 0xd8, 0xff}) {
+			t.Errorf("got %q %x, want JPEG data", mimeType, data[:3])
+		}
+	})
+
+	t.Run("SVG", func(t *testing.T) {
+		data, mimeType, err := (&Result{SVG: "<svg/>"}).ImageBytes()
+		if err != nil || mimeType != "image/svg+xml" || string(data) != "<svg/>" {
+			t.Errorf("ImageBytes() = %q, %q, %v", data, mimeType, err)
+		}
+	})
+
+	t.Run("malformed base64", func(t *testing.T) {
+		_, _, err := (&Result{PNG: "not base64!"}).ImageBytes()
+		if !errors.Is(err, ErrInvalidArgument) || !strings.Contains(err.Error(), "PNG") {
+			t.Errorf("ImageBytes() error = %v, want descriptive ErrInvalidArgument", err)
+		}
+	})
+
+	t.Run("no image", func(t *testing.T) {
+		_, _, err := (&Result{Text: "1"}).ImageBytes()
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("ImageBytes() error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("execution", func(t *testing.T) {
+		chart := &LineChart{}
+		execution := &Execution{Results: []*Result{
+			{Text: "1"},
+			{PNG: pngFixture, Chart: chart},
+			{PNG: "%%%"},
+			{SVG: "<svg/>"},
+		}}
+		if got := execution.FirstChart(); got != chart {
+			t.Errorf("FirstChart() = %v, want %v", got, chart)
+		}
+		images := execution.Images()
+		if len(images) != 2 || string(images[1]) != "<svg/>" {
+			t.Errorf("Images() returned %d images, want PNG and SVG", len(images))
+		}
+		if (&Execution{}).FirstChart() != nil {
+			t.Error("FirstChart() on empty execution should be nil")
+		}
+	})
+
+	t.Run("image results", func(t *testing.T) {
+		execution := &Execution{Results: []*Result{
+			{Text: "1", HTML: "<b>1</b>"},
+			{PNG: pngFixture, JPEG: jpegFixture},
+			// A PNG labelled as JPEG is detected from its content
+			{JPEG: pngFixture, Extra: map[string]any{"text/vnd.custom": "x"}},
+		}}
+		images, err := execution.ImageResults()
+		if err != nil {
+			t.Fatalf("ImageResults() error = %v", err)
+		}
+		var got []string
+		for _, img := range images {
+			got = append(got, img.Format+" "+img.ContentType)
+		}
+		want := []string{"png image/png", "jpeg image/jpeg", "jpeg image/png"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("ImageResults() = %q, want %q", got, want)
+		}
+		if images[2].Result != execution.Results[2] {
+			t.Error("ImageResult.Result does not point to its result")
+		}
+
+		execution.Results = append(execution.Results, &Result{PNG: "%%%"})
+		if _, err := execution.ImageResults(); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("ImageResults() with malformed data error = %v, want %v", err, ErrInvalidArgument)
+		}
+
+		if got := execution.ResultsByFormat("JPEG"); len(got) != 2 || got[0] != execution.Results[1] {
+			t.Errorf("ResultsByFormat(jpeg) = %v, want results 1 and 2", got)
+		}
+		if got := execution.ResultsByFormat("text/vnd.custom"); len(got) != 1 || got[0] != execution.Results[2] {
+			t.Errorf("ResultsByFormat(extra) = %v, want result 2", got)
+		}
+		if got := execution.ResultsByFormat("pdf"); len(got) != 0 {
+			t.Errorf("ResultsByFormat(pdf) = %v, want none", got)
+		}
+	})
+}
+
+func TestResultDataFrame(t *testing.T) {
+	want := []map[string]any{
+		{"name": "a", "score": float64(1)},
+		{"name": "b", "score": float64(2)},
+	}
+
+	tests := []struct {
+		name    string
+		payload string
+	}{
+		{"split", `{"columns":["name","score"],"index":[0,1],"data":[["a",1],["b",2]]}`},
+		{"records", `{"data":[{"name":"a","score":1},{"name":"b","score":2}]}`},
+		{"column lists", `{"name":["a","b"],"score":[1,2]}`},
+		{"column index maps", `{"name":{"10":"b","2":"a"},"score":{"2":1,"10":2}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var data map[string]any
+			if err := json.Unmarshal([]byte(tt.payload), &data); err != nil {
+				t.Fatal(err)
+			}
+			rows, err := (&Result{Data: data}).DataFrame()
+			if err != nil {
+				t.Fatalf("DataFrame() error = %v", err)
+			}
+			if fmt.Sprint(rows) != fmt.Sprint(want) {
+				t.Errorf("DataFrame() = %v, want %v", rows, want)
+			}
+		})
+	}
+
+	t.Run("falls back to JSON", func(t *testing.T) {
+		rows, err := (&Result{JSON: map[string]any{"x": []any{1.0}}}).DataFrame()
+		if err != nil || len(rows) != 1 || rows[0]["x"] != 1.0 {
+			t.Errorf("DataFrame() = %v, %v", rows, err)
+		}
+	})
+
+	t.Run("not tabular", func(t *testing.T) {
+		for _, result := range []*Result{
+			{},
+			{Data: map[string]any{"key": "value"}},
+			{JSON: map[string]any{"a": []any{1.0}, "b": []any{1.0, 2.0}}},
+		} {
+			if _, err := result.DataFrame(); !errors.Is(err, ErrInvalidArgument) {
+				t.Errorf("DataFrame(%v) error = %v, want ErrInvalidArgument", result, err)
+			}
+		}
+	})
+}
+
+func TestSanitizeHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"dataframe table", `<table border="1" class="dataframe"><tr style="text-align: right;"><th>a</th></tr></table>`,
+			`<table border="1" class="dataframe"><tr><th>a</th></tr></table>`},
+		{"script removed with content", `<p>hi</p><script>alert(1)</script><b>x</b>`, `<p>hi</p><b>x</b>`},
+		{"event handler dropped", `<img src="a.png" onerror="alert(1)">`, `<img src="a.png">`},
+		{"javascript url dropped", `<a href="jav&#x61;script:alert(1)">x</a>`, `<a>x</a>`},
+		{"unknown tag text kept", `<custom>text</custom>`, `text`},
+		{"comment dropped", `a<!-- <script> -->b`, `ab`},
+		{"stray bracket escaped", `1 < 2`, `1 &lt; 2`},
+		{"style with url dropped", `<div style="background: url(x)">x</div>`, `<div>x</div>`},
+		{"style with css escape dropped", `<div style="background: u\72l(x)">x</div>`, `<div>x</div>`},
+		{"data image allowed", `<img src="data:image/png;base64,AAAA">`, `<img src="data:image/png;base64,AAAA">`},
+		{"data html rejected", `<a href="data:text/html,x">x</a>`, `<a>x</a>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitizeHTML(tt.in); got != tt.want {
+				t.Errorf("sanitizeHTML() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	execution := &Execution{Logs: NewLogs()}
+	cfg := defaultRunConfig()
+	WithSanitizeHTML(true)(cfg)
+	raw := `<b onclick="x()">bold</b>`
+	parseStreamResponse(&streamResponse{Type: "result", HTML: raw}, execution, cfg)
+	if got := execution.Results[0].HTML; got != "<b>bold</b>" {
+		t.Errorf("HTML = %q, want <b>bold</b>", got)
+	}
+	if got := execution.Results[0].Extra["raw_html"]; got != raw {
+		t.Errorf("Extra[raw_html] = %v, want %q", got, raw)
+	}
+}
+
 func TestOutputMessage(t *testing.T) {
 	msg := OutputMessage{
 		Line:      "test output",
@@ -267,6 +497,110 @@ func TestSandboxError(t *testing.T) {
 	}
 }
 
+func TestRateLimitError(t *testing.T) {
+	server := newMockAPIServer(t)
+	defer server.Close()
+
+	retryAfter := ""
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		if retryAfter != "" {
+			w.Header().Set("Retry-After", retryAfter)
+		}
+		w.WriteHeader(http.StatusTooManyRequests)
+		io.WriteString(w, `{"message":"slow down"}`)
+	})
+	limited := httptest.NewServer(handler)
+	defer limited.Close()
+
+	sandbox, err := New(WithAPIKey("test-api-key"), WithAPIURL(server.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	sandbox.httpClient = newTestHTTPClient(limited.URL, "")
+
+	files, err := New(WithDebug(true), WithSandboxURL(limited.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx := context.Background()
+	calls := map[string]func() error{
+		"RunCode": func() error {
+			_, err := sandbox.RunCode(ctx, "x")
+			return err
+		},
+		"CreateContext": func() error {
+			_, err := sandbox.CreateContext(ctx)
+			return err
+		},
+		"Files.Read": func() error {
+			_, err := files.Files.Read(ctx, "/home/user/a.txt")
+			return err
+		},
+		"Files.Write": func() error {
+			_, err := files.Files.Write(ctx, "/home/user/a.txt", "a")
+			return err
+		},
+	}
+
+	for _, tt := range []struct {
+		header string
+		want   time.Duration
+	}{
+		{"", 0},
+		{"7", 7 * time.Second},
+		{"soon", 0},
+	} {
+		retryAfter = tt.header
+		for name, call := range calls {
+			err := call()
+			var rateErr *RateLimitError
+			if !errors.As(err, &rateErr) {
+				t.Fatalf("%s with Retry-After %q: error = %v, want *RateLimitError", name, tt.header, err)
+			}
+			if !errors.Is(err, ErrRateLimited) || !errors.Is(err, ErrRateLimit) {
+				t.Errorf("%s: errors.Is(err, ErrRateLimited) = false", name)
+			}
+			if rateErr.StatusCode != http.StatusTooManyRequests || rateErr.RetryAfter != tt.want {
+				t.Errorf("%s with Retry-After %q: got status %d, RetryAfter %v, want %v", name, tt.header, rateErr.StatusCode, rateErr.RetryAfter, tt.want)
+			}
+			if !strings.Contains(rateErr.Message, "slow down") {
+				t.Errorf("%s: Message = %q, want server message", name, rateErr.Message)
+			}
+		}
+	}
+}
+
+func TestTimeoutError(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		target  error
+		wantIs  bool
+		wantStr string
+	}{
+		{"execution matches ErrTimeout", NewExecutionTimeoutError(), ErrTimeout, true, "execution timeout exceeded"},
+		{"execution matches ErrExecutionTimeout", NewExecutionTimeoutError(), ErrExecutionTimeout, true, "execution timeout exceeded"},
+		{"execution does not match ErrRequestTimeout", NewExecutionTimeoutError(), ErrRequestTimeout, false, "execution timeout exceeded"},
+		{"request matches ErrTimeout", NewRequestTimeoutError(), ErrTimeout, true, "request timeout exceeded"},
+		{"request matches ErrRequestTimeout", NewRequestTimeoutError(), ErrRequestTimeout, true, "request timeout exceeded"},
+		{"request does not match ErrExecutionTimeout", NewRequestTimeoutError(), ErrExecutionTimeout, false, "request timeout exceeded"},
+		{"wrapped request timeout", fmt.Errorf("list contexts: %w", NewRequestTimeoutError()), ErrRequestTimeout, true, "list contexts: request timeout exceeded"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errors.Is(tt.err, tt.target); got != tt.wantIs {
+				t.Errorf("errors.Is(%v, %v) = %v, want %v", tt.err, tt.target, got, tt.wantIs)
+			}
+			if got := tt.err.Error(); got != tt.wantStr {
+				t.Errorf("Error() = %v, want %v", got, tt.wantStr)
+			}
+		})
+	}
+}
+
 func TestRunOptions(t *testing.T) {
 	cfg := defaultRunConfig()
 
@@ -433,7 +767,7 @@ func TestHTTPClient(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := newHTTPClient(nil, server.URL, "test-token", "")
+	client := newTestHTTPClient(server.URL, "test-token")
 
 	body, statusCode, err := client.doRequest(context.Background(), http.MethodGet, "/test", nil)
 	if err != nil {
@@ -544,3 +878,4746 @@ func TestGetMcpUrl(t *testing.T) {
 		})
 	}
 }
+
+func TestPipelineStopsOnCodeError(t *testing.T) {
+	server := newMockAPIServer(t)
+	defer server.Close()
+
+	var executed []string
+	jupyter := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req executeRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		executed = append(executed, req.Code)
+
+		enc := json.NewEncoder(w)
+		if req.Code == "1/0" {
+			enc.Encode(map[string]string{"type": "error", "name": "ZeroDivisionError", "value": "division by zero"})
+			return
+		}
+		enc.Encode(map[string]any{"type": "result", "text": "ok", "is_main_result": true})
+	}))
+	defer jupyter.Close()
+
+	sandbox, err := New(WithAPIKey("test-api-key"), WithAPIURL(server.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer sandbox.Close()
+	sandbox.httpClient = newTestHTTPClient(jupyter.URL, "")
+
+	results, err := sandbox.NewPipeline().
+		Code("x = 1").
+		Code("1/0").
+		Code("y = 2").
+		Run(context.Background())
+	if err == nil {
+		t.Fatal("Run() expected error, got nil")
+	}
+
+	var execErr *ExecutionError
+	if !errors.As(err, &execErr) || execErr.Name != "ZeroDivisionError" {
+		t.Errorf("Run() error = %v, want ZeroDivisionError", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("len(results) = %d, want 2", len(results))
+	}
+	if results[0].Failed() || !results[1].Failed() {
+		t.Errorf("Failed() = [%v %v], want [false true]", results[0].Failed(), results[1].Failed())
+	}
+	if len(executed) != 2 {
+		t.Errorf("executed %d steps, want 2", len(executed))
+	}
+}
+
+func TestConnectReadOnly(t *testing.T) {
+	var connected, killed bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/sandboxes/test-sandbox-id":
+			json.NewEncoder(w).Encode(map[string]any{
+				"sandboxID":   "test-sandbox-id",
+				"templateID":  "base",
+				"state":       "paused",
+				"envdVersion": "0.5.0",
+			})
+		case r.Method == http.MethodPost && r.URL.Path == "/sandboxes/test-sandbox-id/connect":
+			connected = true
+			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodDelete:
+			killed = true
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	sandbox, err := Connect("test-sandbox-id",
+		WithAPIKey("test-api-key"),
+		WithAPIURL(server.URL),
+		WithReadOnly(true),
+	)
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	if !sandbox.IsReadOnly() {
+		t.Error("IsReadOnly() = false, want true")
+	}
+
+	ctx := context.Background()
+	if _, err := sandbox.RunCode(ctx, "x = 1"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("RunCode() error = %v, want %v", err, ErrReadOnly)
+	}
+	if _, err := sandbox.Commands.Run(ctx, "ls"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Commands.Run() error = %v, want %v", err, ErrReadOnly)
+	}
+	if _, err := sandbox.Files.Read(ctx, "/home/user/file.txt"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Files.Read() error = %v, want %v", err, ErrReadOnly)
+	}
+
+	if err := sandbox.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if connected || killed {
+		t.Errorf("connected = %v, killed = %v, want neither", connected, killed)
+	}
+}
+
+func TestLanguageForFile(t *testing.T) {
+	tests := map[string]string{
+		"main.py":       LanguagePython,
+		"app.js":        LanguageJavaScript,
+		"app.ts":        LanguageTypeScript,
+		"stats.r":       LanguageR,
+		"stats.R":       LanguageR,
+		"setup.sh":      LanguageBash,
+		"Main.java":     LanguageJava,
+		"dir.v2/run.PY": LanguagePython,
+	}
+	for name, want := range tests {
+		if got, ok := languageForFile(name); !ok || got != want {
+			t.Errorf("languageForFile(%q) = %q, %v, want %q", name, got, ok, want)
+		}
+	}
+	for _, name := range []string{"notes.txt", "Makefile", "archive.tar.gz"} {
+		if got, ok := languageForFile(name); ok {
+			t.Errorf("languageForFile(%q) = %q, want no language", name, got)
+		}
+	}
+}
+
+func TestRunCodeFile(t *testing.T) {
+	handler := &mockProcessHandler{requests: make(chan *processpb.StartRequest, 2), stdout: "/tmp/run-abc\n"}
+	var executed []executeRequest
+	uploads := map[string]string{}
+
+	mux := http.NewServeMux()
+	mux.Handle(processpbconnect.NewProcessHandler(handler))
+	mux.HandleFunc("/execute", func(w http.ResponseWriter, r *http.Request) {
+		var req executeRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		executed = append(executed, req)
+		json.NewEncoder(w).Encode(map[string]string{"type": "stdout", "text": "ok\n"})
+	})
+	mux.HandleFunc("/files", func(w http.ResponseWriter, r *http.Request) {
+		p := r.URL.Query().Get("path")
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		data, _ := io.ReadAll(file)
+		uploads[p] = string(data)
+		json.NewEncoder(w).Encode([]map[string]string{{"name": path.Base(p), "type": "file", "path": p}})
+	})
+	envd := httptest.NewServer(mux)
+	defer envd.Close()
+
+	sandbox, err := New(WithDebug(true), WithSandboxURL(envd.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	sandbox.httpClient = newTestHTTPClient(envd.URL, "")
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	writeFile := func(name, content string) string {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	pyFile := writeFile("main.py", "print('ok')")
+	shFile := writeFile("setup.sh", "echo ok")
+	txtFile := writeFile("notes.txt", "x <- 1")
+
+	t.Run("interpreter", func(t *testing.T) {
+		executed = nil
+		for _, p := range []string{pyFile, shFile} {
+			if _, err := sandbox.RunCodeFile(ctx, p); err != nil {
+				t.Fatalf("RunCodeFile(%q) error = %v", p, err)
+			}
+		}
+		if _, err := sandbox.RunCodeFile(ctx, txtFile, WithLanguage(LanguageR)); err != nil {
+			t.Fatalf("RunCodeFile() with language error = %v", err)
+		}
+		// Other languages ignore WithPreferCommands. Spare capacity in opts
+		// must not be written to.
+		opts := make([]RunOption, 1, 2)
+		opts[0] = WithPreferCommands(true)
+		if _, err := sandbox.RunCodeFile(ctx, pyFile, opts...); err != nil {
+			t.Fatalf("RunCodeFile() preferring commands error = %v", err)
+		}
+		if opts[:2][1] != nil {
+			t.Error("RunCodeFile() wrote to the backing array of opts")
+		}
+
+		want := []executeRequest{
+			{Code: "print('ok')", Language: LanguagePython},
+			{Code: "echo ok", Language: LanguageBash},
+			{Code: "x <- 1", Language: LanguageR},
+			{Code: "print('ok')", Language: LanguagePython},
+		}
+		if !reflect.DeepEqual(executed, want) {
+			t.Errorf("executed = %+v, want %+v", executed, want)
+		}
+	})
+
+	t.Run("commands", func(t *testing.T) {
+		executed = nil
+		execution, err := sandbox.RunCodeFile(ctx, shFile, WithPreferCommands(true))
+		if err != nil {
+			t.Fatalf("RunCodeFile() error = %v", err)
+		}
+		if len(executed) != 0 {
+			t.Errorf("executed %d requests in the interpreter, want 0", len(executed))
+		}
+		if got := uploads["/tmp/run-abc"]; got != "echo ok" {
+			t.Errorf("uploaded script = %q, want %q", got, "echo ok")
+		}
+		<-handler.requests // mktemp
+		if cmd := strings.Join((<-handler.requests).GetProcess().GetArgs(), " "); !strings.Contains(cmd, "bash '/tmp/run-abc'") {
+			t.Errorf("command = %q, want it to run the uploaded script", cmd)
+		}
+		if got := strings.Join(execution.Logs.Stdout, ""); got != "/tmp/run-abc\n" {
+			t.Errorf("Logs.Stdout = %q, want %q", got, "/tmp/run-abc\n")
+		}
+		if execution.Error != nil {
+			t.Errorf("Error = %+v, want nil", execution.Error)
+		}
+	})
+
+	t.Run("unknown extension", func(t *testing.T) {
+		if _, err := sandbox.RunCodeFile(ctx, txtFile); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("RunCodeFile() error = %v, want %v", err, ErrInvalidArgument)
+		}
+		if _, err := sandbox.RunCodeFile(ctx, filepath.Join(dir, "missing.py")); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("RunCodeFile() with missing file error = %v, want %v", err, os.ErrNotExist)
+		}
+	})
+}
+
+func TestCheckpointContextReportsSkipped(t *testing.T) {
+	server := newMockAPIServer(t)
+	defer server.Close()
+
+	jupyter := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req executeRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.ContextID != "ctx-1" || !strings.Contains(req.Code, `"/tmp/state.pkl"`) {
+			t.Errorf("unexpected request: context=%q code=%q", req.ContextID, req.Code)
+		}
+		json.NewEncoder(w).Encode(map[string]string{
+			"type": "stdout",
+			"text": `{"saved": ["x"], "skipped": {"f": "TypeError: cannot pickle '_io.TextIOWrapper' object"}}` + "\n",
+		})
+	}))
+	defer jupyter.Close()
+
+	sandbox, err := New(WithAPIKey("test-api-key"), WithAPIURL(server.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer sandbox.Close()
+	sandbox.httpClient = newTestHTTPClient(jupyter.URL, "")
+
+	execCtx := &Context{ID: "ctx-1", Language: LanguagePython}
+	err = sandbox.CheckpointContext(context.Background(), execCtx, "/tmp/state.pkl")
+
+	var cpErr *CheckpointError
+	if !errors.As(err, &cpErr) {
+		t.Fatalf("CheckpointContext() error = %v, want *CheckpointError", err)
+	}
+	if names := cpErr.Names(); len(names) != 1 || names[0] != "f" {
+		t.Errorf("Names() = %v, want [f]", names)
+	}
+
+	err = sandbox.CheckpointContext(context.Background(), &Context{ID: "ctx-2", Language: LanguageJavaScript}, "/tmp/state.pkl")
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("CheckpointContext() javascript error = %v, want %v", err, ErrInvalidArgument)
+	}
+}
+
+func TestCollectMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sandboxes/sbx-1/metrics", "/sandboxes/sbx-2/metrics":
+			json.NewEncoder(w).Encode([]map[string]any{{"cpuCount": 2, "cpuUsedPct": 12.5}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	metrics, errs := CollectMetrics(context.Background(),
+		[]string{"sbx-1", "sbx-2", "sbx-missing"},
+		WithAPIKey("test-api-key"),
+		WithAPIURL(server.URL),
+	)
+
+	if len(metrics) != 2 {
+		t.Fatalf("len(metrics) = %d, want 2", len(metrics))
+	}
+	if m := metrics["sbx-1"]; len(m) != 1 || m[0].CPUCount != 2 {
+		t.Errorf("metrics[sbx-1] = %+v, want one sample with CPUCount 2", m)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], ErrNotFound) || !strings.Contains(errs[0].Error(), "sbx-missing") {
+		t.Errorf("errs = %v, want one ErrNotFound for sbx-missing", errs)
+	}
+}
+
+func TestWithHTTPTrace(t *testing.T) {
+	server := newMockAPIServer(t)
+	defer server.Close()
+
+	var conns atomic.Int32
+	trace := &httptrace.ClientTrace{
+		GotConn: func(httptrace.GotConnInfo) { conns.Add(1) },
+	}
+
+	custom := &http.Client{}
+	sandbox, err := New(WithAPIKey("test-api-key"), WithAPIURL(server.URL), WithHTTPClient(custom), WithHTTPTrace(trace))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	sandbox.Close()
+
+	if conns.Load() < 2 {
+		t.Errorf("GotConn called %d times, want at least 2 (create and kill)", conns.Load())
+	}
+	if custom.Transport != nil {
+		t.Error("WithHTTPTrace modified the user-provided HTTP client")
+	}
+}
+
+func TestWithLogger(t *testing.T) {
+	server := newMockAPIServer(t)
+	defer server.Close()
+
+	var (
+		mu  sync.Mutex
+		buf bytes.Buffer
+	)
+	logger := slog.New(slog.NewTextHandler(&lockedWriter{mu: &mu, w: &buf}, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	custom := &http.Client{}
+	sandbox, err := New(WithAPIKey("test-api-key"), WithAPIURL(server.URL), WithHTTPClient(custom), WithLogger(logger))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	sandbox.Close()
+	if custom.Transport != nil {
+		t.Error("WithLogger modified the user-provided HTTP client")
+	}
+
+	// Signed URLs carry the signature as a query parameter
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/files?path=%2Fa.txt&signature=v1_secret-signature", nil)
+	req.Header.Set("X-API-Key", "test-api-key")
+	req.Header.Set(headerAccessToken, "secret-token")
+	if resp, err := loggedHTTPClient(&http.Client{}, logger).Do(req); err == nil {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+	ListTemplates(context.Background(), WithTemplateAPIKey("template-api-key"), WithTemplateAPIURL(server.URL), WithTemplateLogger(logger))
+
+	// Template layers are uploaded to presigned GCS and S3 URLs
+	for _, query := range []string{
+		"X-Goog-Algorithm=GOOG4-RSA-SHA256&X-Goog-Credential=secret-gcs-credential&X-Goog-Signature=secret-gcs-signature",
+		"X-Amz-Credential=secret-s3-credential&x-amz-security-token=secret-s3-token&X-Amz-Signature=secret-s3-signature",
+	} {
+		req, _ := http.NewRequest(http.MethodPut, server.URL+"/upload?"+query, strings.NewReader("layer"))
+		if resp, err := loggedHTTPClient(&http.Client{}, logger).Do(req); err == nil {
+			resp.Body.Close()
+		}
+	}
+
+	mu.Lock()
+	out := buf.String()
+	mu.Unlock()
+	for _, want := range []string{
+		"e2b: sending request", "method=POST", "/sandboxes", "e2b: received response", "status=",
+		"e2b: response body closed", "headers.X-Api-Key=REDACTED", "headers.X-Access-Token=REDACTED",
+		"signature=REDACTED", "/templates", "X-Goog-Algorithm=GOOG4-RSA-SHA256", "X-Goog-Signature=REDACTED",
+		"x-amz-security-token=REDACTED",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log output does not contain %q:\n%s", want, out)
+		}
+	}
+	for _, secret := range []string{
+		"test-api-key", "template-api-key", "secret-token", "secret-signature",
+		"secret-gcs-credential", "secret-gcs-signature", "secret-s3-credential", "secret-s3-token", "secret-s3-signature",
+	} {
+		if strings.Contains(out, secret) {
+			t.Errorf("log output contains %q:\n%s", secret, out)
+		}
+	}
+}
+
+func TestExecutionMetadata(t *testing.T) {
+	jupyter := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		json.NewEncoder(w).Encode(map[string]string{"type": "stdout", "text": "ok\n"})
+	}))
+	defer jupyter.Close()
+
+	var (
+		mu  sync.Mutex
+		buf bytes.Buffer
+	)
+	logger := slog.New(slog.NewTextHandler(&lockedWriter{mu: &mu, w: &buf}, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	intercepted := make(chan map[string]string, 1)
+	sandbox, err := New(WithDebug(true), WithLogger(logger), WithRequestInterceptor(func(req *http.Request) {
+		intercepted <- ExecutionMetadataFromContext(req.Context())
+	}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	sandbox.httpClient = newHTTPClient(sandbox.envdHTTPClient(), &envdConn{info: envdConnInfo{jupyterURL: jupyter.URL}})
+
+	metadata := map[string]string{"task_id": "task-1", "trace_id": "trace-1"}
+	opt := WithExecutionMetadata(metadata)
+	metadata["task_id"] = "changed"
+
+	execution, err := sandbox.RunCode(context.Background(), "print('ok')", opt)
+	if err != nil {
+		t.Fatalf("RunCode() error = %v", err)
+	}
+	want := map[string]string{"task_id": "task-1", "trace_id": "trace-1"}
+	if !reflect.DeepEqual(execution.Metadata, want) {
+		t.Errorf("Execution.Metadata = %v, want %v", execution.Metadata, want)
+	}
+	if got := <-intercepted; !reflect.DeepEqual(got, want) {
+		t.Errorf("ExecutionMetadataFromContext() in interceptor = %v, want %v", got, want)
+	}
+
+	mu.Lock()
+	out := buf.String()
+	mu.Unlock()
+	for _, want := range []string{"metadata.task_id=task-1", "metadata.trace_id=trace-1"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log output does not contain %q:\n%s", want, out)
+		}
+	}
+}
+
+// lockedWriter serializes writes to w.
+type lockedWriter struct {
+	mu *sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
+
+func TestWithInterceptors(t *testing.T) {
+	server := newMockAPIServer(t)
+	defer server.Close()
+
+	// The recorder serves both API and envd requests, recording the trace
+	// header of each
+	var mu sync.Mutex
+	traced := map[string]string{}
+	recorder := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		traced[r.URL.Path] = r.Header.Get("Traceparent")
+		mu.Unlock()
+		server.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer recorder.Close()
+
+	var statuses atomic.Int32
+	custom := &http.Client{}
+	sandbox, err := New(
+		WithAPIKey("test-api-key"),
+		WithAPIURL(recorder.URL),
+		WithSandboxURL(recorder.URL),
+		WithHTTPClient(custom),
+		WithRequestInterceptor(func(req *http.Request) {
+			req.Header.Set("Traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		}),
+		WithResponseInterceptor(func(resp *http.Response) {
+			if resp.StatusCode > 0 {
+				statuses.Add(1)
+			}
+		}),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer sandbox.Close()
+	if custom.Transport != nil {
+		t.Error("WithRequestInterceptor modified the user-provided HTTP client")
+	}
+
+	ctx := context.Background()
+	sandbox.Files.Write(ctx, "/home/user/a.txt", "hello")
+	sandbox.Commands.Run(ctx, "echo hello")
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, path := range []string{"/sandboxes", "/files", "/process.Process/Start"} {
+		if got := traced[path]; !strings.HasPrefix(got, "00-4bf92f3577b34da6a3ce929d0e0e4736") {
+			t.Errorf("Traceparent of %s = %q, want the intercepted value (requests: %v)", path, got, traced)
+		}
+	}
+	if got := statuses.Load(); got < int32(len(traced)) {
+		t.Errorf("response interceptor called %d times, want at least %d", got, len(traced))
+	}
+}
+
+func TestWithProxy(t *testing.T) {
+	server := newMockAPIServer(t)
+	defer server.Close()
+
+	// The proxy serves every request with the mock API handler and records
+	// the hosts requested through it
+	var mu sync.Mutex
+	var hosts []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hosts = append(hosts, r.Host)
+		mu.Unlock()
+		server.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer proxy.Close()
+
+	sandbox, err := New(WithAPIKey("test-api-key"), WithAPIURL("http://api.e2b.invalid"), WithProxy(proxy.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	sandbox.Files.Read(context.Background(), "/home/user/a.txt")
+	sandbox.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	var api, envd bool
+	for _, host := range hosts {
+		api = api || host == "api.e2b.invalid"
+		envd = envd || strings.HasPrefix(host, fmt.Sprintf("%d-", EnvdPort))
+	}
+	if !api || !envd {
+		t.Errorf("proxied hosts = %v, want both API and envd requests", hosts)
+	}
+
+	for _, proxyURL := range []string{"://bad", "ftp://proxy:21", "http://", "proxy.corp:3128"} {
+		if _, err := New(WithDebug(true), WithProxy(proxyURL)); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("New(WithProxy(%q)) error = %v, want %v", proxyURL, err, ErrInvalidArgument)
+		}
+	}
+	custom := &http.Client{Transport: readOnlyTransport{}}
+	if _, err := New(WithDebug(true), WithHTTPClient(custom), WithProxy(proxy.URL)); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("New() with custom transport error = %v, want %v", err, ErrInvalidArgument)
+	}
+}
+
+func TestRunUntilFirstResult(t *testing.T) {
+	server := newMockAPIServer(t)
+	defer server.Close()
+
+	jupyter := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req executeRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Code == "serve()" {
+			json.NewEncoder(w).Encode(map[string]any{"type": "result", "text": "listening", "is_main_result": false})
+			w.(http.Flusher).Flush()
+		}
+		<-r.Context().Done()
+	}))
+	defer jupyter.Close()
+
+	sandbox, err := New(WithAPIKey("test-api-key"), WithAPIURL(server.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer sandbox.Close()
+	sandbox.httpClient = newTestHTTPClient(jupyter.URL, "")
+
+	ctx := context.Background()
+	result, handle, err := sandbox.RunUntilFirstResult(ctx, "serve()")
+	if err != nil {
+		t.Fatalf("RunUntilFirstResult() error = %v", err)
+	}
+	if result == nil || result.Text != "listening" {
+		t.Fatalf("RunUntilFirstResult() result = %+v, want listening", result)
+	}
+
+	handle.Cancel()
+	if _, err := handle.Wait(ctx); !errors.Is(err, ErrExecutionCanceled) {
+		t.Errorf("Wait() error = %v, want %v", err, ErrExecutionCanceled)
+	}
+
+	_, _, err = sandbox.RunUntilFirstResult(ctx, "hang()", WithFirstResultTimeout(50*time.Millisecond))
+	if !errors.Is(err, ErrExecutionTimeout) {
+		t.Errorf("RunUntilFirstResult() error = %v, want %v", err, ErrExecutionTimeout)
+	}
+}
+
+func TestBetaResume(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sandboxes/running-id/resume":
+			w.WriteHeader(http.StatusConflict)
+		case "/sandboxes/running-id/connect":
+			json.NewEncoder(w).Encode(map[string]any{
+				"sandboxID":          "running-id",
+				"envdVersion":        "0.5.0",
+				"envdAccessToken":    "fresh-token",
+				"trafficAccessToken": "fresh-traffic",
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	sandbox, err := BetaResume(ctx, "running-id", WithAPIKey("test-api-key"), WithAPIURL(server.URL))
+	if err != nil {
+		t.Fatalf("BetaResume() error = %v", err)
+	}
+	if sandbox.accessToken != "fresh-token" || sandbox.TrafficAccessToken != "fresh-traffic" {
+		t.Errorf("tokens = %q, %q, want refreshed tokens", sandbox.accessToken, sandbox.TrafficAccessToken)
+	}
+
+	sandbox.closed = true
+	if err := sandbox.BetaResume(ctx); err != nil {
+		t.Fatalf("Sandbox.BetaResume() error = %v", err)
+	}
+	if sandbox.closed {
+		t.Error("sandbox still closed after BetaResume()")
+	}
+
+	if _, err := BetaResume(ctx, "missing-id", WithAPIKey("test-api-key"), WithAPIURL(server.URL)); !errors.Is(err, ErrNotFound) {
+		t.Errorf("BetaResume() error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestSandboxPool(t *testing.T) {
+	server := newMockAPIServer(t)
+	defer server.Close()
+
+	ctx := context.Background()
+	pool, err := NewSandboxPool(ctx,
+		[]PoolOption{WithPoolSize(1), WithPoolMaxWaitTime(50 * time.Millisecond)},
+		WithAPIKey("test-api-key"),
+		WithAPIURL(server.URL),
+	)
+	if err != nil {
+		t.Fatalf("NewSandboxPool() error = %v", err)
+	}
+
+	first, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	if _, err := pool.Acquire(ctx); !errors.Is(err, ErrTimeout) {
+		t.Errorf("Acquire() on exhausted pool error = %v, want %v", err, ErrTimeout)
+	}
+
+	if err := pool.Release(first); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if err := pool.Release(first); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("second Release() error = %v, want %v", err, ErrInvalidArgument)
+	}
+	if stats := pool.Stats(); stats != (PoolStats{Idle: 1, Total: 1}) {
+		t.Errorf("Stats() = %+v, want 1 idle", stats)
+	}
+
+	second, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	if second != first {
+		t.Error("Acquire() did not reuse the released sandbox")
+	}
+	pool.Release(second)
+
+	if err := pool.Close(ctx); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if !first.IsClosed() {
+		t.Error("idle sandbox not killed by Close()")
+	}
+	if _, err := pool.Acquire(ctx); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("Acquire() after Close() error = %v, want %v", err, ErrPoolClosed)
+	}
+}
+
+func TestSandboxPoolFailedPrewarm(t *testing.T) {
+	mock := newMockAPIServer(t)
+	defer mock.Close()
+
+	// The first create, made by the prewarm, fails once released
+	var creates atomic.Int32
+	firstCreate, releaseFirst := make(chan struct{}), make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == "/sandboxes" && creates.Add(1) == 1 {
+			close(firstCreate)
+			<-releaseFirst
+			http.Error(w, "no capacity", http.StatusBadRequest)
+			return
+		}
+		mock.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	pool, err := NewSandboxPool(ctx,
+		[]PoolOption{WithPoolSize(1), WithPoolMinIdle(1)},
+		WithAPIKey("test-api-key"),
+		WithAPIURL(server.URL),
+	)
+	if err != nil {
+		t.Fatalf("NewSandboxPool() error = %v", err)
+	}
+	defer pool.Close(context.Background())
+
+	<-firstCreate
+	acquired := make(chan error, 1)
+	go func() {
+		_, err := pool.Acquire(ctx)
+		acquired <- err
+	}()
+	// Let Acquire block on the full pool before the prewarm fails
+	time.Sleep(50 * time.Millisecond)
+	close(releaseFirst)
+
+	if err := <-acquired; err != nil {
+		t.Fatalf("Acquire() after a failed prewarm error = %v", err)
+	}
+}
+
+func TestMaxStreamLineSize(t *testing.T) {
+	jupyter := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req executeRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		json.NewEncoder(w).Encode(map[string]string{"type": "stdout", "text": strings.Repeat("x", len(req.Code))})
+	}))
+	defer jupyter.Close()
+
+	sandbox, err := New(WithDebug(true), WithMaxStreamLineSize(4096))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	sandbox.httpClient = newTestHTTPClient(jupyter.URL, "")
+	sandbox.httpClient.maxLineSize = 4096
+	ctx := context.Background()
+
+	execution, err := sandbox.RunCode(ctx, strings.Repeat("a", 1024))
+	if err != nil {
+		t.Fatalf("RunCode() error = %v", err)
+	}
+	if len(execution.Logs.Stdout) != 1 || len(execution.Logs.Stdout[0]) != 1024 {
+		t.Errorf("Logs.Stdout = %d lines, want one line of 1024 bytes", len(execution.Logs.Stdout))
+	}
+
+	if _, err := sandbox.RunCode(ctx, strings.Repeat("a", 8192)); !errors.Is(err, ErrStreamLineTooLong) {
+		t.Errorf("RunCode() with long line error = %v, want %v", err, ErrStreamLineTooLong)
+	}
+}
+
+func TestRunCodeRetry(t *testing.T) {
+	server := newMockAPIServer(t)
+	defer server.Close()
+
+	var attempts atomic.Int32
+	jupyter := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"type": "error", "name": "NameError", "value": "x", "traceback": ""})
+	}))
+	defer jupyter.Close()
+
+	sandbox, err := New(WithAPIKey("test-api-key"), WithAPIURL(server.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	sandbox.httpClient = newTestHTTPClient(jupyter.URL, "")
+
+	ctx := context.Background()
+	execution, err := sandbox.RunCode(ctx, "x", WithRunRetry(3, time.Millisecond))
+	if err != nil {
+		t.Fatalf("RunCode() error = %v", err)
+	}
+	if execution.Error == nil {
+		t.Error("execution.Error = nil, want NameError")
+	}
+	if got := attempts.Load(); got != 3 {
+		t.Errorf("attempts = %d, want 3", got)
+	}
+
+	// Code errors are returned without retrying
+	attempts.Store(2)
+	if _, err := sandbox.RunCode(ctx, "x", WithRunRetry(3, time.Millisecond)); err != nil {
+		t.Fatalf("RunCode() error = %v", err)
+	}
+	if got := attempts.Load(); got != 3 {
+		t.Errorf("attempts = %d, want 3", got)
+	}
+
+	attempts.Store(0)
+	if _, err := sandbox.RunCode(ctx, "x", WithRunRetry(2, time.Millisecond)); err == nil {
+		t.Error("RunCode() error = nil, want error after exhausting retries")
+	}
+
+	// A connection dropped after the code was sent may have run it
+	var dropped atomic.Int32
+	dropping := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		dropped.Add(1)
+		io.Copy(io.Discard, r.Body)
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("Hijack() error = %v", err)
+			return
+		}
+		conn.Close()
+	}))
+	defer dropping.Close()
+	sandbox.httpClient = newTestHTTPClient(dropping.URL, "")
+	if _, err := sandbox.RunCode(ctx, "counter += 1", WithRunRetry(3, time.Millisecond)); err == nil {
+		t.Error("RunCode() on dropped connection error = nil, want error")
+	}
+	if got := dropped.Load(); got != 1 {
+		t.Errorf("attempts on dropped connection = %d, want 1", got)
+	}
+
+	// A refused connection never reached the kernel
+	refused := httptest.NewServer(http.NotFoundHandler())
+	refused.Close()
+	sandbox.httpClient = newTestHTTPClient(refused.URL, "")
+	if _, err = sandbox.RunCode(ctx, "x", WithRunRetry(2, time.Millisecond)); !isRetryableRunError(err) {
+		t.Errorf("RunCode() on refused connection error = %v, want a retryable dial error", err)
+	}
+}
+
+func TestWithNetwork(t *testing.T) {
+	var bodies []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == "/sandboxes" {
+			var body map[string]any
+			json.NewDecoder(r.Body).Decode(&body)
+			bodies = append(bodies, body)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]string{"sandboxID": "test-sandbox-id"})
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	network := NetworkOptions{
+		AllowOut:           []string{"api.example.com", "*.github.com", "10.0.0.0/8", "1.1.1.1"},
+		DenyOut:            []string{"0.0.0.0/0"},
+		AllowPublicTraffic: true,
+	}
+	sandbox, err := New(WithAPIKey("test-api-key"), WithAPIURL(server.URL), WithNetwork(network))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	want := `{"allowOut":["api.example.com","*.github.com","10.0.0.0/8","1.1.1.1"],"allowPublicTraffic":true,"denyOut":["0.0.0.0/0"]}`
+	if got, _ := json.Marshal(bodies[0]["network"]); string(got) != want {
+		t.Errorf("network = %s, want %s", got, want)
+	}
+
+	got := sandbox.NetworkConfig()
+	if got == nil || len(got.AllowOut) != 4 || !got.AllowPublicTraffic {
+		t.Fatalf("NetworkConfig() = %+v, want the configured options", got)
+	}
+	got.AllowOut[0] = "changed"
+	if sandbox.NetworkConfig().AllowOut[0] != "api.example.com" {
+		t.Error("NetworkConfig() returned options sharing state with the sandbox")
+	}
+
+	if _, err := New(WithAPIKey("test-api-key"), WithAPIURL(server.URL), WithNetwork(NetworkOptions{})); err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, ok := bodies[1]["network"]; ok {
+		t.Errorf("create request with empty network options = %v, want network omitted", bodies[1])
+	}
+
+	for _, dest := range []string{"", "http://example.com", "10.0.0.0/33", "bad_host.com", "-a.com"} {
+		_, err := New(WithAPIKey("test-api-key"), WithAPIURL(server.URL), WithNetwork(NetworkOptions{DenyOut: []string{dest}}))
+		if !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("New() with DenyOut %q error = %v, want %v", dest, err, ErrInvalidArgument)
+		}
+	}
+	if len(bodies) != 2 {
+		t.Errorf("create requests = %d, want invalid options rejected before the API call", len(bodies))
+	}
+}
+
+func TestWithMcpServers(t *testing.T) {
+	var recorded []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == "/sandboxes" {
+			recorded, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]string{"sandboxID": "test-sandbox-id", "domain": "e2b.app"})
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	servers := map[string]McpServerConfig{
+		"filesystem": {Command: "npx", Args: []string{"-y", "server-filesystem"}, Env: map[string]string{"ROOT": "/home/user"}},
+		"search":     {URL: "https://mcp.example.com/sse"},
+	}
+	sandbox, err := New(WithAPIKey("test-api-key"), WithAPIURL(server.URL),
+		WithMcp(map[string]any{"browserbase": map[string]any{"apiKey": "k"}}),
+		WithMcpServers(servers),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	var body struct {
+		Mcp map[string]json.RawMessage `json:"mcp"`
+	}
+	if err := json.Unmarshal(recorded, &body); err != nil {
+		t.Fatalf("create request body %s: %v", recorded, err)
+	}
+	if string(body.Mcp["browserbase"]) != `{"apiKey":"k"}` {
+		t.Errorf("mcp.browserbase = %s, want the WithMcp entry", body.Mcp["browserbase"])
+	}
+	if string(body.Mcp["search"]) != `{"url":"https://mcp.example.com/sse"}` {
+		t.Errorf("mcp.search = %s, want only the url", body.Mcp["search"])
+	}
+	var got McpServerConfig
+	if err := json.Unmarshal(body.Mcp["filesystem"], &got); err != nil {
+		t.Fatalf("mcp.filesystem: %v", err)
+	}
+	if fmt.Sprint(got) != fmt.Sprint(servers["filesystem"]) {
+		t.Errorf("mcp.filesystem = %+v, want %+v", got, servers["filesystem"])
+	}
+
+	if got, want := sandbox.McpURL(), "https://50005-test-sandbox-id.e2b.app/mcp"; got != want {
+		t.Errorf("McpURL() = %q, want %q", got, want)
+	}
+
+	for _, invalid := range []map[string]McpServerConfig{{"": {Command: "x"}}, {"empty": {}}} {
+		if _, err := New(WithAPIKey("test-api-key"), WithAPIURL(server.URL), WithMcpServers(invalid)); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("New() with MCP servers %v error = %v, want %v", invalid, err, ErrInvalidArgument)
+		}
+	}
+}
+
+func TestClone(t *testing.T) {
+	var createdFrom []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/sandboxes":
+			var req sandboxCreateRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			createdFrom = append(createdFrom, req.TemplateID)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]string{
+				"sandboxID":   fmt.Sprintf("sandbox-%d", len(createdFrom)),
+				"envdVersion": "0.5.0",
+			})
+		case r.Method == http.MethodPost && r.URL.Path == "/sandboxes/sandbox-1/snapshots":
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]string{"snapshotID": "snap:latest"})
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	sandbox, err := New(WithAPIKey("test-api-key"), WithAPIURL(server.URL), WithTemplate("base"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx := context.Background()
+	clone, err := sandbox.Clone(ctx)
+	if err != nil {
+		t.Fatalf("Clone() error = %v", err)
+	}
+	if clone.ID == sandbox.ID {
+		t.Errorf("clone ID = %q, want a new sandbox", clone.ID)
+	}
+	if len(createdFrom) != 2 || createdFrom[1] != "snap:latest" {
+		t.Errorf("created from templates %v, want clone created from snapshot", createdFrom)
+	}
+
+	sandbox.Close()
+	if _, err := sandbox.Clone(ctx); !errors.Is(err, ErrSandboxClosed) {
+		t.Errorf("Clone() after Close() error = %v, want %v", err, ErrSandboxClosed)
+	}
+}
+
+func TestFilesUploadStreams(t *testing.T) {
+	const size = 100 << 20
+
+	var received int64
+	envd := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reader, err := r.MultipartReader()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		part, err := reader.NextPart()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		received, _ = io.Copy(io.Discard, part)
+		json.NewEncoder(w).Encode([]map[string]string{{"name": "big.bin", "type": "file", "path": "/home/user/big.bin"}})
+	}))
+	defer envd.Close()
+
+	sandbox, err := New(WithDebug(true), WithSandboxURL(envd.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	info, err := sandbox.Files.Upload(context.Background(), "/home/user/big.bin", io.LimitReader(zeroReader{}, size))
+	if err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+
+	runtime.ReadMemStats(&after)
+
+	if received != size {
+		t.Errorf("received %d bytes, want %d", received, size)
+	}
+	if info.Path != "/home/user/big.bin" {
+		t.Errorf("info.Path = %q, want /home/user/big.bin", info.Path)
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > size/4 {
+		t.Errorf("Upload() allocated %d bytes, want bounded memory", allocated)
+	}
+}
+
+func TestFilesWriteProgress(t *testing.T) {
+	const size = 20 << 20
+
+	var requests atomic.Int32
+	envd := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		reader, err := r.MultipartReader()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var infos []map[string]string
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			io.Copy(io.Discard, part)
+			infos = append(infos, map[string]string{"name": path.Base(part.FileName()), "type": "file", "path": part.FileName()})
+		}
+		json.NewEncoder(w).Encode(infos)
+	}))
+	defer envd.Close()
+
+	sandbox, err := New(WithDebug(true), WithSandboxURL(envd.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+
+	t.Run("reader", func(t *testing.T) {
+		var calls int
+		var last int64
+		_, err := sandbox.Files.Write(ctx, "/home/user/data.bin", io.LimitReader(zeroReader{}, size),
+			WithProgress(func(n int64) {
+				calls++
+				if n < last {
+					t.Errorf("progress went backwards: %d < %d", n, last)
+				}
+				last = n
+			}),
+		)
+		if err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		if last != size || calls < 2 {
+			t.Errorf("progress reported %d bytes in %d calls, want %d bytes in several calls", last, calls, size)
+		}
+	})
+
+	t.Run("multiple files", func(t *testing.T) {
+		var last int64
+		infos, err := sandbox.Files.WriteFiles(ctx, []WriteEntry{
+			{Path: "/home/user/a.txt", Data: "hello"},
+			{Path: "/home/user/b.txt", Data: []byte("world!")},
+		}, WithProgress(func(n int64) { last = n }))
+		if err != nil {
+			t.Fatalf("WriteFiles() error = %v", err)
+		}
+		if len(infos) != 2 || last != 11 {
+			t.Errorf("WriteFiles() returned %d infos with progress %d, want 2 and 11", len(infos), last)
+		}
+	})
+
+	t.Run("size limit", func(t *testing.T) {
+		before := requests.Load()
+		_, err := sandbox.Files.Write(ctx, "/home/user/a.txt", "too long", WithMaxWriteSize(4))
+		if !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("Write() error = %v, want ErrInvalidArgument", err)
+		}
+		if requests.Load() != before {
+			t.Error("in-memory data over the limit should not be sent")
+		}
+
+		_, err = sandbox.Files.Write(ctx, "/home/user/data.bin", io.LimitReader(zeroReader{}, size), WithMaxWriteSize(size-1))
+		if !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("Write() error = %v, want ErrInvalidArgument", err)
+		}
+
+		if _, err := sandbox.Files.Write(ctx, "/home/user/a.txt", "fits", WithMaxWriteSize(4)); err != nil {
+			t.Errorf("Write() at the limit error = %v", err)
+		}
+	})
+}
+
+// zeroReader is an endless stream of zero bytes.
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+func TestSandboxState(t *testing.T) {
+	sandbox, err := New(WithDebug(true))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx := context.Background()
+	if got := sandbox.State(); got != SandboxStateRunning {
+		t.Errorf("State() = %v, want %v", got, SandboxStateRunning)
+	}
+	if err := sandbox.BetaPause(ctx); err != nil {
+		t.Fatalf("BetaPause() error = %v", err)
+	}
+	if got := sandbox.State(); got != SandboxStatePaused {
+		t.Errorf("State() after BetaPause() = %v, want %v", got, SandboxStatePaused)
+	}
+	if err := sandbox.BetaResume(ctx); err != nil {
+		t.Fatalf("BetaResume() error = %v", err)
+	}
+	if got := sandbox.State(); got != SandboxStateRunning {
+		t.Errorf("State() after BetaResume() = %v, want %v", got, SandboxStateRunning)
+	}
+	sandbox.Close()
+	if got := sandbox.State(); got != SandboxStateClosed {
+		t.Errorf("State() after Close() = %v, want %v", got, SandboxStateClosed)
+	}
+}
+
+func TestFilesDownloadToFile(t *testing.T) {
+	envd := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("path") != "/home/user/data.txt" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		io.WriteString(w, "hello")
+	}))
+	defer envd.Close()
+
+	sandbox, err := New(WithDebug(true), WithSandboxURL(envd.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	localPath := filepath.Join(dir, "data.txt")
+	if err := sandbox.Files.DownloadToFile(ctx, "/home/user/data.txt", localPath); err != nil {
+		t.Fatalf("DownloadToFile() error = %v", err)
+	}
+	if data, _ := os.ReadFile(localPath); string(data) != "hello" {
+		t.Errorf("downloaded content = %q, want hello", data)
+	}
+
+	missingPath := filepath.Join(dir, "missing.txt")
+	if err := sandbox.Files.DownloadToFile(ctx, "/home/user/missing.txt", missingPath); !errors.Is(err, ErrNotFound) {
+		t.Errorf("DownloadToFile() error = %v, want %v", err, ErrNotFound)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("directory has %d entries, want partial file removed", len(entries))
+	}
+}
+
+// roundTripFunc adapts a function to http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestWaitUntilReady(t *testing.T) {
+	var checks atomic.Int32
+	status := http.StatusOK
+	client := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		code := status
+		if checks.Add(1) < 3 {
+			code = http.StatusBadGateway
+		}
+		return &http.Response{StatusCode: code, Body: io.NopCloser(strings.NewReader("")), Request: r}, nil
+	})}
+
+	sandbox, err := New(WithDebug(true), WithHTTPClient(client))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx := context.Background()
+	var readyCalls int
+	err = sandbox.WaitUntilReady(ctx, WithWaitInterval(time.Millisecond), WithWaitOnReady(func(*Sandbox) { readyCalls++ }))
+	if err != nil {
+		t.Fatalf("WaitUntilReady() error = %v", err)
+	}
+	if got := checks.Load(); got != 3 {
+		t.Errorf("health checks = %d, want 3", got)
+	}
+	if readyCalls != 1 {
+		t.Errorf("OnReady calls = %d, want 1", readyCalls)
+	}
+
+	checks.Store(0)
+	if err := sandbox.WaitUntilReady(ctx, WithWaitInterval(time.Millisecond), WithWaitMaxAttempts(2)); !errors.Is(err, ErrTimeout) {
+		t.Errorf("WaitUntilReady() error = %v, want %v", err, ErrTimeout)
+	}
+
+	checks.Store(2)
+	status = http.StatusNotFound
+	if err := sandbox.WaitUntilReady(ctx, WithWaitInterval(time.Millisecond)); !errors.Is(err, ErrNotFound) {
+		t.Errorf("WaitUntilReady() error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	var connects atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/sandboxes":
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]string{"sandboxID": "test-sandbox-id", "envdAccessToken": "old-token"})
+		case r.Method == http.MethodPost && r.URL.Path == "/sandboxes/test-sandbox-id/connect":
+			connects.Add(1)
+			json.NewEncoder(w).Encode(map[string]string{"sandboxID": "test-sandbox-id", "envdAccessToken": "new-token"})
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	sandbox, err := New(WithAPIKey("test-api-key"), WithAPIURL(server.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	files := sandbox.Files
+
+	ctx := context.Background()
+	if err := sandbox.Reconnect(ctx); err != nil {
+		t.Fatalf("Reconnect() error = %v", err)
+	}
+	if connects.Load() != 1 {
+		t.Errorf("connect calls = %d, want 1", connects.Load())
+	}
+	if sandbox.ID != "test-sandbox-id" || sandbox.accessToken != "new-token" {
+		t.Errorf("ID = %q, accessToken = %q, want same ID with refreshed token", sandbox.ID, sandbox.accessToken)
+	}
+	if sandbox.Files != files || sandbox.conn.load().accessToken != "new-token" {
+		t.Error("Files client was replaced or did not pick up the refreshed token")
+	}
+
+	sandbox.Close()
+	if err := sandbox.Reconnect(ctx); !errors.Is(err, ErrSandboxClosed) {
+		t.Errorf("Reconnect() after Close() error = %v, want %v", err, ErrSandboxClosed)
+	}
+}
+
+func TestSandboxBetaResumeConcurrent(t *testing.T) {
+	handler := &mockProcessHandler{requests: make(chan *processpb.StartRequest, 1024)}
+	sandbox, lastToken := newTokenRotatingSandbox(t, handler)
+	defer sandbox.Close()
+	files, commands := sandbox.Files, sandbox.Commands
+
+	runConcurrently(t, sandbox, 10, sandbox.BetaResume)
+
+	if sandbox.Files != files || sandbox.Commands != commands {
+		t.Error("BetaResume() replaced the envd clients")
+	}
+	if _, err := sandbox.Commands.Run(context.Background(), "true"); err != nil {
+		t.Fatalf("Commands.Run() error = %v", err)
+	}
+	if got := lastToken(); got != "token-10" {
+		t.Errorf("access token after BetaResume() = %q, want %q", got, "token-10")
+	}
+}
+
+func TestReconnectConcurrent(t *testing.T) {
+	handler := &mockProcessHandler{requests: make(chan *processpb.StartRequest, 1024)}
+	sandbox, lastToken := newTokenRotatingSandbox(t, handler)
+	defer sandbox.Close()
+	files, commands := sandbox.Files, sandbox.Commands
+
+	runConcurrently(t, sandbox, 10, sandbox.Reconnect)
+
+	if sandbox.Files != files || sandbox.Commands != commands {
+		t.Error("Reconnect() replaced the envd clients")
+	}
+	if _, err := sandbox.Commands.Run(context.Background(), "true"); err != nil {
+		t.Fatalf("Commands.Run() error = %v", err)
+	}
+	if got := lastToken(); got != "token-10" {
+		t.Errorf("access token after Reconnect() = %q, want %q", got, "token-10")
+	}
+}
+
+func TestSandboxRefresh(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/sandboxes":
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]string{
+				"sandboxID": "test-sandbox-id", "domain": "old.e2b.app",
+				"envdVersion": "0.1.0", "envdAccessToken": "old-token", "trafficAccessToken": "old-traffic",
+			})
+		case r.Method == http.MethodPost && r.URL.Path == "/sandboxes/test-sandbox-id/connect":
+			json.NewEncoder(w).Encode(map[string]string{
+				"sandboxID": "test-sandbox-id", "domain": "new.e2b.app",
+				"envdVersion": "0.5.0", "envdAccessToken": "new-token", "trafficAccessToken": "new-traffic",
+			})
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	sandbox, err := New(WithAPIKey("test-api-key"), WithAPIURL(server.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer sandbox.Close()
+
+	oldURL, _ := sandbox.UploadURL("/tmp/a.txt")
+	if got := sandbox.GetHost(EnvdPort); got != "49983-test-sandbox-id.old.e2b.app" {
+		t.Fatalf("GetHost() = %q before Refresh()", got)
+	}
+	oldFiles := sandbox.Files
+
+	// Readers running alongside Refresh see either the old or the new host
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if host := sandbox.GetHost(EnvdPort); !strings.HasSuffix(host, ".e2b.app") {
+					t.Errorf("GetHost() = %q during Refresh()", host)
+					return
+				}
+			}
+		}()
+	}
+	if err := sandbox.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	wg.Wait()
+
+	if got, want := sandbox.GetHost(EnvdPort), "49983-test-sandbox-id.new.e2b.app"; got != want {
+		t.Errorf("GetHost() = %q, want %q", got, want)
+	}
+	if sandbox.TrafficAccessToken != "new-traffic" || sandbox.envdVersion != "0.5.0" {
+		t.Errorf("TrafficAccessToken = %q, envdVersion = %q, want refreshed values", sandbox.TrafficAccessToken, sandbox.envdVersion)
+	}
+
+	newURL, _ := sandbox.UploadURL("/tmp/a.txt")
+	wantSig, _ := getSignature("/tmp/a.txt", "write", "", "new-token", 0)
+	u, _ := url.Parse(newURL)
+	if u.Host != "49983-test-sandbox-id.new.e2b.app" || u.Query().Get("signature") != wantSig {
+		t.Errorf("UploadURL() = %q, want new host signed with the new token", newURL)
+	}
+	if strings.Contains(oldURL, "username=user") == strings.Contains(newURL, "username=user") {
+		t.Errorf("UploadURL() default user did not follow the refreshed envd version: %q -> %q", oldURL, newURL)
+	}
+
+	if sandbox.Files != oldFiles {
+		t.Error("Refresh() replaced the Files client")
+	}
+	if got := sandbox.Files.envdBaseURL(); !strings.Contains(got, "new.e2b.app") || sandbox.conn.load().accessToken != "new-token" {
+		t.Errorf("Files client base URL = %q, want it to follow the refreshed domain and token", got)
+	}
+}
+
+// newTokenRotatingSandbox returns a sandbox whose connect and resume calls
+// hand out a new envd access token each time, with commands served by
+// handler. It also returns the access token of the last command started.
+func newTokenRotatingSandbox(t *testing.T, handler *mockProcessHandler) (*Sandbox, func() string) {
+	t.Helper()
+	var tokens atomic.Int32
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/sandboxes":
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]string{"sandboxID": "test-sandbox-id", "domain": "e2b.invalid", "envdAccessToken": "token-0"})
+		case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/sandboxes/test-sandbox-id/"):
+			json.NewEncoder(w).Encode(map[string]string{
+				"sandboxID": "test-sandbox-id", "domain": "e2b.invalid",
+				"envdAccessToken": fmt.Sprintf("token-%d", tokens.Add(1)),
+			})
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	t.Cleanup(api.Close)
+
+	var mu sync.Mutex
+	var last string
+	mux := http.NewServeMux()
+	mux.Handle(processpbconnect.NewProcessHandler(handler))
+	envd := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		last = r.Header.Get(headerAccessToken)
+		mu.Unlock()
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(envd.Close)
+
+	sandbox, err := New(WithAPIKey("test-api-key"), WithAPIURL(api.URL), WithSandboxURL(envd.URL), WithoutRetry())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return sandbox, func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		return last
+	}
+}
+
+// runConcurrently runs the sandbox operations in a loop alongside reconnect,
+// which is called n times, so that the race detector sees them overlap.
+func runConcurrently(t *testing.T, sandbox *Sandbox, n int, reconnect func(context.Context) error) {
+	t.Helper()
+	ctx := context.Background()
+	stop := make(chan struct{})
+	var wg, started sync.WaitGroup
+	for _, op := range []func(context.Context){
+		func(ctx context.Context) { sandbox.Commands.Run(ctx, "true") },
+		func(ctx context.Context) { sandbox.Files.Exists(ctx, "/tmp") },
+		func(ctx context.Context) { sandbox.ListContexts(ctx) },
+		func(ctx context.Context) { sandbox.RunCode(ctx, "1") },
+	} {
+		wg.Add(1)
+		started.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; ; i++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				// Jupyter is unreachable, so bound the code execution calls
+				opCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+				op(opCtx)
+				cancel()
+				if i == 0 {
+					started.Done()
+				}
+			}
+		}()
+	}
+	started.Wait()
+	for i := 0; i < n; i++ {
+		if err := reconnect(ctx); err != nil {
+			t.Errorf("reconnect error = %v", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(stop)
+	wg.Wait()
+}
+
+func TestSandboxRefreshConcurrent(t *testing.T) {
+	handler := &mockProcessHandler{requests: make(chan *processpb.StartRequest, 1024)}
+	sandbox, lastToken := newTokenRotatingSandbox(t, handler)
+	defer sandbox.Close()
+	files, commands, pty := sandbox.Files, sandbox.Commands, sandbox.Pty
+
+	runConcurrently(t, sandbox, 10, sandbox.Refresh)
+
+	if sandbox.Files != files || sandbox.Commands != commands || sandbox.Pty != pty {
+		t.Error("Refresh() replaced the envd clients")
+	}
+	if _, err := sandbox.Commands.Run(context.Background(), "true"); err != nil {
+		t.Fatalf("Commands.Run() error = %v", err)
+	}
+	if got := lastToken(); got != "token-10" {
+		t.Errorf("access token after Refresh() = %q, want %q", got, "token-10")
+	}
+}
+
+func TestRunCodeStream(t *testing.T) {
+	server := newMockAPIServer(t)
+	defer server.Close()
+
+	jupyter := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		enc := json.NewEncoder(w)
+		enc.Encode(map[string]any{"type": "stdout", "text": "hello\n"})
+		enc.Encode(map[string]any{"type": "result", "text": "2", "is_main_result": true})
+		enc.Encode(map[string]any{"type": "number_of_executions", "execution_count": 1})
+	}))
+	defer jupyter.Close()
+
+	sandbox, err := New(WithAPIKey("test-api-key"), WithAPIURL(server.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer sandbox.Close()
+	sandbox.httpClient = newTestHTTPClient(jupyter.URL, "")
+
+	var stdout []string
+	events, err := sandbox.RunCodeStream(context.Background(), "print('hello'); 1 + 1", OnStdout(func(m OutputMessage) {
+		stdout = append(stdout, m.Line)
+	}))
+	if err != nil {
+		t.Fatalf("RunCodeStream() error = %v", err)
+	}
+
+	var types []ExecutionEventType
+	for event := range events {
+		types = append(types, event.Type)
+		if event.Type == ExecutionEventResult && event.Result.Text != "2" {
+			t.Errorf("result text = %q, want 2", event.Result.Text)
+		}
+	}
+
+	want := []ExecutionEventType{ExecutionEventStdout, ExecutionEventResult, ExecutionEventCount}
+	if fmt.Sprint(types) != fmt.Sprint(want) {
+		t.Errorf("event types = %v, want %v", types, want)
+	}
+	if len(stdout) != 1 {
+		t.Errorf("OnStdout calls = %d, want 1", len(stdout))
+	}
+
+	if _, err := sandbox.RunCodeStream(context.Background(), "x", WithLanguage("python"), WithContext(&Context{ID: "c"})); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("RunCodeStream() error = %v, want %v", err, ErrInvalidArgument)
+	}
+}
+
+func TestNewWithContextCancelledKillsSandbox(t *testing.T) {
+	var killed atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]string{"sandboxID": "orphan-id"})
+		case http.MethodDelete:
+			killed.Store(r.URL.Path == "/sandboxes/orphan-id")
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	// Cancel right after the create response was received
+	ctx, cancel := context.WithCancel(context.Background())
+	client := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		resp, err := http.DefaultTransport.RoundTrip(r)
+		if err != nil || r.Method != http.MethodPost {
+			return resp, err
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		cancel()
+		return resp, nil
+	})}
+
+	_, err := NewWithContext(ctx, WithAPIKey("test-api-key"), WithAPIURL(server.URL), WithHTTPClient(client))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("NewWithContext() error = %v, want %v", err, context.Canceled)
+	}
+	if !killed.Load() {
+		t.Error("sandbox created before cancellation was not killed")
+	}
+}
+
+func TestGlobMatching(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		path     string
+		wantBase string
+		want     bool
+	}{
+		{"/home/user/*.py", "/home/user/main.py", "/home/user", true},
+		{"/home/user/*.py", "/home/user/pkg/main.py", "/home/user", false},
+		{"/home/user/**/*.py", "/home/user/main.py", "/home/user", true},
+		{"/home/user/**/*.py", "/home/user/a/b/main.py", "/home/user", true},
+		{"/tmp/*/logs/*.log", "/tmp/app/logs/out.log", "/tmp", true},
+		{"/*.log", "/out.log", "/", true},
+		{"*.txt", "notes.txt", ".", true},
+	}
+
+	for _, tt := range tests {
+		base, rest := splitGlobPattern(tt.pattern)
+		if base != tt.wantBase {
+			t.Errorf("splitGlobPattern(%q) base = %q, want %q", tt.pattern, base, tt.wantBase)
+		}
+		rel := strings.TrimPrefix(strings.TrimPrefix(tt.path, base), "/")
+		if got := matchGlobSegments(rest, strings.Split(rel, "/")); got != tt.want {
+			t.Errorf("pattern %q matching %q = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestAPIRetry(t *testing.T) {
+	// newFlakyServer fails the first n requests to path with status, then
+	// serves them from the regular mock API.
+	newFlakyServer := func(path string, n int32, status int, retryAfter string) (*httptest.Server, *atomic.Int32, *[]time.Time) {
+		mock := newMockAPIServer(t)
+		t.Cleanup(mock.Close)
+		var attempts atomic.Int32
+		var times []time.Time
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != path {
+				mock.Config.Handler.ServeHTTP(w, r)
+				return
+			}
+			times = append(times, time.Now())
+			var body sandboxCreateRequest
+			if r.Method == http.MethodPost && json.NewDecoder(r.Body).Decode(&body) != nil {
+				t.Error("request body was not replayed")
+			}
+			if attempts.Add(1) <= n {
+				if retryAfter != "" {
+					w.Header().Set("Retry-After", retryAfter)
+				}
+				w.WriteHeader(status)
+				return
+			}
+			mock.Config.Handler.ServeHTTP(w, r)
+		}))
+		t.Cleanup(server.Close)
+		return server, &attempts, &times
+	}
+
+	t.Run("create retries with backoff", func(t *testing.T) {
+		server, attempts, times := newFlakyServer("/sandboxes", 2, http.StatusServiceUnavailable, "")
+		sandbox, err := New(WithAPIKey("test-api-key"), WithAPIURL(server.URL), WithRetry(3, 20*time.Millisecond))
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		sandbox.Close()
+		if got := attempts.Load(); got != 3 {
+			t.Errorf("attempts = %d, want 3", got)
+		}
+		// Jitter keeps each delay within [base/2, base] of the doubled base
+		for i, want := range []time.Duration{10 * time.Millisecond, 20 * time.Millisecond} {
+			if gap := (*times)[i+1].Sub((*times)[i]); gap < want {
+				t.Errorf("delay before attempt %d = %v, want >= %v", i+2, gap, want)
+			}
+		}
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		server, attempts, _ := newFlakyServer("/sandboxes", 5, http.StatusBadGateway, "")
+		if _, err := New(WithAPIKey("test-api-key"), WithAPIURL(server.URL), WithRetry(2, time.Millisecond)); err == nil {
+			t.Fatal("New() error = nil, want error")
+		}
+		if got := attempts.Load(); got != 2 {
+			t.Errorf("attempts = %d, want 2", got)
+		}
+	})
+
+	t.Run("respects Retry-After", func(t *testing.T) {
+		server, attempts, times := newFlakyServer("/sandboxes/sandbox-id", 1, http.StatusTooManyRequests, "1")
+		if err := Kill(context.Background(), "sandbox-id", WithAPIKey("test-api-key"), WithAPIURL(server.URL), WithRetry(3, time.Millisecond)); err != nil {
+			t.Fatalf("Kill() error = %v", err)
+		}
+		if got := attempts.Load(); got != 2 {
+			t.Errorf("attempts = %d, want 2", got)
+		}
+		if gap := (*times)[1].Sub((*times)[0]); gap < time.Second {
+			t.Errorf("delay = %v, want >= 1s", gap)
+		}
+	})
+
+	t.Run("non-transient errors are not retried", func(t *testing.T) {
+		for _, status := range []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError} {
+			server, attempts, _ := newFlakyServer("/sandboxes", 1, status, "")
+			if _, err := New(WithAPIKey("test-api-key"), WithAPIURL(server.URL), WithRetry(3, time.Millisecond)); err == nil {
+				t.Fatalf("New() with status %d error = nil, want error", status)
+			}
+			if got := attempts.Load(); got != 1 {
+				t.Errorf("attempts with status %d = %d, want 1", status, got)
+			}
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		server, attempts, _ := newFlakyServer("/sandboxes", 1, http.StatusServiceUnavailable, "")
+		if _, err := New(WithAPIKey("test-api-key"), WithAPIURL(server.URL), WithoutRetry()); err == nil {
+			t.Fatal("New() error = nil, want error")
+		}
+		if got := attempts.Load(); got != 1 {
+			t.Errorf("attempts = %d, want 1", got)
+		}
+	})
+}
+
+func TestAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-123")
+		switch r.URL.Path {
+		case "/sandboxes":
+			w.WriteHeader(http.StatusForbidden)
+			io.WriteString(w, `{"code": 403, "message": "team is blocked"}`)
+		default:
+			http.Error(w, "template not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	_, err := New(WithAPIKey("test-api-key"), WithAPIURL(server.URL), WithoutRetry())
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("New() error = %v, want *APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusForbidden || apiErr.Code != 403 || apiErr.Message != "team is blocked" || apiErr.RequestID != "req-123" {
+		t.Errorf("New() error = %+v, want the parsed error envelope", apiErr)
+	}
+
+	_, err = GetBuildStatus(context.Background(), "template-id", "build-id", WithTemplateAPIKey("test-api-key"), WithTemplateAPIURL(server.URL))
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || apiErr.Message != "template not found" {
+		t.Errorf("GetBuildStatus() error = %v, want a 404 *APIError", err)
+	}
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetBuildStatus() error = %v, want it to match %v", err, ErrNotFound)
+	}
+}
+
+// mockProcessHandler records StartRequests and reports every process as
+// started and exited successfully.
+type mockProcessHandler struct {
+	processpbconnect.UnimplementedProcessHandler
+
+	requests chan *processpb.StartRequest
+
+	// release, if set, delays the end event until it is closed
+	release chan struct{}
+	// fail makes the stream fail instead of sending the end event
+	fail bool
+	// endBeforeStart sends the output and end event before the start event
+	endBeforeStart bool
+	// exitCode is the exit code reported by the end event
+	exitCode int32
+	// stdout, if set, is sent after the start event
+	stdout string
+}
+
+func (h *mockProcessHandler) Start(ctx context.Context, req *connect.Request[processpb.StartRequest], stream *connect.ServerStream[processpb.StartResponse]) error {
+	h.requests <- req.Msg
+	end := &processpb.ProcessEvent{Event: &processpb.ProcessEvent_End{End: &processpb.ProcessEvent_EndEvent{ExitCode: h.exitCode, Exited: true}}}
+	if h.endBeforeStart {
+		data := &processpb.ProcessEvent{Event: &processpb.ProcessEvent_Data{Data: &processpb.ProcessEvent_DataEvent{
+			Output: &processpb.ProcessEvent_DataEvent_Stdout{Stdout: []byte("done\n")},
+		}}}
+		if err := stream.Send(&processpb.StartResponse{Event: data}); err != nil {
+			return err
+		}
+		return stream.Send(&processpb.StartResponse{Event: end})
+	}
+	start := &processpb.ProcessEvent{Event: &processpb.ProcessEvent_Start{Start: &processpb.ProcessEvent_StartEvent{Pid: 42}}}
+	if err := stream.Send(&processpb.StartResponse{Event: start}); err != nil {
+		return err
+	}
+	if h.stdout != "" {
+		data := &processpb.ProcessEvent{Event: &processpb.ProcessEvent_Data{Data: &processpb.ProcessEvent_DataEvent{
+			Output: &processpb.ProcessEvent_DataEvent_Stdout{Stdout: []byte(h.stdout)},
+		}}}
+		if err := stream.Send(&processpb.StartResponse{Event: data}); err != nil {
+			return err
+		}
+	}
+	if h.release != nil {
+		select {
+		case <-h.release:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if h.fail {
+		return connect.NewError(connect.CodeInternal, errors.New("process lost"))
+	}
+	return stream.Send(&processpb.StartResponse{Event: end})
+}
+
+// newTestHTTPClient returns an httpClient sending Jupyter requests to
+// baseURL.
+func newTestHTTPClient(baseURL, accessToken string) *httpClient {
+	return newHTTPClient(nil, &envdConn{info: envdConnInfo{jupyterURL: baseURL, accessToken: accessToken}})
+}
+
+// newMockProcessSandbox returns a debug sandbox whose commands are served by handler.
+func newMockProcessSandbox(t *testing.T, handler *mockProcessHandler) *Sandbox {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.Handle(processpbconnect.NewProcessHandler(handler))
+	envd := httptest.NewServer(mux)
+	t.Cleanup(envd.Close)
+
+	sandbox, err := New(WithDebug(true), WithSandboxURL(envd.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return sandbox
+}
+
+func TestCommandsRunArgs(t *testing.T) {
+	handler := &mockProcessHandler{requests: make(chan *processpb.StartRequest, 1)}
+	sandbox := newMockProcessSandbox(t, handler)
+	ctx := context.Background()
+
+	args := []string{"-n", "$(rm -rf /)", "two words"}
+	if _, err := sandbox.Commands.RunArgs(ctx, "echo", args, WithShell("/bin/sh")); err != nil {
+		t.Fatalf("RunArgs() error = %v", err)
+	}
+	process := (<-handler.requests).GetProcess()
+	if process.GetCmd() != "echo" {
+		t.Errorf("Cmd = %q, want %q", process.GetCmd(), "echo")
+	}
+	if got := process.GetArgs(); strings.Join(got, "\x00") != strings.Join(args, "\x00") {
+		t.Errorf("Args = %q, want %q", got, args)
+	}
+
+	handle, err := sandbox.Commands.RunArgsBackground(ctx, "ls", []string{"-la", "a;b"})
+	if err != nil {
+		t.Fatalf("RunArgsBackground() error = %v", err)
+	}
+	if handle.PID() != 42 {
+		t.Errorf("PID() = %d, want 42", handle.PID())
+	}
+	if got := (<-handler.requests).GetProcess().GetArgs(); len(got) != 2 || got[1] != "a;b" {
+		t.Errorf("Args = %q, want [-la a;b]", got)
+	}
+
+	if _, err := sandbox.Commands.Run(ctx, "echo hi"); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	process = (<-handler.requests).GetProcess()
+	if process.GetCmd() != "/bin/bash" || strings.Join(process.GetArgs(), " ") != "-l -c echo hi" {
+		t.Errorf("Run() argv = %q %q, want /bin/bash [-l -c echo hi]", process.GetCmd(), process.GetArgs())
+	}
+
+	if _, err := sandbox.Commands.RunArgs(ctx, "", nil); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("RunArgs() with empty name error = %v, want %v", err, ErrInvalidArgument)
+	}
+}
+
+func TestWithShell(t *testing.T) {
+	handler := &mockProcessHandler{requests: make(chan *processpb.StartRequest, 1)}
+	sandbox := newMockProcessSandbox(t, handler)
+	ctx := context.Background()
+
+	tests := []struct {
+		opts     []CommandOption
+		wantCmd  string
+		wantArgs []string
+	}{
+		{nil, "/bin/bash", []string{"-l", "-c", "echo $0"}},
+		{[]CommandOption{WithShell("/bin/sh")}, "/bin/sh", []string{"-c", "echo $0"}},
+		{[]CommandOption{WithShell("/bin/zsh", "-e", "-c")}, "/bin/zsh", []string{"-e", "-c", "echo $0"}},
+	}
+	for _, tt := range tests {
+		if _, err := sandbox.Commands.Run(ctx, "echo $0", tt.opts...); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		process := (<-handler.requests).GetProcess()
+		if process.GetCmd() != tt.wantCmd || strings.Join(process.GetArgs(), "\x00") != strings.Join(tt.wantArgs, "\x00") {
+			t.Errorf("Run() argv = %q %q, want %q %q", process.GetCmd(), process.GetArgs(), tt.wantCmd, tt.wantArgs)
+		}
+	}
+
+	if _, err := sandbox.Commands.Run(ctx, "echo $0", WithShell("")); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("Run() with empty shell error = %v, want %v", err, ErrInvalidArgument)
+	}
+}
+
+func TestFilesReadJSON(t *testing.T) {
+	envd := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("path") != "/home/user/config.json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		io.WriteString(w, `{"name":"app","port":8080}`)
+	}))
+	defer envd.Close()
+
+	sandbox, err := New(WithDebug(true), WithSandboxURL(envd.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx := context.Background()
+	var cfg struct {
+		Name string `json:"name"`
+		Port int    `json:"port"`
+	}
+	if err := sandbox.Files.ReadJSON(ctx, "/home/user/config.json", &cfg); err != nil {
+		t.Fatalf("ReadJSON() error = %v", err)
+	}
+	if cfg.Name != "app" || cfg.Port != 8080 {
+		t.Errorf("ReadJSON() decoded %+v", cfg)
+	}
+
+	if err := sandbox.Files.ReadJSON(ctx, "/home/user/missing.json", &cfg); !errors.Is(err, ErrNotFound) {
+		t.Errorf("ReadJSON() missing file error = %v, want %v", err, ErrNotFound)
+	}
+	if err := sandbox.Files.ReadJSON(ctx, "/home/user/config.json", cfg); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("ReadJSON() non-pointer error = %v, want %v", err, ErrInvalidArgument)
+	}
+}
+
+func TestFilesReadMany(t *testing.T) {
+	var inFlight, maxInFlight, reads atomic.Int32
+	envd := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reads.Add(1)
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		p := r.URL.Query().Get("path")
+		if strings.HasSuffix(p, "missing.txt") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		io.WriteString(w, "contents of "+p)
+	}))
+	defer envd.Close()
+
+	sandbox, err := New(WithDebug(true), WithSandboxURL(envd.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	var paths []string
+	for i := range 3 * readManyWorkers {
+		paths = append(paths, fmt.Sprintf("/data/%02d.txt", i))
+	}
+	paths = append(paths, "/data/missing.txt", paths[0])
+
+	contents, errs := sandbox.Files.ReadMany(context.Background(), paths)
+	if len(contents) != 3*readManyWorkers {
+		t.Errorf("ReadMany() returned %d contents, want %d", len(contents), 3*readManyWorkers)
+	}
+	for _, p := range paths[:3*readManyWorkers] {
+		if got := string(contents[p]); got != "contents of "+p {
+			t.Errorf("ReadMany() contents[%q] = %q, want %q", p, got, "contents of "+p)
+		}
+	}
+	if len(errs) != 1 || !errors.Is(errs["/data/missing.txt"], ErrNotFound) {
+		t.Errorf("ReadMany() errs = %v, want only %q matching %v", errs, "/data/missing.txt", ErrNotFound)
+	}
+	if got := reads.Load(); got != int32(3*readManyWorkers+1) {
+		t.Errorf("ReadMany() made %d reads, want %d with the duplicate read once", got, 3*readManyWorkers+1)
+	}
+	if got := maxInFlight.Load(); got > readManyWorkers {
+		t.Errorf("ReadMany() ran %d reads at once, want at most %d", got, readManyWorkers)
+	}
+}
+
+func TestFilesGetMimeType(t *testing.T) {
+	png := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 2048)...)
+	files := map[string][]byte{
+		"/out/plot":       png,
+		"/out/notes.txt":  []byte("hello"),
+		"/out/data.json":  []byte(`{"a": 1}`),
+		"/out/fake.png":   []byte("just text"),
+		"/out/bundle.zip": []byte("PK\x03\x04rest"),
+		"/out/blob":       {0x00, 0x01, 0x02, 0xfe},
+	}
+	envd := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := files[r.URL.Query().Get("path")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	}))
+	defer envd.Close()
+
+	sandbox, err := New(WithDebug(true), WithSandboxURL(envd.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+
+	want := map[string]string{
+		"/out/plot":       "image/png",
+		"/out/notes.txt":  "text/plain",
+		"/out/data.json":  "application/json",
+		"/out/fake.png":   "text/plain",
+		"/out/bundle.zip": "application/zip",
+		"/out/blob":       "application/octet-stream",
+	}
+	for p, wantType := range want {
+		got, err := sandbox.Files.GetMimeType(ctx, p)
+		if err != nil {
+			t.Fatalf("GetMimeType(%q) error = %v", p, err)
+		}
+		if got != wantType {
+			t.Errorf("GetMimeType(%q) = %q, want %q", p, got, wantType)
+		}
+	}
+
+	if got, _ := sandbox.Files.GetMimeType(ctx, "/out/plot", WithSniffBytes(4)); got == "image/png" {
+		t.Errorf("GetMimeType() with 4 sniff bytes = %q, want the truncated signature to be unrecognized", got)
+	}
+	if _, err := sandbox.Files.GetMimeType(ctx, "/out/missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetMimeType() missing file error = %v, want %v", err, ErrNotFound)
+	}
+	if _, err := sandbox.Files.GetMimeType(ctx, "/out/plot", WithSniffBytes(0)); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("GetMimeType() with 0 sniff bytes error = %v, want %v", err, ErrInvalidArgument)
+	}
+}
+
+func TestFilesReadOffset(t *testing.T) {
+	content := "0123456789abcdefghij"
+	for _, ranged := range []bool{true, false} {
+		envd := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("path") != "/var/log/app.log" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			if ranged {
+				http.ServeContent(w, r, "app.log", time.Time{}, strings.NewReader(content))
+				return
+			}
+			io.WriteString(w, content)
+		}))
+		defer envd.Close()
+
+		sandbox, err := New(WithDebug(true), WithSandboxURL(envd.URL))
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		ctx := context.Background()
+
+		tests := []struct {
+			offset, length int64
+			want           string
+		}{
+			{5, 3, "567"},
+			{15, 0, "fghij"},
+			{-4, 0, "ghij"},
+			{-4, 2, "gh"},
+			{18, 10, "ij"},
+			{20, 0, ""},
+		}
+		for _, tt := range tests {
+			got, err := sandbox.Files.ReadOffset(ctx, "/var/log/app.log", tt.offset, tt.length)
+			if err != nil {
+				t.Fatalf("ReadOffset(%d, %d) with range support %v error = %v", tt.offset, tt.length, ranged, err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("ReadOffset(%d, %d) with range support %v = %q, want %q", tt.offset, tt.length, ranged, got, tt.want)
+			}
+		}
+
+		if _, err := sandbox.Files.ReadOffset(ctx, "/var/log/app.log", 0, -1); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("ReadOffset() with negative length error = %v, want %v", err, ErrInvalidArgument)
+		}
+		if _, err := sandbox.Files.ReadOffset(ctx, "/missing", 0, 1); !errors.Is(err, ErrNotFound) {
+			t.Errorf("ReadOffset() of missing file error = %v, want %v", err, ErrNotFound)
+		}
+	}
+}
+
+func TestCommandHandleWait(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("done and result", func(t *testing.T) {
+		handler := &mockProcessHandler{requests: make(chan *processpb.StartRequest, 1), release: make(chan struct{})}
+		sandbox := newMockProcessSandbox(t, handler)
+
+		handle, err := sandbox.Commands.RunBackground(ctx, "sleep 1")
+		if err != nil {
+			t.Fatalf("RunBackground() error = %v", err)
+		}
+		if _, ok := handle.Result(); ok {
+			t.Error("Result() ok = true before the command finished")
+		}
+
+		waitCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+		_, err = handle.Wait(waitCtx)
+		if !errors.Is(err, ErrWaitCanceled) || !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Wait() error = %v, want %v wrapping %v", err, ErrWaitCanceled, context.DeadlineExceeded)
+		}
+		if errors.Is(err, ErrCommandStream) {
+			t.Errorf("Wait() error = %v, should not wrap %v", err, ErrCommandStream)
+		}
+
+		close(handler.release)
+		select {
+		case <-handle.Done():
+		case <-time.After(5 * time.Second):
+			t.Fatal("Done() not closed after the end event")
+		}
+		if result, ok := handle.Result(); !ok || result.ExitCode != 0 {
+			t.Errorf("Result() = %+v, %v, want exit code 0", result, ok)
+		}
+		if _, err := handle.Wait(ctx); err != nil {
+			t.Errorf("Wait() after finish error = %v", err)
+		}
+	})
+
+	t.Run("stream error", func(t *testing.T) {
+		handler := &mockProcessHandler{requests: make(chan *processpb.StartRequest, 1), fail: true}
+		sandbox := newMockProcessSandbox(t, handler)
+
+		handle, err := sandbox.Commands.RunBackground(ctx, "sleep 1")
+		if err != nil {
+			t.Fatalf("RunBackground() error = %v", err)
+		}
+		_, err = handle.Wait(ctx)
+		if !errors.Is(err, ErrCommandStream) || errors.Is(err, ErrWaitCanceled) {
+			t.Errorf("Wait() error = %v, want %v", err, ErrCommandStream)
+		}
+		if _, ok := handle.Result(); ok {
+			t.Error("Result() ok = true after stream error")
+		}
+	})
+}
+
+// mockStdinHandler records the input sent to processes.
+type mockStdinHandler struct {
+	*mockProcessHandler
+	inputs chan *processpb.SendInputRequest
+	// closed receives the pid of each CloseStdin call
+	closed chan uint32
+	// releaseOnClose ends the process when its stdin is closed
+	releaseOnClose bool
+}
+
+func (h *mockStdinHandler) SendInput(ctx context.Context, req *connect.Request[processpb.SendInputRequest]) (*connect.Response[processpb.SendInputResponse], error) {
+	h.inputs <- req.Msg
+	return connect.NewResponse(&processpb.SendInputResponse{}), nil
+}
+
+func (h *mockStdinHandler) CloseStdin(ctx context.Context, req *connect.Request[processpb.CloseStdinRequest]) (*connect.Response[processpb.CloseStdinResponse], error) {
+	h.closed <- req.Msg.GetProcess().GetPid()
+	if h.releaseOnClose {
+		close(h.release)
+	}
+	return connect.NewResponse(&processpb.CloseStdinResponse{}), nil
+}
+
+func TestCommandHandleStdin(t *testing.T) {
+	handler := &mockStdinHandler{
+		mockProcessHandler: &mockProcessHandler{requests: make(chan *processpb.StartRequest, 1), release: make(chan struct{})},
+		inputs:             make(chan *processpb.SendInputRequest, 1),
+		closed:             make(chan uint32, 1),
+	}
+	mux := http.NewServeMux()
+	mux.Handle(processpbconnect.NewProcessHandler(handler))
+	envd := httptest.NewServer(mux)
+	defer envd.Close()
+
+	sandbox, err := New(WithDebug(true), WithSandboxURL(envd.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+
+	handle, err := sandbox.Commands.RunBackground(ctx, "cat", WithStdin(true))
+	if err != nil {
+		t.Fatalf("RunBackground() error = %v", err)
+	}
+	<-handler.requests
+	defer close(handler.release)
+
+	if err := handle.SendStdin(ctx, "hello\n"); err != nil {
+		t.Fatalf("SendStdin() error = %v", err)
+	}
+	input := <-handler.inputs
+	if input.GetProcess().GetPid() != 42 || string(input.GetInput().GetStdin()) != "hello\n" {
+		t.Errorf("SendInput request = %v, want stdin %q for pid 42", input, "hello\n")
+	}
+
+	if err := handle.CloseStdin(ctx); err != nil {
+		t.Fatalf("CloseStdin() error = %v", err)
+	}
+	if pid := <-handler.closed; pid != 42 {
+		t.Errorf("CloseStdin pid = %d, want 42", pid)
+	}
+
+	// envd versions without the CloseStdin RPC
+	old := newMockProcessSandbox(t, &mockProcessHandler{requests: make(chan *processpb.StartRequest, 1)})
+	if err := old.Commands.CloseStdin(ctx, 42); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("CloseStdin() on old envd error = %v, want %v", err, ErrInvalidArgument)
+	}
+
+	if err := (&CommandHandle{pid: 1}).SendStdin(ctx, "x"); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("SendStdin() on unattached handle error = %v, want %v", err, ErrInvalidArgument)
+	}
+}
+
+func TestCommandsRunWithStdinPipe(t *testing.T) {
+	handler := &mockStdinHandler{
+		mockProcessHandler: &mockProcessHandler{requests: make(chan *processpb.StartRequest, 1), release: make(chan struct{})},
+		inputs:             make(chan *processpb.SendInputRequest, 3),
+		closed:             make(chan uint32, 1),
+		releaseOnClose:     true,
+	}
+	mux := http.NewServeMux()
+	mux.Handle(processpbconnect.NewProcessHandler(handler))
+	envd := httptest.NewServer(mux)
+	defer envd.Close()
+
+	sandbox, err := New(WithDebug(true), WithSandboxURL(envd.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+
+	// Spare capacity in opts must not be written to
+	opts := make([]CommandOption, 1, 2)
+	opts[0] = WithStdin(false)
+	stdin, handle, err := sandbox.Commands.RunWithStdinPipe(ctx, "gzip > /tmp/data.gz", opts...)
+	if err != nil {
+		t.Fatalf("RunWithStdinPipe() error = %v", err)
+	}
+	if opts[:2][1] != nil {
+		t.Error("RunWithStdinPipe() wrote to the backing array of opts")
+	}
+	if req := <-handler.requests; !req.GetStdin() {
+		t.Error("RunWithStdinPipe() started the command without stdin")
+	}
+
+	data := bytes.Repeat([]byte("0123456789"), 15000)
+	if n, err := io.Copy(stdin, bytes.NewReader(data)); err != nil || n != int64(len(data)) {
+		t.Fatalf("io.Copy() = %d, %v, want %d, nil", n, err, len(data))
+	}
+	var received []byte
+	for len(received) < len(data) {
+		input := <-handler.inputs
+		if chunk := input.GetInput().GetStdin(); len(chunk) > maxStdinChunkSize || input.GetProcess().GetPid() != 42 {
+			t.Fatalf("SendInput request for pid %d with %d bytes, want pid 42 and at most %d bytes",
+				input.GetProcess().GetPid(), len(chunk), maxStdinChunkSize)
+		}
+		received = append(received, input.GetInput().GetStdin()...)
+	}
+	if !bytes.Equal(received, data) {
+		t.Error("stdin received different data than was written")
+	}
+
+	if err := stdin.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if pid := <-handler.closed; pid != 42 {
+		t.Errorf("CloseStdin pid = %d, want 42", pid)
+	}
+	if _, err := stdin.Write([]byte("late")); !errors.Is(err, io.ErrClosedPipe) {
+		t.Errorf("Write() after Close error = %v, want %v", err, io.ErrClosedPipe)
+	}
+	if _, err := handle.Wait(ctx); err != nil {
+		t.Errorf("Wait() error = %v", err)
+	}
+}
+
+// mockSignalHandler records the signals sent to processes.
+type mockSignalHandler struct {
+	*mockProcessHandler
+	signals chan *processpb.SendSignalRequest
+}
+
+func (h *mockSignalHandler) SendSignal(ctx context.Context, req *connect.Request[processpb.SendSignalRequest]) (*connect.Response[processpb.SendSignalResponse], error) {
+	h.signals <- req.Msg
+	return connect.NewResponse(&processpb.SendSignalResponse{}), nil
+}
+
+func TestCommandTimeoutKills(t *testing.T) {
+	ctx := context.Background()
+
+	// run starts a command that never exits and returns the signal sent to
+	// it and the Run error
+	run := func(t *testing.T, ctx context.Context, opts ...CommandOption) (*processpb.SendSignalRequest, error) {
+		t.Helper()
+		handler := &mockSignalHandler{
+			mockProcessHandler: &mockProcessHandler{
+				requests: make(chan *processpb.StartRequest, 1),
+				release:  make(chan struct{}),
+				stdout:   "partial\n",
+			},
+			signals: make(chan *processpb.SendSignalRequest, 1),
+		}
+		defer close(handler.release)
+		mux := http.NewServeMux()
+		mux.Handle(processpbconnect.NewProcessHandler(handler))
+		envd := httptest.NewServer(mux)
+		defer envd.Close()
+
+		sandbox, err := New(WithDebug(true), WithSandboxURL(envd.URL))
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		_, err = sandbox.Commands.Run(ctx, "sleep infinity", opts...)
+		select {
+		case signal := <-handler.signals:
+			return signal, err
+		default:
+			return nil, err
+		}
+	}
+
+	t.Run("command timeout", func(t *testing.T) {
+		signal, err := run(t, ctx, WithCommandTimeout(100*time.Millisecond))
+		var timeoutErr *CommandTimeoutError
+		if !errors.As(err, &timeoutErr) || !errors.Is(err, ErrTimeout) {
+			t.Fatalf("Run() error = %v, want *CommandTimeoutError", err)
+		}
+		if !timeoutErr.Killed || timeoutErr.PID != 42 || timeoutErr.Timeout != 100*time.Millisecond || timeoutErr.Stdout != "partial\n" {
+			t.Errorf("Run() error = %+v, want killed pid 42 with partial stdout", timeoutErr)
+		}
+		if signal == nil || signal.GetProcess().GetPid() != 42 || signal.GetSignal() != processpb.Signal_SIGNAL_SIGKILL {
+			t.Errorf("SendSignal request = %v, want SIGKILL for pid 42", signal)
+		}
+	})
+
+	t.Run("wait deadline", func(t *testing.T) {
+		waitCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+		signal, err := run(t, waitCtx, WithCommandTimeout(0), WithKillOnWaitCancel(true))
+		var timeoutErr *CommandTimeoutError
+		if !errors.As(err, &timeoutErr) || !timeoutErr.Killed || timeoutErr.Stdout != "partial\n" {
+			t.Errorf("Run() error = %v, want killed *CommandTimeoutError", err)
+		}
+		if signal == nil || signal.GetSignal() != processpb.Signal_SIGNAL_SIGKILL {
+			t.Errorf("SendSignal request = %v, want SIGKILL", signal)
+		}
+	})
+
+	t.Run("wait deadline without kill", func(t *testing.T) {
+		waitCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+		signal, err := run(t, waitCtx, WithCommandTimeout(0))
+		if !errors.Is(err, ErrWaitCanceled) {
+			t.Errorf("Run() error = %v, want %v", err, ErrWaitCanceled)
+		}
+		if signal != nil {
+			t.Errorf("SendSignal request = %v, want none", signal)
+		}
+	})
+}
+
+func TestCommandDetach(t *testing.T) {
+	// The process keeps running until the test ends
+	handler := &mockSignalHandler{
+		mockProcessHandler: &mockProcessHandler{
+			requests: make(chan *processpb.StartRequest, 1),
+			release:  make(chan struct{}),
+		},
+		signals: make(chan *processpb.SendSignalRequest, 1),
+	}
+	defer close(handler.release)
+	mux := http.NewServeMux()
+	mux.Handle(processpbconnect.NewProcessHandler(handler))
+	envd := httptest.NewServer(mux)
+	defer envd.Close()
+
+	sandbox, err := New(WithDebug(true), WithSandboxURL(envd.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+
+	handle, err := sandbox.Commands.RunBackground(ctx, "python -m http.server", WithCommandDetach(true))
+	if err != nil {
+		t.Fatalf("RunBackground() error = %v", err)
+	}
+	if args := (<-handler.requests).GetProcess().GetArgs(); args[len(args)-1] != "python -m http.server" {
+		t.Errorf("started args = %q, want the command last", args)
+	}
+	if handle.PID() != 42 {
+		t.Errorf("PID() = %d, want 42", handle.PID())
+	}
+
+	if _, err := handle.Wait(ctx); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("Wait() error = %v, want %v", err, ErrInvalidArgument)
+	}
+	select {
+	case <-handle.Done():
+		t.Error("Done() closed for a detached command")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if _, err := handle.KillWithContext(ctx); err != nil {
+		t.Fatalf("KillWithContext() error = %v", err)
+	}
+	if signal := <-handler.signals; signal.GetProcess().GetPid() != 42 {
+		t.Errorf("signal sent to pid %d, want 42", signal.GetProcess().GetPid())
+	}
+}
+
+func TestWithEnvdUser(t *testing.T) {
+	handler := &mockProcessHandler{requests: make(chan *processpb.StartRequest, 1)}
+	mux := http.NewServeMux()
+	mux.Handle(processpbconnect.NewProcessHandler(handler))
+	mux.HandleFunc("/files", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.URL.Query().Get("username"))
+	})
+	auth := make(chan string, 1)
+	envd := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/files" {
+			auth <- r.Header.Get("Authorization")
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	defer envd.Close()
+
+	sandbox, err := New(WithDebug(true), WithSandboxURL(envd.URL), WithEnvdUser("root"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+
+	// Basic auth carries "<user>:"
+	tests := []struct {
+		opts []CommandOption
+		want string
+	}{
+		{nil, "Basic cm9vdDo="},
+		{[]CommandOption{WithCommandUser("alice")}, "Basic YWxpY2U6"},
+	}
+	for _, tt := range tests {
+		if _, err := sandbox.Commands.Run(ctx, "id -un", tt.opts...); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		<-handler.requests
+		if got := <-auth; got != tt.want {
+			t.Errorf("Run() Authorization = %q, want %q", got, tt.want)
+		}
+	}
+
+	if got, err := sandbox.Files.Read(ctx, "/etc/hostname"); err != nil || got != "root" {
+		t.Errorf("Read() username = %q, %v, want %q", got, err, "root")
+	}
+	if got, err := sandbox.Files.Read(ctx, "/etc/hostname", WithReadUser("alice")); err != nil || got != "alice" {
+		t.Errorf("Read() with WithReadUser username = %q, %v, want %q", got, err, "alice")
+	}
+}
+
+// mockPtyHandler starts a PTY that writes output and exits.
+type mockPtyHandler struct {
+	processpbconnect.UnimplementedProcessHandler
+	output   [][]byte
+	exitCode int32
+	status   string
+}
+
+func (h *mockPtyHandler) Start(ctx context.Context, req *connect.Request[processpb.StartRequest], stream *connect.ServerStream[processpb.StartResponse]) error {
+	events := []*processpb.ProcessEvent{{Event: &processpb.ProcessEvent_Start{Start: &processpb.ProcessEvent_StartEvent{Pid: 7}}}}
+	for _, out := range h.output {
+		events = append(events, &processpb.ProcessEvent{Event: &processpb.ProcessEvent_Data{Data: &processpb.ProcessEvent_DataEvent{
+			Output: &processpb.ProcessEvent_DataEvent_Pty{Pty: out},
+		}}})
+	}
+	events = append(events, &processpb.ProcessEvent{Event: &processpb.ProcessEvent_End{End: &processpb.ProcessEvent_EndEvent{
+		ExitCode: h.exitCode, Exited: h.exitCode >= 0, Status: h.status,
+	}}})
+	for _, event := range events {
+		if err := stream.Send(&processpb.StartResponse{Event: event}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestPtyOutput(t *testing.T) {
+	output := [][]byte{[]byte("\x1b[1muser@sandbox\x1b[0m$ "), {0xff, 0xfe, '\n'}}
+	mux := http.NewServeMux()
+	mux.Handle(processpbconnect.NewProcessHandler(&mockPtyHandler{output: output}))
+	envd := httptest.NewServer(mux)
+	defer envd.Close()
+
+	sandbox, err := New(WithDebug(true), WithSandboxURL(envd.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	var data []byte
+	var stdout strings.Builder
+	handle, err := sandbox.Pty.Create(context.Background(), PtySize{Rows: 24, Cols: 80},
+		OnPtyData(func(b []byte) { data = append(data, b...) }),
+		OnPtyStdout(func(s string) { stdout.WriteString(s) }),
+	)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := handle.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+
+	want := bytes.Join(output, nil)
+	if !bytes.Equal(data, want) {
+		t.Errorf("OnPtyData received %q, want %q", data, want)
+	}
+	if stdout.String() != string(want) {
+		t.Errorf("OnPtyStdout received %q, want %q", stdout.String(), want)
+	}
+	if !bytes.Equal(handle.StdoutBytes(), want) || handle.Stdout() != string(want) {
+		t.Errorf("StdoutBytes() = %q, want %q", handle.StdoutBytes(), want)
+	}
+}
+
+// mockPtyInputHandler records the input and signals sent to a PTY.
+type mockPtyInputHandler struct {
+	*mockPtyHandler
+	inputs  chan []byte
+	signals chan processpb.Signal
+}
+
+func (h *mockPtyInputHandler) SendInput(ctx context.Context, req *connect.Request[processpb.SendInputRequest]) (*connect.Response[processpb.SendInputResponse], error) {
+	h.inputs <- req.Msg.GetInput().GetPty()
+	return connect.NewResponse(&processpb.SendInputResponse{}), nil
+}
+
+func (h *mockPtyInputHandler) SendSignal(ctx context.Context, req *connect.Request[processpb.SendSignalRequest]) (*connect.Response[processpb.SendSignalResponse], error) {
+	h.signals <- req.Msg.GetSignal()
+	return connect.NewResponse(&processpb.SendSignalResponse{}), nil
+}
+
+func TestPtyTerminal(t *testing.T) {
+	output := [][]byte{[]byte("$ "), []byte("ls\r\n"), []byte("file.txt\r\n$ ")}
+	handler := &mockPtyInputHandler{
+		mockPtyHandler: &mockPtyHandler{output: output},
+		inputs:         make(chan []byte, 1),
+		signals:        make(chan processpb.Signal, 1),
+	}
+	mux := http.NewServeMux()
+	mux.Handle(processpbconnect.NewProcessHandler(handler))
+	envd := httptest.NewServer(mux)
+	defer envd.Close()
+
+	sandbox, err := New(WithDebug(true), WithSandboxURL(envd.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	handle, err := sandbox.Pty.Create(context.Background(), PtySize{Rows: 24, Cols: 80})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	term := handle.Terminal()
+	if term != handle.Terminal() {
+		t.Error("Terminal() returned a different terminal on the second call")
+	}
+	got, err := io.ReadAll(term)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if want := bytes.Join(output, nil); !bytes.Equal(got, want) {
+		t.Errorf("Terminal output = %q, want %q", got, want)
+	}
+
+	if n, err := term.Write([]byte("exit\r")); err != nil || n != 5 {
+		t.Fatalf("Write() = %d, %v, want 5, nil", n, err)
+	}
+	if input := <-handler.inputs; string(input) != "exit\r" {
+		t.Errorf("SendInput received %q, want %q", input, "exit\r")
+	}
+
+	if err := term.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if signal := <-handler.signals; signal != processpb.Signal_SIGNAL_SIGKILL {
+		t.Errorf("SendSignal received %v, want SIGKILL", signal)
+	}
+	if _, err := term.Write([]byte("x")); !errors.Is(err, io.ErrClosedPipe) {
+		t.Errorf("Write() after Close error = %v, want %v", err, io.ErrClosedPipe)
+	}
+
+	if (&CommandHandle{}).Terminal() != nil {
+		t.Error("Terminal() on a non-PTY handle is not nil")
+	}
+}
+
+func TestPtyWaitExit(t *testing.T) {
+	handler := &mockPtyHandler{}
+	mux := http.NewServeMux()
+	mux.Handle(processpbconnect.NewProcessHandler(handler))
+	envd := httptest.NewServer(mux)
+	defer envd.Close()
+
+	sandbox, err := New(WithDebug(true), WithSandboxURL(envd.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		exitCode int32
+		status   string
+	}{
+		{0, "exit status 0"},
+		{3, "exit status 3"},
+		{-1, "signal: killed"},
+	}
+	for _, tt := range tests {
+		handler.exitCode, handler.status = tt.exitCode, tt.status
+		terminal, err := sandbox.Pty.Create(ctx, PtySize{Rows: 24, Cols: 80})
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+
+		code, err := terminal.WaitExit(ctx)
+		if err != nil || code != int(tt.exitCode) {
+			t.Errorf("WaitExit() = %d, %v, want %d", code, err, tt.exitCode)
+		}
+		if got := terminal.ExitCode(); got == nil || *got != int(tt.exitCode) {
+			t.Errorf("ExitCode() = %v, want %d", got, tt.exitCode)
+		}
+		if result, ok := terminal.Result(); !ok || result.Status != tt.status {
+			t.Errorf("Result() = %+v, %v, want status %q", result, ok, tt.status)
+		}
+		var exitErr *CommandExitError
+		if _, err := terminal.Wait(ctx); tt.exitCode != 0 && !errors.As(err, &exitErr) {
+			t.Errorf("Wait() error = %v, want *CommandExitError", err)
+		}
+	}
+
+	waitCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := (&CommandHandle{done: make(chan struct{})}).WaitExit(waitCtx); !errors.Is(err, ErrWaitCanceled) {
+		t.Errorf("WaitExit() with cancelled context error = %v, want %v", err, ErrWaitCanceled)
+	}
+}
+
+func TestCommandOnExit(t *testing.T) {
+	ctx := context.Background()
+
+	type exit struct {
+		result *CommandResult
+		err    error
+	}
+	run := func(t *testing.T, handler *mockProcessHandler) (*CommandHandle, <-chan exit, error) {
+		t.Helper()
+		handler.requests = make(chan *processpb.StartRequest, 1)
+		sandbox := newMockProcessSandbox(t, handler)
+		exits := make(chan exit, 2)
+		handle, err := sandbox.Commands.RunBackground(ctx, "server", OnCommandExit(func(result *CommandResult, err error) {
+			exits <- exit{result, err}
+		}))
+		return handle, exits, err
+	}
+	receive := func(t *testing.T, exits <-chan exit) exit {
+		t.Helper()
+		select {
+		case e := <-exits:
+			select {
+			case <-exits:
+				t.Error("exit callback called more than once")
+			case <-time.After(20 * time.Millisecond):
+			}
+			return e
+		case <-time.After(5 * time.Second):
+			t.Fatal("exit callback not called")
+			return exit{}
+		}
+	}
+
+	t.Run("end event", func(t *testing.T) {
+		handle, exits, err := run(t, &mockProcessHandler{exitCode: 3})
+		if err != nil {
+			t.Fatalf("RunBackground() error = %v", err)
+		}
+		e := receive(t, exits)
+		var exitErr *CommandExitError
+		if !errors.As(e.err, &exitErr) || exitErr.ExitCode != 3 || e.result == nil || e.result.ExitCode != 3 {
+			t.Errorf("exit callback got %+v, %v, want exit code 3", e.result, e.err)
+		}
+		select {
+		case <-handle.Done():
+		default:
+			t.Error("Done() not closed when the exit callback runs")
+		}
+	})
+
+	t.Run("stream error", func(t *testing.T) {
+		_, exits, err := run(t, &mockProcessHandler{fail: true})
+		if err != nil {
+			t.Fatalf("RunBackground() error = %v", err)
+		}
+		if e := receive(t, exits); !errors.Is(e.err, ErrCommandStream) || e.result != nil {
+			t.Errorf("exit callback got %+v, %v, want %v", e.result, e.err, ErrCommandStream)
+		}
+	})
+
+	t.Run("end before start", func(t *testing.T) {
+		_, exits, err := run(t, &mockProcessHandler{endBeforeStart: true})
+		if err != nil {
+			t.Fatalf("RunBackground() error = %v", err)
+		}
+		if e := receive(t, exits); e.err != nil || e.result == nil || e.result.Stdout != "done\n" {
+			t.Errorf("exit callback got %+v, %v, want successful result", e.result, e.err)
+		}
+
+		_, exits, err = run(t, &mockProcessHandler{endBeforeStart: true, exitCode: 1})
+		var exitErr *CommandExitError
+		if !errors.As(err, &exitErr) {
+			t.Fatalf("RunBackground() error = %v, want *CommandExitError", err)
+		}
+		if e := receive(t, exits); !errors.As(e.err, &exitErr) || e.result.ExitCode != 1 {
+			t.Errorf("exit callback got %+v, %v, want exit code 1", e.result, e.err)
+		}
+	})
+
+	t.Run("disconnect", func(t *testing.T) {
+		handler := &mockProcessHandler{release: make(chan struct{})}
+		handle, exits, err := run(t, handler)
+		if err != nil {
+			t.Fatalf("RunBackground() error = %v", err)
+		}
+		handle.Disconnect()
+		close(handler.release)
+		select {
+		case e := <-exits:
+			t.Errorf("exit callback called after Disconnect with %+v, %v", e.result, e.err)
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+}
+
+func TestContextFields(t *testing.T) {
+	jupyter := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			json.NewEncoder(w).Encode(map[string]string{"id": "ctx-1", "language": "python", "cwd": "/home/user"})
+			return
+		}
+		json.NewEncoder(w).Encode([]map[string]string{
+			{"id": "ctx-1", "language": "python", "cwd": "/home/user", "created_at": "2026-01-02T03:04:05Z"},
+			{"id": "ctx-2", "language": "javascript", "cwd": "/tmp"},
+		})
+	}))
+	defer jupyter.Close()
+
+	sandbox, err := New(WithDebug(true))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	sandbox.httpClient = newTestHTTPClient(jupyter.URL, "")
+
+	ctx := context.Background()
+	before := time.Now()
+	created, err := sandbox.CreateContext(ctx, WithContextLanguage("python"))
+	if err != nil {
+		t.Fatalf("CreateContext() error = %v", err)
+	}
+	if created.Language != "python" || created.CWD != "/home/user" {
+		t.Errorf("CreateContext() = %+v, want python context in /home/user", created)
+	}
+	if created.CreatedAt.Before(before) {
+		t.Errorf("CreatedAt = %v, want client creation time", created.CreatedAt)
+	}
+
+	contexts, err := sandbox.ListContexts(ctx)
+	if err != nil {
+		t.Fatalf("ListContexts() error = %v", err)
+	}
+	if len(contexts) != 2 || contexts[1].Language != "javascript" || contexts[1].CWD != "/tmp" {
+		t.Fatalf("ListContexts() = %+v", contexts)
+	}
+	if want := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC); !contexts[0].CreatedAt.Equal(want) {
+		t.Errorf("CreatedAt = %v, want %v", contexts[0].CreatedAt, want)
+	}
+	if !contexts[1].CreatedAt.IsZero() {
+		t.Errorf("CreatedAt = %v, want zero when not reported", contexts[1].CreatedAt)
+	}
+}
+
+func TestContextMethods(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	var executed []executeRequest
+	jupyter := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch {
+		case r.URL.Path == "/execute":
+			var req executeRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			executed = append(executed, req)
+		case r.Method == http.MethodPost && r.URL.Path == "/contexts":
+			json.NewEncoder(w).Encode(map[string]string{"id": "ctx-1", "language": "python"})
+		case r.Method == http.MethodGet:
+			json.NewEncoder(w).Encode([]map[string]string{{"id": "ctx-2", "language": "javascript"}})
+		}
+	}))
+	defer jupyter.Close()
+
+	sandbox, err := New(WithDebug(true))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	sandbox.httpClient = newTestHTTPClient(jupyter.URL, "")
+	ctx := context.Background()
+
+	pyCtx, err := sandbox.CreateContext(ctx, WithContextLanguage(LanguagePython))
+	if err != nil {
+		t.Fatalf("CreateContext() error = %v", err)
+	}
+	if _, err := pyCtx.RunCode(ctx, "x = 1"); err != nil {
+		t.Fatalf("RunCode() error = %v", err)
+	}
+	// Spare capacity in opts must not be written to
+	opts := make([]RunOption, 2, 4)
+	opts[0], opts[1] = WithLanguage(LanguagePython), WithContext(pyCtx)
+	if _, err := pyCtx.RunCode(ctx, "x", opts...); err != nil {
+		t.Fatalf("RunCode() with matching options error = %v", err)
+	}
+	if spare := opts[:4]; spare[2] != nil || spare[3] != nil {
+		t.Error("RunCode() wrote to the backing array of opts")
+	}
+	if err := pyCtx.Restart(ctx); err != nil {
+		t.Fatalf("Restart() error = %v", err)
+	}
+
+	contexts, err := sandbox.ListContexts(ctx)
+	if err != nil {
+		t.Fatalf("ListContexts() error = %v", err)
+	}
+	if err := contexts[0].Remove(ctx); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+
+	for _, opt := range []RunOption{WithLanguage(LanguageJavaScript), WithContext(contexts[0])} {
+		if _, err := pyCtx.RunCode(ctx, "x", opt); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("RunCode() with conflicting option error = %v, want %v", err, ErrInvalidArgument)
+		}
+	}
+	unbound := &Context{ID: "ctx-3", Language: LanguagePython}
+	if _, err := unbound.RunCode(ctx, "x"); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("RunCode() on unbound context error = %v, want %v", err, ErrInvalidArgument)
+	}
+	if err := unbound.Remove(ctx); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("Remove() on unbound context error = %v, want %v", err, ErrInvalidArgument)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	wantExecuted := []executeRequest{{Code: "x = 1", ContextID: "ctx-1"}, {Code: "x", ContextID: "ctx-1"}}
+	if !reflect.DeepEqual(executed, wantExecuted) {
+		t.Errorf("executed = %+v, want %+v", executed, wantExecuted)
+	}
+	wantRequests := []string{"POST /contexts", "POST /execute", "POST /execute", "POST /contexts/ctx-1/restart", "GET /contexts", "DELETE /contexts/ctx-2"}
+	if !reflect.DeepEqual(requests, wantRequests) {
+		t.Errorf("requests = %q, want %q", requests, wantRequests)
+	}
+}
+
+func TestSplitLines(t *testing.T) {
+	tests := []struct {
+		text      string
+		skipEmpty bool
+		want      []string
+	}{
+		{"", false, []string{}},
+		{"a\nb\n", false, []string{"a", "b"}},
+		{"a\nb", false, []string{"a", "b"}},
+		{"a\r\nb\r\n", false, []string{"a", "b"}},
+		{"a\n\nb\n", false, []string{"a", "", "b"}},
+		{"a\n\n  \nb\n", true, []string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		got := splitLines(tt.text, tt.skipEmpty)
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") || len(got) != len(tt.want) {
+			t.Errorf("splitLines(%q, %v) = %q, want %q", tt.text, tt.skipEmpty, got, tt.want)
+		}
+	}
+
+	if got := joinLines([]string{"a", "b"}, "\r\n"); got != "a\r\nb\r\n" {
+		t.Errorf("joinLines() = %q, want %q", got, "a\r\nb\r\n")
+	}
+	if got := splitLines(joinLines([]string{"x", "", "y"}, "\n"), false); len(got) != 3 {
+		t.Errorf("round trip = %q, want 3 lines", got)
+	}
+}
+
+func TestUnixMode(t *testing.T) {
+	tests := []struct {
+		mode os.FileMode
+		want uint32
+	}{
+		{0o755, 0o755},
+		{0o644 | os.ModeDir, 0o644},
+		{0o755 | os.ModeSetuid, 0o4755},
+		{0o1777 | os.ModeSticky | os.ModeSetgid, 0o3777},
+	}
+	for _, tt := range tests {
+		if got := unixMode(tt.mode); got != tt.want {
+			t.Errorf("unixMode(%v) = %o, want %o", tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestFilesTruncate(t *testing.T) {
+	handler := &mockProcessHandler{requests: make(chan *processpb.StartRequest, 1)}
+	sandbox := newMockProcessSandbox(t, handler)
+	ctx := context.Background()
+
+	if err := sandbox.Files.Truncate(ctx, "/home/user/app.log", 1024); err != nil {
+		t.Fatalf("Truncate() error = %v", err)
+	}
+	script := strings.Join((<-handler.requests).GetProcess().GetArgs(), " ")
+	for _, want := range []string{"[ -e '/home/user' ]", "[ ! -d '/home/user/app.log' ]", "truncate -s 1024 -- '/home/user/app.log'"} {
+		if !strings.Contains(script, want) {
+			t.Errorf("Truncate() script = %q, want it to contain %q", script, want)
+		}
+	}
+
+	if err := sandbox.Files.Truncate(ctx, "/home/user/app.log", -1); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("Truncate() with negative size error = %v, want %v", err, ErrInvalidArgument)
+	}
+
+	handler.exitCode = shellExitNotFound
+	if err := sandbox.Files.Truncate(ctx, "/missing/app.log", 0); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Truncate() in missing directory error = %v, want %v", err, ErrNotFound)
+	}
+	<-handler.requests
+}
+
+// mockWatchHandler serves WatchDir, sending a fixed list of events after
+// the start event and keeping the stream open until it is cancelled.
+type mockWatchHandler struct {
+	filesystempbconnect.UnimplementedFilesystemHandler
+
+	requests chan *filesystempb.WatchDirRequest
+	events   []*filesystempb.FilesystemEvent
+}
+
+func (h *mockWatchHandler) WatchDir(ctx context.Context, req *connect.Request[filesystempb.WatchDirRequest], stream *connect.ServerStream[filesystempb.WatchDirResponse]) error {
+	h.requests <- req.Msg
+	start := &filesystempb.WatchDirResponse{Event: &filesystempb.WatchDirResponse_Start{Start: &filesystempb.WatchDirResponse_StartEvent{}}}
+	if err := stream.Send(start); err != nil {
+		return err
+	}
+	for _, event := range h.events {
+		if err := stream.Send(&filesystempb.WatchDirResponse{Event: &filesystempb.WatchDirResponse_Filesystem{Filesystem: event}}); err != nil {
+			return err
+		}
+	}
+	<-ctx.Done()
+	return nil
+}
+
+func TestFilesWatchFile(t *testing.T) {
+	handler := &mockWatchHandler{
+		requests: make(chan *filesystempb.WatchDirRequest, 2),
+		events: []*filesystempb.FilesystemEvent{
+			{Name: "other.yaml", Type: filesystempb.EventType_EVENT_TYPE_WRITE},
+			{Name: "config.yaml", Type: filesystempb.EventType_EVENT_TYPE_WRITE},
+		},
+	}
+	mux := http.NewServeMux()
+	mux.Handle(filesystempbconnect.NewFilesystemHandler(handler))
+	mux.HandleFunc("/files", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("path") != "/home/user/config.yaml" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		io.WriteString(w, "v1")
+	})
+	envd := httptest.NewServer(mux)
+	defer envd.Close()
+
+	sandbox, err := New(WithDebug(true), WithSandboxURL(envd.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+
+	received := make(chan FilesystemEvent, 4)
+	handle, err := sandbox.Files.WatchFile(ctx, "/home/user/config.yaml", func(event FilesystemEvent) {
+		received <- event
+	}, WithWatchFileInitialRead(true), WithRecursive(true))
+	if err != nil {
+		t.Fatalf("WatchFile() error = %v", err)
+	}
+	defer handle.Stop()
+
+	req := <-handler.requests
+	if req.GetPath() != "/home/user" || req.GetRecursive() {
+		t.Errorf("WatchDir request = %v, want a non-recursive watch of /home/user", req)
+	}
+
+	initial := <-received
+	if initial.Name != "config.yaml" || initial.Type != EventTypeWrite || string(initial.Content) != "v1" {
+		t.Errorf("initial event = %+v, want a write of config.yaml with content v1", initial)
+	}
+	select {
+	case event := <-received:
+		if event.Name != "config.yaml" || event.Content != nil {
+			t.Errorf("event = %+v, want the write of config.yaml without content", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("WatchFile() did not report the change")
+	}
+	select {
+	case event := <-received:
+		t.Errorf("unexpected event %+v for another file", event)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if _, err := sandbox.Files.WatchFile(ctx, "/home/user/missing.yaml", nil, WithWatchFileInitialRead(true)); !errors.Is(err, ErrNotFound) {
+		t.Errorf("WatchFile() of missing file with initial read error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestFilesWatchDirDebounce(t *testing.T) {
+	write, chmod := filesystempb.EventType_EVENT_TYPE_WRITE, filesystempb.EventType_EVENT_TYPE_CHMOD
+	handler := &mockWatchHandler{
+		requests: make(chan *filesystempb.WatchDirRequest, 1),
+		events: []*filesystempb.FilesystemEvent{
+			{Name: "a.txt", Type: write},
+			{Name: "a.txt", Type: chmod},
+			{Name: "sub/b.txt", Type: write},
+			{Name: "a.txt", Type: write},
+			{Name: "sub/b.txt", Type: chmod},
+		},
+	}
+	mux := http.NewServeMux()
+	mux.Handle(filesystempbconnect.NewFilesystemHandler(handler))
+	envd := httptest.NewServer(mux)
+	defer envd.Close()
+
+	sandbox, err := New(WithDebug(true), WithSandboxURL(envd.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	// Recursive watches are checked against the envd version
+	conn := sandbox.conn.load()
+	conn.envdVersion = EnvdVersionRecursiveWatch
+	sandbox.conn.store(conn)
+
+	var mu sync.Mutex
+	var received []FilesystemEvent
+	handle, err := sandbox.Files.WatchDir(context.Background(), "/home/user", func(event FilesystemEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, event)
+	}, WithRecursive(true), WithWatchDebounce(50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("WatchDir() error = %v", err)
+	}
+	if req := <-handler.requests; !req.GetRecursive() {
+		t.Error("WatchDir() request is not recursive")
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	handle.Stop()
+
+	sort.Slice(received, func(i, j int) bool { return received[i].Name < received[j].Name })
+	want := []FilesystemEvent{{Name: "a.txt", Type: EventTypeWrite}, {Name: "sub/b.txt", Type: EventTypeChmod}}
+	if !reflect.DeepEqual(received, want) {
+		t.Errorf("debounced events = %v, want %v", received, want)
+	}
+}
+
+func TestFilesReadHeadAndLast(t *testing.T) {
+	handler := &mockProcessHandler{requests: make(chan *processpb.StartRequest, 1), stdout: "b\nc\n"}
+	sandbox := newMockProcessSandbox(t, handler)
+	ctx := context.Background()
+
+	data, err := sandbox.Files.ReadLast(ctx, "/home/user/app.log", 2, HeadTailLines)
+	if err != nil {
+		t.Fatalf("ReadLast() error = %v", err)
+	}
+	if string(data) != "b\nc\n" {
+		t.Errorf("ReadLast() = %q, want %q", data, "b\nc\n")
+	}
+	script := strings.Join((<-handler.requests).GetProcess().GetArgs(), " ")
+	if !strings.Contains(script, "tail -n 2 -- '/home/user/app.log'") {
+		t.Errorf("ReadLast() script = %q, want it to run tail -n 2", script)
+	}
+
+	if _, err := sandbox.Files.ReadHead(ctx, "/home/user/app.log", 1024, HeadTailBytes); err != nil {
+		t.Fatalf("ReadHead() error = %v", err)
+	}
+	script = strings.Join((<-handler.requests).GetProcess().GetArgs(), " ")
+	if !strings.Contains(script, "head -c 1024 -- '/home/user/app.log'") {
+		t.Errorf("ReadHead() script = %q, want it to run head -c 1024", script)
+	}
+
+	if _, err := sandbox.Files.ReadHead(ctx, "/home/user/app.log", -1, HeadTailLines); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("ReadHead() with negative count error = %v, want %v", err, ErrInvalidArgument)
+	}
+	if _, err := sandbox.Files.ReadHead(ctx, "/home/user/app.log", 1, "words"); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("ReadHead() with unknown unit error = %v, want %v", err, ErrInvalidArgument)
+	}
+
+	handler.exitCode = shellExitNotFound
+	if _, err := sandbox.Files.ReadLast(ctx, "/home/user/missing.log", 10, HeadTailLines); !errors.Is(err, ErrNotFound) {
+		t.Errorf("ReadLast() of missing file error = %v, want %v", err, ErrNotFound)
+	}
+	<-handler.requests
+}
+
+func TestFilesDiskUsage(t *testing.T) {
+	out := "16395\t/home/user/out\x008198\t/home/user/out/a b\x004096\t/home/user/out/d\x00\x00" +
+		"      2 a b\x00      1 link\x00      1 top\x00"
+	handler := &mockProcessHandler{requests: make(chan *processpb.StartRequest, 1), stdout: out}
+	sandbox := newMockProcessSandbox(t, handler)
+	ctx := context.Background()
+
+	usage, err := sandbox.Files.DiskUsage(ctx, "/home/user/out/", WithDiskUsageMaxDepth(1))
+	if err != nil {
+		t.Fatalf("DiskUsage() error = %v", err)
+	}
+	script := strings.Join((<-handler.requests).GetProcess().GetArgs(), " ")
+	if !strings.Contains(script, "du -b -0 --max-depth=1 -- '/home/user/out'") {
+		t.Errorf("DiskUsage() script = %q, want it to run du on the cleaned path", script)
+	}
+
+	want := &DiskUsageInfo{Path: "/home/user/out", TotalBytes: 16395, FileCount: 4, Subdirectories: []DiskUsageInfo{
+		{Path: "/home/user/out/a b", TotalBytes: 8198, FileCount: 2},
+		{Path: "/home/user/out/d", TotalBytes: 4096},
+	}}
+	if !reflect.DeepEqual(usage, want) {
+		t.Errorf("DiskUsage() = %+v, want %+v", usage, want)
+	}
+
+	if _, err := sandbox.Files.DiskUsage(ctx, "/home/user/out", WithDiskUsageMaxDepth(-1)); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("DiskUsage() with negative depth error = %v, want %v", err, ErrInvalidArgument)
+	}
+
+	handler.exitCode = shellExitNotFound
+	if _, err := sandbox.Files.DiskUsage(ctx, "/home/user/missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("DiskUsage() of missing path error = %v, want %v", err, ErrNotFound)
+	}
+	<-handler.requests
+}
+
+func TestFilesHardLink(t *testing.T) {
+	handler := &mockProcessHandler{requests: make(chan *processpb.StartRequest, 1)}
+	mux := http.NewServeMux()
+	mux.Handle(processpbconnect.NewProcessHandler(handler))
+	mux.Handle(filesystempbconnect.NewFilesystemHandler(&mockStatHandler{contents: map[string]string{"/home/user/b.txt": "hello"}}))
+	envd := httptest.NewServer(mux)
+	defer envd.Close()
+
+	sandbox, err := New(WithDebug(true), WithSandboxURL(envd.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		opts    []HardLinkOption
+		want    []string
+		notWant string
+	}{
+		{nil, []string{"[ -e '/home/user/a.txt' ]", "[ ! -d '/home/user/a.txt' ]", "[ ! -e '/home/user/b.txt' ]", "ln -T -- '/home/user/a.txt' '/home/user/b.txt'", "*cross-device*"}, "-f"},
+		{[]HardLinkOption{WithHardLinkForce(true)}, []string{"ln -T -f -- '/home/user/a.txt' '/home/user/b.txt'"}, "already exists"},
+	}
+	for _, tt := range tests {
+		info, err := sandbox.Files.HardLink(ctx, "/home/user/a.txt", "/home/user/b.txt", tt.opts...)
+		if err != nil {
+			t.Fatalf("HardLink() error = %v", err)
+		}
+		if info.Path != "/home/user/b.txt" || info.Size != 5 {
+			t.Errorf("HardLink() info = %+v, want the new link", info)
+		}
+		script := strings.Join((<-handler.requests).GetProcess().GetArgs(), " ")
+		for _, want := range tt.want {
+			if !strings.Contains(script, want) {
+				t.Errorf("HardLink() script = %q, want it to contain %q", script, want)
+			}
+		}
+		if strings.Contains(script, tt.notWant) {
+			t.Errorf("HardLink() script = %q, want it not to contain %q", script, tt.notWant)
+		}
+	}
+
+	handler.exitCode = shellExitExists
+	if _, err := sandbox.Files.HardLink(ctx, "/home/user/dir", "/home/user/c"); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("HardLink() of directory error = %v, want %v", err, ErrInvalidArgument)
+	}
+	<-handler.requests
+	if _, err := sandbox.Files.HardLink(ctx, "", "/home/user/c"); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("HardLink() without existing path error = %v, want %v", err, ErrInvalidArgument)
+	}
+}
+
+func TestFilesTouch(t *testing.T) {
+	handler := &mockProcessHandler{requests: make(chan *processpb.StartRequest, 1)}
+	mux := http.NewServeMux()
+	mux.Handle(processpbconnect.NewProcessHandler(handler))
+	mux.Handle(filesystempbconnect.NewFilesystemHandler(&mockStatHandler{contents: map[string]string{"/home/user/a.txt": ""}}))
+	envd := httptest.NewServer(mux)
+	defer envd.Close()
+
+	sandbox, err := New(WithDebug(true), WithSandboxURL(envd.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+
+	stamp := time.Date(2026, 3, 4, 5, 6, 7, 500_000_000, time.FixedZone("CET", 3600))
+	tests := []struct {
+		opts []TouchOption
+		want []string
+	}{
+		{nil, []string{"[ -e '/home/user' ]", "touch -- '/home/user/a.txt'"}},
+		{[]TouchOption{WithTouchTime(stamp)}, []string{"touch -d '2026-03-04 04:06:07.5 +0000' -- '/home/user/a.txt'"}},
+		{[]TouchOption{WithTouchNoCreate(true)}, []string{"[ -e '/home/user/a.txt' ]"}},
+		{[]TouchOption{WithTouchMkdirAll(true)}, []string{"mkdir -p -- '/home/user' && touch"}},
+	}
+	for _, tt := range tests {
+		info, err := sandbox.Files.Touch(ctx, "/home/user/a.txt", tt.opts...)
+		if err != nil {
+			t.Fatalf("Touch() error = %v", err)
+		}
+		if info.Path != "/home/user/a.txt" {
+			t.Errorf("Touch() info path = %q, want %q", info.Path, "/home/user/a.txt")
+		}
+		script := strings.Join((<-handler.requests).GetProcess().GetArgs(), " ")
+		for _, want := range tt.want {
+			if !strings.Contains(script, want) {
+				t.Errorf("Touch() script = %q, want it to contain %q", script, want)
+			}
+		}
+	}
+
+	handler.exitCode = shellExitNotFound
+	if _, err := sandbox.Files.Touch(ctx, "/home/user/missing.txt", WithTouchNoCreate(true)); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Touch() of missing file without create error = %v, want %v", err, ErrNotFound)
+	}
+	<-handler.requests
+}
+
+func TestFilesCopy(t *testing.T) {
+	handler := &mockProcessHandler{requests: make(chan *processpb.StartRequest, 1)}
+	mux := http.NewServeMux()
+	mux.Handle(processpbconnect.NewProcessHandler(handler))
+	mux.Handle(filesystempbconnect.NewFilesystemHandler(&mockStatHandler{contents: map[string]string{"/home/user/b.txt": ""}}))
+	envd := httptest.NewServer(mux)
+	defer envd.Close()
+
+	sandbox, err := New(WithDebug(true), WithSandboxURL(envd.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		opts []FilesCopyOption
+		want []string
+	}{
+		{nil, []string{"[ -e '/home/user/a.txt' ]", "mkdir -p -- '/home/user'", "cp -T -p -R -L -- '/home/user/a.txt' '/home/user/b.txt'"}},
+		{[]FilesCopyOption{WithFilesCopyNonRecursive(), WithFilesCopyResolveSymlinks(false)}, []string{"cp -T -p -P --"}},
+		{[]FilesCopyOption{WithFilesCopyOverwrite(false)}, []string{"[ ! -e '/home/user/b.txt' ]", "destination already exists"}},
+		{[]FilesCopyOption{WithFilesCopyMode(0o640)}, []string{"chmod 0640 -- '/home/user/b.txt'"}},
+		{[]FilesCopyOption{WithFilesCopyMode(0o755 | os.ModeSetgid)}, []string{"chmod 2755 -- '/home/user/b.txt'"}},
+	}
+	for _, tt := range tests {
+		info, err := sandbox.Files.Copy(ctx, "/home/user/a.txt", "/home/user/b.txt", tt.opts...)
+		if err != nil {
+			t.Fatalf("Copy() error = %v", err)
+		}
+		if info.Path != "/home/user/b.txt" {
+			t.Errorf("Copy() info path = %q, want %q", info.Path, "/home/user/b.txt")
+		}
+		script := strings.Join((<-handler.requests).GetProcess().GetArgs(), " ")
+		for _, want := range tt.want {
+			if !strings.Contains(script, want) {
+				t.Errorf("Copy() script = %q, want it to contain %q", script, want)
+			}
+		}
+	}
+
+	if _, err := sandbox.Files.Copy(ctx, "", "/home/user/b.txt"); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("Copy() with empty source error = %v, want %v", err, ErrInvalidArgument)
+	}
+}
+
+// mockRefusingProcessHandler fails every command with code, like an envd
+// that does not allow command execution.
+type mockRefusingProcessHandler struct {
+	processpbconnect.UnimplementedProcessHandler
+	code connect.Code
+}
+
+func (h *mockRefusingProcessHandler) Start(ctx context.Context, req *connect.Request[processpb.StartRequest], stream *connect.ServerStream[processpb.StartResponse]) error {
+	return connect.NewError(h.code, errors.New("commands are not allowed"))
+}
+
+func TestFilesCopyFallback(t *testing.T) {
+	for _, code := range []connect.Code{connect.CodeUnimplemented, connect.CodePermissionDenied} {
+		t.Run(code.String(), func(t *testing.T) {
+			var (
+				mu       sync.Mutex
+				uploaded = make(map[string]string)
+			)
+			mux := http.NewServeMux()
+			mux.Handle(processpbconnect.NewProcessHandler(&mockRefusingProcessHandler{code: code}))
+			mux.Handle(filesystempbconnect.NewFilesystemHandler(&mockStatHandler{contents: map[string]string{
+				"/home/user/a.txt": "hello", "/home/user/b.txt": "",
+			}}))
+			mux.HandleFunc("/files", func(w http.ResponseWriter, r *http.Request) {
+				p := r.URL.Query().Get("path")
+				if r.Method == http.MethodGet {
+					io.WriteString(w, "hello")
+					return
+				}
+				reader, err := r.MultipartReader()
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				part, err := reader.NextPart()
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				data, _ := io.ReadAll(part)
+				mu.Lock()
+				uploaded[p] = string(data)
+				mu.Unlock()
+				json.NewEncoder(w).Encode([]map[string]string{{"name": path.Base(p), "type": "file", "path": p}})
+			})
+			envd := httptest.NewServer(mux)
+			defer envd.Close()
+
+			sandbox, err := New(WithDebug(true), WithSandboxURL(envd.URL))
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			ctx := context.Background()
+
+			info, err := sandbox.Files.Copy(ctx, "/home/user/a.txt", "/home/user/b.txt")
+			if err != nil {
+				t.Fatalf("Copy() error = %v", err)
+			}
+			if info.Path != "/home/user/b.txt" {
+				t.Errorf("Copy() info path = %q, want %q", info.Path, "/home/user/b.txt")
+			}
+			mu.Lock()
+			got := uploaded["/home/user/b.txt"]
+			mu.Unlock()
+			if got != "hello" {
+				t.Errorf("Copy() uploaded %q, want %q", got, "hello")
+			}
+
+			if _, err := sandbox.Files.Copy(ctx, "/home/user/a.txt", "/home/user/b.txt", WithFilesCopyMode(0o600)); !errors.Is(err, ErrInvalidArgument) {
+				t.Errorf("Copy() with mode error = %v, want %v", err, ErrInvalidArgument)
+			}
+			if _, err := sandbox.Files.Copy(ctx, "/home/user/a.txt", "/home/user/b.txt", WithFilesCopyOverwrite(false)); !errors.Is(err, ErrInvalidArgument) {
+				t.Errorf("Copy() over existing file error = %v, want %v", err, ErrInvalidArgument)
+			}
+		})
+	}
+}
+
+func TestFilesSymlink(t *testing.T) {
+	handler := &mockProcessHandler{requests: make(chan *processpb.StartRequest, 1)}
+	mux := http.NewServeMux()
+	mux.Handle(processpbconnect.NewProcessHandler(handler))
+	mux.Handle(filesystempbconnect.NewFilesystemHandler(&mockStatHandler{contents: map[string]string{"/home/user/current": ""}}))
+	envd := httptest.NewServer(mux)
+	defer envd.Close()
+
+	sandbox, err := New(WithDebug(true), WithSandboxURL(envd.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		opts    []SymlinkOption
+		want    string
+		checked bool
+	}{
+		{nil, "ln -s -T -- 'releases/v2' '/home/user/current'", true},
+		{[]SymlinkOption{WithSymlinkForce(true)}, "ln -s -T -f -- 'releases/v2' '/home/user/current'", false},
+	}
+	for _, tt := range tests {
+		info, err := sandbox.Files.Symlink(ctx, "releases/v2", "/home/user/current", tt.opts...)
+		if err != nil {
+			t.Fatalf("Symlink() error = %v", err)
+		}
+		if info.SymlinkTarget == nil || *info.SymlinkTarget != "releases/v2" {
+			t.Errorf("Symlink() info target = %v, want %q", info.SymlinkTarget, "releases/v2")
+		}
+		script := strings.Join((<-handler.requests).GetProcess().GetArgs(), " ")
+		if !strings.Contains(script, tt.want) {
+			t.Errorf("Symlink() script = %q, want it to contain %q", script, tt.want)
+		}
+		if checked := strings.Contains(script, "link path already exists"); checked != tt.checked {
+			t.Errorf("Symlink() script = %q, existing link path check = %v, want %v", script, checked, tt.checked)
+		}
+	}
+
+	handler.exitCode = shellExitExists
+	if _, err := sandbox.Files.Symlink(ctx, "releases/v2", "/home/user/current"); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("Symlink() over existing path error = %v, want %v", err, ErrInvalidArgument)
+	}
+	<-handler.requests
+}
+
+func TestFilesMakeTemp(t *testing.T) {
+	handler := &mockProcessHandler{requests: make(chan *processpb.StartRequest, 1), stdout: "/tmp/build-a1b2c3d4e5\n"}
+	sandbox := newMockProcessSandbox(t, handler)
+	ctx := context.Background()
+
+	got, err := sandbox.Files.MakeTempDir(ctx, WithTempPrefix("build-"))
+	if err != nil {
+		t.Fatalf("MakeTempDir() error = %v", err)
+	}
+	if got != "/tmp/build-a1b2c3d4e5" {
+		t.Errorf("MakeTempDir() = %q, want %q", got, "/tmp/build-a1b2c3d4e5")
+	}
+	script := strings.Join((<-handler.requests).GetProcess().GetArgs(), " ")
+	if want := "mktemp -d -- '/tmp/build-XXXXXXXXXX'"; !strings.Contains(script, want) {
+		t.Errorf("MakeTempDir() script = %q, want it to contain %q", script, want)
+	}
+
+	if _, err := sandbox.Files.MakeTemp(ctx, WithTempDir("/home/user/cache")); err != nil {
+		t.Fatalf("MakeTemp() error = %v", err)
+	}
+	script = strings.Join((<-handler.requests).GetProcess().GetArgs(), " ")
+	for _, want := range []string{"[ -e '/home/user/cache' ]", "mktemp -- '/home/user/cache/tmp.XXXXXXXXXX'"} {
+		if !strings.Contains(script, want) {
+			t.Errorf("MakeTemp() script = %q, want it to contain %q", script, want)
+		}
+	}
+
+	if _, err := sandbox.Files.MakeTemp(ctx, WithTempPrefix("a/b")); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("MakeTemp() with '/' in prefix error = %v, want %v", err, ErrInvalidArgument)
+	}
+
+	for _, p := range []string{"/tmp", "/tmp/../etc/passwd", "/tmpfoo/x", "/home/user/x", "tmp/x", ""} {
+		if err := sandbox.Files.RemoveTemp(ctx, p); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("RemoveTemp(%q) error = %v, want %v", p, err, ErrInvalidArgument)
+		}
+	}
+	if err := sandbox.Files.RemoveTemp(ctx, "/tmp/x", WithTempDir("/home/user")); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("RemoveTemp() outside custom dir error = %v, want %v", err, ErrInvalidArgument)
+	}
+}
+
+func TestFilesExtract(t *testing.T) {
+	handler := &mockProcessHandler{requests: make(chan *processpb.StartRequest, 1)}
+	sandbox := newMockProcessSandbox(t, handler)
+	ctx := context.Background()
+
+	tests := []struct {
+		archive string
+		opts    []ExtractOption
+		want    []string
+	}{
+		{"/tmp/a.tar.gz", nil, []string{"tar -xzf '/tmp/a.tar.gz' -C '/home/user/out'"}},
+		{"/tmp/a.TAR.XZ", []ExtractOption{WithExtractStripComponents(1), WithExtractFilter("pkg/*")},
+			[]string{"tar -xJf", "--strip-components=1 --wildcards -- 'pkg/*'"}},
+		{"/tmp/a.zip", nil, []string{"unzip -q -o -d '/home/user/out' '/tmp/a.zip'"}},
+		{"/tmp/a.zip", []ExtractOption{WithExtractStripComponents(2)}, []string{`"$tmp"/*/*/`, "cp -a"}},
+	}
+	for _, tt := range tests {
+		if err := sandbox.Files.Extract(ctx, tt.archive, "/home/user/out", tt.opts...); err != nil {
+			t.Fatalf("Extract(%q) error = %v", tt.archive, err)
+		}
+		script := strings.Join((<-handler.requests).GetProcess().GetArgs(), " ")
+		for _, want := range append(tt.want, "mkdir -p -- '/home/user/out'") {
+			if !strings.Contains(script, want) {
+				t.Errorf("Extract(%q) script = %q, want it to contain %q", tt.archive, script, want)
+			}
+		}
+	}
+
+	for _, archive := range []string{"/tmp/a.rar", "/tmp/a.gz", ""} {
+		if err := sandbox.Files.Extract(ctx, archive, "/home/user/out"); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("Extract(%q) error = %v, want %v", archive, err, ErrInvalidArgument)
+		}
+	}
+	if err := sandbox.Files.Extract(ctx, "/tmp/a.tar", "/out", WithExtractStripComponents(-1)); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("Extract() with negative strip error = %v, want %v", err, ErrInvalidArgument)
+	}
+	for _, archive := range []string{"/tmp/a.zip", "/tmp/a.tar"} {
+		if err := sandbox.Files.Extract(ctx, archive, "/out", WithExtractFilter("-x")); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("Extract(%q) with filter starting with '-' error = %v, want %v", archive, err, ErrInvalidArgument)
+		}
+	}
+
+	handler.exitCode = shellExitNotFound
+	if err := sandbox.Files.Extract(ctx, "/tmp/missing.tar", "/out"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Extract() of missing archive error = %v, want %v", err, ErrNotFound)
+	}
+	<-handler.requests
+}
+
+func TestFilesArchive(t *testing.T) {
+	handler := &mockProcessHandler{requests: make(chan *processpb.StartRequest, 1)}
+	sandbox := newMockProcessSandbox(t, handler)
+	ctx := context.Background()
+
+	tests := []struct {
+		archive string
+		opts    []ArchiveOption
+		want    string
+	}{
+		{"/tmp/out.tar.gz", nil, "tar -czf - -C '/home/user' -- 'project'"},
+		{"/tmp/out", nil, "tar -czf -"},
+		{"/tmp/out.zip", []ArchiveOption{WithArchiveExclude("*.log")}, "cd '/home/user' && zip -q -r - 'project' -x '*.log'"},
+		{"/tmp/out.zip", []ArchiveOption{WithArchiveFormat("tar.bz2"), WithArchiveExclude("node_modules")}, "tar -cjf - -C '/home/user' --exclude='node_modules' -- 'project'"},
+	}
+	for _, tt := range tests {
+		if err := sandbox.Files.Archive(ctx, "/home/user/project/", tt.archive, tt.opts...); err != nil {
+			t.Fatalf("Archive(%q) error = %v", tt.archive, err)
+		}
+		script := strings.Join((<-handler.requests).GetProcess().GetArgs(), " ")
+		if !strings.Contains(script, tt.want) || !strings.Contains(script, "mv -f -- ") {
+			t.Errorf("Archive(%q) script = %q, want it to contain %q", tt.archive, script, tt.want)
+		}
+	}
+
+	if err := sandbox.Files.Archive(ctx, "/home/user/project", "/tmp/out.rar", WithArchiveFormat("rar")); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("Archive() with unsupported format error = %v, want %v", err, ErrInvalidArgument)
+	}
+
+	// The archive is streamed from the command's stdout
+	handler.endBeforeStart = true
+	var buf bytes.Buffer
+	if err := sandbox.Files.ArchiveAndDownload(ctx, "/home/user/project", &buf, WithArchiveFormat("zip")); err != nil {
+		t.Fatalf("ArchiveAndDownload() error = %v", err)
+	}
+	if script := strings.Join((<-handler.requests).GetProcess().GetArgs(), " "); !strings.Contains(script, "zip -q -r - 'project'") {
+		t.Errorf("ArchiveAndDownload() script = %q, want a zip written to stdout", script)
+	}
+	if buf.String() != "done\n" {
+		t.Errorf("ArchiveAndDownload() wrote %q, want the command output", buf.String())
+	}
+
+	handler.exitCode = shellExitNotFound
+	if err := sandbox.Files.ArchiveAndDownload(ctx, "/missing", io.Discard); !errors.Is(err, ErrNotFound) {
+		t.Errorf("ArchiveAndDownload() of missing path error = %v, want %v", err, ErrNotFound)
+	}
+	<-handler.requests
+}
+
+func TestFilesSearchContent(t *testing.T) {
+	handler := &mockProcessHandler{
+		requests: make(chan *processpb.StartRequest, 1),
+		stdout:   "src/a.go\x001-package a\nsrc/a.go\x002:// TODO fix\nsrc/a.go\x003-func A() {}\n--\nsrc/b.go\x009:x := 1 // todo\n",
+	}
+	sandbox := newMockProcessSandbox(t, handler)
+	ctx := context.Background()
+
+	matches, err := sandbox.Files.SearchContent(ctx, "src", "TODO", WithSearchCaseSensitive(false), WithSearchContext(1))
+	if err != nil {
+		t.Fatalf("SearchContent() error = %v", err)
+	}
+	script := strings.Join((<-handler.requests).GetProcess().GetArgs(), " ")
+	if !strings.Contains(script, "grep -r -n -H -Z -I -s -F -i -C 1 -e 'TODO' -- 'src'") {
+		t.Errorf("SearchContent() script = %q, want a case-insensitive literal grep with context", script)
+	}
+	if len(matches) != 2 {
+		t.Fatalf("SearchContent() returned %d matches, want 2", len(matches))
+	}
+	first := matches[0]
+	if first.Path != "src/a.go" || first.LineNumber != 2 || first.Line != "// TODO fix" || first.Column != 4 {
+		t.Errorf("SearchContent() first match = %+v, want src/a.go:2 at column 4", first)
+	}
+	if first.ContextStart != 1 || !reflect.DeepEqual(first.Context, []string{"package a", "// TODO fix", "func A() {}"}) {
+		t.Errorf("SearchContent() first match context = %d %q, want lines 1-3", first.ContextStart, first.Context)
+	}
+	if second := matches[1]; second.Path != "src/b.go" || second.LineNumber != 9 || second.Column != 11 {
+		t.Errorf("SearchContent() second match = %+v, want src/b.go:9 at column 11", second)
+	}
+
+	matches, err = sandbox.Files.SearchContent(ctx, "src", "TODO", WithSearchCaseSensitive(false), WithSearchMaxResults(1))
+	if err != nil {
+		t.Fatalf("SearchContent() with max results error = %v", err)
+	}
+	if script := strings.Join((<-handler.requests).GetProcess().GetArgs(), " "); !strings.Contains(script, " -m 1 ") {
+		t.Errorf("SearchContent() script = %q, want a per-file match limit", script)
+	}
+	if len(matches) != 1 || matches[0].Context != nil {
+		t.Errorf("SearchContent() with max results = %+v, want 1 match without context", matches)
+	}
+
+	handler.stdout = ""
+	handler.exitCode = 1
+	matches, err = sandbox.Files.SearchContent(ctx, "src", "f(o+", WithSearchRegex(false))
+	if err != nil || len(matches) != 0 || matches == nil {
+		t.Errorf("SearchContent() without matches = %v, %v, want an empty slice", matches, err)
+	}
+	<-handler.requests
+
+	if _, err := sandbox.Files.SearchContent(ctx, "src", "f(o+", WithSearchRegex(true)); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("SearchContent() with invalid regex error = %v, want %v", err, ErrInvalidArgument)
+	}
+
+	handler.exitCode = shellExitNotFound
+	if _, err := sandbox.Files.SearchContent(ctx, "/missing", "x"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("SearchContent() of missing path error = %v, want %v", err, ErrNotFound)
+	}
+	<-handler.requests
+}
+
+func TestFilesSearchContentKillsAtLimit(t *testing.T) {
+	// grep keeps running after printing the first matches
+	handler := &mockSignalHandler{
+		mockProcessHandler: &mockProcessHandler{
+			requests: make(chan *processpb.StartRequest, 1),
+			release:  make(chan struct{}),
+			stdout:   "src/a.go\x001:TODO one\nsrc/b.go\x002:TODO two\n",
+		},
+		signals: make(chan *processpb.SendSignalRequest, 1),
+	}
+	defer close(handler.release)
+	mux := http.NewServeMux()
+	mux.Handle(processpbconnect.NewProcessHandler(handler))
+	envd := httptest.NewServer(mux)
+	defer envd.Close()
+
+	sandbox, err := New(WithDebug(true), WithSandboxURL(envd.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	matches, err := sandbox.Files.SearchContent(context.Background(), "src", "TODO", WithSearchMaxResults(1))
+	if err != nil {
+		t.Fatalf("SearchContent() error = %v", err)
+	}
+	if len(matches) != 1 {
+		t.Errorf("SearchContent() returned %d matches, want 1", len(matches))
+	}
+	<-handler.requests
+	select {
+	case signal := <-handler.signals:
+		if signal.GetProcess().GetPid() != 42 || signal.GetSignal() != processpb.Signal_SIGNAL_SIGKILL {
+			t.Errorf("signal = %v, want SIGKILL for pid 42", signal)
+		}
+	default:
+		t.Error("SearchContent() left grep running after reaching the result limit")
+	}
+}
+
+func TestFilesFindFiles(t *testing.T) {
+	handler := &mockProcessHandler{
+		requests: make(chan *processpb.StartRequest, 1),
+		stdout: "f\tf\t5\t644\tuser\tuser\t1700000000.5000000000\t/src/b/x.go\x00\x00" +
+			"l\tf\t6\t777\tuser\tuser\t1700000000\t/src/link.go\x00b/x.go\x00" +
+			"d\td\t4096\t755\troot\troot\t1700000000\t/src/dir.go\x00\x00",
+	}
+	sandbox := newMockProcessSandbox(t, handler)
+	ctx := context.Background()
+
+	newer := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	entries, err := sandbox.Files.FindFiles(ctx, "/src/", "*.go",
+		WithFindType(FileTypeFile), WithFindMinSize(3), WithFindMaxSize(10), WithFindNewerThan(newer), WithFindMaxDepth(2))
+	if err != nil {
+		t.Fatalf("FindFiles() error = %v", err)
+	}
+	script := strings.Join((<-handler.requests).GetProcess().GetArgs(), " ")
+	for _, want := range []string{
+		"[ -e '/src' ]", "find '/src' -mindepth 1 -maxdepth 2 -name '*.go' -xtype f -size +2c -size -11c",
+		"-newermt '2026-03-04 05:06:07 +0000'",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("FindFiles() script = %q, want it to contain %q", script, want)
+		}
+	}
+
+	if len(entries) != 3 {
+		t.Fatalf("FindFiles() returned %d entries, want 3", len(entries))
+	}
+	file, link, dir := entries[0], entries[2], entries[1]
+	if file.Path != "/src/b/x.go" || file.Name != "x.go" || file.Type != FileTypeFile || file.Size != 5 ||
+		file.Permissions != "-rw-r--r--" || file.Owner != "user" || !file.ModifiedTime.Equal(time.Unix(1700000000, 500_000_000)) {
+		t.Errorf("FindFiles() file = %+v", file)
+	}
+	if link.SymlinkTarget == nil || *link.SymlinkTarget != "b/x.go" || os.FileMode(link.Mode)&os.ModeSymlink == 0 {
+		t.Errorf("FindFiles() link = %+v, want a symlink to b/x.go", link)
+	}
+	if dir.Type != FileTypeDir || dir.Size != 0 || dir.SymlinkTarget != nil {
+		t.Errorf("FindFiles() dir = %+v, want a directory", dir)
+	}
+
+	// Patterns with "/" are matched against the path relative to the root
+	entries, err = sandbox.Files.FindFiles(ctx, "/src", "b/**/*.go", WithFindExtendedGlob(true))
+	if err != nil {
+		t.Fatalf("FindFiles() with extended glob error = %v", err)
+	}
+	if script := strings.Join((<-handler.requests).GetProcess().GetArgs(), " "); strings.Contains(script, "-name") {
+		t.Errorf("FindFiles() with extended glob script = %q, want no -name test", script)
+	}
+	if len(entries) != 1 || entries[0].Path != "/src/b/x.go" {
+		t.Errorf("FindFiles() with extended glob = %v, want /src/b/x.go", entries)
+	}
+
+	for _, pattern := range []string{"**/*.go", "b/*.go", "[", ""} {
+		if _, err := sandbox.Files.FindFiles(ctx, "/src", pattern); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("FindFiles(%q) error = %v, want %v", pattern, err, ErrInvalidArgument)
+		}
+	}
+	if _, err := sandbox.Files.FindFiles(ctx, "/src", "*", WithFindMinSize(10), WithFindMaxSize(5)); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("FindFiles() with an empty size range error = %v, want %v", err, ErrInvalidArgument)
+	}
+
+	handler.stdout = ""
+	handler.exitCode = shellExitNotFound
+	if _, err := sandbox.Files.FindFiles(ctx, "/missing", "*"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("FindFiles() of missing root error = %v, want %v", err, ErrNotFound)
+	}
+	<-handler.requests
+}
+
+func TestParseChecksumOutput(t *testing.T) {
+	const sha256Empty = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+	sum, err := parseChecksumOutput(sha256Empty+"  -\n", "sha256")
+	if err != nil || sum != sha256Empty {
+		t.Errorf("parseChecksumOutput() = %q, %v, want %q", sum, err, sha256Empty)
+	}
+	sum, err = parseChecksumOutput("D41D8CD98F00B204E9800998ECF8427E  -\n", "md5")
+	if err != nil || sum != "d41d8cd98f00b204e9800998ecf8427e" {
+		t.Errorf("parseChecksumOutput() = %q, %v, want lowercase md5", sum, err)
+	}
+
+	for _, output := range []string{"", sha256Empty[:40] + "  -", strings.Repeat("z", 64) + "  -"} {
+		if _, err := parseChecksumOutput(output, "sha256"); err == nil {
+			t.Errorf("parseChecksumOutput(%q) should fail", output)
+		}
+	}
+
+	sandbox, err := New(WithDebug(true))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	_, err = sandbox.Files.GetChecksum(context.Background(), "/home/user/a", WithChecksumAlgorithm("crc32"))
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("GetChecksum() with unsupported algorithm error = %v, want ErrInvalidArgument", err)
+	}
+}
+
+func TestFilesUploadDir(t *testing.T) {
+	var uploaded []string
+	envd := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reader, err := r.MultipartReader()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var infos []map[string]string
+		for {
+			part, err := reader.NextPart()
+			if err != nil {
+				break
+			}
+			// FileName() strips directories, so read the raw parameter
+			_, params, _ := mime.ParseMediaType(part.Header.Get("Content-Disposition"))
+			uploaded = append(uploaded, params["filename"])
+			infos = append(infos, map[string]string{"name": path.Base(params["filename"]), "type": "file", "path": params["filename"]})
+		}
+		json.NewEncoder(w).Encode(infos)
+	}))
+	defer envd.Close()
+
+	dir := t.TempDir()
+	for _, name := range []string{"main.py", "pkg/util.py", "pkg/util.pyc", ".git/HEAD", "build/out/a.tmp", "build/out/a.txt"} {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(p), 0o755)
+		os.WriteFile(p, []byte(name), 0o644)
+	}
+	os.Symlink(filepath.Join(dir, "main.py"), filepath.Join(dir, "link.py"))
+
+	sandbox, err := New(WithDebug(true), WithSandboxURL(envd.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	infos, err := sandbox.Files.UploadDir(context.Background(), dir, "/home/user/app",
+		WithUploadDirIgnorePatterns(".git", "*.pyc", "build/**/*.tmp"))
+	if err != nil {
+		t.Fatalf("UploadDir() error = %v", err)
+	}
+
+	want := []string{"/home/user/app/build/out/a.txt", "/home/user/app/link.py", "/home/user/app/main.py", "/home/user/app/pkg/util.py"}
+	sort.Strings(uploaded)
+	if strings.Join(uploaded, ",") != strings.Join(want, ",") {
+		t.Errorf("uploaded %q, want %q", uploaded, want)
+	}
+	if len(infos) != len(want) {
+		t.Errorf("UploadDir() returned %d infos, want %d", len(infos), len(want))
+	}
+
+	uploaded = nil
+	if _, err := sandbox.Files.UploadDir(context.Background(), dir, "/home/user/app",
+		WithUploadDirIgnorePatterns(".git", "build", "pkg"), WithUploadDirResolveSymlinks(false)); err != nil {
+		t.Fatalf("UploadDir() error = %v", err)
+	}
+	if strings.Join(uploaded, ",") != "/home/user/app/main.py" {
+		t.Errorf("uploaded %q, want only main.py", uploaded)
+	}
+}
+
+func TestFilesWriteDir(t *testing.T) {
+	var requests [][]string
+	envd := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reader, err := r.MultipartReader()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var parts []string
+		var infos []map[string]string
+		for {
+			part, err := reader.NextPart()
+			if err != nil {
+				break
+			}
+			_, params, _ := mime.ParseMediaType(part.Header.Get("Content-Disposition"))
+			parts = append(parts, params["filename"])
+			infos = append(infos, map[string]string{"name": path.Base(params["filename"]), "type": "file", "path": params["filename"]})
+		}
+		requests = append(requests, parts)
+		for _, p := range parts {
+			if strings.Contains(p, "bad") {
+				http.Error(w, "disk error", http.StatusInternalServerError)
+				return
+			}
+		}
+		json.NewEncoder(w).Encode(infos)
+	}))
+	defer envd.Close()
+
+	dir := t.TempDir()
+	files := map[string]int{"a.txt": 40, "b.txt": 40, "bad.txt": 40, "big.bin": 200}
+	for name, size := range files {
+		os.WriteFile(filepath.Join(dir, name), bytes.Repeat([]byte("x"), size), 0o644)
+	}
+
+	sandbox, err := New(WithDebug(true), WithSandboxURL(envd.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	infos, err := sandbox.Files.WriteDir(context.Background(), dir, "/home/user/data", WithUploadDirBatchSize(100))
+	var uploadErr *UploadDirError
+	if !errors.As(err, &uploadErr) {
+		t.Fatalf("WriteDir() error = %v, want *UploadDirError", err)
+	}
+	if len(uploadErr.Failed) != 1 || uploadErr.Failed["/home/user/data/bad.txt"] == nil {
+		t.Errorf("Failed = %v, want bad.txt", uploadErr.Failed)
+	}
+	if !strings.Contains(err.Error(), "bad.txt") {
+		t.Errorf("error %q does not name the failed file", err)
+	}
+
+	// a.txt and b.txt together, bad.txt alone, big.bin streamed on its own
+	if len(requests) != 3 {
+		t.Errorf("made %d requests %q, want 3", len(requests), requests)
+	}
+	var uploaded []string
+	for _, info := range infos {
+		uploaded = append(uploaded, info.Path)
+	}
+	sort.Strings(uploaded)
+	if want := "/home/user/data/a.txt,/home/user/data/b.txt,/home/user/data/big.bin"; strings.Join(uploaded, ",") != want {
+		t.Errorf("uploaded %q, want %q", uploaded, want)
+	}
+}
+
+func TestChownValidation(t *testing.T) {
+	for name, want := range map[string]bool{
+		"root": true, "1000": true, "www-data": true, "svc_user.1": true,
+		"": false, "-R": false, "a b": false, "$(id)": false, "a;b": false, "a:b": false,
+	} {
+		if got := isValidAccountName(name); got != want {
+			t.Errorf("isValidAccountName(%q) = %v, want %v", name, got, want)
+		}
+	}
+
+	sandbox, err := New(WithDebug(true))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+	if err := sandbox.Files.Chown(ctx, "/tmp/x", "root; rm -rf /", ""); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("Chown() unsafe user error = %v, want %v", err, ErrInvalidArgument)
+	}
+	if err := sandbox.Files.Chown(ctx, "/tmp/x", "", ""); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("Chown() empty owner and group error = %v, want %v", err, ErrInvalidArgument)
+	}
+}
+
+// mockFilesystemHandler serves Stat and ListDir from a fixed set of entries.
+type mockFilesystemHandler struct {
+	filesystempbconnect.UnimplementedFilesystemHandler
+
+	entries []*filesystempb.EntryInfo
+}
+
+func (h *mockFilesystemHandler) Stat(ctx context.Context, req *connect.Request[filesystempb.StatRequest]) (*connect.Response[filesystempb.StatResponse], error) {
+	if req.Msg.GetPath() == "/home/user/out" {
+		return connect.NewResponse(&filesystempb.StatResponse{Entry: &filesystempb.EntryInfo{
+			Name: "out", Type: filesystempb.FileType_FILE_TYPE_DIRECTORY, Path: "/home/user/out",
+		}}), nil
+	}
+	return nil, connect.NewError(connect.CodeNotFound, errors.New("not found"))
+}
+
+func (h *mockFilesystemHandler) ListDir(ctx context.Context, req *connect.Request[filesystempb.ListDirRequest]) (*connect.Response[filesystempb.ListDirResponse], error) {
+	return connect.NewResponse(&filesystempb.ListDirResponse{Entries: h.entries}), nil
+}
+
+func TestFilesDownloadDir(t *testing.T) {
+	file := func(p string, size int64, mode uint32) *filesystempb.EntryInfo {
+		return &filesystempb.EntryInfo{Name: path.Base(p), Type: filesystempb.FileType_FILE_TYPE_FILE, Path: p, Size: size, Mode: mode}
+	}
+	handler := &mockFilesystemHandler{entries: []*filesystempb.EntryInfo{
+		file("/home/user/out/a.txt", 5, 0o600),
+		{Name: "sub", Type: filesystempb.FileType_FILE_TYPE_DIRECTORY, Path: "/home/user/out/sub", Mode: 0o755},
+		file("/home/user/out/sub/b.png", 3, 0o644),
+		file("/home/user/out/../escape.txt", 1, 0o644),
+	}}
+	contents := map[string]string{"/home/user/out/a.txt": "hello", "/home/user/out/sub/b.png": "png", "/home/user/out/slow.bin": ""}
+
+	mux := http.NewServeMux()
+	mux.Handle(filesystempbconnect.NewFilesystemHandler(handler))
+	mux.HandleFunc("/files", func(w http.ResponseWriter, r *http.Request) {
+		p := r.URL.Query().Get("path")
+		content, ok := contents[p]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if p == "/home/user/out/slow.bin" {
+			<-r.Context().Done()
+			return
+		}
+		io.WriteString(w, content)
+	})
+	envd := httptest.NewServer(mux)
+	defer envd.Close()
+
+	sandbox, err := New(WithDebug(true), WithSandboxURL(envd.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+
+	t.Run("all files", func(t *testing.T) {
+		dir := t.TempDir()
+		var progress atomic.Int64
+		result, err := sandbox.Files.DownloadDir(ctx, "/home/user/out", dir,
+			WithDownloadDirProgress(func(p string, written, total int64) { progress.Add(written) }))
+		if err != nil {
+			t.Fatalf("DownloadDir() error = %v", err)
+		}
+		if result.Files != 2 || result.Bytes != 8 {
+			t.Errorf("result = %+v, want 2 files and 8 bytes", result)
+		}
+		if len(result.Skipped) != 1 {
+			t.Errorf("Skipped = %q, want the entry outside the directory", result.Skipped)
+		}
+		if data, _ := os.ReadFile(filepath.Join(dir, "sub", "b.png")); string(data) != "png" {
+			t.Errorf("sub/b.png = %q, want png", data)
+		}
+		if info, err := os.Stat(filepath.Join(dir, "a.txt")); err != nil || info.Mode().Perm() != 0o600 {
+			t.Errorf("a.txt mode = %v, %v, want 0600", info.Mode().Perm(), err)
+		}
+		if progress.Load() == 0 {
+			t.Error("progress callback not called")
+		}
+	})
+
+	t.Run("glob", func(t *testing.T) {
+		dir := t.TempDir()
+		result, err := sandbox.Files.DownloadDir(ctx, "/home/user/out", dir, WithDownloadDirGlob("**/*.png"))
+		if err != nil {
+			t.Fatalf("DownloadDir() error = %v", err)
+		}
+		if result.Files != 1 {
+			t.Errorf("Files = %d, want 1", result.Files)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "a.txt")); !os.IsNotExist(err) {
+			t.Errorf("a.txt downloaded despite glob filter")
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		handler.entries = append(handler.entries, file("/home/user/out/slow.bin", 1, 0o644))
+		defer func() { handler.entries = handler.entries[:len(handler.entries)-1] }()
+
+		cctx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+		defer cancel()
+		_, err := sandbox.Files.DownloadDir(cctx, "/home/user/out", t.TempDir(), WithDownloadDirConcurrency(1))
+		var dirErr *DownloadDirError
+		if !errors.As(err, &dirErr) {
+			t.Fatalf("DownloadDir() error = %v, want *DownloadDirError", err)
+		}
+		if len(dirErr.Completed) != 1 || dirErr.Completed[0] != "/home/user/out/a.txt" {
+			t.Errorf("Completed = %q, want [/home/user/out/a.txt]", dirErr.Completed)
+		}
+	})
+}
+
+// mockTreeHandler serves Stat and ListDir for a fixed directory tree.
+type mockTreeHandler struct {
+	filesystempbconnect.UnimplementedFilesystemHandler
+
+	dirs     map[string][]*filesystempb.EntryInfo
+	failures map[string]bool
+	listed   atomic.Int32
+}
+
+func (h *mockTreeHandler) Stat(ctx context.Context, req *connect.Request[filesystempb.StatRequest]) (*connect.Response[filesystempb.StatResponse], error) {
+	p := req.Msg.GetPath()
+	if _, ok := h.dirs[p]; ok {
+		return connect.NewResponse(&filesystempb.StatResponse{Entry: &filesystempb.EntryInfo{
+			Name: path.Base(p), Type: filesystempb.FileType_FILE_TYPE_DIRECTORY, Path: p,
+		}}), nil
+	}
+	return nil, connect.NewError(connect.CodeNotFound, errors.New("not found"))
+}
+
+func (h *mockTreeHandler) ListDir(ctx context.Context, req *connect.Request[filesystempb.ListDirRequest]) (*connect.Response[filesystempb.ListDirResponse], error) {
+	h.listed.Add(1)
+	p := req.Msg.GetPath()
+	if h.failures[p] {
+		return nil, connect.NewError(connect.CodePermissionDenied, errors.New("permission denied"))
+	}
+	return connect.NewResponse(&filesystempb.ListDirResponse{Entries: h.dirs[p]}), nil
+}
+
+func TestFilesWalkDir(t *testing.T) {
+	dir := func(p string) *filesystempb.EntryInfo {
+		return &filesystempb.EntryInfo{Name: path.Base(p), Type: filesystempb.FileType_FILE_TYPE_DIRECTORY, Path: p}
+	}
+	file := func(p string) *filesystempb.EntryInfo {
+		return &filesystempb.EntryInfo{Name: path.Base(p), Type: filesystempb.FileType_FILE_TYPE_FILE, Path: p}
+	}
+	link := dir("/w/link")
+	target := "../shared"
+	link.SymlinkTarget = &target
+	loop := dir("/shared/loop")
+	loopTarget := "/shared"
+	loop.SymlinkTarget = &loopTarget
+
+	handler := &mockTreeHandler{
+		dirs: map[string][]*filesystempb.EntryInfo{
+			"/w":        {file("/w/z.txt"), dir("/w/b"), dir("/w/a"), link, dir("/w/denied")},
+			"/w/a":      {file("/w/a/1.txt"), dir("/w/a/deep")},
+			"/w/a/deep": {file("/w/a/deep/2.txt")},
+			"/w/b":      {file("/w/b/3.txt"), file("/w/b/4.txt")},
+			"/w/denied": nil,
+			"/shared":   {file("/shared/s.txt"), loop},
+		},
+		failures: map[string]bool{"/w/denied": true},
+	}
+	mux := http.NewServeMux()
+	mux.Handle(filesystempbconnect.NewFilesystemHandler(handler))
+	envd := httptest.NewServer(mux)
+	defer envd.Close()
+
+	sandbox, err := New(WithDebug(true), WithSandboxURL(envd.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+
+	walk := func(fn func(p string, info *EntryInfo, err error) error, opts ...WalkOption) ([]string, error) {
+		var visited []string
+		err := sandbox.Files.WalkDir(ctx, "/w", func(p string, info *EntryInfo, err error) error {
+			if err != nil {
+				visited = append(visited, p+" (error)")
+			} else {
+				visited = append(visited, p)
+			}
+			if fn != nil {
+				return fn(p, info, err)
+			}
+			return nil
+		}, opts...)
+		return visited, err
+	}
+
+	t.Run("lexical order", func(t *testing.T) {
+		visited, err := walk(nil, WithWalkConcurrency(2))
+		if err != nil {
+			t.Fatalf("WalkDir() error = %v", err)
+		}
+		want := []string{"/w", "/w/a", "/w/a/1.txt", "/w/a/deep", "/w/a/deep/2.txt", "/w/b", "/w/b/3.txt", "/w/b/4.txt",
+			"/w/denied", "/w/denied (error)", "/w/link", "/w/z.txt"}
+		if fmt.Sprint(visited) != fmt.Sprint(want) {
+			t.Errorf("visited %v, want %v", visited, want)
+		}
+	})
+
+	t.Run("skip and max depth", func(t *testing.T) {
+		visited, err := walk(func(p string, info *EntryInfo, err error) error {
+			switch p {
+			case "/w/b":
+				return filepath.SkipDir
+			case "/w/denied":
+				return filepath.SkipAll
+			}
+			return nil
+		}, WithWalkMaxDepth(2))
+		if err != nil {
+			t.Fatalf("WalkDir() error = %v", err)
+		}
+		want := []string{"/w", "/w/a", "/w/a/1.txt", "/w/a/deep", "/w/b", "/w/denied"}
+		if fmt.Sprint(visited) != fmt.Sprint(want) {
+			t.Errorf("visited %v, want %v", visited, want)
+		}
+	})
+
+	t.Run("follow symlinks", func(t *testing.T) {
+		visited, err := walk(nil, WithWalkFollowSymlinks(true))
+		if err != nil {
+			t.Fatalf("WalkDir() error = %v", err)
+		}
+		joined := strings.Join(visited, ",")
+		if !strings.Contains(joined, "/w/link,/w/link/loop,/w/link/s.txt,") {
+			t.Errorf("visited %v, want link contents once", visited)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		errBoom := errors.New("boom")
+		_, err := walk(func(p string, info *EntryInfo, err error) error {
+			if strings.HasSuffix(p, ".txt") {
+				return errBoom
+			}
+			return nil
+		})
+		if err != errBoom {
+			t.Errorf("WalkDir() error = %v, want %v", err, errBoom)
+		}
+
+		visited, err := walk(func(p string, info *EntryInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if strings.HasSuffix(p, ".txt") {
+				return errBoom
+			}
+			return nil
+		}, WithWalkContinueOnError(true))
+		if !errors.Is(err, errBoom) || !strings.Contains(err.Error(), "permission denied") {
+			t.Errorf("WalkDir() error = %v, want joined errors", err)
+		}
+		if len(visited) != 12 {
+			t.Errorf("visited %d entries, want 12", len(visited))
+		}
+
+		var gotErr error
+		err = sandbox.Files.WalkDir(ctx, "/missing", func(p string, info *EntryInfo, err error) error {
+			gotErr = err
+			return err
+		})
+		if !errors.Is(err, ErrNotFound) || !errors.Is(gotErr, ErrNotFound) {
+			t.Errorf("WalkDir() on missing root error = %v", err)
+		}
+	})
+}
+
+// mockStatHandler serves Stat for the files of a content map.
+type mockStatHandler struct {
+	filesystempbconnect.UnimplementedFilesystemHandler
+
+	contents map[string]string
+}
+
+func (h *mockStatHandler) Stat(ctx context.Context, req *connect.Request[filesystempb.StatRequest]) (*connect.Response[filesystempb.StatResponse], error) {
+	p := req.Msg.GetPath()
+	content, ok := h.contents[p]
+	if !ok {
+		return nil, connect.NewError(connect.CodeNotFound, errors.New("not found"))
+	}
+	return connect.NewResponse(&filesystempb.StatResponse{Entry: &filesystempb.EntryInfo{
+		Name: path.Base(p), Type: filesystempb.FileType_FILE_TYPE_FILE, Path: p, Size: int64(len(content)),
+	}}), nil
+}
+
+func TestFilesGzip(t *testing.T) {
+	contents := map[string]string{"/home/user/plain.txt": "not compressed"}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/files", func(w http.ResponseWriter, r *http.Request) {
+		p := r.URL.Query().Get("path")
+		if r.Method == http.MethodGet {
+			content, ok := contents[p]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			io.WriteString(w, content)
+			return
+		}
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		data, _ := io.ReadAll(file)
+		contents[p] = string(data)
+		json.NewEncoder(w).Encode([]map[string]string{{"name": path.Base(p), "type": "file", "path": p}})
+	})
+	envd := httptest.NewServer(mux)
+	defer envd.Close()
+
+	sandbox, err := New(WithDebug(true), WithSandboxURL(envd.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+
+	text := strings.Repeat("compressible output\n", 1000)
+	tests := []struct {
+		path     string
+		data     any
+		wantPath string
+	}{
+		{"/home/user/out.log", text, "/home/user/out.log.gz"},
+		{"/home/user/data.gz", []byte(text), "/home/user/data.gz"},
+		{"/home/user/stream.txt", strings.NewReader(text), "/home/user/stream.txt.gz"},
+	}
+	for _, tt := range tests {
+		info, err := sandbox.Files.WriteGzip(ctx, tt.path, tt.data, WithGzipLevel(gzip.BestSpeed))
+		if err != nil {
+			t.Fatalf("WriteGzip(%q) error = %v", tt.path, err)
+		}
+		if info.Path != tt.wantPath {
+			t.Errorf("WriteGzip(%q) path = %q, want %q", tt.path, info.Path, tt.wantPath)
+		}
+		if len(contents[tt.wantPath]) >= len(text) {
+			t.Errorf("WriteGzip(%q) stored %d bytes, want fewer than %d", tt.path, len(contents[tt.wantPath]), len(text))
+		}
+
+		got, err := sandbox.Files.ReadGzip(ctx, tt.wantPath)
+		if err != nil {
+			t.Fatalf("ReadGzip(%q) error = %v", tt.wantPath, err)
+		}
+		if string(got) != text {
+			t.Errorf("ReadGzip(%q) returned %d bytes, want the written %d bytes", tt.wantPath, len(got), len(text))
+		}
+	}
+
+	if _, err := sandbox.Files.WriteGzip(ctx, "/home/user/a", "x", WithGzipLevel(42)); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("WriteGzip() with invalid level error = %v, want %v", err, ErrInvalidArgument)
+	}
+	if _, err := sandbox.Files.ReadGzip(ctx, "/home/user/plain.txt"); err == nil || !strings.Contains(err.Error(), "not valid gzip") {
+		t.Errorf("ReadGzip() of plain file error = %v, want a not valid gzip error", err)
+	}
+	if _, err := sandbox.Files.ReadGzip(ctx, "/home/user/missing.gz"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("ReadGzip() of missing file error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+// mockRemoveHandler records the paths removed through the filesystem RPC.
+type mockRemoveHandler struct {
+	filesystempbconnect.UnimplementedFilesystemHandler
+
+	removed chan string
+}
+
+func (h *mockRemoveHandler) Remove(ctx context.Context, req *connect.Request[filesystempb.RemoveRequest]) (*connect.Response[filesystempb.RemoveResponse], error) {
+	h.removed <- req.Msg.GetPath()
+	return connect.NewResponse(&filesystempb.RemoveResponse{}), nil
+}
+
+func TestFilesAppend(t *testing.T) {
+	process := &mockProcessHandler{requests: make(chan *processpb.StartRequest, 1)}
+	remover := &mockRemoveHandler{removed: make(chan string, 1)}
+	uploads := make(chan string, 1)
+	// beforeUpload, if set, runs before an upload is answered
+	var beforeUpload func()
+
+	mux := http.NewServeMux()
+	mux.Handle(processpbconnect.NewProcessHandler(process))
+	mux.Handle(filesystempbconnect.NewFilesystemHandler(remover))
+	mux.HandleFunc("/files", func(w http.ResponseWriter, r *http.Request) {
+		p := r.URL.Query().Get("path")
+		uploads <- p
+		if beforeUpload != nil {
+			beforeUpload()
+		}
+		json.NewEncoder(w).Encode([]map[string]string{{"name": path.Base(p), "type": "file", "path": p}})
+	})
+	envd := httptest.NewServer(mux)
+	defer envd.Close()
+
+	sandbox, err := New(WithDebug(true), WithSandboxURL(envd.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+
+	info, err := sandbox.Files.Append(ctx, "/home/user/app.log", "started\n")
+	if err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if info.Path != "/home/user/app.log" || info.Name != "app.log" {
+		t.Errorf("Append() = %+v, want the target path", info)
+	}
+	tmpPath := <-uploads
+	if !strings.HasPrefix(tmpPath, "/home/user/.app.log.append-") {
+		t.Errorf("Append() uploaded to %q, want a temporary file next to the target", tmpPath)
+	}
+	script := strings.Join((<-process.requests).GetProcess().GetArgs(), " ")
+	q := shellQuote(tmpPath)
+	for _, want := range []string{"exec 9>>'/home/user/app.log'", "flock 9", "cat " + q + " >&9", "rm -f " + q} {
+		if !strings.Contains(script, want) {
+			t.Errorf("Append() script = %q, want it to contain %q", script, want)
+		}
+	}
+	if len(remover.removed) > 0 {
+		t.Errorf("Append() removed %q after succeeding", <-remover.removed)
+	}
+
+	// The temporary file is removed when appending fails
+	process.exitCode = 1
+	if _, err := sandbox.Files.Append(ctx, "/home/user/app.log", "more\n"); err == nil {
+		t.Fatal("Append() with a failing script succeeded")
+	}
+	tmpPath = <-uploads
+	<-process.requests
+	if removed := <-remover.removed; removed != tmpPath {
+		t.Errorf("Append() removed %q, want %q", removed, tmpPath)
+	}
+
+	// ... and when ctx is cancelled once the upload is under way
+	cancelCtx, cancel := context.WithCancel(ctx)
+	beforeUpload = cancel
+	if _, err := sandbox.Files.Append(cancelCtx, "/home/user/app.log", "late\n"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Append() with cancelled context error = %v, want %v", err, context.Canceled)
+	}
+	tmpPath = <-uploads
+	if removed := <-remover.removed; removed != tmpPath {
+		t.Errorf("Append() with cancelled context removed %q, want %q", removed, tmpPath)
+	}
+}
+
+type mockMoveHandler struct {
+	filesystempbconnect.UnimplementedFilesystemHandler
+
+	moves chan *filesystempb.MoveRequest
+}
+
+func (h *mockMoveHandler) Move(ctx context.Context, req *connect.Request[filesystempb.MoveRequest]) (*connect.Response[filesystempb.MoveResponse], error) {
+	h.moves <- req.Msg
+	return connect.NewResponse(&filesystempb.MoveResponse{Entry: &filesystempb.EntryInfo{
+		Name: path.Base(req.Msg.GetDestination()), Path: req.Msg.GetDestination(),
+	}}), nil
+}
+
+func TestFilesWriteAtomic(t *testing.T) {
+	const tmpPath = "/home/user/.config.yaml.atomic-a1b2c3d4e5"
+	for _, moveImplemented := range []bool{true, false} {
+		process := &mockProcessHandler{requests: make(chan *processpb.StartRequest, 4), stdout: tmpPath + "\n"}
+		var uploaded string
+		mux := http.NewServeMux()
+		mux.Handle(processpbconnect.NewProcessHandler(process))
+		moves := &mockMoveHandler{moves: make(chan *filesystempb.MoveRequest, 1)}
+		if moveImplemented {
+			mux.Handle(filesystempbconnect.NewFilesystemHandler(moves))
+		} else {
+			mux.Handle(filesystempbconnect.NewFilesystemHandler(filesystempbconnect.UnimplementedFilesystemHandler{}))
+		}
+		mux.HandleFunc("/files", func(w http.ResponseWriter, r *http.Request) {
+			p := r.URL.Query().Get("path")
+			uploaded = p
+			json.NewEncoder(w).Encode([]map[string]string{{"name": path.Base(p), "type": "file", "path": p}})
+		})
+		envd := httptest.NewServer(mux)
+
+		sandbox, err := New(WithDebug(true), WithSandboxURL(envd.URL))
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+
+		info, err := sandbox.Files.WriteAtomic(context.Background(), "/home/user/config.yaml", "key: value")
+		envd.Close()
+		if err != nil {
+			t.Fatalf("WriteAtomic() with Move implemented %v error = %v", moveImplemented, err)
+		}
+		if info.Path != "/home/user/config.yaml" || info.Name != "config.yaml" {
+			t.Errorf("WriteAtomic() = %+v, want the target path", info)
+		}
+		if uploaded != tmpPath {
+			t.Errorf("WriteAtomic() uploaded to %q, want %q", uploaded, tmpPath)
+		}
+
+		var scripts []string
+		for len(process.requests) > 0 {
+			scripts = append(scripts, strings.Join((<-process.requests).GetProcess().GetArgs(), " "))
+		}
+		want := []string{"mktemp -- '/home/user/.config.yaml.atomic-XXXXXXXXXX'", "chmod --reference='/home/user/config.yaml'"}
+		if moveImplemented {
+			if req := <-moves.moves; req.GetSource() != tmpPath || req.GetDestination() != "/home/user/config.yaml" {
+				t.Errorf("Move() request = %v, want %s -> /home/user/config.yaml", req, tmpPath)
+			}
+		} else {
+			want = append(want, "mv -f -- '"+tmpPath+"' '/home/user/config.yaml'")
+		}
+		if len(scripts) != len(want) {
+			t.Fatalf("WriteAtomic() ran %d scripts %q, want %d", len(scripts), scripts, len(want))
+		}
+		for i := range want {
+			if !strings.Contains(scripts[i], want[i]) {
+				t.Errorf("WriteAtomic() script %d = %q, want it to contain %q", i, scripts[i], want[i])
+			}
+		}
+	}
+}
+
+func TestFilesWriteIfChanged(t *testing.T) {
+	handler := &mockStatHandler{contents: map[string]string{"/home/user/a.txt": "hello"}}
+	var uploads atomic.Int32
+
+	mux := http.NewServeMux()
+	mux.Handle(filesystempbconnect.NewFilesystemHandler(handler))
+	mux.HandleFunc("/files", func(w http.ResponseWriter, r *http.Request) {
+		p := r.URL.Query().Get("path")
+		if r.Method == http.MethodGet {
+			io.WriteString(w, handler.contents[p])
+			return
+		}
+		uploads.Add(1)
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		data, _ := io.ReadAll(file)
+		handler.contents[p] = string(data)
+		json.NewEncoder(w).Encode([]map[string]string{{"name": path.Base(p), "type": "file", "path": p}})
+	})
+	envd := httptest.NewServer(mux)
+	defer envd.Close()
+
+	sandbox, err := New(WithDebug(true), WithSandboxURL(envd.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+
+	// Command execution is not served, so content is compared by download
+	tests := []struct {
+		path    string
+		data    any
+		written bool
+	}{
+		{"/home/user/a.txt", "hello", false},
+		{"/home/user/a.txt", strings.NewReader("hello"), false},
+		{"/home/user/a.txt", []byte("world"), true},
+		{"/home/user/a.txt", "world!", true},
+		{"/home/user/new.txt", "new", true},
+	}
+	for _, tt := range tests {
+		before := uploads.Load()
+		info, written, err := sandbox.Files.WriteIfChanged(ctx, tt.path, tt.data)
+		if err != nil {
+			t.Fatalf("WriteIfChanged(%q) error = %v", tt.path, err)
+		}
+		if written != tt.written || (uploads.Load() != before) != tt.written {
+			t.Errorf("WriteIfChanged(%q) written = %v, want %v", tt.path, written, tt.written)
+		}
+		sum := sha256.Sum256([]byte(handler.contents[tt.path]))
+		if info.Hash != hex.EncodeToString(sum[:]) || info.Path != tt.path {
+			t.Errorf("WriteIfChanged(%q) info = %+v", tt.path, info)
+		}
+	}
+}
+
+// mockRemoteFS is an in-memory sandbox filesystem serving the filesystem
+// RPCs, file uploads and checksum commands.
+type mockRemoteFS struct {
+	filesystempbconnect.UnimplementedFilesystemHandler
+	processpbconnect.UnimplementedProcessHandler
+
+	mu       sync.Mutex
+	files    map[string]string
+	dirs     map[string]bool
+	uploaded []string
+}
+
+func (m *mockRemoteFS) entry(p string) *filesystempb.EntryInfo {
+	if m.dirs[p] {
+		return &filesystempb.EntryInfo{Name: path.Base(p), Type: filesystempb.FileType_FILE_TYPE_DIRECTORY, Path: p}
+	}
+	if content, ok := m.files[p]; ok {
+		return &filesystempb.EntryInfo{Name: path.Base(p), Type: filesystempb.FileType_FILE_TYPE_FILE, Path: p, Size: int64(len(content))}
+	}
+	return nil
+}
+
+func (m *mockRemoteFS) Stat(ctx context.Context, req *connect.Request[filesystempb.StatRequest]) (*connect.Response[filesystempb.StatResponse], error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if entry := m.entry(req.Msg.GetPath()); entry != nil {
+		return connect.NewResponse(&filesystempb.StatResponse{Entry: entry}), nil
+	}
+	return nil, connect.NewError(connect.CodeNotFound, errors.New("not found"))
+}
+
+func (m *mockRemoteFS) ListDir(ctx context.Context, req *connect.Request[filesystempb.ListDirRequest]) (*connect.Response[filesystempb.ListDirResponse], error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	prefix := req.Msg.GetPath() + "/"
+	var entries []*filesystempb.EntryInfo
+	for p := range m.files {
+		if strings.HasPrefix(p, prefix) {
+			entries = append(entries, m.entry(p))
+		}
+	}
+	for p := range m.dirs {
+		if strings.HasPrefix(p, prefix) {
+			entries = append(entries, m.entry(p))
+		}
+	}
+	return connect.NewResponse(&filesystempb.ListDirResponse{Entries: entries}), nil
+}
+
+func (m *mockRemoteFS) MakeDir(ctx context.Context, req *connect.Request[filesystempb.MakeDirRequest]) (*connect.Response[filesystempb.MakeDirResponse], error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dirs[req.Msg.GetPath()] = true
+	return connect.NewResponse(&filesystempb.MakeDirResponse{Entry: m.entry(req.Msg.GetPath())}), nil
+}
+
+func (m *mockRemoteFS) Remove(ctx context.Context, req *connect.Request[filesystempb.RemoveRequest]) (*connect.Response[filesystempb.RemoveResponse], error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p := req.Msg.GetPath()
+	for f := range m.files {
+		if f == p || strings.HasPrefix(f, p+"/") {
+			delete(m.files, f)
+		}
+	}
+	for d := range m.dirs {
+		if d == p || strings.HasPrefix(d, p+"/") {
+			delete(m.dirs, d)
+		}
+	}
+	return connect.NewResponse(&filesystempb.RemoveResponse{}), nil
+}
+
+func (m *mockRemoteFS) Start(ctx context.Context, req *connect.Request[processpb.StartRequest], stream *connect.ServerStream[processpb.StartResponse]) error {
+	args := req.Msg.GetProcess().GetArgs()
+	match := regexp.MustCompile(`sha256sum < '([^']*)'`).FindStringSubmatch(args[len(args)-1])
+	if match == nil {
+		return connect.NewError(connect.CodeInvalidArgument, errors.New("unexpected command"))
+	}
+	m.mu.Lock()
+	sum := sha256.Sum256([]byte(m.files[match[1]]))
+	m.mu.Unlock()
+
+	events := []*processpb.ProcessEvent{
+		{Event: &processpb.ProcessEvent_Start{Start: &processpb.ProcessEvent_StartEvent{Pid: 7}}},
+		{Event: &processpb.ProcessEvent_Data{Data: &processpb.ProcessEvent_DataEvent{
+			Output: &processpb.ProcessEvent_DataEvent_Stdout{Stdout: []byte(hex.EncodeToString(sum[:]) + "  -\n")},
+		}}},
+		{Event: &processpb.ProcessEvent_End{End: &processpb.ProcessEvent_EndEvent{Exited: true}}},
+	}
+	for _, event := range events {
+		if err := stream.Send(&processpb.StartResponse{Event: event}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *mockRemoteFS) serveFiles(w http.ResponseWriter, r *http.Request) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var infos []map[string]string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		data, _ := io.ReadAll(part)
+		_, params, _ := mime.ParseMediaType(part.Header.Get("Content-Disposition"))
+		p := params["filename"]
+		m.mu.Lock()
+		m.files[p] = string(data)
+		m.uploaded = append(m.uploaded, p)
+		m.mu.Unlock()
+		infos = append(infos, map[string]string{"name": path.Base(p), "type": "file", "path": p})
+	}
+	json.NewEncoder(w).Encode(infos)
+}
+
+func TestFilesSyncDir(t *testing.T) {
+	remote := &mockRemoteFS{
+		files: map[string]string{
+			"/app/same.txt":       "same",
+			"/app/edit.txt":       "old!",
+			"/app/grow.txt":       "x",
+			"/app/stale.txt":      "gone",
+			"/app/old/a.txt":      "a",
+			"/app/old/b.txt":      "b",
+			"/app/cache/keep.pyc": "compiled",
+		},
+		dirs: map[string]bool{"/app": true, "/app/old": true, "/app/cache": true},
+	}
+	mux := http.NewServeMux()
+	mux.Handle(filesystempbconnect.NewFilesystemHandler(remote))
+	mux.Handle(processpbconnect.NewProcessHandler(remote))
+	mux.HandleFunc("/files", remote.serveFiles)
+	envd := httptest.NewServer(mux)
+	defer envd.Close()
+
+	sandbox, err := New(WithDebug(true), WithSandboxURL(envd.URL))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	local := t.TempDir()
+	for name, content := range map[string]string{
+		"same.txt":        "same",
+		"edit.txt":        "new!",
+		"grow.txt":        "xyz",
+		"new/deep/n.txt":  "n",
+		"debug.log":       "log",
+		"keep.log":        "keep",
+		".git/HEAD":       "ref",
+		"cache/local.pyc": "compiled",
+	} {
+		p := filepath.Join(local, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(p), 0o755)
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.MkdirAll(filepath.Join(local, "empty"), 0o755)
+
+	ctx := context.Background()
+	result, err := sandbox.Files.SyncDir(ctx, local, "/app",
+		WithSyncIgnore("# build output", ".git/", "*.log", "!keep.log", "/cache"),
+		WithSyncDelete(true),
+	)
+	if err != nil {
+		t.Fatalf("SyncDir() error = %v", err)
+	}
+
+	want := SyncResult{Added: 2, Updated: 2, Deleted: 3, Unchanged: 1}
+	if *result != want {
+		t.Errorf("SyncDir() = %+v, want %+v", *result, want)
+	}
+	sort.Strings(remote.uploaded)
+	wantUploaded := []string{"/app/edit.txt", "/app/grow.txt", "/app/keep.log", "/app/new/deep/n.txt"}
+	if fmt.Sprint(remote.uploaded) != fmt.Sprint(wantUploaded) {
+		t.Errorf("uploaded %v, want %v", remote.uploaded, wantUploaded)
+	}
+	if remote.files["/app/edit.txt"] != "new!" || !remote.dirs["/app/empty"] {
+		t.Error("remote tree does not mirror the local directory")
+	}
+	for _, p := range []string{"/app/stale.txt", "/app/old/a.txt"} {
+		if _, ok := remote.files[p]; ok {
+			t.Errorf("%s was not deleted", p)
+		}
+	}
+	if _, ok := remote.files["/app/cache/keep.pyc"]; !ok {
+		t.Error("ignored remote file was deleted")
+	}
+
+	t.Run("cancelled", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		if _, err := sandbox.Files.SyncDir(cancelled, local, "/app"); !errors.Is(err, context.Canceled) {
+			t.Errorf("SyncDir() error = %v, want %v", err, context.Canceled)
+		}
+	})
+}
+
+func TestMatchIgnoreRules(t *testing.T) {
+	rules, err := parseIgnoreRules([]string{"*.log", "!keep.log", "build/", "/root.txt", "docs/**/*.md", ""})
+	if err != nil {
+		t.Fatalf("parseIgnoreRules() error = %v", err)
+	}
+
+	tests := []struct {
+		rel   string
+		isDir bool
+		want  bool
+	}{
+		{"a.log", false, true},
+		{"sub/a.log", false, true},
+		{"keep.log", false, false},
+		{"build", true, true},
+		{"build", false, false},
+		{"sub/build/out.o", false, true},
+		{"root.txt", false, true},
+		{"sub/root.txt", false, false},
+		{"docs/a/b/c.md", false, true},
+		{"docs/c.md", false, true},
+		{"src/main.go", false, false},
+	}
+	for _, tt := range tests {
+		if got := matchIgnoreRules(rules, tt.rel, tt.isDir); got != tt.want {
+			t.Errorf("matchIgnoreRules(%q, %v) = %v, want %v", tt.rel, tt.isDir, got, tt.want)
+		}
+	}
+
+	if _, err := parseIgnoreRules([]string{"[a-"}); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("parseIgnoreRules() error = %v, want ErrInvalidArgument", err)
+	}
+}
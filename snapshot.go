@@ -46,6 +46,60 @@ func (s *Sandbox) CreateSnapshot(ctx context.Context, opts ...SnapshotCreateOpti
 	return createSnapshot(ctx, client, apiURL, apiKey, s.ID, opts...)
 }
 
+// Clone creates a new, independent sandbox from the current state of this sandbox.
+//
+// The sandbox is snapshotted with CreateSnapshot and a new sandbox is created
+// from the snapshot, so the clone has its own ID and timeout. The clone uses
+// this sandbox's configuration; opts are applied on top of it, for example to
+// set a different timeout or metadata. The snapshot is kept so that it can be
+// cloned again; remove it with DeleteSnapshot when it is no longer needed.
+//
+// In debug mode, a fresh debug sandbox is returned.
+//
+// Example:
+//
+//	// Run an expensive setup once, then fan out
+//	if _, err := sandbox.RunCode(ctx, setupCode); err != nil {
+//	    log.Fatal(err)
+//	}
+//	clone, err := sandbox.Clone(ctx, e2b.WithTimeout(10*time.Minute))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer clone.Close()
+func (s *Sandbox) Clone(ctx context.Context, opts ...Option) (*Sandbox, error) {
+	s.mu.RLock()
+	if s.closed {
+		s.mu.RUnlock()
+		return nil, ErrSandboxClosed
+	}
+	if s.readOnly {
+		s.mu.RUnlock()
+		return nil, ErrReadOnly
+	}
+	base := *s.config
+	s.mu.RUnlock()
+
+	cloneOpts := append([]Option{func(c *sandboxConfig) { *c = base }}, opts...)
+
+	if base.debug {
+		return NewWithContext(ctx, cloneOpts...)
+	}
+
+	info, err := createSnapshot(ctx, base.httpClient, base.apiURL, base.apiKey, s.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot sandbox: %w", err)
+	}
+
+	cloneOpts = append(cloneOpts, func(c *sandboxConfig) { c.template = info.SnapshotID })
+	clone, err := NewWithContext(ctx, cloneOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sandbox from snapshot %s: %w", info.SnapshotID, err)
+	}
+
+	return clone, nil
+}
+
 // CreateSnapshotStatic creates a snapshot from a sandbox by ID.
 // This is a package-level function that can be called without a sandbox instance.
 func CreateSnapshotStatic(ctx context.Context, sandboxID string, apiKey string, opts ...SnapshotCreateOption) (*SnapshotInfo, error) {
@@ -98,7 +152,7 @@ func createSnapshot(ctx context.Context, client *http.Client, apiURL, apiKey, sa
 	}
 
 	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("api error (status %d): %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp, body)
 	}
 
 	var info SnapshotInfo
@@ -146,7 +200,7 @@ func DeleteSnapshot(ctx context.Context, snapshotID string, opts ...SnapshotOpti
 
 	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("api error (status %d): %s", resp.StatusCode, string(body))
+		return newAPIError(resp, body)
 	}
 
 	return nil
@@ -241,7 +295,7 @@ func (p *SnapshotPaginator) NextItems(ctx context.Context) ([]SnapshotInfo, erro
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("api error (status %d): %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp, body)
 	}
 
 	var snapshots []SnapshotInfo
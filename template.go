@@ -9,6 +9,9 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -26,6 +29,9 @@ type TemplateBuilder struct {
 	instructions   []TemplateStep
 	contextPath    string
 	ignorePatterns []string
+
+	// copySources holds the settings of COPY steps by instruction index
+	copySources map[int]copySource
 }
 
 // NewTemplate creates a new template builder.
@@ -205,6 +211,12 @@ func (b *TemplateBuilder) RunCmd(cmd string, opts ...StepOption) *TemplateBuilde
 
 // Copy copies files into the template.
 //
+// src is a file, directory or glob pattern relative to the context path set
+// with WithBuilderContextPath. When the template is built, the matching
+// files, minus those excluded by WithBuilderIgnorePatterns, are hashed and
+// uploaded as an archive unless the build cache already has them;
+// WithCopyForceUpload uploads them regardless.
+//
 // Example:
 //
 //	template.Copy("requirements.txt", "/app/")
@@ -223,11 +235,17 @@ func (b *TemplateBuilder) Copy(src, dest string, opts ...CopyOption) *TemplateBu
 		args = append(args, fmt.Sprintf("%o", cfg.mode))
 	}
 
+	if b.copySources == nil {
+		b.copySources = make(map[int]copySource)
+	}
+	b.copySources[len(b.instructions)] = copySource{
+		resolveSymlinks: cfg.resolveSymlinks,
+		forceUpload:     cfg.forceUpload,
+	}
 	b.instructions = append(b.instructions, TemplateStep{
 		Type:  string(InstructionTypeCopy),
 		Args:  args,
 		Force: cfg.forceUpload || b.forceNextLayer,
-		// FilesHash will be computed during build
 	})
 	b.forceNextLayer = false
 	return b
@@ -375,7 +393,7 @@ func (b *TemplateBuilder) GitClone(repoURL string, dest string) *TemplateBuilder
 // toBuildSpec converts the builder to a TemplateBuildSpec for the API.
 func (b *TemplateBuilder) toBuildSpec() *TemplateBuildSpec {
 	spec := &TemplateBuildSpec{
-		Steps:    b.instructions,
+		Steps:    slices.Clone(b.instructions),
 		StartCmd: b.startCmd,
 		ReadyCmd: b.readyCmd,
 		Force:    b.force,
@@ -391,7 +409,8 @@ func (b *TemplateBuilder) toBuildSpec() *TemplateBuildSpec {
 	return spec
 }
 
-// Build deploys the template and waits for completion.
+// Build deploys the template and waits for completion. The template is
+// checked with Validate first, and nothing is sent if it is invalid.
 //
 // Example:
 //
@@ -401,6 +420,11 @@ func (b *TemplateBuilder) toBuildSpec() *TemplateBuildSpec {
 //	    }),
 //	)
 func (b *TemplateBuilder) Build(ctx context.Context, alias string, opts ...BuildOption) (*BuildInfo, error) {
+	// Catch problems in the template before calling the API
+	if err := b.Validate(opts...); err != nil {
+		return nil, err
+	}
+
 	cfg := defaultBuildConfig()
 	for _, opt := range opts {
 		opt(cfg)
@@ -413,14 +437,29 @@ func (b *TemplateBuilder) Build(ctx context.Context, alias string, opts ...Build
 	}
 	applyTemplateEnvConfig(templateCfg)
 
+	// Hash the files of COPY steps so unreadable sources fail before the
+	// API registers a build
+	spec := b.toBuildSpec()
+	stepFiles, err := b.hashCopySteps(spec)
+	if err != nil {
+		return nil, err
+	}
+
 	// Request build
 	buildInfo, err := requestBuildInternal(ctx, alias, cfg, templateCfg)
 	if err != nil {
 		return nil, err
 	}
 
-	// Trigger build with spec
-	spec := b.toBuildSpec()
+	// Do not trigger a build from a partially prepared spec after cancellation
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Upload the files of COPY steps and trigger build with spec
+	if err := b.uploadCopyFiles(ctx, buildInfo.TemplateID, spec, stepFiles, templateCfg); err != nil {
+		return nil, err
+	}
 	if err := triggerBuildInternal(ctx, buildInfo.TemplateID, buildInfo.BuildID, spec, templateCfg); err != nil {
 		return nil, err
 	}
@@ -434,12 +473,18 @@ func (b *TemplateBuilder) Build(ctx context.Context, alias string, opts ...Build
 }
 
 // BuildInBackground deploys the template without waiting for completion.
+// Like Build, it checks the template with Validate first.
 //
 // Example:
 //
 //	info, err := template.BuildInBackground(ctx, "my-template")
 //	// Later: status, err := e2b.GetBuildStatus(ctx, info.TemplateID, info.BuildID, opts...)
 func (b *TemplateBuilder) BuildInBackground(ctx context.Context, alias string, opts ...BuildOption) (*BuildInfo, error) {
+	// Catch problems in the template before calling the API
+	if err := b.Validate(opts...); err != nil {
+		return nil, err
+	}
+
 	cfg := defaultBuildConfig()
 	for _, opt := range opts {
 		opt(cfg)
@@ -452,14 +497,29 @@ func (b *TemplateBuilder) BuildInBackground(ctx context.Context, alias string, o
 	}
 	applyTemplateEnvConfig(templateCfg)
 
+	// Hash the files of COPY steps so unreadable sources fail before the
+	// API registers a build
+	spec := b.toBuildSpec()
+	stepFiles, err := b.hashCopySteps(spec)
+	if err != nil {
+		return nil, err
+	}
+
 	// Request build
 	buildInfo, err := requestBuildInternal(ctx, alias, cfg, templateCfg)
 	if err != nil {
 		return nil, err
 	}
 
-	// Trigger build with spec
-	spec := b.toBuildSpec()
+	// Do not trigger a build from a partially prepared spec after cancellation
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Upload the files of COPY steps and trigger build with spec
+	if err := b.uploadCopyFiles(ctx, buildInfo.TemplateID, spec, stepFiles, templateCfg); err != nil {
+		return nil, err
+	}
 	if err := triggerBuildInternal(ctx, buildInfo.TemplateID, buildInfo.BuildID, spec, templateCfg); err != nil {
 		return nil, err
 	}
@@ -504,6 +564,9 @@ func applyTemplateEnvConfig(cfg *templateConfig) {
 			Timeout: cfg.requestTimeout,
 		}
 	}
+	if cfg.logger != nil {
+		cfg.httpClient = loggedHTTPClient(cfg.httpClient, cfg.logger)
+	}
 }
 
 // templateConfigFromOptions creates a template config from options.
@@ -577,7 +640,7 @@ func requestBuildInternal(ctx context.Context, alias string, cfg *buildConfig, t
 	}
 
 	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return nil, fmt.Errorf("api error (status %d): %s", resp.StatusCode, string(respBody))
+		return nil, newAPIError(resp, respBody)
 	}
 
 	var buildResp templateBuildResponse
@@ -635,7 +698,7 @@ func triggerBuildInternal(ctx context.Context, templateID, buildID string, spec
 
 	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
 		respBody, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("api error (status %d): %s", resp.StatusCode, string(respBody))
+		return newAPIError(resp, respBody)
 	}
 
 	return nil
@@ -650,30 +713,46 @@ func triggerBuildInternal(ctx context.Context, templateID, buildID string, spec
 //	)
 func GetBuildStatus(ctx context.Context, templateID, buildID string, opts ...TemplateOption) (*TemplateBuildInfo, error) {
 	cfg := templateConfigFromOptions(opts)
-	return getBuildStatusInternal(ctx, templateID, buildID, 0, cfg)
+	return getBuildStatusInternal(ctx, templateID, buildID, &getBuildStatusConfig{}, cfg)
 }
 
 // GetBuildStatusWithOptions retrieves the status with additional options.
+// WithLogsLimit and WithLogsLevel are sent to the API and also applied to
+// the returned log entries, in case the API ignores them.
+//
+// Example:
+//
+//	status, err := e2b.GetBuildStatusWithOptions(ctx, templateID, buildID,
+//	    []e2b.GetBuildStatusOption{e2b.WithLogsLevel(e2b.LogLevelWarn), e2b.WithLogsLimit(50)},
+//	)
 func GetBuildStatusWithOptions(ctx context.Context, templateID, buildID string, statusOpts []GetBuildStatusOption, opts ...TemplateOption) (*TemplateBuildInfo, error) {
 	cfg := templateConfigFromOptions(opts)
 	statusCfg := defaultGetBuildStatusConfig()
 	for _, opt := range statusOpts {
 		opt(statusCfg)
 	}
-	return getBuildStatusInternal(ctx, templateID, buildID, statusCfg.logsOffset, cfg)
+	return getBuildStatusInternal(ctx, templateID, buildID, statusCfg, cfg)
 }
 
 // getBuildStatusInternal is the internal implementation of GetBuildStatus.
-func getBuildStatusInternal(ctx context.Context, templateID, buildID string, logsOffset int, cfg *templateConfig) (*TemplateBuildInfo, error) {
+func getBuildStatusInternal(ctx context.Context, templateID, buildID string, statusCfg *getBuildStatusConfig, cfg *templateConfig) (*TemplateBuildInfo, error) {
 	if cfg.apiKey == "" && cfg.accessToken == "" {
 		return nil, fmt.Errorf("%w: API key or access token is required", ErrInvalidArgument)
 	}
 
 	endpoint, _ := url.JoinPath(cfg.apiURL, "templates", templateID, "builds", buildID, "status")
-	if logsOffset > 0 {
+	params := url.Values{}
+	if statusCfg.logsOffset > 0 {
+		params.Set("logsOffset", fmt.Sprintf("%d", statusCfg.logsOffset))
+	}
+	if statusCfg.limit > 0 {
+		params.Set("limit", fmt.Sprintf("%d", statusCfg.limit))
+	}
+	if statusCfg.level != "" {
+		params.Set("level", string(statusCfg.level))
+	}
+	if len(params) > 0 {
 		parsedURL, _ := url.Parse(endpoint)
-		params := url.Values{}
-		params.Set("logsOffset", fmt.Sprintf("%d", logsOffset))
 		parsedURL.RawQuery = params.Encode()
 		endpoint = parsedURL.String()
 	}
@@ -697,7 +776,7 @@ func getBuildStatusInternal(ctx context.Context, templateID, buildID string, log
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("api error (status %d): %s", resp.StatusCode, string(respBody))
+		return nil, newAPIError(resp, respBody)
 	}
 
 	var buildInfo TemplateBuildInfo
@@ -705,10 +784,44 @@ func getBuildStatusInternal(ctx context.Context, templateID, buildID string, log
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
+	// Older APIs ignore the level and limit parameters
+	buildInfo.LogEntries = filterBuildLogs(buildInfo.LogEntries, statusCfg.level)
+	if statusCfg.limit > 0 && len(buildInfo.LogEntries) > statusCfg.limit {
+		buildInfo.LogEntries = buildInfo.LogEntries[:statusCfg.limit]
+	}
+
 	return &buildInfo, nil
 }
 
-// WaitForBuild polls until a build completes or fails.
+// logLevelRanks orders the log levels by severity.
+var logLevelRanks = map[LogLevel]int{
+	LogLevelDebug: 0,
+	LogLevelInfo:  1,
+	LogLevelWarn:  2,
+	LogLevelError: 3,
+}
+
+// filterBuildLogs returns the entries at level or above. Entries with an
+// unknown level are kept, and all entries are returned for an empty or
+// unknown level.
+func filterBuildLogs(entries []BuildLogEntry, level LogLevel) []BuildLogEntry {
+	minRank, ok := logLevelRanks[level]
+	if !ok {
+		return entries
+	}
+
+	var filtered []BuildLogEntry
+	for _, entry := range entries {
+		if rank, ok := logLevelRanks[entry.Level]; !ok || rank >= minRank {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+// WaitForBuild polls until a build completes or fails. The polling
+// interval can grow with WithBuildBackoff, and WithBuildMaxWait limits how
+// long to wait, returning a *BuildTimeoutError once it is exceeded.
 //
 // Example:
 //
@@ -734,7 +847,9 @@ func WaitForBuild(ctx context.Context, templateID, buildID string, opts ...Build
 
 // waitForBuildInternal is the internal implementation of WaitForBuild.
 func waitForBuildInternal(ctx context.Context, templateID, buildID string, cfg *buildConfig, templateCfg *templateConfig) error {
-	logsOffset := 0
+	logsOffset := cfg.logsOffset
+	interval := cfg.pollInterval
+	start := time.Now()
 
 	for {
 		select {
@@ -743,14 +858,15 @@ func waitForBuildInternal(ctx context.Context, templateID, buildID string, cfg *
 		default:
 		}
 
-		status, err := getBuildStatusInternal(ctx, templateID, buildID, logsOffset, templateCfg)
+		// The level is filtered here so the offset counts all entries
+		status, err := getBuildStatusInternal(ctx, templateID, buildID, &getBuildStatusConfig{logsOffset: logsOffset}, templateCfg)
 		if err != nil {
 			return err
 		}
 
 		// Send log entries to callback
 		if cfg.onLogs != nil {
-			for _, entry := range status.LogEntries {
+			for _, entry := range filterBuildLogs(status.LogEntries, cfg.logLevel) {
 				cfg.onLogs(entry)
 			}
 		}
@@ -771,10 +887,52 @@ func waitForBuildInternal(ctx context.Context, templateID, buildID string, cfg *
 			return fmt.Errorf("unknown build status: %s", status.Status)
 		}
 
-		time.Sleep(cfg.pollInterval)
+		wait := interval
+		if cfg.maxWait > 0 {
+			remaining := cfg.maxWait - time.Since(start)
+			if remaining <= 0 {
+				return &BuildTimeoutError{
+					TemplateID: templateID,
+					BuildID:    buildID,
+					Status:     status.Status,
+					LogsOffset: logsOffset,
+					Waited:     time.Since(start),
+				}
+			}
+			// Poll one last time when the limit is reached
+			wait = min(wait, remaining)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		if cfg.adaptivePoll && len(status.LogEntries) > 0 {
+			// Poll quickly again while logs are flowing
+			interval = cfg.pollInterval
+		} else {
+			interval = nextBuildPollInterval(interval, cfg)
+		}
 	}
 }
 
+// nextBuildPollInterval returns the interval to wait after polling the
+// build status following an interval of d, grown as set with
+// WithBuildBackoff.
+func nextBuildPollInterval(d time.Duration, cfg *buildConfig) time.Duration {
+	if cfg.backoffFactor <= 1 {
+		return d
+	}
+	next := time.Duration(float64(d) * cfg.backoffFactor)
+	if cfg.backoffMax > 0 && next > cfg.backoffMax {
+		return cfg.backoffMax
+	}
+	return next
+}
+
 // GetFileUploadLink gets a presigned URL for uploading layer files.
 //
 // Example:
@@ -784,8 +942,11 @@ func waitForBuildInternal(ctx context.Context, templateID, buildID string, cfg *
 //	    // Upload file to upload.URL
 //	}
 func GetFileUploadLink(ctx context.Context, templateID, hash string, opts ...TemplateOption) (*FileUploadInfo, error) {
-	cfg := templateConfigFromOptions(opts)
+	return getFileUploadLinkInternal(ctx, templateID, hash, templateConfigFromOptions(opts))
+}
 
+// getFileUploadLinkInternal is the internal implementation of GetFileUploadLink.
+func getFileUploadLinkInternal(ctx context.Context, templateID, hash string, cfg *templateConfig) (*FileUploadInfo, error) {
 	if cfg.apiKey == "" && cfg.accessToken == "" {
 		return nil, fmt.Errorf("%w: API key or access token is required", ErrInvalidArgument)
 	}
@@ -811,7 +972,7 @@ func GetFileUploadLink(ctx context.Context, templateID, hash string, opts ...Tem
 	}
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return nil, fmt.Errorf("api error (status %d): %s", resp.StatusCode, string(respBody))
+		return nil, newAPIError(resp, respBody)
 	}
 
 	var uploadInfo FileUploadInfo
@@ -822,6 +983,72 @@ func GetFileUploadLink(ctx context.Context, templateID, hash string, opts ...Tem
 	return &uploadInfo, nil
 }
 
+// UploadLayerFile uploads layer files to the presigned URL returned by GetFileUploadLink.
+// It does nothing if the files are already present.
+//
+// The upload is bound to ctx: cancelling it aborts an in-flight upload and
+// returns ctx.Err().
+//
+// Example:
+//
+//	upload, err := e2b.GetFileUploadLink(ctx, templateID, filesHash)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	err = e2b.UploadLayerFile(ctx, upload, archive)
+func UploadLayerFile(ctx context.Context, upload *FileUploadInfo, body io.Reader, opts ...TemplateOption) error {
+	return uploadLayerFileInternal(ctx, upload, body, templateConfigFromOptions(opts))
+}
+
+// uploadLayerFileInternal is the internal implementation of UploadLayerFile.
+func uploadLayerFileInternal(ctx context.Context, upload *FileUploadInfo, body io.Reader, cfg *templateConfig) error {
+	if upload == nil {
+		return fmt.Errorf("%w: upload info is required", ErrInvalidArgument)
+	}
+	if upload.Present {
+		return nil
+	}
+	if upload.URL == "" {
+		return fmt.Errorf("%w: upload URL is empty", ErrInvalidArgument)
+	}
+
+	// Stop reading the body as soon as ctx is done
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPut, upload.URL, &contextReader{ctx: ctx, r: body})
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := cfg.httpClient.Do(httpReq)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return fmt.Errorf("failed to upload files: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("upload error (status %d): %s", resp.StatusCode, string(respBody))
+	}
+
+	return nil
+}
+
+// contextReader is an io.Reader that fails once its context is done.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+// Read implements io.Reader.
+func (c *contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+
 // AliasExists checks if a template alias already exists.
 //
 // Example:
@@ -859,7 +1086,7 @@ func AliasExists(ctx context.Context, alias string, opts ...TemplateOption) (boo
 		return true, nil
 	default:
 		respBody, _ := io.ReadAll(resp.Body)
-		return false, fmt.Errorf("api error (status %d): %s", resp.StatusCode, string(respBody))
+		return false, newAPIError(resp, respBody)
 	}
 }
 
@@ -869,13 +1096,36 @@ func AliasExists(ctx context.Context, alias string, opts ...TemplateOption) (boo
 //
 //	templates, err := e2b.ListTemplates(ctx)
 func ListTemplates(ctx context.Context, opts ...TemplateOption) ([]TemplateInfo, error) {
+	return ListTemplatesWithOptions(ctx, nil, opts...)
+}
+
+// ListTemplatesWithOptions returns templates filtered and sorted by listOpts.
+//
+// The team filter (WithListTeamID) is applied by the API. The API does not
+// support sorting or the other filters, so WithListSort, WithListAliasPrefix
+// and WithListPublic are applied client-side after fetching the templates.
+//
+// Example:
+//
+//	templates, err := e2b.ListTemplatesWithOptions(ctx, []e2b.ListTemplatesOption{
+//	    e2b.WithListAliasPrefix("python"),
+//	    e2b.WithListSort(e2b.TemplateSortSpawnCount, true),
+//	})
+func ListTemplatesWithOptions(ctx context.Context, listOpts []ListTemplatesOption, opts ...TemplateOption) ([]TemplateInfo, error) {
 	cfg := templateConfigFromOptions(opts)
+	listCfg := defaultListTemplatesConfig()
+	for _, opt := range listOpts {
+		opt(listCfg)
+	}
 
 	if cfg.apiKey == "" && cfg.accessToken == "" {
 		return nil, fmt.Errorf("%w: API key or access token is required", ErrInvalidArgument)
 	}
 
 	endpoint := cfg.apiURL + "/templates"
+	if listCfg.teamID != "" {
+		endpoint += "?" + url.Values{"teamID": {listCfg.teamID}}.Encode()
+	}
 
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
@@ -896,7 +1146,7 @@ func ListTemplates(ctx context.Context, opts ...TemplateOption) ([]TemplateInfo,
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("api error (status %d): %s", resp.StatusCode, string(respBody))
+		return nil, newAPIError(resp, respBody)
 	}
 
 	var templates []TemplateInfo
@@ -904,7 +1154,73 @@ func ListTemplates(ctx context.Context, opts ...TemplateOption) ([]TemplateInfo,
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
-	return templates, nil
+	return filterAndSortTemplates(templates, listCfg), nil
+}
+
+// filterAndSortTemplates applies the client-side list options.
+func filterAndSortTemplates(templates []TemplateInfo, cfg *listTemplatesConfig) []TemplateInfo {
+	if cfg.aliasPrefix != "" || cfg.public != nil {
+		filtered := templates[:0]
+		for _, t := range templates {
+			if cfg.public != nil && t.Public != *cfg.public {
+				continue
+			}
+			if cfg.aliasPrefix != "" && !templateHasNamePrefix(t, cfg.aliasPrefix) {
+				continue
+			}
+			filtered = append(filtered, t)
+		}
+		templates = filtered
+	}
+
+	if cfg.sortBy != "" {
+		sort.SliceStable(templates, func(i, j int) bool {
+			a, b := templates[i], templates[j]
+			if cfg.sortDescending {
+				a, b = b, a
+			}
+			return templateLess(a, b, cfg.sortBy)
+		})
+	}
+
+	return templates
+}
+
+// templateHasNamePrefix reports whether a template name or alias starts with prefix.
+// Names in namespace/alias format also match on the alias part.
+func templateHasNamePrefix(t TemplateInfo, prefix string) bool {
+	for _, names := range [][]string{t.Names, t.Aliases} {
+		for _, name := range names {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+			if i := strings.LastIndexByte(name, '/'); i >= 0 && strings.HasPrefix(name[i+1:], prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// templateLess compares two templates by the given sort key.
+func templateLess(a, b TemplateInfo, key TemplateSortKey) bool {
+	switch key {
+	case TemplateSortSpawnCount:
+		return a.SpawnCount < b.SpawnCount
+	case TemplateSortBuildCount:
+		return a.BuildCount < b.BuildCount
+	case TemplateSortCreatedAt:
+		return a.CreatedAt.Before(b.CreatedAt)
+	case TemplateSortUpdatedAt:
+		return a.UpdatedAt.Before(b.UpdatedAt)
+	case TemplateSortLastSpawnedAt:
+		if a.LastSpawnedAt == nil || b.LastSpawnedAt == nil {
+			return a.LastSpawnedAt == nil && b.LastSpawnedAt != nil
+		}
+		return a.LastSpawnedAt.Before(*b.LastSpawnedAt)
+	default:
+		return false
+	}
 }
 
 // GetTemplateByID retrieves a template with its build history.
@@ -914,12 +1230,78 @@ func ListTemplates(ctx context.Context, opts ...TemplateOption) ([]TemplateInfo,
 //	template, err := e2b.GetTemplateByID(ctx, "template-id")
 func GetTemplateByID(ctx context.Context, templateID string, opts ...TemplateOption) (*TemplateWithBuilds, error) {
 	cfg := templateConfigFromOptions(opts)
+	return getTemplateByIDInternal(ctx, templateID, &getTemplateConfig{}, cfg)
+}
 
+// GetTemplateByIDWithOptions retrieves a template with a page of its build
+// history. WithGetTemplateLimit sets the page size and
+// WithGetTemplateNextToken the page to fetch; the token of the next page is
+// returned in TemplateWithBuilds.NextToken.
+//
+// Example:
+//
+//	template, err := e2b.GetTemplateByIDWithOptions(ctx, "template-id",
+//	    []e2b.GetTemplateOption{e2b.WithGetTemplateLimit(10)},
+//	)
+func GetTemplateByIDWithOptions(ctx context.Context, templateID string, getOpts []GetTemplateOption, opts ...TemplateOption) (*TemplateWithBuilds, error) {
+	cfg := templateConfigFromOptions(opts)
+	getCfg := defaultGetTemplateConfig()
+	for _, opt := range getOpts {
+		opt(getCfg)
+	}
+	return getTemplateByIDInternal(ctx, templateID, getCfg, cfg)
+}
+
+// GetTemplateBuilds returns a page of the build history of a template and
+// the token of the next page, which is empty on the last page. It takes
+// the same options as GetTemplateByIDWithOptions.
+//
+// Example:
+//
+//	var token string
+//	for {
+//	    builds, next, err := e2b.GetTemplateBuilds(ctx, "template-id",
+//	        []e2b.GetTemplateOption{e2b.WithGetTemplateNextToken(token)},
+//	    )
+//	    if err != nil {
+//	        log.Fatal(err)
+//	    }
+//	    for _, build := range builds {
+//	        fmt.Println(build.BuildID, build.Status)
+//	    }
+//	    if next == "" {
+//	        break
+//	    }
+//	    token = next
+//	}
+func GetTemplateBuilds(ctx context.Context, templateID string, getOpts []GetTemplateOption, opts ...TemplateOption) ([]TemplateBuild, string, error) {
+	template, err := GetTemplateByIDWithOptions(ctx, templateID, getOpts, opts...)
+	if err != nil {
+		return nil, "", err
+	}
+	return template.Builds, template.NextToken, nil
+}
+
+// getTemplateByIDInternal is the internal implementation of GetTemplateByID.
+func getTemplateByIDInternal(ctx context.Context, templateID string, getCfg *getTemplateConfig, cfg *templateConfig) (*TemplateWithBuilds, error) {
 	if cfg.apiKey == "" && cfg.accessToken == "" {
 		return nil, fmt.Errorf("%w: API key or access token is required", ErrInvalidArgument)
 	}
+	if getCfg.limit < 0 {
+		return nil, fmt.Errorf("%w: limit must not be negative", ErrInvalidArgument)
+	}
 
 	endpoint, _ := url.JoinPath(cfg.apiURL, "templates", templateID)
+	params := url.Values{}
+	if getCfg.limit > 0 {
+		params.Set("limit", fmt.Sprintf("%d", getCfg.limit))
+	}
+	if getCfg.nextToken != "" {
+		params.Set("nextToken", getCfg.nextToken)
+	}
+	if len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
 
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
@@ -940,13 +1322,14 @@ func GetTemplateByID(ctx context.Context, templateID string, opts ...TemplateOpt
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("api error (status %d): %s", resp.StatusCode, string(respBody))
+		return nil, newAPIError(resp, respBody)
 	}
 
 	var template TemplateWithBuilds
 	if err := json.Unmarshal(respBody, &template); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
+	template.NextToken = resp.Header.Get("X-Next-Token")
 
 	return &template, nil
 }
@@ -980,7 +1363,7 @@ func DeleteTemplate(ctx context.Context, templateID string, opts ...TemplateOpti
 
 	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
 		respBody, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("api error (status %d): %s", resp.StatusCode, string(respBody))
+		return newAPIError(resp, respBody)
 	}
 
 	return nil
@@ -1023,7 +1406,7 @@ func UpdateTemplate(ctx context.Context, templateID string, update *TemplateUpda
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
 		respBody, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("api error (status %d): %s", resp.StatusCode, string(respBody))
+		return newAPIError(resp, respBody)
 	}
 
 	return nil
@@ -1112,7 +1495,7 @@ func GetTemplateTags(ctx context.Context, templateID string, opts ...TemplateOpt
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("api error (status %d): %s", resp.StatusCode, string(respBody))
+		return nil, newAPIError(resp, respBody)
 	}
 
 	var tags []TemplateTag
@@ -1160,7 +1543,7 @@ func AssignTemplateTags(ctx context.Context, targetName string, tags []string, o
 	}
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return nil, fmt.Errorf("api error (status %d): %s", resp.StatusCode, string(respBody))
+		return nil, newAPIError(resp, respBody)
 	}
 
 	var info TemplateTagInfo
@@ -1204,7 +1587,7 @@ func RemoveTemplateTags(ctx context.Context, name string, tags []string, opts ..
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
 		respBody, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("api error (status %d): %s", resp.StatusCode, string(respBody))
+		return newAPIError(resp, respBody)
 	}
 
 	return nil
@@ -1259,7 +1642,7 @@ func UpdateTemplateV2(ctx context.Context, templateID string, update *TemplateUp
 	}
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
-		return nil, fmt.Errorf("api error (status %d): %s", resp.StatusCode, string(respBody))
+		return nil, newAPIError(resp, respBody)
 	}
 
 	var result TemplateUpdateResponse
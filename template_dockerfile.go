@@ -0,0 +1,361 @@
+package e2b
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// FromDockerfile reads the Dockerfile at path and adds its instructions to
+// the template, like ParseDockerfile.
+//
+// Example:
+//
+//	template, err := e2b.NewTemplate(e2b.WithBuilderContextPath(".")).
+//	    FromDockerfile("Dockerfile")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	template.SetStartCmd("python app.py")
+func (b *TemplateBuilder) FromDockerfile(path string) (*TemplateBuilder, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return b, fmt.Errorf("failed to read Dockerfile: %w", err)
+	}
+	return b.ParseDockerfile(content)
+}
+
+// ParseDockerfile adds the instructions of a Dockerfile to the template:
+//
+//   - FROM sets the base image, like FromImage
+//   - RUN adds a command, like RunCmd
+//   - COPY adds a step per source, like Copy; --chown and --chmod map to
+//     WithCopyUser and WithCopyMode
+//   - ENV sets environment variables, like SetEnv
+//   - WORKDIR and USER are added like SetWorkdir and SetUser
+//
+// Both the shell and the JSON exec forms are accepted, as are line
+// continuations and comments. Variables are not expanded. Other
+// instructions, multi-stage builds and flags such as RUN --mount or
+// COPY --from are not supported: every such line is reported in an error
+// wrapping ErrInvalidArgument, and the template is left unchanged.
+//
+// Example:
+//
+//	template, err := e2b.NewTemplate().ParseDockerfile([]byte(`
+//	FROM python:3.11
+//	WORKDIR /app
+//	COPY requirements.txt .
+//	RUN pip install -r requirements.txt
+//	`))
+func (b *TemplateBuilder) ParseDockerfile(content []byte) (*TemplateBuilder, error) {
+	var steps []func(*TemplateBuilder)
+	var errs []error
+	seenFrom := false
+
+	for _, line := range dockerfileLines(string(content)) {
+		keyword, rest := line.text, ""
+		if i := strings.IndexAny(line.text, " \t"); i >= 0 {
+			keyword, rest = line.text[:i], strings.TrimSpace(line.text[i:])
+		}
+		keyword = strings.ToUpper(keyword)
+
+		fail := func(format string, args ...any) {
+			errs = append(errs, fmt.Errorf("%w: Dockerfile line %d: %s", ErrInvalidArgument, line.number, fmt.Sprintf(format, args...)))
+		}
+		if !seenFrom && slices.Contains([]string{"RUN", "COPY", "ENV", "WORKDIR", "USER"}, keyword) {
+			fail("%s before FROM", keyword)
+			continue
+		}
+
+		switch keyword {
+		case "FROM":
+			if seenFrom {
+				fail("multi-stage builds are not supported")
+				continue
+			}
+			seenFrom = true
+			fields, err := splitDockerfileWords(rest)
+			if err != nil {
+				fail("%v", err)
+				continue
+			}
+			if len(fields) > 0 && strings.HasPrefix(fields[0], "--") {
+				fail("FROM flags are not supported")
+				continue
+			}
+			if len(fields) != 1 && (len(fields) != 3 || !strings.EqualFold(fields[1], "AS")) {
+				fail("FROM expects an image")
+				continue
+			}
+			image := fields[0]
+			steps = append(steps, func(b *TemplateBuilder) { b.FromImage(image) })
+
+		case "RUN":
+			if strings.HasPrefix(rest, "--") {
+				fail("RUN flags are not supported")
+				continue
+			}
+			cmd, err := dockerfileCommand(rest)
+			if err != nil {
+				fail("%v", err)
+				continue
+			}
+			steps = append(steps, func(b *TemplateBuilder) { b.RunCmd(cmd) })
+
+		case "COPY":
+			args, err := dockerfileArgs(rest)
+			if err != nil {
+				fail("%v", err)
+				continue
+			}
+			values, unknown := dockerfileFlags(args)
+			if len(unknown) > 0 {
+				fail("COPY flag --%s is not supported", unknown[0])
+				continue
+			}
+			args = values.args
+			if len(args) < 2 {
+				fail("COPY expects a source and a destination")
+				continue
+			}
+			var opts []CopyOption
+			if values.chown != "" {
+				opts = append(opts, WithCopyUser(values.chown))
+			}
+			if values.chmod != "" {
+				mode, err := strconv.ParseUint(values.chmod, 8, 32)
+				if err != nil {
+					fail("invalid COPY --chmod %q", values.chmod)
+					continue
+				}
+				opts = append(opts, WithCopyMode(uint32(mode)))
+			}
+			dest := args[len(args)-1]
+			for _, src := range args[:len(args)-1] {
+				steps = append(steps, func(b *TemplateBuilder) { b.Copy(src, dest, opts...) })
+			}
+
+		case "ENV":
+			envs, err := dockerfileEnv(rest)
+			if err != nil {
+				fail("%v", err)
+				continue
+			}
+			for _, kv := range envs {
+				steps = append(steps, func(b *TemplateBuilder) { b.SetEnv(kv[0], kv[1]) })
+			}
+
+		case "WORKDIR", "USER":
+			fields, err := splitDockerfileWords(rest)
+			if err != nil || len(fields) != 1 {
+				fail("%s expects a single argument", keyword)
+				continue
+			}
+			if keyword == "WORKDIR" {
+				steps = append(steps, func(b *TemplateBuilder) { b.SetWorkdir(fields[0]) })
+			} else {
+				steps = append(steps, func(b *TemplateBuilder) { b.SetUser(fields[0]) })
+			}
+
+		default:
+			fail("unsupported instruction %s", keyword)
+		}
+	}
+
+	if len(errs) == 0 && !seenFrom {
+		errs = append(errs, fmt.Errorf("%w: Dockerfile has no FROM instruction", ErrInvalidArgument))
+	}
+	if len(errs) > 0 {
+		return b, errors.Join(errs...)
+	}
+
+	for _, step := range steps {
+		step(b)
+	}
+	return b, nil
+}
+
+// dockerfileLine is a logical Dockerfile line, with continuations joined.
+type dockerfileLine struct {
+	number int
+	text   string
+}
+
+// dockerfileLines splits a Dockerfile into logical lines, joining lines
+// ending in a backslash and dropping blank lines and comments. Each line
+// is numbered by the physical line it starts on.
+func dockerfileLines(content string) []dockerfileLine {
+	var lines []dockerfileLine
+	var current strings.Builder
+	start := 0
+
+	for i, raw := range strings.Split(content, "\n") {
+		text := strings.TrimRight(raw, " \t\r")
+		// Blank lines and comments are dropped, even inside continuations
+		if trimmed := strings.TrimSpace(text); trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if current.Len() == 0 {
+			start = i + 1
+		}
+		if continued, ok := strings.CutSuffix(text, "\\"); ok {
+			current.WriteString(continued)
+			continue
+		}
+		current.WriteString(text)
+		lines = append(lines, dockerfileLine{number: start, text: strings.TrimSpace(current.String())})
+		current.Reset()
+	}
+	if s := strings.TrimSpace(current.String()); s != "" {
+		lines = append(lines, dockerfileLine{number: start, text: s})
+	}
+	return lines
+}
+
+// dockerfileCommand returns the shell command of a RUN instruction. The
+// exec form is converted to a shell command with each argument quoted.
+func dockerfileCommand(rest string) (string, error) {
+	if !strings.HasPrefix(rest, "[") {
+		if rest == "" {
+			return "", fmt.Errorf("RUN expects a command")
+		}
+		return rest, nil
+	}
+
+	var args []string
+	if err := json.Unmarshal([]byte(rest), &args); err != nil || len(args) == 0 {
+		return "", fmt.Errorf("invalid RUN exec form %s", rest)
+	}
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = shellQuote(arg)
+	}
+	return strings.Join(quoted, " "), nil
+}
+
+// dockerfileArgs returns the arguments of an instruction in either the
+// JSON exec form or the whitespace-separated form.
+func dockerfileArgs(rest string) ([]string, error) {
+	// Flags come before the JSON array, e.g. COPY --chown=app ["a", "b"]
+	if i := strings.Index(rest, "["); i >= 0 && strings.HasSuffix(rest, "]") {
+		flags, err := splitDockerfileWords(rest[:i])
+		if err != nil {
+			return nil, err
+		}
+		var args []string
+		if err := json.Unmarshal([]byte(rest[i:]), &args); err == nil {
+			return append(flags, args...), nil
+		}
+	}
+	return splitDockerfileWords(rest)
+}
+
+// dockerfileFlagValues holds the flags of a COPY instruction.
+type dockerfileFlagValues struct {
+	chown string
+	chmod string
+	args  []string
+}
+
+// dockerfileFlags parses the leading --name=value flags of args. It
+// returns the supported flag values, the arguments after the flags, and
+// the names of unsupported flags.
+func dockerfileFlags(args []string) (dockerfileFlagValues, []string) {
+	var values dockerfileFlagValues
+	var unknown []string
+	i := 0
+	for ; i < len(args) && strings.HasPrefix(args[i], "--"); i++ {
+		name, value, _ := strings.Cut(strings.TrimPrefix(args[i], "--"), "=")
+		switch name {
+		case "chown":
+			values.chown = value
+		case "chmod":
+			values.chmod = value
+		default:
+			unknown = append(unknown, name)
+		}
+	}
+	values.args = args[i:]
+	return values, unknown
+}
+
+// dockerfileEnv parses the key=value pairs of an ENV instruction, or the
+// legacy "ENV key value" form.
+func dockerfileEnv(rest string) ([][2]string, error) {
+	words, err := splitDockerfileWords(rest)
+	if err != nil {
+		return nil, err
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("ENV expects a key and a value")
+	}
+
+	if !strings.Contains(words[0], "=") {
+		key, value, _ := strings.Cut(rest, " ")
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return nil, fmt.Errorf("ENV %s has no value", key)
+		}
+		return [][2]string{{key, value}}, nil
+	}
+
+	envs := make([][2]string, 0, len(words))
+	for _, word := range words {
+		key, value, ok := strings.Cut(word, "=")
+		if !ok {
+			return nil, fmt.Errorf("ENV %q is not key=value", word)
+		}
+		envs = append(envs, [2]string{key, value})
+	}
+	return envs, nil
+}
+
+// splitDockerfileWords splits s on whitespace, honoring single and double
+// quotes and backslash escapes like the Dockerfile shell form.
+func splitDockerfileWords(s string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\\' && quote != '\'' && i+1 < len(runes):
+			i++
+			word.WriteRune(runes[i])
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in %q", s)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
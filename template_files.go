@@ -0,0 +1,272 @@
+package e2b
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// copySource holds the settings of a COPY step needed to collect and upload
+// its files during the build.
+type copySource struct {
+	resolveSymlinks bool
+	forceUpload     bool
+}
+
+// templateFile is a local file, directory or symbolic link included in a
+// COPY step.
+type templateFile struct {
+	// rel is the slash-separated path relative to the context path, which
+	// is also its name in the uploaded archive
+	rel     string
+	absPath string
+	info    os.FileInfo
+
+	// link is the target of a symbolic link that is not resolved
+	link string
+}
+
+// uploadCopyFiles uploads the files of each COPY step of spec, hashed by
+// hashCopySteps into stepFiles, that the build cache does not have yet.
+func (b *TemplateBuilder) uploadCopyFiles(ctx context.Context, templateID string, spec *TemplateBuildSpec, stepFiles map[int][]templateFile, cfg *templateConfig) error {
+	for _, i := range slices.Sorted(maps.Keys(stepFiles)) {
+		step := &spec.Steps[i]
+		upload, err := getFileUploadLinkInternal(ctx, templateID, step.FilesHash, cfg)
+		if err != nil {
+			return err
+		}
+		if upload.Present && !b.copySources[i].forceUpload {
+			continue
+		}
+		if upload.URL == "" {
+			return fmt.Errorf("no upload URL for files of COPY %s", step.Args[0])
+		}
+
+		// Stream the archive into the upload instead of holding it in memory
+		pr, pw := io.Pipe()
+		go func() {
+			pw.CloseWithError(writeCopyArchive(pw, stepFiles[i]))
+		}()
+		err = uploadLayerFileInternal(ctx, &FileUploadInfo{URL: upload.URL}, pr, cfg)
+		pr.Close()
+		if err != nil {
+			return fmt.Errorf("failed to upload files for COPY %s: %w", step.Args[0], err)
+		}
+	}
+	return nil
+}
+
+// hashCopySteps collects the files of each COPY step of spec and sets its
+// FilesHash. It returns the files by step index.
+func (b *TemplateBuilder) hashCopySteps(spec *TemplateBuildSpec) (map[int][]templateFile, error) {
+	if len(b.copySources) == 0 {
+		return nil, nil
+	}
+
+	rules, err := parseIgnoreRules(b.ignorePatterns)
+	if err != nil {
+		return nil, err
+	}
+
+	stepFiles := make(map[int][]templateFile, len(b.copySources))
+	for _, i := range slices.Sorted(maps.Keys(b.copySources)) {
+		step := &spec.Steps[i]
+		src := b.copySources[i]
+		files, err := collectCopyFiles(b.contextPath, step.Args[0], rules, src.resolveSymlinks)
+		if err != nil {
+			return nil, err
+		}
+		if step.FilesHash, err = hashCopyFiles(step.Args, files); err != nil {
+			return nil, err
+		}
+		stepFiles[i] = files
+	}
+	return stepFiles, nil
+}
+
+// collectCopyFiles returns the files matched by the COPY source src, a file,
+// directory or glob pattern relative to contextPath, sorted by path.
+// Directories are included recursively, and entries matching the ignore
+// rules are skipped.
+func collectCopyFiles(contextPath, src string, rules []ignoreRule, resolveSymlinks bool) ([]templateFile, error) {
+	matches, err := matchCopySource(contextPath, src)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &copyCollector{contextPath: contextPath, rules: rules, resolveSymlinks: resolveSymlinks, seen: map[string]bool{}}
+	for _, match := range matches {
+		matchRel, err := filepath.Rel(contextPath, match)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", match, err)
+		}
+		if err := c.add(match, filepath.ToSlash(matchRel), map[string]bool{}); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(c.files, func(i, j int) bool { return c.files[i].rel < c.files[j].rel })
+	return c.files, nil
+}
+
+// matchCopySource returns the local paths matched by the COPY source src.
+// It returns an error wrapping ErrInvalidArgument if src is not inside
+// contextPath and one wrapping ErrNotFound if it matches nothing.
+func matchCopySource(contextPath, src string) ([]string, error) {
+	rel := path.Clean(filepath.ToSlash(src))
+	if path.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, "../") {
+		return nil, fmt.Errorf("%w: COPY source %q must be relative to the context path and inside it", ErrInvalidArgument, src)
+	}
+
+	matches, err := filepath.Glob(filepath.Join(contextPath, filepath.FromSlash(rel)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid COPY source pattern %q", ErrInvalidArgument, src)
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("%w: COPY source %q matches no files in %s", ErrNotFound, src, contextPath)
+	}
+	return matches, nil
+}
+
+// copyCollector gathers the files of a COPY step.
+type copyCollector struct {
+	contextPath     string
+	rules           []ignoreRule
+	resolveSymlinks bool
+	seen            map[string]bool
+	files           []templateFile
+}
+
+// add records the entry at absPath and, for a directory, its contents.
+// visited holds the resolved directories on the current path so symbolic
+// link cycles are not followed.
+func (c *copyCollector) add(absPath, rel string, visited map[string]bool) error {
+	info, err := os.Lstat(absPath)
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", absPath, err)
+	}
+
+	f := templateFile{rel: rel, absPath: absPath, info: info}
+	if info.Mode()&os.ModeSymlink != 0 {
+		if c.resolveSymlinks {
+			if f.info, err = os.Stat(absPath); err != nil {
+				return fmt.Errorf("failed to resolve %s: %w", absPath, err)
+			}
+		} else if f.link, err = os.Readlink(absPath); err != nil {
+			return fmt.Errorf("failed to read link %s: %w", absPath, err)
+		}
+	}
+
+	if matchIgnoreRules(c.rules, rel, f.info.IsDir()) {
+		return nil
+	}
+	if !c.seen[rel] {
+		c.seen[rel] = true
+		c.files = append(c.files, f)
+	}
+	if !f.info.IsDir() {
+		return nil
+	}
+
+	realDir, err := filepath.EvalSymlinks(absPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", absPath, err)
+	}
+	if visited[realDir] {
+		return nil
+	}
+	visited[realDir] = true
+	defer delete(visited, realDir)
+
+	entries, err := os.ReadDir(absPath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", absPath, err)
+	}
+	for _, entry := range entries {
+		if err := c.add(filepath.Join(absPath, entry.Name()), path.Join(rel, entry.Name()), visited); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hashCopyFiles returns the hex-encoded SHA-256 hash identifying the files
+// of the COPY step with the given args: source, destination, and the
+// optional owner and mode. It covers the args and the path, mode and content
+// of every file, so it changes whenever any of them does.
+func hashCopyFiles(args []string, files []templateFile) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "COPY %q\n", args)
+
+	for _, f := range files {
+		fmt.Fprintf(h, "%s\x00%o\x00", f.rel, f.info.Mode())
+		switch {
+		case f.link != "":
+			fmt.Fprintf(h, "%s\x00", f.link)
+		case f.info.Mode().IsRegular():
+			fmt.Fprintf(h, "%d\x00", f.info.Size())
+			if err := copyFileTo(h, f.absPath); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// writeCopyArchive writes files to w as a gzip-compressed tar archive.
+func writeCopyArchive(w io.Writer, files []templateFile) error {
+	zw := gzip.NewWriter(w)
+	tw := tar.NewWriter(zw)
+
+	for _, f := range files {
+		hdr, err := tar.FileInfoHeader(f.info, f.link)
+		if err != nil {
+			return fmt.Errorf("failed to archive %s: %w", f.absPath, err)
+		}
+		hdr.Name = f.rel
+		if f.info.IsDir() {
+			hdr.Name += "/"
+		}
+		hdr.Uid, hdr.Gid, hdr.Uname, hdr.Gname = 0, 0, "", ""
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if f.link == "" && f.info.Mode().IsRegular() {
+			if err := copyFileTo(tw, f.absPath); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// copyFileTo writes the content of the local file at p to w.
+func copyFileTo(w io.Writer, p string) error {
+	f, err := os.Open(p)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", p, err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(w, f); err != nil {
+		return fmt.Errorf("failed to read %s: %w", p, err)
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package e2b
 
 import (
+	"log/slog"
 	"net/http"
 	"time"
 )
@@ -14,6 +15,7 @@ type templateConfig struct {
 	httpClient     *http.Client
 	requestTimeout time.Duration
 	debug          bool
+	logger         *slog.Logger
 }
 
 // defaultTemplateConfig returns the default template configuration.
@@ -66,6 +68,20 @@ func WithTemplateHTTPClient(client *http.Client) TemplateOption {
 	}
 }
 
+// WithTemplateLogger logs template API requests to logger at Debug level,
+// like WithLogger does for sandboxes. API keys and access tokens are
+// redacted.
+//
+// Example:
+//
+//	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+//	templates, err := e2b.ListTemplates(ctx, e2b.WithTemplateLogger(logger))
+func WithTemplateLogger(logger *slog.Logger) TemplateOption {
+	return func(c *templateConfig) {
+		c.logger = logger
+	}
+}
+
 // WithTemplateRequestTimeout sets the timeout for template API requests.
 // Defaults to 60 seconds.
 func WithTemplateRequestTimeout(d time.Duration) TemplateOption {
@@ -92,6 +108,12 @@ type buildConfig struct {
 	teamID         string
 	requestTimeout time.Duration
 	pollInterval   time.Duration
+	backoffMax     time.Duration
+	backoffFactor  float64
+	maxWait        time.Duration
+	logsOffset     int
+	logLevel       LogLevel
+	adaptivePoll   bool
 	templateConfig *templateConfig
 }
 
@@ -147,6 +169,15 @@ func WithBuildOnLogs(handler func(BuildLogEntry)) BuildOption {
 	}
 }
 
+// WithBuildLogLevel makes WithBuildOnLogs receive only log entries at
+// level or above, such as LogLevelWarn for warnings and errors. Defaults
+// to all entries.
+func WithBuildLogLevel(level LogLevel) BuildOption {
+	return func(c *buildConfig) {
+		c.logLevel = level
+	}
+}
+
 // WithBuildTeamID sets the team ID for the build.
 func WithBuildTeamID(teamID string) BuildOption {
 	return func(c *buildConfig) {
@@ -169,6 +200,59 @@ func WithBuildPollInterval(d time.Duration) BuildOption {
 	}
 }
 
+// WithBuildBackoff makes the interval for polling build status grow
+// exponentially while the build is waiting or building: it starts at
+// initial and is multiplied by factor after each poll, up to maxInterval.
+// A factor of 1 or less keeps the interval constant. Without it, the status
+// is polled every WithBuildPollInterval.
+//
+// An initial interval that is not positive is replaced by the poll interval
+// (200ms by default), and a maxInterval below the initial interval is raised
+// to it.
+//
+// Example:
+//
+//	err := e2b.WaitForBuild(ctx, templateID, buildID,
+//	    e2b.WithBuildBackoff(200*time.Millisecond, 10*time.Second, 2),
+//	)
+func WithBuildBackoff(initial, maxInterval time.Duration, factor float64) BuildOption {
+	return func(c *buildConfig) {
+		if initial > 0 {
+			c.pollInterval = initial
+		}
+		c.backoffMax = max(maxInterval, c.pollInterval)
+		c.backoffFactor = factor
+	}
+}
+
+// WithBuildMaxWait limits how long to wait for a build to complete. Once
+// it is exceeded, waiting stops with a *BuildTimeoutError holding the
+// last observed status; the build itself keeps running. Defaults to no
+// limit.
+func WithBuildMaxWait(d time.Duration) BuildOption {
+	return func(c *buildConfig) {
+		c.maxWait = d
+	}
+}
+
+// WithBuildLogsOffset skips the first offset build log entries, so that
+// waiting for a build again after a BuildTimeoutError does not repeat the
+// logs already received.
+//
+// Example:
+//
+//	var timeoutErr *e2b.BuildTimeoutError
+//	if errors.As(err, &timeoutErr) {
+//	    err = e2b.WaitForBuild(ctx, templateID, buildID,
+//	        e2b.WithBuildLogsOffset(timeoutErr.LogsOffset),
+//	    )
+//	}
+func WithBuildLogsOffset(offset int) BuildOption {
+	return func(c *buildConfig) {
+		c.logsOffset = offset
+	}
+}
+
 // WithBuildTemplateOptions applies TemplateOptions to the build config.
 func WithBuildTemplateOptions(opts ...TemplateOption) BuildOption {
 	return func(c *buildConfig) {
@@ -256,7 +340,7 @@ func defaultCopyConfig() *copyConfig {
 	}
 }
 
-// CopyOption configures a copy operation.
+// CopyOption configures a template copy step.
 type CopyOption func(*copyConfig)
 
 // WithCopyUser sets the user/owner for copied files.
@@ -281,6 +365,7 @@ func WithCopyForceUpload(force bool) CopyOption {
 }
 
 // WithCopyResolveSymlinks sets whether to resolve symlinks when copying.
+// If true, the target of a link is copied; if false, the link itself.
 // Defaults to true.
 func WithCopyResolveSymlinks(resolve bool) CopyOption {
 	return func(c *copyConfig) {
@@ -320,7 +405,7 @@ func WithLogsLimit(limit int) GetBuildStatusOption {
 	}
 }
 
-// WithLogsLevel filters logs by level.
+// WithLogsLevel filters logs to those at level or above.
 func WithLogsLevel(level LogLevel) GetBuildStatusOption {
 	return func(c *getBuildStatusConfig) {
 		c.level = level
@@ -329,7 +414,11 @@ func WithLogsLevel(level LogLevel) GetBuildStatusOption {
 
 // listTemplatesConfig holds configuration for listing templates.
 type listTemplatesConfig struct {
-	teamID string
+	teamID         string
+	sortBy         TemplateSortKey
+	sortDescending bool
+	aliasPrefix    string
+	public         *bool
 }
 
 // defaultListTemplatesConfig returns the default list templates configuration.
@@ -347,6 +436,31 @@ func WithListTeamID(teamID string) ListTemplatesOption {
 	}
 }
 
+// WithListSort sorts the listed templates by the given key.
+// Sorting is applied client-side.
+func WithListSort(key TemplateSortKey, descending bool) ListTemplatesOption {
+	return func(c *listTemplatesConfig) {
+		c.sortBy = key
+		c.sortDescending = descending
+	}
+}
+
+// WithListAliasPrefix keeps only templates with a name or alias starting with prefix.
+// Filtering is applied client-side.
+func WithListAliasPrefix(prefix string) ListTemplatesOption {
+	return func(c *listTemplatesConfig) {
+		c.aliasPrefix = prefix
+	}
+}
+
+// WithListPublic keeps only public templates (true) or only private templates (false).
+// By default, both are listed. Filtering is applied client-side.
+func WithListPublic(public bool) ListTemplatesOption {
+	return func(c *listTemplatesConfig) {
+		c.public = &public
+	}
+}
+
 // getTemplateConfig holds configuration for getting a template.
 type getTemplateConfig struct {
 	limit     int
@@ -1,10 +1,21 @@
 package e2b
 
 import (
+	"archive/tar"
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -719,6 +730,44 @@ func TestListTemplatesAPI(t *testing.T) {
 	}
 }
 
+func TestListTemplatesWithOptionsAPI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("teamID"); got != "team-1" {
+			t.Errorf("teamID = %v, want team-1", got)
+		}
+
+		templates := []TemplateInfo{
+			{ID: "template-1", Names: []string{"team/python-small"}, SpawnCount: 5},
+			{ID: "template-2", Names: []string{"team/node"}, SpawnCount: 50},
+			{ID: "template-3", Names: []string{"team/python-large"}, SpawnCount: 20},
+			{ID: "template-4", Names: []string{"python-public"}, SpawnCount: 100, Public: true},
+		}
+		json.NewEncoder(w).Encode(templates)
+	}))
+	defer server.Close()
+
+	templates, err := ListTemplatesWithOptions(context.Background(),
+		[]ListTemplatesOption{
+			WithListTeamID("team-1"),
+			WithListAliasPrefix("python"),
+			WithListPublic(false),
+			WithListSort(TemplateSortSpawnCount, true),
+		},
+		WithTemplateAPIKey("test-key"),
+		WithTemplateAPIURL(server.URL),
+	)
+	if err != nil {
+		t.Fatalf("ListTemplatesWithOptions() error = %v", err)
+	}
+
+	if len(templates) != 2 {
+		t.Fatalf("templates length = %d, want 2", len(templates))
+	}
+	if templates[0].ID != "template-3" || templates[1].ID != "template-1" {
+		t.Errorf("templates = [%v %v], want [template-3 template-1]", templates[0].ID, templates[1].ID)
+	}
+}
+
 func TestDeleteTemplateAPI(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodDelete {
@@ -859,6 +908,64 @@ func TestGetBuildStatusAPI(t *testing.T) {
 	}
 }
 
+func TestGetTemplateBuildsAPI(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/templates/template-123" {
+			t.Errorf("Path = %v, want /templates/template-123", r.URL.Path)
+		}
+		queries = append(queries, r.URL.RawQuery)
+
+		template := TemplateWithBuilds{ID: "template-123"}
+		switch r.URL.Query().Get("nextToken") {
+		case "":
+			template.Builds = []TemplateBuild{{BuildID: "build-1"}, {BuildID: "build-2"}}
+			w.Header().Set("X-Next-Token", "page-2")
+		case "page-2":
+			template.Builds = []TemplateBuild{{BuildID: "build-3"}}
+		}
+		json.NewEncoder(w).Encode(template)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	templateOpts := []TemplateOption{WithTemplateAPIKey("test-key"), WithTemplateAPIURL(server.URL)}
+
+	var buildIDs []string
+	token := ""
+	for page := 0; ; page++ {
+		builds, next, err := GetTemplateBuilds(ctx, "template-123",
+			[]GetTemplateOption{WithGetTemplateLimit(2), WithGetTemplateNextToken(token)},
+			templateOpts...,
+		)
+		if err != nil {
+			t.Fatalf("GetTemplateBuilds() error = %v", err)
+		}
+		for _, build := range builds {
+			buildIDs = append(buildIDs, build.BuildID)
+		}
+		if next == "" || page > 2 {
+			break
+		}
+		token = next
+	}
+	if want := []string{"build-1", "build-2", "build-3"}; !reflect.DeepEqual(buildIDs, want) {
+		t.Errorf("build IDs = %v, want %v", buildIDs, want)
+	}
+	if want := []string{"limit=2", "limit=2&nextToken=page-2"}; !reflect.DeepEqual(queries, want) {
+		t.Errorf("queries = %q, want %q", queries, want)
+	}
+
+	queries = nil
+	template, err := GetTemplateByID(ctx, "template-123", templateOpts...)
+	if err != nil {
+		t.Fatalf("GetTemplateByID() error = %v", err)
+	}
+	if template.NextToken != "page-2" || len(queries) != 1 || queries[0] != "" {
+		t.Errorf("GetTemplateByID() NextToken = %q, queries = %q, want page-2 without query", template.NextToken, queries)
+	}
+}
+
 func TestGetFileUploadLinkAPI(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
@@ -891,6 +998,677 @@ func TestGetFileUploadLinkAPI(t *testing.T) {
 	}
 }
 
+func TestUploadLayerFileCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	received := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf := make([]byte, 1024)
+		r.Body.Read(buf)
+		close(received)
+		io.Copy(io.Discard, r.Body)
+	}))
+	defer server.Close()
+
+	go func() {
+		<-received
+		cancel()
+	}()
+
+	// The body never ends, so the upload only stops on cancellation
+	err := UploadLayerFile(ctx, &FileUploadInfo{URL: server.URL}, endlessReader{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("UploadLayerFile() error = %v, want %v", err, context.Canceled)
+	}
+
+	if err := UploadLayerFile(ctx, &FileUploadInfo{Present: true}, nil); err != nil {
+		t.Errorf("UploadLayerFile() with present files error = %v, want nil", err)
+	}
+}
+
+// endlessReader is an io.Reader that never reaches EOF.
+type endlessReader struct{}
+
+func (endlessReader) Read(p []byte) (int, error) {
+	time.Sleep(time.Millisecond)
+	return len(p), nil
+}
+
+func TestBuildUploadsCopyFiles(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"app/main.py":        "print('hello')",
+		"app/lib/util.py":    "x = 1",
+		"app/debug.log":      "noise",
+		"requirements.txt":   "numpy",
+		"app/cache/data.bin": "cached",
+	} {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var (
+		mu       sync.Mutex
+		present  = map[string]bool{}
+		uploads  = map[string][]byte{}
+		lastSpec TemplateBuildSpec
+		requests int
+		noURL    bool
+	)
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case r.URL.Path == "/v3/templates":
+			requests++
+			w.WriteHeader(http.StatusAccepted)
+			json.NewEncoder(w).Encode(templateBuildResponse{TemplateID: "template-123", BuildID: "build-456"})
+		case strings.HasPrefix(r.URL.Path, "/templates/template-123/files/"):
+			hash := strings.TrimPrefix(r.URL.Path, "/templates/template-123/files/")
+			info := FileUploadInfo{Present: present[hash], URL: server.URL + "/upload/" + hash}
+			if noURL {
+				info.URL = ""
+			}
+			json.NewEncoder(w).Encode(info)
+		case strings.HasPrefix(r.URL.Path, "/upload/"):
+			hash := strings.TrimPrefix(r.URL.Path, "/upload/")
+			uploads[hash], _ = io.ReadAll(r.Body)
+			present[hash] = true
+		case r.URL.Path == "/v2/templates/template-123/builds/build-456":
+			json.NewDecoder(r.Body).Decode(&lastSpec)
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	build := func(template *TemplateBuilder) []TemplateStep {
+		t.Helper()
+		_, err := template.BuildInBackground(context.Background(), "my-template",
+			WithBuildTemplateOptions(
+				WithTemplateAPIKey("test-key"),
+				WithTemplateAPIURL(server.URL),
+			),
+		)
+		if err != nil {
+			t.Fatalf("BuildInBackground() error = %v", err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		steps := lastSpec.Steps
+		lastSpec = TemplateBuildSpec{}
+		return steps
+	}
+	newTemplate := func(opts ...CopyOption) *TemplateBuilder {
+		return NewTemplate(WithBuilderContextPath(dir), WithBuilderIgnorePatterns("*.log", "cache/")).
+			Copy("app", "/app/", opts...).
+			Copy("*.txt", "/app/").
+			RunCmd("pip install -r /app/requirements.txt")
+	}
+
+	t.Run("hashes and uploads", func(t *testing.T) {
+		steps := build(newTemplate())
+		if len(steps) != 3 || steps[0].FilesHash == "" || steps[1].FilesHash == "" || steps[2].FilesHash != "" {
+			t.Fatalf("Steps = %+v, want hashes on the COPY steps only", steps)
+		}
+		if steps[0].FilesHash == steps[1].FilesHash {
+			t.Error("COPY steps with different files have the same hash")
+		}
+		if len(uploads) != 2 {
+			t.Fatalf("uploads = %d, want 2", len(uploads))
+		}
+
+		zr, err := gzip.NewReader(strings.NewReader(string(uploads[steps[0].FilesHash])))
+		if err != nil {
+			t.Fatalf("upload is not gzip: %v", err)
+		}
+		tr := tar.NewReader(zr)
+		contents := map[string]string{}
+		var names []string
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("tar error = %v", err)
+			}
+			data, _ := io.ReadAll(tr)
+			names = append(names, hdr.Name)
+			contents[hdr.Name] = string(data)
+		}
+		want := []string{"app/", "app/lib/", "app/lib/util.py", "app/main.py"}
+		if !sort.StringsAreSorted(names) || strings.Join(names, ",") != strings.Join(want, ",") {
+			t.Errorf("archive entries = %v, want %v", names, want)
+		}
+		if contents["app/main.py"] != "print('hello')" {
+			t.Errorf("app/main.py = %q, want print('hello')", contents["app/main.py"])
+		}
+	})
+
+	t.Run("hash is stable", func(t *testing.T) {
+		first := build(newTemplate())
+		second := build(newTemplate())
+		if first[0].FilesHash != second[0].FilesHash || first[1].FilesHash != second[1].FilesHash {
+			t.Errorf("hashes changed between builds: %v, %v", first, second)
+		}
+
+		// Ignored files do not affect the hash
+		os.WriteFile(filepath.Join(dir, "app", "debug.log"), []byte("more noise"), 0o644)
+		if steps := build(newTemplate()); steps[0].FilesHash != first[0].FilesHash {
+			t.Error("hash changed after modifying an ignored file")
+		}
+
+		if steps := build(newTemplate(WithCopyUser("root"))); steps[0].FilesHash == first[0].FilesHash {
+			t.Error("hash did not change after changing the COPY owner")
+		}
+
+		os.WriteFile(filepath.Join(dir, "app", "lib", "util.py"), []byte("x = 2"), 0o644)
+		if steps := build(newTemplate()); steps[0].FilesHash == first[0].FilesHash {
+			t.Error("hash did not change after modifying a file")
+		}
+		os.WriteFile(filepath.Join(dir, "app", "lib", "util.py"), []byte("x = 1"), 0o644)
+
+		os.Chmod(filepath.Join(dir, "app", "main.py"), 0o755)
+		defer os.Chmod(filepath.Join(dir, "app", "main.py"), 0o644)
+		if steps := build(newTemplate()); steps[0].FilesHash == first[0].FilesHash {
+			t.Error("hash did not change after changing a file mode")
+		}
+	})
+
+	t.Run("cached files skip upload", func(t *testing.T) {
+		build(newTemplate())
+		mu.Lock()
+		clear(uploads)
+		mu.Unlock()
+
+		build(newTemplate())
+		if len(uploads) != 0 {
+			t.Errorf("uploads = %d, want 0 for cached files", len(uploads))
+		}
+
+		steps := build(newTemplate(WithCopyForceUpload(true)))
+		if _, ok := uploads[steps[0].FilesHash]; !ok || len(uploads) != 1 {
+			t.Errorf("uploads = %d, want only the forced COPY step uploaded", len(uploads))
+		}
+	})
+
+	t.Run("missing upload URL", func(t *testing.T) {
+		mu.Lock()
+		clear(present)
+		noURL = true
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			noURL = false
+			mu.Unlock()
+		}()
+
+		_, err := newTemplate().BuildInBackground(context.Background(), "my-template",
+			WithBuildTemplateOptions(WithTemplateAPIKey("test-key"), WithTemplateAPIURL(server.URL)),
+		)
+		if err == nil || !strings.Contains(err.Error(), "no upload URL") {
+			t.Errorf("BuildInBackground() error = %v, want missing upload URL error", err)
+		}
+	})
+
+	t.Run("missing source", func(t *testing.T) {
+		mu.Lock()
+		before := requests
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			defer mu.Unlock()
+			if requests != before {
+				t.Errorf("build requests = %d, want none for unreadable COPY sources", requests-before)
+			}
+		}()
+
+		template := NewTemplate(WithBuilderContextPath(dir)).Copy("missing", "/app/")
+		_, err := template.BuildInBackground(context.Background(), "my-template",
+			WithBuildTemplateOptions(WithTemplateAPIKey("test-key"), WithTemplateAPIURL(server.URL)),
+		)
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("BuildInBackground() error = %v, want ErrNotFound", err)
+		}
+
+		template = NewTemplate(WithBuilderContextPath(dir)).Copy("../outside", "/app/")
+		_, err = template.BuildInBackground(context.Background(), "my-template",
+			WithBuildTemplateOptions(WithTemplateAPIKey("test-key"), WithTemplateAPIURL(server.URL)),
+		)
+		if !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("BuildInBackground() error = %v, want ErrInvalidArgument", err)
+		}
+
+		// A dangling link passes Validate but cannot be hashed when resolved
+		link := filepath.Join(dir, "dangling")
+		if err := os.Symlink(filepath.Join(dir, "nowhere"), link); err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(link)
+		template = NewTemplate(WithBuilderContextPath(dir)).Copy("dangling", "/app/", WithCopyResolveSymlinks(true))
+		_, err = template.BuildInBackground(context.Background(), "my-template",
+			WithBuildTemplateOptions(WithTemplateAPIKey("test-key"), WithTemplateAPIURL(server.URL)),
+		)
+		if err == nil {
+			t.Error("BuildInBackground() error = nil, want an error for a dangling link")
+		}
+	})
+}
+
+func TestTemplateValidate(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "app.py"), []byte("print(1)"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	valid := NewTemplate(WithBuilderContextPath(dir)).
+		FromPythonImage("3.11").
+		Copy("*.py", "/app/").
+		SetEnv("PORT", "8080").
+		SetWorkdir("/app")
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+
+	spec, err := valid.DryRun()
+	if err != nil {
+		t.Fatalf("DryRun() error = %v", err)
+	}
+	if len(spec.Steps) != 3 || spec.Steps[0].FilesHash == "" {
+		t.Errorf("DryRun() steps = %+v, want 3 steps with the COPY hash set", spec.Steps)
+	}
+	if valid.instructions[0].FilesHash != "" {
+		t.Error("DryRun() modified the builder's steps")
+	}
+
+	invalid := NewTemplate(WithBuilderContextPath(dir)).
+		FromTemplate("").
+		Copy("missing.txt", "/app/").
+		SetEnv("", "value").
+		SetWorkdir("app")
+	err = invalid.Validate(WithBuildCPUCount(64), WithBuildMemoryMB(64))
+	if !errors.Is(err, ErrInvalidArgument) || !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Validate() error = %v, want ErrInvalidArgument and ErrNotFound", err)
+	}
+	for _, want := range []string{"base image", "missing.txt", "ENV key", "WORKDIR", "CPU count", "memory"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() error = %q, want it to mention %q", err, want)
+		}
+	}
+	if _, err := invalid.DryRun(); err == nil {
+		t.Error("DryRun() of invalid template expected error, got nil")
+	}
+
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	_, err = invalid.Build(context.Background(), "my-template",
+		WithBuildTemplateOptions(WithTemplateAPIKey("test-key"), WithTemplateAPIURL(server.URL)),
+	)
+	if err == nil || called {
+		t.Errorf("Build() of invalid template error = %v, API called = %v, want an error without calling the API", err, called)
+	}
+}
+
+func TestTemplateParseDockerfile(t *testing.T) {
+	dockerfile := `# syntax=docker/dockerfile:1
+FROM python:3.11-slim AS app
+ENV PYTHONUNBUFFERED=1 APP_NAME="my app"
+ENV LEGACY some value
+WORKDIR /app
+COPY --chown=app:app --chmod=755 requirements.txt setup.py ./
+RUN apt-get update && \
+    # comments inside continuations are dropped
+    apt-get install -y curl
+RUN ["pip", "install", "-r", "requirements.txt"]
+user app
+`
+	template, err := NewTemplate().ParseDockerfile([]byte(dockerfile))
+	if err != nil {
+		t.Fatalf("ParseDockerfile() error = %v", err)
+	}
+	if template.baseImage != "python:3.11-slim" {
+		t.Errorf("baseImage = %q, want %q", template.baseImage, "python:3.11-slim")
+	}
+	want := []TemplateStep{
+		{Type: "ENV", Args: []string{"PYTHONUNBUFFERED", "1"}},
+		{Type: "ENV", Args: []string{"APP_NAME", "my app"}},
+		{Type: "ENV", Args: []string{"LEGACY", "some value"}},
+		{Type: "WORKDIR", Args: []string{"/app"}},
+		{Type: "COPY", Args: []string{"requirements.txt", "./", "app:app", "755"}},
+		{Type: "COPY", Args: []string{"setup.py", "./", "app:app", "755"}},
+		{Type: "RUN", Args: []string{"apt-get update &&     apt-get install -y curl"}},
+		{Type: "RUN", Args: []string{"'pip' 'install' '-r' 'requirements.txt'"}},
+		{Type: "USER", Args: []string{"app"}},
+	}
+	if !reflect.DeepEqual(template.instructions, want) {
+		t.Errorf("instructions = %+v, want %+v", template.instructions, want)
+	}
+
+	unsupported := NewTemplate().FromNodeImage("20")
+	_, err = unsupported.ParseDockerfile([]byte("FROM node:20\nRUN npm ci\nHEALTHCHECK CMD curl -f localhost\nCOPY --from=build /out /app\nFROM alpine\n"))
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("ParseDockerfile() error = %v, want %v", err, ErrInvalidArgument)
+	}
+	for _, want := range []string{"line 3: unsupported instruction HEALTHCHECK", "line 4: COPY flag --from", "line 5: multi-stage"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("ParseDockerfile() error = %q, want it to mention %q", err, want)
+		}
+	}
+	if len(unsupported.instructions) != 0 {
+		t.Errorf("ParseDockerfile() with errors added %d steps, want none", len(unsupported.instructions))
+	}
+
+	if _, err := NewTemplate().ParseDockerfile([]byte("RUN true\n")); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("ParseDockerfile() without FROM error = %v, want %v", err, ErrInvalidArgument)
+	}
+
+	path := filepath.Join(t.TempDir(), "Dockerfile")
+	if err := os.WriteFile(path, []byte("FROM ubuntu:24.04\nRUN echo hi\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	template, err = NewTemplate().FromDockerfile(path)
+	if err != nil {
+		t.Fatalf("FromDockerfile() error = %v", err)
+	}
+	if template.baseImage != "ubuntu:24.04" || len(template.instructions) != 1 {
+		t.Errorf("FromDockerfile() base = %q, steps = %+v", template.baseImage, template.instructions)
+	}
+}
+
+func TestWaitForBuildBackoff(t *testing.T) {
+	cfg := defaultBuildConfig()
+	WithBuildBackoff(10*time.Millisecond, 50*time.Millisecond, 2)(cfg)
+	var intervals []time.Duration
+	for d := cfg.pollInterval; len(intervals) < 5; d = nextBuildPollInterval(d, cfg) {
+		intervals = append(intervals, d)
+	}
+	want := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond, 50 * time.Millisecond, 50 * time.Millisecond}
+	if !reflect.DeepEqual(intervals, want) {
+		t.Errorf("poll intervals = %v, want %v", intervals, want)
+	}
+
+	cfg = defaultBuildConfig()
+	WithBuildBackoff(0, 0, 2)(cfg)
+	if cfg.pollInterval != 200*time.Millisecond || cfg.backoffMax != 200*time.Millisecond {
+		t.Errorf("WithBuildBackoff(0, 0, 2) interval = %v, max = %v, want 200ms for both", cfg.pollInterval, cfg.backoffMax)
+	}
+	cfg = defaultBuildConfig()
+	WithBuildBackoff(time.Second, 100*time.Millisecond, 2)(cfg)
+	if d := nextBuildPollInterval(cfg.pollInterval, cfg); d != time.Second {
+		t.Errorf("next poll interval with max below initial = %v, want 1s", d)
+	}
+
+	var (
+		mu       sync.Mutex
+		polls    []time.Time
+		offsets  []string
+		statuses = []TemplateBuildStatus{TemplateBuildStatusWaiting, TemplateBuildStatusBuilding, TemplateBuildStatusBuilding}
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		polls = append(polls, time.Now())
+		offsets = append(offsets, r.URL.Query().Get("logsOffset"))
+		status := TemplateBuildStatusBuilding
+		if len(polls) <= len(statuses) {
+			status = statuses[len(polls)-1]
+		}
+		json.NewEncoder(w).Encode(TemplateBuildInfo{
+			Status:     status,
+			LogEntries: []BuildLogEntry{{Message: "step"}},
+		})
+	}))
+	defer server.Close()
+
+	opts := []BuildOption{
+		WithBuildTemplateOptions(WithTemplateAPIKey("test-key"), WithTemplateAPIURL(server.URL)),
+		WithBuildBackoff(10*time.Millisecond, 40*time.Millisecond, 2),
+		WithBuildMaxWait(150 * time.Millisecond),
+		WithBuildLogsOffset(3),
+	}
+	err := WaitForBuild(context.Background(), "template-123", "build-456", opts...)
+
+	var timeoutErr *BuildTimeoutError
+	if !errors.As(err, &timeoutErr) || !errors.Is(err, ErrTimeout) {
+		t.Fatalf("WaitForBuild() error = %v, want *BuildTimeoutError", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if timeoutErr.Status != TemplateBuildStatusBuilding || timeoutErr.LogsOffset != 3+len(polls) {
+		t.Errorf("BuildTimeoutError = %+v, want status building and logs offset %d", timeoutErr, 3+len(polls))
+	}
+	if timeoutErr.Waited < 150*time.Millisecond {
+		t.Errorf("BuildTimeoutError.Waited = %v, want at least 150ms", timeoutErr.Waited)
+	}
+	if offsets[0] != "3" || offsets[1] != "4" {
+		t.Errorf("logsOffset params = %v, want to start at 3", offsets)
+	}
+
+	// Each wait lasts at least the growing interval
+	minGaps := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond, 40 * time.Millisecond}
+	if len(polls) < 4 {
+		t.Fatalf("polls = %d, want at least 4", len(polls))
+	}
+	for i := 1; i < len(polls) && i <= len(minGaps); i++ {
+		if gap := polls[i].Sub(polls[i-1]); gap < minGaps[i-1] {
+			t.Errorf("gap before poll %d = %v, want at least %v", i+1, gap, minGaps[i-1])
+		}
+	}
+}
+
+func TestGetBuildStatusLogOptions(t *testing.T) {
+	entries := []BuildLogEntry{
+		{Level: LogLevelDebug, Message: "d"},
+		{Level: LogLevelInfo, Message: "i"},
+		{Level: LogLevelWarn, Message: "w1"},
+		{Level: LogLevelError, Message: "e"},
+		{Level: LogLevelWarn, Message: "w2"},
+	}
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		// The server ignores the parameters and returns all entries
+		json.NewEncoder(w).Encode(TemplateBuildInfo{Status: TemplateBuildStatusReady, LogEntries: entries})
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		opts      []GetBuildStatusOption
+		wantQuery string
+		want      []string
+	}{
+		{"defaults", nil, "limit=100", []string{"d", "i", "w1", "e", "w2"}},
+		{"offset", []GetBuildStatusOption{WithLogsOffset(5), WithLogsLimit(0)}, "logsOffset=5", []string{"d", "i", "w1", "e", "w2"}},
+		{"limit", []GetBuildStatusOption{WithLogsLimit(2)}, "limit=2", []string{"d", "i"}},
+		{"level", []GetBuildStatusOption{WithLogsLevel(LogLevelWarn)}, "level=warn&limit=100", []string{"w1", "e", "w2"}},
+		{"level and limit", []GetBuildStatusOption{WithLogsLevel(LogLevelInfo), WithLogsLimit(3)}, "level=info&limit=3", []string{"i", "w1", "e"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, err := GetBuildStatusWithOptions(context.Background(), "template-123", "build-456", tt.opts,
+				WithTemplateAPIKey("test-key"), WithTemplateAPIURL(server.URL))
+			if err != nil {
+				t.Fatalf("GetBuildStatusWithOptions() error = %v", err)
+			}
+			if query != tt.wantQuery {
+				t.Errorf("query = %q, want %q", query, tt.wantQuery)
+			}
+			var got []string
+			for _, entry := range status.LogEntries {
+				got = append(got, entry.Message)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("log entries = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	var logged []string
+	err := WaitForBuild(context.Background(), "template-123", "build-456",
+		WithBuildTemplateOptions(WithTemplateAPIKey("test-key"), WithTemplateAPIURL(server.URL)),
+		WithBuildLogLevel(LogLevelWarn),
+		WithBuildOnLogs(func(entry BuildLogEntry) {
+			logged = append(logged, entry.Message)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("WaitForBuild() error = %v", err)
+	}
+	if query != "" {
+		t.Errorf("WaitForBuild() query = %q, want no level or limit", query)
+	}
+	if want := []string{"w1", "e", "w2"}; !reflect.DeepEqual(logged, want) {
+		t.Errorf("WaitForBuild() logged %v, want %v", logged, want)
+	}
+}
+
+func TestWatchBuild(t *testing.T) {
+	opts := func(server *httptest.Server) BuildOption {
+		return WithBuildTemplateOptions(WithTemplateAPIKey("test-key"), WithTemplateAPIURL(server.URL))
+	}
+
+	t.Run("stream", func(t *testing.T) {
+		var statusQuery string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/templates/template-123/builds/build-456/logs":
+				if r.URL.Query().Get("follow") != "true" || r.Header.Get("Accept") != "application/x-ndjson" {
+					t.Errorf("logs request = %s with Accept %q, want a follow request for NDJSON", r.URL, r.Header.Get("Accept"))
+				}
+				w.Header().Set("Content-Type", "application/x-ndjson")
+				for _, msg := range []string{"pulling", "building", "done"} {
+					json.NewEncoder(w).Encode(BuildLogEntry{Level: LogLevelInfo, Message: msg})
+					w.(http.Flusher).Flush()
+				}
+			case "/templates/template-123/builds/build-456/status":
+				statusQuery = r.URL.RawQuery
+				json.NewEncoder(w).Encode(TemplateBuildInfo{Status: TemplateBuildStatusReady})
+			default:
+				t.Errorf("unexpected request %s", r.URL.Path)
+			}
+		}))
+		defer server.Close()
+
+		var logged []string
+		err := WatchBuild(context.Background(), "template-123", "build-456", func(entry BuildLogEntry) {
+			logged = append(logged, entry.Message)
+		}, opts(server))
+		if err != nil {
+			t.Fatalf("WatchBuild() error = %v", err)
+		}
+		if want := []string{"pulling", "building", "done"}; !reflect.DeepEqual(logged, want) {
+			t.Errorf("WatchBuild() logged %v, want %v", logged, want)
+		}
+		if statusQuery != "logsOffset=3" {
+			t.Errorf("status query = %q, want logsOffset=3", statusQuery)
+		}
+	})
+
+	t.Run("polling fallback", func(t *testing.T) {
+		polls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/templates/template-123/builds/build-456/logs" {
+				http.NotFound(w, r)
+				return
+			}
+			polls++
+			info := TemplateBuildInfo{Status: TemplateBuildStatusBuilding}
+			switch polls {
+			case 1, 2:
+				info.LogEntries = []BuildLogEntry{{Level: LogLevelInfo, Message: fmt.Sprintf("step %d", polls)}}
+			case 3:
+			default:
+				info.Status = TemplateBuildStatusError
+				info.Reason = &BuildStatusReason{Message: "apt-get failed"}
+			}
+			json.NewEncoder(w).Encode(info)
+		}))
+		defer server.Close()
+
+		var logged []string
+		err := WatchBuild(context.Background(), "template-123", "build-456", func(entry BuildLogEntry) {
+			logged = append(logged, entry.Message)
+		}, opts(server), WithBuildPollInterval(time.Millisecond))
+		if err == nil || !strings.Contains(err.Error(), "apt-get failed") {
+			t.Errorf("WatchBuild() error = %v, want the build failure reason", err)
+		}
+		if want := []string{"step 1", "step 2"}; !reflect.DeepEqual(logged, want) {
+			t.Errorf("WatchBuild() logged %v, want %v", logged, want)
+		}
+	})
+
+	t.Run("max wait bounds the stream", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/templates/template-123/builds/build-456/logs" {
+				// The stream stays open without sending anything
+				w.Header().Set("Content-Type", "application/x-ndjson")
+				w.(http.Flusher).Flush()
+				<-r.Context().Done()
+				return
+			}
+			json.NewEncoder(w).Encode(TemplateBuildInfo{Status: TemplateBuildStatusBuilding})
+		}))
+		defer server.Close()
+
+		start := time.Now()
+		err := WatchBuild(context.Background(), "template-123", "build-456", nil, opts(server), WithBuildMaxWait(50*time.Millisecond))
+		var timeoutErr *BuildTimeoutError
+		if !errors.As(err, &timeoutErr) {
+			t.Errorf("WatchBuild() error = %v, want a *BuildTimeoutError", err)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("WatchBuild() returned after %v, want it bounded by the max wait", elapsed)
+		}
+	})
+}
+
+func TestBuildCancelledDoesNotTrigger(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	triggered := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v3/templates" {
+			cancel()
+			w.WriteHeader(http.StatusAccepted)
+			json.NewEncoder(w).Encode(templateBuildResponse{TemplateID: "template-123", BuildID: "build-456"})
+			return
+		}
+		triggered = true
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	_, err := NewTemplate().FromPythonImage("3.11").BuildInBackground(ctx, "my-template",
+		WithBuildTemplateOptions(
+			WithTemplateAPIKey("test-key"),
+			WithTemplateAPIURL(server.URL),
+		),
+	)
+	if err == nil {
+		t.Error("BuildInBackground() expected error after cancellation, got nil")
+	}
+	if triggered {
+		t.Error("BuildInBackground() triggered the build after cancellation")
+	}
+}
+
 func TestAPIRequiresAuth(t *testing.T) {
 	tests := []struct {
 		name string
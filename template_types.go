@@ -49,6 +49,23 @@ const (
 	InstructionTypeUser InstructionType = "USER"
 )
 
+// TemplateSortKey is a key for sorting listed templates.
+type TemplateSortKey string
+
+const (
+	// TemplateSortSpawnCount sorts templates by SpawnCount.
+	TemplateSortSpawnCount TemplateSortKey = "spawnCount"
+	// TemplateSortBuildCount sorts templates by BuildCount.
+	TemplateSortBuildCount TemplateSortKey = "buildCount"
+	// TemplateSortCreatedAt sorts templates by CreatedAt.
+	TemplateSortCreatedAt TemplateSortKey = "createdAt"
+	// TemplateSortUpdatedAt sorts templates by UpdatedAt.
+	TemplateSortUpdatedAt TemplateSortKey = "updatedAt"
+	// TemplateSortLastSpawnedAt sorts templates by LastSpawnedAt.
+	// Templates that were never spawned sort first in ascending order.
+	TemplateSortLastSpawnedAt TemplateSortKey = "lastSpawnedAt"
+)
+
 // TemplateInfo represents an E2B template.
 type TemplateInfo struct {
 	// ID is the unique identifier of the template.
@@ -137,6 +154,9 @@ type TemplateWithBuilds struct {
 	UpdatedAt time.Time `json:"updatedAt"`
 	// LastSpawnedAt is when the template was last used (can be nil).
 	LastSpawnedAt *time.Time `json:"lastSpawnedAt"`
+	// NextToken is the token of the next page of builds, or empty if
+	// Builds is the last page (see WithGetTemplateNextToken).
+	NextToken string `json:"-"`
 }
 
 // BuildLogEntry represents a log entry from the build process.
@@ -183,7 +203,9 @@ type TemplateStep struct {
 	Type string `json:"type"`
 	// Args are the arguments for the step.
 	Args []string `json:"args,omitempty"`
-	// FilesHash is the hash of files used in COPY steps.
+	// FilesHash is the hash of the files used in COPY steps. Build computes
+	// it from the files under the context path, honoring the ignore
+	// patterns, so changed files invalidate the build cache.
 	FilesHash string `json:"filesHash,omitempty"`
 	// Force indicates whether to force rebuild regardless of cache.
 	Force bool `json:"force,omitempty"`
@@ -0,0 +1,93 @@
+package e2b
+
+import (
+	"errors"
+	"fmt"
+	"path"
+)
+
+// Validate checks the template for problems that would otherwise only
+// surface during a remote build:
+//
+//   - a base image or base template must be set
+//   - COPY sources must exist inside the context path
+//   - ENV keys must not be empty
+//   - WORKDIR paths must be absolute
+//   - the CPU count and memory set with WithBuildCPUCount and
+//     WithBuildMemoryMB must be within the allowed limits
+//
+// All problems are reported together in an error joined with errors.Join.
+// Each wraps ErrInvalidArgument, except missing COPY sources, which wrap
+// ErrNotFound. Build and BuildInBackground validate the template before
+// calling the API.
+//
+// Example:
+//
+//	if err := template.Validate(e2b.WithBuildCPUCount(4)); err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *TemplateBuilder) Validate(opts ...BuildOption) error {
+	cfg := defaultBuildConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	var errs []error
+	if b.baseImage == "" && b.baseTemplate == "" {
+		errs = append(errs, fmt.Errorf("%w: a base image or base template is required", ErrInvalidArgument))
+	}
+	if cfg.cpuCount < MinTemplateCPU || cfg.cpuCount > MaxTemplateCPU {
+		errs = append(errs, fmt.Errorf("%w: CPU count %d must be between %d and %d",
+			ErrInvalidArgument, cfg.cpuCount, MinTemplateCPU, MaxTemplateCPU))
+	}
+	if cfg.memoryMB < MinTemplateMemory {
+		errs = append(errs, fmt.Errorf("%w: memory %d MiB must be at least %d MiB",
+			ErrInvalidArgument, cfg.memoryMB, MinTemplateMemory))
+	}
+
+	for i, step := range b.instructions {
+		switch InstructionType(step.Type) {
+		case InstructionTypeCopy:
+			if _, err := matchCopySource(b.contextPath, step.Args[0]); err != nil {
+				errs = append(errs, fmt.Errorf("step %d: %w", i+1, err))
+			}
+		case InstructionTypeEnv:
+			if step.Args[0] == "" {
+				errs = append(errs, fmt.Errorf("%w: step %d: ENV key is empty", ErrInvalidArgument, i+1))
+			}
+		case InstructionTypeWorkdir:
+			if !path.IsAbs(step.Args[0]) {
+				errs = append(errs, fmt.Errorf("%w: step %d: WORKDIR %q is not an absolute path",
+					ErrInvalidArgument, i+1, step.Args[0]))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// DryRun validates the template like Validate and returns the build
+// specification Build would send, with the FilesHash of each COPY step
+// computed from the local files. Nothing is uploaded and the API is not
+// called.
+//
+// Example:
+//
+//	spec, err := template.DryRun()
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, step := range spec.Steps {
+//	    fmt.Println(step.Type, step.Args)
+//	}
+func (b *TemplateBuilder) DryRun(opts ...BuildOption) (*TemplateBuildSpec, error) {
+	if err := b.Validate(opts...); err != nil {
+		return nil, err
+	}
+
+	spec := b.toBuildSpec()
+	if _, err := b.hashCopySteps(spec); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
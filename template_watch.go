@@ -0,0 +1,148 @@
+package e2b
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Defaults for the adaptive polling of WatchBuild.
+const (
+	watchBuildBackoffFactor = 2
+	watchBuildBackoffMax    = 5 * time.Second
+)
+
+// buildLogsStreamContentType is the content type of the streaming build
+// logs endpoint: one JSON-encoded BuildLogEntry per line.
+const buildLogsStreamContentType = "application/x-ndjson"
+
+// errBuildLogsStreamUnsupported reports that the API has no streaming build
+// logs endpoint.
+var errBuildLogsStreamUnsupported = errors.New("build log streaming is not supported")
+
+// WatchBuild waits for a build to complete like WaitForBuild, calling onLog
+// for each build log entry as it arrives.
+//
+// Build logs are first requested from the logs endpoint with follow=true
+// and an Accept header of application/x-ndjson. If the API answers with an
+// NDJSON stream, it is consumed directly. Otherwise, for example on a 404 or
+// a JSON response from an API without streaming, or if the stream ends
+// early, the build status is polled: the interval starts at
+// WithBuildPollInterval, grows while no logs arrive (see WithBuildBackoff, by
+// default doubling up to 5 seconds) and is reset as soon as logs flow again.
+// WithBuildLogLevel, WithBuildMaxWait and WithBuildLogsOffset apply as for
+// WaitForBuild; the max wait also bounds the stream.
+//
+// An error containing the reason is returned if the build fails.
+//
+// Example:
+//
+//	err := e2b.WatchBuild(ctx, info.TemplateID, info.BuildID, func(entry e2b.BuildLogEntry) {
+//	    fmt.Printf("[%s] %s\n", entry.Level, entry.Message)
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func WatchBuild(ctx context.Context, templateID, buildID string, onLog func(BuildLogEntry), opts ...BuildOption) error {
+	cfg := defaultBuildConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	cfg.onLogs = onLog
+	cfg.adaptivePoll = true
+	if cfg.backoffFactor == 0 {
+		cfg.backoffFactor = watchBuildBackoffFactor
+		cfg.backoffMax = watchBuildBackoffMax
+	}
+
+	templateCfg := cfg.templateConfig
+	if templateCfg == nil {
+		templateCfg = defaultTemplateConfig()
+	}
+	applyTemplateEnvConfig(templateCfg)
+
+	// A failed or unsupported stream falls back to polling. The stream is
+	// cut off at the max wait, after which polling reports the timeout.
+	start := time.Now()
+	streamCtx := ctx
+	if cfg.maxWait > 0 {
+		var cancel context.CancelFunc
+		streamCtx, cancel = context.WithTimeout(ctx, cfg.maxWait)
+		defer cancel()
+	}
+	streamed, _ := streamBuildLogsInternal(streamCtx, templateID, buildID, cfg, templateCfg)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// The stream has ended, so the status tells whether the build is done;
+	// if it is still running, polling picks up after the streamed logs
+	cfg.logsOffset += streamed
+	if cfg.maxWait > 0 {
+		cfg.maxWait = max(cfg.maxWait-time.Since(start), time.Nanosecond)
+	}
+	return waitForBuildInternal(ctx, templateID, buildID, cfg, templateCfg)
+}
+
+// streamBuildLogsInternal passes the entries of the streaming build logs
+// endpoint to cfg.onLogs until the stream ends, returning the number of
+// entries received. It returns errBuildLogsStreamUnsupported if the API
+// does not stream build logs.
+func streamBuildLogsInternal(ctx context.Context, templateID, buildID string, cfg *buildConfig, templateCfg *templateConfig) (int, error) {
+	if templateCfg.apiKey == "" && templateCfg.accessToken == "" {
+		return 0, fmt.Errorf("%w: API key or access token is required", ErrInvalidArgument)
+	}
+
+	endpoint, _ := url.JoinPath(templateCfg.apiURL, "templates", templateID, "builds", buildID, "logs")
+	parsedURL, _ := url.Parse(endpoint)
+	params := url.Values{}
+	params.Set("follow", "true")
+	if cfg.logsOffset > 0 {
+		params.Set("logsOffset", fmt.Sprintf("%d", cfg.logsOffset))
+	}
+	parsedURL.RawQuery = params.Encode()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, parsedURL.String(), nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	setTemplateHeaders(httpReq, templateCfg)
+	httpReq.Header.Set("Accept", buildLogsStreamContentType)
+
+	resp, err := templateCfg.httpClient.Do(httpReq)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if resp.StatusCode != http.StatusOK || mediaType != buildLogsStreamContentType {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+		return 0, errBuildLogsStreamUnsupported
+	}
+
+	count := 0
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), DefaultMaxStreamLineSize)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry BuildLogEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return count, fmt.Errorf("failed to parse build log entry: %w", err)
+		}
+		count++
+		if cfg.onLogs != nil && len(filterBuildLogs([]BuildLogEntry{entry}, cfg.logLevel)) > 0 {
+			cfg.onLogs(entry)
+		}
+	}
+	return count, scanner.Err()
+}
@@ -131,7 +131,7 @@ func CreateVolume(ctx context.Context, name string, opts ...VolumeOption) (*Volu
 	}
 
 	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("api error (status %d): %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp, body)
 	}
 
 	var vol VolumeInfo
@@ -178,7 +178,7 @@ func ListVolumes(ctx context.Context, opts ...VolumeOption) ([]VolumeInfo, error
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("api error (status %d): %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp, body)
 	}
 
 	var volumes []VolumeInfo
@@ -224,7 +224,7 @@ func GetVolume(ctx context.Context, volumeID string, opts ...VolumeOption) (*Vol
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("api error (status %d): %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp, body)
 	}
 
 	var vol VolumeInfo
@@ -266,7 +266,7 @@ func DeleteVolume(ctx context.Context, volumeID string, opts ...VolumeOption) er
 
 	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("api error (status %d): %s", resp.StatusCode, string(body))
+		return newAPIError(resp, body)
 	}
 
 	return nil